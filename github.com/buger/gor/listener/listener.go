@@ -15,6 +15,12 @@ import (
 	"strconv"
 )
 
+// Separates request from original response when -capture-response enabled.
+// Followed by original response latency in nanoseconds on its own line, and then raw response.
+//
+//    <request>\n🐵🙈🙉\n<latency>\n<response>
+const ResponseSeparator = "\n🐵🙈🙉\n"
+
 // Enable debug logging only if "--verbose" flag passed
 func Debug(v ...interface{}) {
 	if Settings.Verbose {
@@ -45,7 +51,7 @@ func Run() {
 	fmt.Println("Forwarding requests to replay server:", Settings.ReplayAddress)
 
 	// Sniffing traffic from given address
-	listener := RAWTCPListen(Settings.Address, Settings.Port)
+	listener := RAWTCPListen(Settings.Address, Settings.Port, Settings.CaptureResponse)
 
 	for {
 		// Receiving TCPMessage object
@@ -81,7 +87,14 @@ func sendMessage(m *TCPMessage) {
 		}
 	}
 
-	_, err = conn.Write(m.Bytes())
+	payload := m.Bytes()
+
+	if m.Response != nil {
+		payload = append(payload, []byte(ResponseSeparator+strconv.FormatInt(int64(m.Latency), 10)+"\n")...)
+		payload = append(payload, m.Response.Bytes()...)
+	}
+
+	_, err = conn.Write(payload)
 
 	if err != nil {
 		log.Println("Error while sending requests", err)
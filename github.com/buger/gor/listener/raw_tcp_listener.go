@@ -4,8 +4,12 @@ import (
 	"encoding/binary"
 	"log"
 	"net"
+	"time"
 )
 
+// How long request waits for its response, before being sent without it
+const RESPONSE_EXPIRE = 2 * time.Second
+
 // Capture traffic from socket using RAW_SOCKET's
 // http://en.wikipedia.org/wiki/Raw_socket
 //
@@ -21,17 +25,27 @@ type RAWTCPListener struct {
 
 	c_del_message chan *TCPMessage // Used for notifications about completed or expired messages
 
+	// Used only when capturing responses: completed requests waiting for response, and responses which completed before its request
+	// Both indexed by request Ack
+	pending_requests  map[uint32]*TCPMessage
+	pending_responses map[uint32]*TCPMessage
+
 	addr string // IP to listen
 	port int    // Port to listen
+
+	captureResponse bool
 }
 
-func RAWTCPListen(addr string, port int) (listener *RAWTCPListener) {
-	listener = &RAWTCPListener{}
+func RAWTCPListen(addr string, port int, captureResponse bool) (listener *RAWTCPListener) {
+	listener = &RAWTCPListener{captureResponse: captureResponse}
 
 	listener.c_packets = make(chan *TCPPacket)
 	listener.c_messages = make(chan *TCPMessage)
 	listener.c_del_message = make(chan *TCPMessage)
 
+	listener.pending_requests = make(map[uint32]*TCPMessage)
+	listener.pending_responses = make(map[uint32]*TCPMessage)
+
 	listener.addr = addr
 	listener.port = port
 
@@ -42,16 +56,74 @@ func RAWTCPListen(addr string, port int) (listener *RAWTCPListener) {
 }
 
 func (t *RAWTCPListener) listen() {
+	sweep := time.Tick(RESPONSE_EXPIRE)
+
 	for {
 		select {
 		// If message ready for deletion it means that its also complete or expired by timeout
 		case message := <-t.c_del_message:
-			t.c_messages <- message
 			t.deleteMessage(message)
 
+			if t.captureResponse {
+				t.pairMessage(message)
+			} else {
+				t.c_messages <- message
+			}
+
 		// We need to use channgels to process each packet to avoid data races
 		case packet := <-t.c_packets:
 			t.processTCPPacket(packet)
+
+		case <-sweep:
+			t.sweepPending()
+		}
+	}
+}
+
+// Match completed request with its response
+//
+// Server responds with sequence number equal to request acknowledgment number, so response message Seq() is the request Ack
+func (t *RAWTCPListener) pairMessage(message *TCPMessage) {
+	if message.IsIncoming {
+		if response, ok := t.pending_responses[message.Ack]; ok {
+			delete(t.pending_responses, message.Ack)
+			t.sendPair(message, response)
+		} else {
+			t.pending_requests[message.Ack] = message
+		}
+	} else {
+		ack := message.Seq()
+
+		if request, ok := t.pending_requests[ack]; ok {
+			delete(t.pending_requests, ack)
+			t.sendPair(request, message)
+		} else {
+			t.pending_responses[ack] = message
+		}
+	}
+}
+
+func (t *RAWTCPListener) sendPair(request *TCPMessage, response *TCPMessage) {
+	request.Response = response
+	request.Latency = response.created.Sub(request.updated)
+
+	t.c_messages <- request
+}
+
+// Send requests which did not get response in time without it, and drop orphaned responses
+func (t *RAWTCPListener) sweepPending() {
+	now := time.Now()
+
+	for ack, request := range t.pending_requests {
+		if now.Sub(request.updated) > RESPONSE_EXPIRE {
+			delete(t.pending_requests, ack)
+			t.c_messages <- request
+		}
+	}
+
+	for ack, response := range t.pending_responses {
+		if now.Sub(response.updated) > RESPONSE_EXPIRE {
+			delete(t.pending_responses, ack)
 		}
 	}
 }
@@ -62,7 +134,7 @@ func (t *RAWTCPListener) deleteMessage(message *TCPMessage) bool {
 
 	// Searching for given message in messages buffer
 	for i, m := range t.messages {
-		if m.Ack == message.Ack {
+		if m.Ack == message.Ack && m.IsIncoming == message.IsIncoming {
 			idx = i
 			break
 		}
@@ -103,24 +175,37 @@ func (t *RAWTCPListener) readRAWSocket() {
 		if n > 0 {
 			// To avoid full packet parsing every time, we manually parsing values needed for packet filtering
 			// http://en.wikipedia.org/wiki/Transmission_Control_Protocol
+			src_port := binary.BigEndian.Uint16(buf[0:2])
 			dest_port := binary.BigEndian.Uint16(buf[2:4])
 
 			// Because RAW_SOCKET can't be bound to port, we have to control it by ourself
-			if int(dest_port) == t.port {
+			// Packets sent from given port are responses, and needed only if we capture them
+			is_incoming := int(dest_port) == t.port
+
+			if is_incoming || (t.captureResponse && int(src_port) == t.port) {
 				// Check TCPPacket code for more description
 				flags := binary.BigEndian.Uint16(buf[12:14]) & 0x1FF
 				f_psh := (flags & TCP_PSH) != 0
 
 				// We need only packets with data inside
 				// TCP PSH flag indicate that packet have data inside
-				if f_psh {
+				//
+				// Responses larger than one segment usually have PSH set only in the last segment,
+				// so for them we keep every packet with non-empty payload
+				has_data := f_psh
+				if !is_incoming {
+					data_offset := int(buf[12]&0xF0) >> 4
+					has_data = n > data_offset*4
+				}
+
+				if has_data {
 					// We should create new buffer because go slices is pointers. So buffer data shoud be immutable.
 					new_buf := make([]byte, n)
 					copy(new_buf, buf[:n])
 
 					// To avoid socket locking processing packet in new goroutine
 					go func(buf []byte) {
-						packet := NewTCPPacket(new_buf)
+						packet := NewTCPPacket(new_buf, is_incoming)
 						t.c_packets <- packet
 					}(new_buf)
 				}
@@ -132,12 +217,13 @@ func (t *RAWTCPListener) readRAWSocket() {
 // Trying to add packet to existing message or creating new message
 //
 // For TCP message unique id is Acknowledgment number (see tcp_packet.go)
+// Requests and responses have different sequence spaces, so they are matched separately
 func (t *RAWTCPListener) processTCPPacket(packet *TCPPacket) {
 	var message *TCPMessage
 
 	// Searching for message with same Ack
 	for _, msg := range t.messages {
-		if msg.Ack == packet.Ack {
+		if msg.Ack == packet.Ack && msg.IsIncoming == packet.IsIncoming {
 			message = msg
 			break
 		}
@@ -145,7 +231,7 @@ func (t *RAWTCPListener) processTCPPacket(packet *TCPPacket) {
 
 	if message == nil {
 		// We sending c_del_message channel, so message object can communicate with Listener and notify it if message completed
-		message = NewTCPMessage(packet.Ack, t.c_del_message)
+		message = NewTCPMessage(packet.Ack, packet.IsIncoming, t.c_del_message)
 
 		t.messages = append(t.messages, message)
 	}
@@ -18,6 +18,8 @@ type ListenerSettings struct {
 
 	ReplayAddress string
 
+	CaptureResponse bool
+
 	Verbose bool
 }
 
@@ -34,5 +36,7 @@ func init() {
 
 	flag.StringVar(&Settings.ReplayAddress, "r", defaultReplayAddress, "Address of replay server.")
 
+	flag.BoolVar(&Settings.CaptureResponse, "capture-response", false, "Capture original responses and send them to replay server along with requests, so replayed responses can be compared with production")
+
 	flag.BoolVar(&Settings.Verbose, "verbose", false, "Log requests")
 }
@@ -17,6 +17,14 @@ type TCPMessage struct {
 	Ack     uint32 // Message ID
 	packets []*TCPPacket
 
+	IsIncoming bool // Request sent to server, or response sent by server
+
+	Response *TCPMessage   // Original response, used only if -capture-response enabled
+	Latency  time.Duration // Time between last request packet and first response packet
+
+	created time.Time
+	updated time.Time
+
 	timer *time.Timer // Used for expire check
 
 	expired bool
@@ -27,8 +35,9 @@ type TCPMessage struct {
 	c_del_message chan *TCPMessage
 }
 
-func NewTCPMessage(Ack uint32, c_del chan *TCPMessage) (msg *TCPMessage) {
-	msg = &TCPMessage{Ack: Ack}
+func NewTCPMessage(Ack uint32, isIncoming bool, c_del chan *TCPMessage) (msg *TCPMessage) {
+	msg = &TCPMessage{Ack: Ack, IsIncoming: isIncoming}
+	msg.created = time.Now()
 
 	msg.c_packets = make(chan *TCPPacket)
 	msg.c_closing = make(chan int)
@@ -61,18 +70,29 @@ func (t *TCPMessage) Timeout() {
 
 // Sort packets in right orders and return message content
 func (t *TCPMessage) Bytes() (output []byte) {
-	mk := make([]int, len(t.packets))
+	sort.Sort(bySeq(t.packets))
 
-	i := 0
-	for k, _ := range t.packets {
-		mk[i] = k
-		i++
+	for _, pkt := range t.packets {
+		output = append(output, pkt.Data...)
 	}
 
-	sort.Ints(mk)
+	return
+}
+
+// Packets of a message ordered by sequence number, so segments received out of order are assembled in right order
+type bySeq []*TCPPacket
 
-	for _, k := range mk {
-		output = append(output, t.packets[k].Data...)
+func (s bySeq) Len() int           { return len(s) }
+func (s bySeq) Less(i, j int) bool { return s[i].Seq < s[j].Seq }
+func (s bySeq) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Sequence number of the first packet in message.
+// For response message it equals Ack of the request it belongs to.
+func (t *TCPMessage) Seq() (seq uint32) {
+	for i, pkt := range t.packets {
+		if i == 0 || pkt.Seq < seq {
+			seq = pkt.Seq
+		}
 	}
 
 	return
@@ -99,6 +119,7 @@ func (t *TCPMessage) AddPacket(packet *TCPPacket) {
 		Debug("Received packet with same sequence")
 	} else {
 		t.packets = append(t.packets, packet)
+		t.updated = time.Now()
 	}
 
 	// Reset message timeout timer
@@ -34,10 +34,12 @@ type TCPPacket struct {
 	Urgent     uint16
 
 	Data []byte
+
+	IsIncoming bool // Packet sent to listened port, or sent from it
 }
 
-func NewTCPPacket(b []byte) (p *TCPPacket) {
-	p = &TCPPacket{Data: b}
+func NewTCPPacket(b []byte, isIncoming bool) (p *TCPPacket) {
+	p = &TCPPacket{Data: b, IsIncoming: isIncoming}
 	p.ParseFast()
 
 	return p
@@ -27,14 +27,15 @@ package replay
 import (
 	"bufio"
 	"bytes"
-	"io"
+	"github.com/buger/gor/listener"
+	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
+	"strconv"
+	"time"
 )
 
-const bufSize = 4096
-
 // Enable debug logging only if "--verbose" flag passed
 func Debug(v ...interface{}) {
 	if Settings.Verbose {
@@ -50,6 +51,59 @@ func ParseRequest(data []byte) (request *http.Request, err error) {
 	return
 }
 
+// Parse request and, if listener was started with -capture-response, original response
+//
+// Payload format described in listener.ResponseSeparator
+func ParsePayload(data []byte) (request *http.Request, original *OriginalResponse, err error) {
+	parts := bytes.SplitN(data, []byte(listener.ResponseSeparator), 2)
+
+	if request, err = ParseRequest(parts[0]); err != nil || len(parts) == 1 {
+		return
+	}
+
+	// Original response needed only for comparison, so request still replayed if it broken
+	if original, err = ParseOriginalResponse(parts[1], request); err != nil {
+		Debug("Error while parsing original response", err)
+		original, err = nil, nil
+	}
+
+	return
+}
+
+func ParseOriginalResponse(data []byte, request *http.Request) (original *OriginalResponse, err error) {
+	reader := bufio.NewReader(bytes.NewBuffer(data))
+
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return
+	}
+
+	latency, err := strconv.ParseInt(line[:len(line)-1], 10, 64)
+	if err != nil {
+		return
+	}
+
+	resp, err := http.ReadResponse(reader, request)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+
+	original = &OriginalResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		BodyHash:   BodyHash(body),
+		Latency:    time.Duration(latency),
+	}
+
+	return
+}
+
 // Because its sub-program, Run acts as `main`
 // Replay server listen to UDP traffic from Listeners
 // Each request processed by RequestFactory
@@ -84,35 +138,20 @@ func Run() {
 func handleConnection(conn net.Conn, rf *RequestFactory) error {
 	defer conn.Close()
 
-	var read = true
-	var response []byte
-	var buf []byte
-
-	buf = make([]byte, bufSize)
-
-	for read {
-		n, err := conn.Read(buf)
-
-		switch err {
-		case io.EOF:
-			read = false
-		case nil:
-			response = append(response, buf[0:n]...)
-			if n < bufSize {
-				read = false
-			}
-		default:
-			read = false
-		}
+	// Listener closes connection right after writing message, and payloads with responses can be large, so read till EOF
+	payload, err := ioutil.ReadAll(conn)
+
+	if err != nil {
+		Debug("Error while reading payload", err)
 	}
 
 	go func() {
-		if request, err := ParseRequest(response); err != nil {
-			Debug("Error while parsing request", err, response)
+		if request, original, err := ParsePayload(payload); err != nil {
+			Debug("Error while parsing request", err, payload)
 		} else {
 			Debug("Adding request", request)
 
-			rf.Add(request)
+			rf.Add(request, original)
 		}
 	}()
 
@@ -1,15 +1,26 @@
 package replay
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
 )
 
+type HttpRequest struct {
+	req      *http.Request
+	original *OriginalResponse // Response captured by listener, if any
+}
+
 type HttpResponse struct {
 	host *ForwardHost
 	req  *http.Request
 	resp *http.Response
 	err  error
+
+	original *OriginalResponse
+	bodyHash string
+	elapsed  time.Duration
 }
 
 // Class for processing requests
@@ -17,12 +28,14 @@ type HttpResponse struct {
 // Basic workflow:
 //
 // 1. When request added via Add() it get pushed to `responses` chan
-// 2. handleRequest() listen for `responses` chan and decide where request should be forwarded, and apply rate-limit if needed
+// 2. handleRequest() listen for `responses` chan, drops or rewrites request according to Settings.Rules, and decide where request should be forwarded, and apply rate-limit if needed
 // 3. sendRequest() forwards request and returns response info to `responses` chan
-// 4. handleRequest() listen for `response` channel and updates stats
+// 4. handleRequest() listen for `response` channel and updates stats, and compares response with original one if listener captured it
 type RequestFactory struct {
 	c_responses chan *HttpResponse
-	c_requests  chan *http.Request
+	c_requests  chan *HttpRequest
+
+	comparator *ResponseComparator
 }
 
 // RequestFactory contstuctor
@@ -30,7 +43,8 @@ type RequestFactory struct {
 func NewRequestFactory() (factory *RequestFactory) {
 	factory = &RequestFactory{}
 	factory.c_responses = make(chan *HttpResponse)
-	factory.c_requests = make(chan *http.Request)
+	factory.c_requests = make(chan *HttpRequest)
+	factory.comparator = NewResponseComparator()
 
 	go factory.handleRequests()
 
@@ -38,7 +52,7 @@ func NewRequestFactory() (factory *RequestFactory) {
 }
 
 // Forward http request to given host
-func (f *RequestFactory) sendRequest(host *ForwardHost, request *http.Request) {
+func (f *RequestFactory) sendRequest(host *ForwardHost, request *http.Request, original *OriginalResponse) {
 	client := &http.Client{}
 
 	// Change HOST of original request
@@ -49,24 +63,46 @@ func (f *RequestFactory) sendRequest(host *ForwardHost, request *http.Request) {
 
 	Debug("Sending request:", host.Url, request)
 
+	start := time.Now()
 	resp, err := client.Do(request)
 
+	response := &HttpResponse{host: host, req: request, resp: resp, err: err, original: original}
+
 	if err == nil {
 		defer resp.Body.Close()
+
+		// Body needed only for comparison
+		if original != nil {
+			body, _ := ioutil.ReadAll(resp.Body)
+			response.bodyHash = BodyHash(body)
+		}
 	} else {
 		Debug("Request error:", err)
 	}
 
-	f.c_responses <- &HttpResponse{host, request, resp, err}
+	response.elapsed = time.Since(start)
+
+	f.c_responses <- response
 }
 
 // Handle incoming requests, and they responses
 func (f *RequestFactory) handleRequests() {
 	hosts := Settings.ForwardedHosts()
 
+	summary := time.Tick(Settings.SummaryInterval)
+
 	for {
 		select {
-		case req := <-f.c_requests:
+		case r := <-f.c_requests:
+			req := r.req
+
+			if !Settings.Rules.Allowed(req) {
+				Debug("Request filtered:", req.Method, req.URL)
+				continue
+			}
+
+			Settings.Rules.Rewrite(req)
+
 			for _, host := range hosts {
 				// Ensure that we have actual stats for given timestamp
 				host.Stat.Touch()
@@ -75,17 +111,24 @@ func (f *RequestFactory) handleRequests() {
 					// Increment Stat.Count
 					host.Stat.IncReq()
 
-					go f.sendRequest(host, req)
+					go f.sendRequest(host, req, r.original)
 				}
 			}
 		case resp := <-f.c_responses:
 			// Increment returned http code stats, and elapsed time
 			resp.host.Stat.IncResp(resp)
+
+			if resp.original != nil {
+				f.comparator.Compare(resp)
+			}
+		case <-summary:
+			f.comparator.WriteSummary()
 		}
 	}
 }
 
 // Add request to channel for further processing
-func (f *RequestFactory) Add(request *http.Request) {
-	f.c_requests <- request
+// original can be nil if listener did not capture response
+func (f *RequestFactory) Add(request *http.Request, original *OriginalResponse) {
+	f.c_requests <- &HttpRequest{request, original}
 }
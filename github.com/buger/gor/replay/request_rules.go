@@ -0,0 +1,246 @@
+package replay
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Rules applied to every request before it is forwarded.
+//
+// Filtering:
+//
+//     # Replay only GET and HEAD requests
+//     gor replay -f http://staging.server -allow-method GET -allow-method HEAD
+//
+//     # Skip anything that looks destructive
+//     gor replay -f http://staging.server -disallow-url "^/(admin|payments)/"
+//
+//     # Replay only requests with given header value
+//     gor replay -f http://staging.server -allow-header "User-Agent: Replayed"
+//
+// Rewriting:
+//
+//     # Rewrite url path using regexp and replacement, separated by the last ":"
+//     # (so the regexp may contain ":", but the replacement may not)
+//     gor replay -f http://staging.server -rewrite-url "/v1/user/([^/]+)/ping:/v2/user/$1/ping"
+//     gor replay -f http://staging.server -rewrite-url "/v1/(?:user|users)/(.*):/v2/user/$1"
+//
+//     # Override Host header, strip production cookies and inject staging auth token
+//     gor replay -f http://staging.server -host-header staging.server -strip-header Cookie -set-header "Authorization: Bearer staging"
+type RequestRules struct {
+	AllowMethods StringList
+
+	AllowURL    RegexpList
+	DisallowURL RegexpList
+
+	AllowHeaders    HeaderFilters
+	DisallowHeaders HeaderFilters
+
+	RewriteURL URLRewrites
+
+	HostHeader string
+
+	SetHeaders   Headers
+	StripHeaders StringList
+}
+
+// Check if request passes all filtering rules
+func (r *RequestRules) Allowed(req *http.Request) bool {
+	if len(r.AllowMethods) > 0 {
+		allowed := false
+
+		for _, method := range r.AllowMethods {
+			if strings.EqualFold(method, req.Method) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return false
+		}
+	}
+
+	url := req.URL.String()
+
+	for _, re := range r.AllowURL {
+		if !re.MatchString(url) {
+			return false
+		}
+	}
+
+	for _, re := range r.DisallowURL {
+		if re.MatchString(url) {
+			return false
+		}
+	}
+
+	for _, f := range r.AllowHeaders {
+		if !f.regexp.MatchString(req.Header.Get(f.name)) {
+			return false
+		}
+	}
+
+	for _, f := range r.DisallowHeaders {
+		if f.regexp.MatchString(req.Header.Get(f.name)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply url and header rewrites to request
+func (r *RequestRules) Rewrite(req *http.Request) {
+	if len(r.RewriteURL) > 0 {
+		path := req.URL.Path
+
+		for _, rw := range r.RewriteURL {
+			if rw.src.MatchString(path) {
+				path = rw.src.ReplaceAllString(path, rw.target)
+			}
+		}
+
+		req.URL.Path = path
+	}
+
+	if r.HostHeader != "" {
+		req.Host = r.HostHeader
+	}
+
+	for _, name := range r.StripHeaders {
+		req.Header.Del(name)
+	}
+
+	for _, h := range r.SetHeaders {
+		req.Header.Set(h.Name, h.Value)
+	}
+}
+
+// StringList used for flags which can be specified multiple times
+type StringList []string
+
+func (l *StringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *StringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// RegexpList used for url filtering flags
+type RegexpList []*regexp.Regexp
+
+func (l *RegexpList) String() string {
+	return ""
+}
+
+func (l *RegexpList) Set(value string) error {
+	re, err := regexp.Compile(value)
+
+	if err != nil {
+		return err
+	}
+
+	*l = append(*l, re)
+	return nil
+}
+
+type headerFilter struct {
+	name   string
+	regexp *regexp.Regexp
+}
+
+// HeaderFilters parses "Name: regexp" pairs
+type HeaderFilters []headerFilter
+
+func (l *HeaderFilters) String() string {
+	return ""
+}
+
+func (l *HeaderFilters) Set(value string) error {
+	name, expr, err := splitHeader(value)
+
+	if err != nil {
+		return err
+	}
+
+	re, err := regexp.Compile(expr)
+
+	if err != nil {
+		return err
+	}
+
+	*l = append(*l, headerFilter{name: name, regexp: re})
+	return nil
+}
+
+type urlRewrite struct {
+	src    *regexp.Regexp
+	target string
+}
+
+// URLRewrites parses "regexp:replacement" pairs. The pair is split on the
+// last ":", as regexps may contain colons themselves, e.g. "(?:a|b)".
+type URLRewrites []urlRewrite
+
+func (l *URLRewrites) String() string {
+	return ""
+}
+
+func (l *URLRewrites) Set(value string) error {
+	idx := strings.LastIndex(value, ":")
+
+	if idx == -1 {
+		return errors.New("Expected `regexp:replacement` format")
+	}
+
+	re, err := regexp.Compile(value[:idx])
+
+	if err != nil {
+		return err
+	}
+
+	*l = append(*l, urlRewrite{src: re, target: value[idx+1:]})
+	return nil
+}
+
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Headers parses "Name: value" pairs
+type Headers []Header
+
+func (l *Headers) String() string {
+	return ""
+}
+
+func (l *Headers) Set(value string) error {
+	name, val, err := splitHeader(value)
+
+	if err != nil {
+		return err
+	}
+
+	*l = append(*l, Header{Name: name, Value: val})
+	return nil
+}
+
+func splitHeader(value string) (name, val string, err error) {
+	idx := strings.LastIndex(value, ":")
+
+	if idx == -1 {
+		err = errors.New("Expected `Name: value` format")
+		return
+	}
+
+	name = strings.TrimSpace(value[:idx])
+	val = strings.TrimSpace(value[idx+1:])
+
+	return
+}
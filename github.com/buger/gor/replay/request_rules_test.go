@@ -0,0 +1,41 @@
+package replay
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestURLRewrites(t *testing.T) {
+	tests := []struct {
+		flag string
+		path string
+		want string
+	}{
+		{"/v1/user/([^/]+)/ping:/v2/user/$1/ping", "/v1/user/joe/ping", "/v2/user/joe/ping"},
+		{"/v1/(?:a|b)/(.*):/v2/$1", "/v1/b/items", "/v2/items"},
+		{"/v1/(?:a|b)/(.*):/v2/$1", "/v1/c/items", "/v1/c/items"},
+	}
+
+	for _, tt := range tests {
+		rules := &RequestRules{}
+		if err := rules.RewriteURL.Set(tt.flag); err != nil {
+			t.Errorf("Set(%q) failed: %v", tt.flag, err)
+			continue
+		}
+
+		req, _ := http.NewRequest("GET", "http://localhost"+tt.path, nil)
+		rules.Rewrite(req)
+
+		if req.URL.Path != tt.want {
+			t.Errorf("Rewrite with %q: got %q, want %q", tt.flag, req.URL.Path, tt.want)
+		}
+	}
+}
+
+func TestURLRewritesInvalid(t *testing.T) {
+	var rewrites URLRewrites
+
+	if err := rewrites.Set("/v1/(.*)"); err == nil {
+		t.Error("Expected an error for a value without separator")
+	}
+}
@@ -0,0 +1,227 @@
+package replay
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Response captured by listener on production server
+type OriginalResponse struct {
+	StatusCode int
+	Header     http.Header
+	BodyHash   string
+	Latency    time.Duration
+}
+
+func BodyHash(body []byte) string {
+	h := sha1.New()
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Comparison stats per forwarded host, since last summary
+type DiffStat struct {
+	Compared int
+	Matched  int
+	Errors   int // Replayed request failed (timeout or host not reachable)
+
+	StatusMismatch int
+	HeaderMismatch int
+	BodyMismatch   int
+
+	OriginalLatency time.Duration // Total, used to calculate average
+	ReplayedLatency time.Duration
+}
+
+// Mismatch log record. Written as one JSON object per line.
+type Mismatch struct {
+	Time   time.Time
+	Host   string
+	Method string
+	URL    string
+
+	Fields []string // e.g. ["status", "header:Content-Type", "body"]
+
+	Original ResponseSummary
+	Replayed ResponseSummary
+
+	Error string `json:",omitempty"`
+}
+
+type ResponseSummary struct {
+	StatusCode int
+	Header     map[string]string `json:",omitempty"`
+	BodyHash   string
+	Latency    time.Duration
+}
+
+// Compares replayed responses with original ones, captured by listener with -capture-response flag
+//
+// Compared are status code, headers specified by -compare-header and body hash.
+// Every mismatch written to -mismatch-log file, and summary per host logged every -summary-interval.
+//
+// Compare() and WriteSummary() should be called from the same goroutine (see RequestFactory.handleRequests)
+type ResponseComparator struct {
+	headers []string
+
+	stats map[string]*DiffStat
+
+	mismatchLog *json.Encoder
+}
+
+// ResponseComparator constructor
+func NewResponseComparator() (c *ResponseComparator) {
+	c = &ResponseComparator{headers: Settings.CompareHeaders}
+	c.stats = make(map[string]*DiffStat)
+
+	if Settings.MismatchLog != "" {
+		file, err := os.OpenFile(Settings.MismatchLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+
+		if err != nil {
+			log.Fatal("Can't open mismatch log:", err)
+		}
+
+		c.mismatchLog = json.NewEncoder(file)
+	}
+
+	return
+}
+
+func (c *ResponseComparator) stat(host string) *DiffStat {
+	stat, ok := c.stats[host]
+
+	if !ok {
+		stat = &DiffStat{}
+		c.stats[host] = stat
+	}
+
+	return stat
+}
+
+// Compare replayed response with original one and update stats
+func (c *ResponseComparator) Compare(resp *HttpResponse) {
+	stat := c.stat(resp.host.Url)
+	stat.Compared++
+
+	original := resp.original
+
+	m := &Mismatch{
+		Time:   time.Now(),
+		Host:   resp.host.Url,
+		Method: resp.req.Method,
+		URL:    resp.req.URL.RequestURI(),
+		Original: ResponseSummary{
+			StatusCode: original.StatusCode,
+			Header:     c.selectHeaders(original.Header),
+			BodyHash:   original.BodyHash,
+			Latency:    original.Latency,
+		},
+	}
+
+	if resp.err != nil {
+		stat.Errors++
+
+		m.Error = resp.err.Error()
+		c.logMismatch(m)
+		return
+	}
+
+	stat.OriginalLatency += original.Latency
+	stat.ReplayedLatency += resp.elapsed
+
+	m.Replayed = ResponseSummary{
+		StatusCode: resp.resp.StatusCode,
+		Header:     c.selectHeaders(resp.resp.Header),
+		BodyHash:   resp.bodyHash,
+		Latency:    resp.elapsed,
+	}
+
+	if original.StatusCode != resp.resp.StatusCode {
+		stat.StatusMismatch++
+		m.Fields = append(m.Fields, "status")
+	}
+
+	headerMismatch := false
+
+	for _, name := range c.headers {
+		if original.Header.Get(name) != resp.resp.Header.Get(name) {
+			headerMismatch = true
+			m.Fields = append(m.Fields, "header:"+name)
+		}
+	}
+
+	if headerMismatch {
+		stat.HeaderMismatch++
+	}
+
+	if original.BodyHash != resp.bodyHash {
+		stat.BodyMismatch++
+		m.Fields = append(m.Fields, "body")
+	}
+
+	if len(m.Fields) == 0 {
+		stat.Matched++
+	} else {
+		c.logMismatch(m)
+	}
+}
+
+func (c *ResponseComparator) selectHeaders(header http.Header) (selected map[string]string) {
+	if len(c.headers) == 0 {
+		return
+	}
+
+	selected = make(map[string]string)
+
+	for _, name := range c.headers {
+		selected[name] = header.Get(name)
+	}
+
+	return
+}
+
+func (c *ResponseComparator) logMismatch(m *Mismatch) {
+	Debug("Response mismatch:", m.Host, m.Method, m.URL, m.Fields, m.Error)
+
+	if c.mismatchLog == nil {
+		return
+	}
+
+	if err := c.mismatchLog.Encode(m); err != nil {
+		log.Println("Error while writing mismatch log", err)
+	}
+}
+
+// Log comparison summary for each host and reset stats
+func (c *ResponseComparator) WriteSummary() {
+	for host, stat := range c.stats {
+		if stat.Compared == 0 {
+			continue
+		}
+
+		var originalAvg, replayedAvg time.Duration
+
+		if replied := stat.Compared - stat.Errors; replied > 0 {
+			originalAvg = stat.OriginalLatency / time.Duration(replied)
+			replayedAvg = stat.ReplayedLatency / time.Duration(replied)
+		}
+
+		log.Println("Host:", host,
+			"Compared:", stat.Compared,
+			"Matched:", stat.Matched,
+			"Errors:", stat.Errors,
+			"Status mismatch:", stat.StatusMismatch,
+			"Header mismatch:", stat.HeaderMismatch,
+			"Body mismatch:", stat.BodyMismatch,
+			"Avg latency original:", originalAvg,
+			"replayed:", replayedAvg)
+	}
+
+	c.stats = make(map[string]*DiffStat)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ForwardHost struct {
@@ -20,6 +21,12 @@ type ReplaySettings struct {
 
 	ForwardAddress string
 
+	Rules RequestRules
+
+	CompareHeaders  StringList
+	MismatchLog     string
+	SummaryInterval time.Duration
+
 	Verbose bool
 }
 
@@ -75,5 +82,29 @@ func init() {
 
 	flag.StringVar(&Settings.ForwardAddress, "f", defaultAddress, "http address to forward traffic.\n\tYou can limit requests per second by adding `|num` after address.\n\tIf you have multiple addresses with different limits. For example: http://staging.example.com|100,http://dev.example.com|10")
 
+	flag.Var(&Settings.Rules.AllowMethods, "allow-method", "Replay only requests with given method. Can be specified multiple times: -allow-method GET -allow-method HEAD")
+
+	flag.Var(&Settings.Rules.AllowURL, "allow-url", "Replay only requests whose url matches given regexp")
+
+	flag.Var(&Settings.Rules.DisallowURL, "disallow-url", "Skip requests whose url matches given regexp")
+
+	flag.Var(&Settings.Rules.AllowHeaders, "allow-header", "Replay only requests whose header matches given regexp, in `Name: regexp` format")
+
+	flag.Var(&Settings.Rules.DisallowHeaders, "disallow-header", "Skip requests whose header matches given regexp, in `Name: regexp` format")
+
+	flag.Var(&Settings.Rules.RewriteURL, "rewrite-url", "Rewrite request url path, in `regexp:replacement` format. For example: /v1/(.*):/v2/$1")
+
+	flag.StringVar(&Settings.Rules.HostHeader, "host-header", "", "Override Host header of forwarded requests")
+
+	flag.Var(&Settings.Rules.SetHeaders, "set-header", "Inject header into forwarded requests, in `Name: value` format")
+
+	flag.Var(&Settings.Rules.StripHeaders, "strip-header", "Remove header from forwarded requests")
+
+	flag.Var(&Settings.CompareHeaders, "compare-header", "Header that should be equal in original and replayed responses. Can be specified multiple times. Works only if listener started with -capture-response")
+
+	flag.StringVar(&Settings.MismatchLog, "mismatch-log", "", "File to write responses which differ from original ones, one JSON record per line")
+
+	flag.DurationVar(&Settings.SummaryInterval, "summary-interval", 10*time.Second, "How often to log response comparison summary")
+
 	flag.BoolVar(&Settings.Verbose, "verbose", false, "Log requests")
 }
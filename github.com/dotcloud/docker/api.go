@@ -15,6 +15,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const APIVERSION = 1.3
@@ -177,6 +178,71 @@ func getContainersExport(srv *Server, version float64, w http.ResponseWriter, r
 	return nil
 }
 
+func getEvents(srv *Server, version float64, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := parseForm(r); err != nil {
+		return err
+	}
+	var since, until int64
+	if s := r.Form.Get("since"); s != "" {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Bad parameter: since")
+		}
+		since = v
+	}
+	if u := r.Form.Get("until"); u != "" {
+		v, err := strconv.ParseInt(u, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Bad parameter: until")
+		}
+		until = v
+	}
+
+	listenerName := r.RemoteAddr + ":" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	past, listener := srv.AddEventListener(listenerName, since)
+	defer srv.RemoveEventListener(listenerName)
+
+	w.Header().Set("Content-Type", "application/json")
+	out := utils.NewWriteFlusher(w)
+	sendEvent := func(event utils.JSONMessage) error {
+		b, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(b)
+		return err
+	}
+
+	for _, event := range past {
+		if until != 0 && event.Time > until {
+			return nil
+		}
+		if err := sendEvent(event); err != nil {
+			return err
+		}
+	}
+
+	var timeout <-chan time.Time
+	if until != 0 {
+		if until <= time.Now().Unix() {
+			return nil
+		}
+		timeout = time.After(time.Unix(until, 0).Sub(time.Now()))
+	}
+	for {
+		select {
+		case event := <-listener:
+			// The client has gone away if the event can't be written
+			if err := sendEvent(event); err != nil {
+				utils.Debugf("Stop sending events to %s: %s", r.RemoteAddr, err)
+				return nil
+			}
+		case <-timeout:
+			return nil
+		}
+	}
+}
+
 func getImagesJSON(srv *Server, version float64, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := parseForm(r); err != nil {
 		return err
@@ -200,6 +266,33 @@ func getImagesJSON(srv *Server, version float64, w http.ResponseWriter, r *http.
 	return nil
 }
 
+func getImagesGet(srv *Server, version float64, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if vars == nil {
+		return fmt.Errorf("Missing parameter")
+	}
+	name := vars["name"]
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	if err := srv.ImageExport(name, w); err != nil {
+		utils.Debugf("%s", err)
+		return err
+	}
+	return nil
+}
+
+func postImagesLoad(srv *Server, version float64, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	w.Header().Set("Content-Type", "application/json")
+	sf := utils.NewStreamFormatter(true)
+	if err := srv.ImageLoad(r.Body, w, sf); err != nil {
+		if sf.Used() {
+			w.Write(sf.FormatError(err))
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
 func getImagesViz(srv *Server, version float64, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := srv.ImagesViz(w); err != nil {
 		return err
@@ -850,11 +943,13 @@ func createRouter(srv *Server, logging bool) (*mux.Router, error) {
 			"/auth":                         getAuth,
 			"/version":                      getVersion,
 			"/info":                         getInfo,
+			"/events":                       getEvents,
 			"/images/json":                  getImagesJSON,
 			"/images/viz":                   getImagesViz,
 			"/images/search":                getImagesSearch,
 			"/images/{name:.*}/history":     getImagesHistory,
 			"/images/{name:.*}/json":        getImagesByName,
+			"/images/{name:.*}/get":         getImagesGet,
 			"/containers/ps":                getContainersJSON,
 			"/containers/json":              getContainersJSON,
 			"/containers/{name:.*}/export":  getContainersExport,
@@ -871,6 +966,7 @@ func createRouter(srv *Server, logging bool) (*mux.Router, error) {
 			"/images/{name:.*}/push":        postImagesPush,
 			"/images/{name:.*}/tag":         postImagesTag,
 			"/images/getCache":              postImagesGetCache,
+			"/images/load":                  postImagesLoad,
 			"/containers/create":            postContainersCreate,
 			"/containers/{name:.*}/kill":    postContainersKill,
 			"/containers/{name:.*}/restart": postContainersRestart,
@@ -40,6 +40,7 @@ type APIRmi struct {
 
 type APIContainers struct {
 	ID         string `json:"Id"`
+	Name       string `json:",omitempty"`
 	Image      string
 	Command    string
 	Created    int64
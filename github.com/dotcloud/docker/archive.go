@@ -96,6 +96,18 @@ func TarFilter(path string, compression Compression, filter []string) (io.Reader
 	return CmdStream(exec.Command(args[0], args[1:]...))
 }
 
+// TarExclude creates an archive from the directory at `path`, leaving out the files matching
+// any of the `excludes` patterns. Patterns follow the syntax of tar --exclude: shell wildcards,
+// matched against any trailing part of the file path (eg. "*.log", "tmp", "build/output").
+func TarExclude(path string, compression Compression, excludes []string) (io.Reader, error) {
+	args := []string{"tar", "--numeric-owner", "-f", "-", "-C", path}
+	for _, pattern := range excludes {
+		args = append(args, "--exclude="+pattern)
+	}
+	args = append(args, "-c"+compression.Flag(), ".")
+	return CmdStream(exec.Command(args[0], args[1:]...))
+}
+
 // Untar reads a stream of bytes from `archive`, parses it as a tar archive,
 // and unpacks it into the directory at `path`.
 // The archive may be compressed with one of the following algorithgms:
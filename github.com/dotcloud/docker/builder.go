@@ -42,6 +42,22 @@ func (builder *Builder) Create(config *Config) (*Container, error) {
 		return nil, fmt.Errorf("No command specified")
 	}
 
+	if config.Name != "" {
+		if err := validateName(config.Name); err != nil {
+			return nil, err
+		}
+		if c := builder.runtime.Get(config.Name); c != nil && c.Name == config.Name {
+			return nil, fmt.Errorf("Conflict, the name %s is already assigned to %s", config.Name, c.ShortID())
+		}
+	}
+	if err := config.RestartPolicy.Validate(); err != nil {
+		return nil, err
+	}
+	links, err := builder.runtime.resolveLinks(config.Links)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate id
 	id := GenerateID()
 	// Generate default hostname
@@ -64,6 +80,7 @@ func (builder *Builder) Create(config *Config) (*Container, error) {
 	container := &Container{
 		// FIXME: we should generate the ID here instead of receiving it as an argument
 		ID:              id,
+		Name:            config.Name,
 		Created:         time.Now(),
 		Path:            entrypoint,
 		Args:            args, //FIXME: de-duplicate from config
@@ -72,6 +89,7 @@ func (builder *Builder) Create(config *Config) (*Container, error) {
 		NetworkSettings: &NetworkSettings{},
 		// FIXME: do we need to store this in the container?
 		SysInitPath: sysInitPath,
+		Links:       links,
 	}
 	container.root = builder.runtime.containerRoot(container.ID)
 	// Step 1: create the container directory.
@@ -116,6 +134,7 @@ func (builder *Builder) Create(config *Config) (*Container, error) {
 	if err := builder.runtime.Register(container); err != nil {
 		return nil, err
 	}
+	builder.runtime.LogEvent("create", container.ShortID(), builder.repositories.ImageName(img.ID))
 	return container, nil
 }
 
@@ -2,6 +2,8 @@ package docker
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/dotcloud/docker/utils"
@@ -9,6 +11,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"strings"
 )
@@ -152,8 +155,21 @@ func (b *buildFile) CmdInsert(args string) error {
 	return fmt.Errorf("INSERT has been deprecated. Please use ADD instead")
 }
 
-func (b *buildFile) CmdCopy(args string) error {
-	return fmt.Errorf("COPY has been deprecated. Please use ADD instead")
+func (b *buildFile) CmdUser(args string) error {
+	b.config.User = args
+	return b.commit("", b.config.Cmd, fmt.Sprintf("USER %v", args))
+}
+
+func (b *buildFile) CmdWorkdir(workdir string) error {
+	if !path.IsAbs(workdir) {
+		current := b.config.WorkingDir
+		if current == "" {
+			current = "/"
+		}
+		workdir = path.Join(current, workdir)
+	}
+	b.config.WorkingDir = workdir
+	return b.commit("", b.config.Cmd, fmt.Sprintf("WORKDIR %v", workdir))
 }
 
 func (b *buildFile) CmdEntrypoint(args string) error {
@@ -194,18 +210,39 @@ func (b *buildFile) CmdVolume(args string) error {
 	return nil
 }
 
-func (b *buildFile) addRemote(container *Container, orig, dest string) error {
-	file, err := utils.Download(orig, ioutil.Discard)
+// download fetches the remote file at url into a temporary file, and returns its path
+func (b *buildFile) download(url string) (string, error) {
+	resp, err := utils.Download(url, ioutil.Discard)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	tmpFile, err := ioutil.TempFile("", "docker-build-remote")
+	if err != nil {
+		return "", err
+	}
+	defer tmpFile.Close()
+	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", err
+	}
+	return tmpFile.Name(), nil
+}
+
+func (b *buildFile) addRemote(container *Container, origPath, dest string) error {
+	file, err := os.Open(origPath)
 	if err != nil {
 		return err
 	}
-	defer file.Body.Close()
+	defer file.Close()
 
-	return container.Inject(file.Body, dest)
+	return container.Inject(file, dest)
 }
 
-func (b *buildFile) addContext(container *Container, orig, dest string) error {
-	origPath := path.Join(b.context, orig)
+// addContext copies the file or directory at origPath into the container. If decompress is set,
+// files are first tried as archives to unpack at dest.
+func (b *buildFile) addContext(container *Container, origPath, dest string, decompress bool) error {
 	destPath := path.Join(container.RootfsPath(), dest)
 	// Preserve the trailing '/'
 	if dest[len(dest)-1] == '/' {
@@ -219,35 +256,133 @@ func (b *buildFile) addContext(container *Container, orig, dest string) error {
 		if err := CopyWithTar(origPath, destPath); err != nil {
 			return err
 		}
-		// First try to unpack the source as an archive
-	} else if err := UntarPath(origPath, destPath); err != nil {
+		return nil
+	}
+	// First try to unpack the source as an archive
+	if decompress {
+		err := UntarPath(origPath, destPath)
+		if err == nil {
+			return nil
+		}
 		utils.Debugf("Couldn't untar %s to %s: %s", origPath, destPath, err)
-		// If that fails, just copy it as a regular file
-		if err := os.MkdirAll(path.Dir(destPath), 0700); err != nil {
+	}
+	// If that fails, just copy it as a regular file
+	if err := os.MkdirAll(path.Dir(destPath), 0700); err != nil {
+		return err
+	}
+	if err := CopyWithTar(origPath, destPath); err != nil {
+		return err
+	}
+	return nil
+}
+
+// hashPath returns a hash of the file or directory at root, covering the relative paths, modes
+// and contents of all its files. Modification times are left out on purpose, so that a fresh
+// checkout of unchanged sources still hits the build cache.
+func hashPath(root string) (string, error) {
+	h := sha256.New()
+	err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
 			return err
 		}
-		if err := CopyWithTar(origPath, destPath); err != nil {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
 			return err
 		}
+		fmt.Fprintf(h, "%s %o %d\n", rel, fi.Mode(), fi.Size())
+		if fi.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "-> %s\n", target)
+		} else if fi.Mode().IsRegular() {
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if _, err := io.Copy(h, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
-	return nil
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
 }
 
+// CmdAdd copies files, directories or remote urls into the image. Local archives are unpacked.
 func (b *buildFile) CmdAdd(args string) error {
+	return b.add("ADD", args)
+}
+
+// CmdCopy copies files and directories from the context into the image, as they are.
+func (b *buildFile) CmdCopy(args string) error {
+	return b.add("COPY", args)
+}
+
+// add implements ADD and COPY. The step is cached using a hash of the added content as cache key,
+// so that it is only run again if the content changed.
+func (b *buildFile) add(instruction, args string) error {
+	isAdd := instruction == "ADD"
 	if b.context == "" {
-		return fmt.Errorf("No context given. Impossible to use ADD")
+		return fmt.Errorf("No context given. Impossible to use %s", instruction)
 	}
 	tmp := strings.SplitN(args, " ", 2)
 	if len(tmp) != 2 {
-		return fmt.Errorf("Invalid ADD format")
+		return fmt.Errorf("Invalid %s format", instruction)
 	}
 	orig := strings.Trim(tmp[0], " \t")
 	dest := strings.Trim(tmp[1], " \t")
+	// Relative destinations are relative to the WORKDIR
+	if !path.IsAbs(dest) && b.config.WorkingDir != "" {
+		trailingSlash := dest[len(dest)-1] == '/'
+		dest = path.Join(b.config.WorkingDir, dest)
+		if trailingSlash {
+			dest = dest + "/"
+		}
+	}
+
+	isRemote := utils.IsURL(orig)
+	if isRemote && !isAdd {
+		return fmt.Errorf("Impossible to %s a remote url, please use ADD instead", instruction)
+	}
+
+	var origPath string
+	if isRemote {
+		tmpFile, err := b.download(orig)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmpFile)
+		origPath = tmpFile
+	} else {
+		origPath = path.Join(b.context, orig)
+	}
+	hash, err := hashPath(origPath)
+	if err != nil {
+		return err
+	}
 
 	cmd := b.config.Cmd
-	b.config.Cmd = []string{"/bin/sh", "-c", fmt.Sprintf("#(nop) ADD %s in %s", orig, dest)}
+	b.config.Cmd = []string{"/bin/sh", "-c", fmt.Sprintf("#(nop) %s %s in %s", instruction, hash, dest)}
+	defer func(cmd []string) { b.config.Cmd = cmd }(cmd)
 
 	b.config.Image = b.image
+	if cache, err := b.srv.ImageGetCached(b.image, b.config); err != nil {
+		return err
+	} else if cache != nil {
+		fmt.Fprintf(b.out, " ---> Using cache\n")
+		utils.Debugf("[BUILDER] Use cached version")
+		b.image = cache.ID
+		return nil
+	} else {
+		utils.Debugf("[BUILDER] Cache miss")
+	}
+
 	// Create the container and start it
 	container, err := b.builder.Create(b.config)
 	if err != nil {
@@ -261,20 +396,19 @@ func (b *buildFile) CmdAdd(args string) error {
 	}
 	defer container.Unmount()
 
-	if utils.IsURL(orig) {
-		if err := b.addRemote(container, orig, dest); err != nil {
+	if isRemote {
+		if err := b.addRemote(container, origPath, dest); err != nil {
 			return err
 		}
 	} else {
-		if err := b.addContext(container, orig, dest); err != nil {
+		if err := b.addContext(container, origPath, dest, isAdd); err != nil {
 			return err
 		}
 	}
 
-	if err := b.commit(container.ID, cmd, fmt.Sprintf("ADD %s in %s", orig, dest)); err != nil {
+	if err := b.commit(container.ID, cmd, fmt.Sprintf("%s %s in %s", instruction, orig, dest)); err != nil {
 		return err
 	}
-	b.config.Cmd = cmd
 	return nil
 }
 
@@ -3,7 +3,10 @@ package docker
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path"
 	"testing"
+	"time"
 )
 
 // mkTestContext generates a build context from the contents of the provided dockerfile.
@@ -99,6 +102,25 @@ CMD Hello world
 `,
 		nil,
 	},
+
+	{
+		`
+from %s
+workdir /app
+add f ./
+run [ "$(pwd)" = "/app" ]
+run [ "$(cat /app/f)" = "hello" ]
+workdir sub
+copy d dest
+run [ "$(cat /app/sub/dest/ga)" = "bu" ]
+user daemon
+run [ "$(id -un)" = "daemon" ]
+`,
+		[][2]string{
+			{"f", "hello"},
+			{"d/ga", "bu"},
+		},
+	},
 }
 
 // FIXME: test building with 2 successive overlapping ADD commands
@@ -156,3 +178,71 @@ CMD Hello world
 		}
 	}
 }
+
+func TestBuildCacheAdd(t *testing.T) {
+	runtime := mkRuntime(t)
+	defer nuke(runtime)
+
+	srv := &Server{
+		runtime:     runtime,
+		pullingPool: make(map[string]struct{}),
+		pushingPool: make(map[string]struct{}),
+	}
+
+	dockerfile := `
+from %s
+add foo /foo
+`
+	build := func(content string) string {
+		imgID, err := NewBuildFile(srv, ioutil.Discard).Build(mkTestContext(dockerfile, [][2]string{{"foo", content}}, t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return imgID
+	}
+
+	first := build("hello")
+	if second := build("hello"); second != first {
+		t.Errorf("Adding the same content should use the cache: %s != %s", second, first)
+	}
+	if third := build("world"); third == first {
+		t.Errorf("Adding different content should not use the cache")
+	}
+}
+
+func TestHashPath(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-test-hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := ioutil.WriteFile(path.Join(tmp, "foo"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := hashPath(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Touching the file doesn't change the hash
+	now := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path.Join(tmp, "foo"), now, now); err != nil {
+		t.Fatal(err)
+	}
+	if after, err := hashPath(tmp); err != nil {
+		t.Fatal(err)
+	} else if after != before {
+		t.Errorf("The hash should not depend on modification times")
+	}
+
+	// Changing the content does
+	if err := ioutil.WriteFile(path.Join(tmp, "foo"), []byte("world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if after, err := hashPath(tmp); err != nil {
+		t.Fatal(err)
+	} else if after == before {
+		t.Errorf("The hash should change with the content")
+	}
+}
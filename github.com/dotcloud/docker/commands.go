@@ -78,6 +78,7 @@ func (cli *DockerCli) CmdHelp(args ...string) error {
 		{"build", "Build a container from a Dockerfile"},
 		{"commit", "Create a new image from a container's changes"},
 		{"diff", "Inspect changes on a container's filesystem"},
+		{"events", "Get real time events from the server"},
 		{"export", "Stream the contents of a container as a tar archive"},
 		{"history", "Show the history of an image"},
 		{"images", "List images"},
@@ -86,6 +87,7 @@ func (cli *DockerCli) CmdHelp(args ...string) error {
 		{"insert", "Insert a file in an image"},
 		{"inspect", "Return low-level information on a container"},
 		{"kill", "Kill a running container"},
+		{"load", "Load an image and its tags from a tar archive"},
 		{"login", "Register or Login to the docker registry server"},
 		{"logs", "Fetch the logs of a container"},
 		{"port", "Lookup the public-facing port which is NAT-ed to PRIVATE_PORT"},
@@ -97,6 +99,7 @@ func (cli *DockerCli) CmdHelp(args ...string) error {
 		{"rm", "Remove a container"},
 		{"rmi", "Remove an image"},
 		{"run", "Run a command in a new container"},
+		{"save", "Save an image, its parent layers and tags to a tar archive"},
 		{"search", "Search for an image in the docker index"},
 		{"start", "Start a stopped container"},
 		{"stop", "Stop a running container"},
@@ -155,6 +158,29 @@ func mkBuildContext(dockerfile string, files [][2]string) (Archive, error) {
 	return buf, nil
 }
 
+// readIgnoreFile returns the exclude patterns listed in the .dockerignore file of a build context,
+// one per line. Empty lines and lines starting with '#' are skipped. A missing file is not an error.
+func readIgnoreFile(filename string) ([]string, error) {
+	content, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", filename, err)
+	}
+	var excludes []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line == "Dockerfile" {
+			return nil, fmt.Errorf("Error reading %s: the Dockerfile can't be excluded from the build context", filename)
+		}
+		excludes = append(excludes, line)
+	}
+	return excludes, nil
+}
+
 func (cli *DockerCli) CmdBuild(args ...string) error {
 	cmd := Subcmd("build", "[OPTIONS] PATH | URL | -", "Build a new container image from the source code at PATH")
 	tag := cmd.String("t", "", "Tag to be applied to the resulting image in case of success")
@@ -183,7 +209,11 @@ func (cli *DockerCli) CmdBuild(args ...string) error {
 	} else if utils.IsURL(cmd.Arg(0)) || utils.IsGIT(cmd.Arg(0)) {
 		isRemote = true
 	} else {
-		context, err = Tar(cmd.Arg(0), Uncompressed)
+		var excludes []string
+		if excludes, err = readIgnoreFile(filepath.Join(cmd.Arg(0), ".dockerignore")); err != nil {
+			return err
+		}
+		context, err = TarExclude(cmd.Arg(0), Uncompressed, excludes)
 	}
 	var body io.Reader
 	// Setup an upload progress bar
@@ -965,7 +995,7 @@ func (cli *DockerCli) CmdPs(args ...string) error {
 	}
 	w := tabwriter.NewWriter(cli.out, 20, 1, 3, ' ', 0)
 	if !*quiet {
-		fmt.Fprint(w, "ID\tIMAGE\tCOMMAND\tCREATED\tSTATUS\tPORTS")
+		fmt.Fprint(w, "ID\tIMAGE\tCOMMAND\tCREATED\tSTATUS\tPORTS\tNAME")
 		if *size {
 			fmt.Fprintln(w, "\tSIZE")
 		} else {
@@ -976,9 +1006,9 @@ func (cli *DockerCli) CmdPs(args ...string) error {
 	for _, out := range outs {
 		if !*quiet {
 			if *noTrunc {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\t%s\t", out.ID, out.Image, out.Command, utils.HumanDuration(time.Now().Sub(time.Unix(out.Created, 0))), out.Status, out.Ports)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\t%s\t%s\t", out.ID, out.Image, out.Command, utils.HumanDuration(time.Now().Sub(time.Unix(out.Created, 0))), out.Status, out.Ports, out.Name)
 			} else {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\t%s\t", utils.TruncateID(out.ID), out.Image, utils.Trunc(out.Command, 20), utils.HumanDuration(time.Now().Sub(time.Unix(out.Created, 0))), out.Status, out.Ports)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\t%s\t%s\t", utils.TruncateID(out.ID), out.Image, utils.Trunc(out.Command, 20), utils.HumanDuration(time.Now().Sub(time.Unix(out.Created, 0))), out.Status, out.Ports, out.Name)
 			}
 			if *size {
 				if out.SizeRootFs > 0 {
@@ -1046,6 +1076,33 @@ func (cli *DockerCli) CmdCommit(args ...string) error {
 	return nil
 }
 
+func (cli *DockerCli) CmdEvents(args ...string) error {
+	cmd := Subcmd("events", "[OPTIONS]", "Get real time events from the server")
+	since := cmd.String("since", "", "Show previously created events and then stream (unix timestamp)")
+	until := cmd.String("until", "", "Stop streaming at the given time (unix timestamp)")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	v := url.Values{}
+	if *since != "" {
+		v.Set("since", *since)
+	}
+	if *until != "" {
+		v.Set("until", *until)
+	}
+
+	if err := cli.stream("GET", "/events?"+v.Encode(), nil, cli.out); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (cli *DockerCli) CmdExport(args ...string) error {
 	cmd := Subcmd("export", "CONTAINER", "Export the contents of a filesystem as a tar archive")
 	if err := cmd.Parse(args); err != nil {
@@ -1063,6 +1120,40 @@ func (cli *DockerCli) CmdExport(args ...string) error {
 	return nil
 }
 
+func (cli *DockerCli) CmdSave(args ...string) error {
+	cmd := Subcmd("save", "IMAGE", "Save an image, its parent layers and tags to a tar archive (streamed to stdout)")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+
+	if cmd.NArg() != 1 {
+		cmd.Usage()
+		return nil
+	}
+
+	if err := cli.stream("GET", "/images/"+cmd.Arg(0)+"/get", nil, cli.out); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cli *DockerCli) CmdLoad(args ...string) error {
+	cmd := Subcmd("load", "< ARCHIVE", "Load an image and its tags from a tar archive created by 'docker save' (read from stdin)")
+	if err := cmd.Parse(args); err != nil {
+		return nil
+	}
+
+	if cmd.NArg() != 0 {
+		cmd.Usage()
+		return nil
+	}
+
+	if err := cli.stream("POST", "/images/load", cli.in, cli.out); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (cli *DockerCli) CmdDiff(args ...string) error {
 	cmd := Subcmd("diff", "CONTAINER", "Inspect changes on a container's filesystem")
 	if err := cmd.Parse(args); err != nil {
@@ -1482,6 +1573,12 @@ func (cli *DockerCli) stream(method, path string, in io.Reader, out io.Writer) e
 			}
 			if m.Progress != "" {
 				fmt.Fprintf(out, "%s %s\r", m.Status, m.Progress)
+			} else if m.Time != 0 {
+				if m.From != "" {
+					fmt.Fprintf(out, "[%s] %s: (from %s) %s\n", time.Unix(m.Time, 0).Format(time.RFC3339), m.ID, m.From, m.Status)
+				} else {
+					fmt.Fprintf(out, "[%s] %s: %s\n", time.Unix(m.Time, 0).Format(time.RFC3339), m.ID, m.Status)
+				}
 			} else if m.Error != "" {
 				return fmt.Errorf(m.Error)
 			} else {
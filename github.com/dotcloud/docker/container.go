@@ -14,6 +14,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -24,7 +25,8 @@ import (
 type Container struct {
 	root string
 
-	ID string
+	ID   string
+	Name string
 
 	Created time.Time
 
@@ -55,28 +57,38 @@ type Container struct {
 	// Store rw/ro in a separate structure to preserve reserve-compatibility on-disk.
 	// Easier than migrating older container configs :)
 	VolumesRW map[string]bool
+
+	// Linked containers, indexed by alias
+	Links map[string]string
+
+	manualStop   bool          // Stopped by the operator, the restart policy doesn't apply
+	restartDelay time.Duration // Current backoff delay of the restart policy
 }
 
 type Config struct {
-	Hostname     string
-	User         string
-	Memory       int64 // Memory limit (in bytes)
-	MemorySwap   int64 // Total memory usage (memory + swap); set `-1' to disable swap
-	CpuShares    int64 // CPU shares (relative weight vs. other containers)
-	AttachStdin  bool
-	AttachStdout bool
-	AttachStderr bool
-	PortSpecs    []string
-	Tty          bool // Attach standard streams to a tty, including stdin if it is not closed.
-	OpenStdin    bool // Open stdin
-	StdinOnce    bool // If true, close stdin after the 1 attached client disconnects.
-	Env          []string
-	Cmd          []string
-	Dns          []string
-	Image        string // Name of the image as it was passed by the operator (eg. could be symbolic)
-	Volumes      map[string]struct{}
-	VolumesFrom  string
-	Entrypoint   []string
+	Hostname      string
+	User          string
+	Memory        int64 // Memory limit (in bytes)
+	MemorySwap    int64 // Total memory usage (memory + swap); set `-1' to disable swap
+	CpuShares     int64 // CPU shares (relative weight vs. other containers)
+	AttachStdin   bool
+	AttachStdout  bool
+	AttachStderr  bool
+	PortSpecs     []string
+	Tty           bool // Attach standard streams to a tty, including stdin if it is not closed.
+	OpenStdin     bool // Open stdin
+	StdinOnce     bool // If true, close stdin after the 1 attached client disconnects.
+	Env           []string
+	Cmd           []string
+	Dns           []string
+	Image         string // Name of the image as it was passed by the operator (eg. could be symbolic)
+	Volumes       map[string]struct{}
+	VolumesFrom   string
+	Entrypoint    []string
+	WorkingDir    string
+	Name          string   // Optional unique name, usable in place of the container id
+	Links         []string // Containers to link to, as name:alias
+	RestartPolicy RestartPolicy
 }
 
 type HostConfig struct {
@@ -125,6 +137,13 @@ func ParseRun(args []string, capabilities *Capabilities) (*Config, *HostConfig,
 
 	flVolumesFrom := cmd.String("volumes-from", "", "Mount volumes from the specified container")
 	flEntrypoint := cmd.String("entrypoint", "", "Overwrite the default entrypoint of the image")
+	flWorkingDir := cmd.String("w", "", "Working directory inside the container")
+	flName := cmd.String("name", "", "Assign a name to the container")
+
+	var flLinks ListOpts
+	cmd.Var(&flLinks, "link", "Add a link to another container (name:alias)")
+
+	flRestart := cmd.String("restart", "no", "Restart policy when the container exits: no, on-failure[:max-retry] or always")
 
 	var flBinds ListOpts
 	cmd.Var(&flBinds, "b", "Bind mount a volume from the host (e.g. -b /host:/container)")
@@ -132,6 +151,9 @@ func ParseRun(args []string, capabilities *Capabilities) (*Config, *HostConfig,
 	if err := cmd.Parse(args); err != nil {
 		return nil, nil, cmd, err
 	}
+	if *flWorkingDir != "" && !path.IsAbs(*flWorkingDir) {
+		return nil, nil, cmd, fmt.Errorf("The working directory must be an absolute path")
+	}
 	if *flDetach && len(flAttach) > 0 {
 		return nil, nil, cmd, fmt.Errorf("Conflicting options: -a and -d")
 	}
@@ -146,6 +168,11 @@ func ParseRun(args []string, capabilities *Capabilities) (*Config, *HostConfig,
 		}
 	}
 
+	restartPolicy, err := ParseRestartPolicy(*flRestart)
+	if err != nil {
+		return nil, nil, cmd, err
+	}
+
 	// add any bind targets to the list of container volumes
 	for _, bind := range flBinds {
 		arr := strings.Split(bind, ":")
@@ -168,23 +195,27 @@ func ParseRun(args []string, capabilities *Capabilities) (*Config, *HostConfig,
 	}
 
 	config := &Config{
-		Hostname:     *flHostname,
-		PortSpecs:    flPorts,
-		User:         *flUser,
-		Tty:          *flTty,
-		OpenStdin:    *flStdin,
-		Memory:       *flMemory,
-		CpuShares:    *flCpuShares,
-		AttachStdin:  flAttach.Get("stdin"),
-		AttachStdout: flAttach.Get("stdout"),
-		AttachStderr: flAttach.Get("stderr"),
-		Env:          flEnv,
-		Cmd:          runCmd,
-		Dns:          flDns,
-		Image:        image,
-		Volumes:      flVolumes,
-		VolumesFrom:  *flVolumesFrom,
-		Entrypoint:   entrypoint,
+		Hostname:      *flHostname,
+		PortSpecs:     flPorts,
+		User:          *flUser,
+		Tty:           *flTty,
+		OpenStdin:     *flStdin,
+		Memory:        *flMemory,
+		CpuShares:     *flCpuShares,
+		AttachStdin:   flAttach.Get("stdin"),
+		AttachStdout:  flAttach.Get("stdout"),
+		AttachStderr:  flAttach.Get("stderr"),
+		Env:           flEnv,
+		Cmd:           runCmd,
+		Dns:           flDns,
+		Image:         image,
+		Volumes:       flVolumes,
+		VolumesFrom:   *flVolumesFrom,
+		Entrypoint:    entrypoint,
+		WorkingDir:    *flWorkingDir,
+		Name:          *flName,
+		Links:         flLinks,
+		RestartPolicy: restartPolicy,
 	}
 	hostConfig := &HostConfig{
 		Binds: flBinds,
@@ -500,9 +531,19 @@ func (container *Container) Start(hostConfig *HostConfig) error {
 	if container.State.Running {
 		return fmt.Errorf("The container %s is already running.", container.ID)
 	}
+	container.manualStop = false
+	linkEnv, err := container.linkEnv()
+	if err != nil {
+		return err
+	}
 	if err := container.EnsureMounted(); err != nil {
 		return err
 	}
+	if container.Config.WorkingDir != "" {
+		if err := os.MkdirAll(path.Join(container.RootfsPath(), container.Config.WorkingDir), 0755); err != nil {
+			return err
+		}
+	}
 	if err := container.allocateNetwork(); err != nil {
 		return err
 	}
@@ -619,6 +660,11 @@ func (container *Container) Start(hostConfig *HostConfig) error {
 		params = append(params, "-u", container.Config.User)
 	}
 
+	// Working directory
+	if container.Config.WorkingDir != "" {
+		params = append(params, "-w", container.Config.WorkingDir)
+	}
+
 	if container.Config.Tty {
 		params = append(params, "-e", "TERM=xterm")
 	}
@@ -629,6 +675,10 @@ func (container *Container) Start(hostConfig *HostConfig) error {
 		"-e", "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
 	)
 
+	for _, elem := range linkEnv {
+		params = append(params, "-e", elem)
+	}
+
 	for _, elem := range container.Config.Env {
 		params = append(params, "-e", elem)
 	}
@@ -647,7 +697,6 @@ func (container *Container) Start(hostConfig *HostConfig) error {
 		return err
 	}
 
-	var err error
 	if container.Config.Tty {
 		err = container.startPty()
 	} else {
@@ -666,6 +715,7 @@ func (container *Container) Start(hostConfig *HostConfig) error {
 	container.ToDisk()
 	container.SaveHostConfig(hostConfig)
 	go container.monitor()
+	container.runtime.LogEvent("start", container.ShortID(), container.runtime.repositories.ImageName(container.Image))
 	return nil
 }
 
@@ -810,11 +860,16 @@ func (container *Container) monitor() {
 	}
 
 	// Report status back
+	ranFor := time.Now().Sub(container.State.StartedAt)
 	container.State.setStopped(exitCode)
 
 	// Release the lock
 	close(container.waitLock)
 
+	container.runtime.LogEvent("die", container.ShortID(), container.runtime.repositories.ImageName(container.Image))
+
+	container.runtime.supervise(container, exitCode, ranFor)
+
 	if err := container.ToDisk(); err != nil {
 		// FIXME: there is a race condition here which causes this to fail during the unit tests.
 		// If another goroutine was waiting for Wait() to return before removing the container's root
@@ -856,6 +911,7 @@ func (container *Container) kill() error {
 func (container *Container) Kill() error {
 	container.State.Lock()
 	defer container.State.Unlock()
+	container.cancelRestart()
 	if !container.State.Running {
 		return nil
 	}
@@ -865,6 +921,7 @@ func (container *Container) Kill() error {
 func (container *Container) Stop(seconds int) error {
 	container.State.Lock()
 	defer container.State.Unlock()
+	container.cancelRestart()
 	if !container.State.Running {
 		return nil
 	}
@@ -995,6 +1052,80 @@ func (container *Container) ShortID() string {
 	return utils.TruncateID(container.ID)
 }
 
+// DisplayName returns the container name if it has one, its short id otherwise
+func (container *Container) DisplayName() string {
+	if container.Name != "" {
+		return container.Name
+	}
+	return container.ShortID()
+}
+
+// linkEnv returns the environment variables describing the linked containers.
+// A link aliased "db" to a container exposing port 5432 yields DB_NAME, DB_IP,
+// DB_PORT=tcp://<ip>:5432 (first exposed port) and DB_PORT_5432_TCP=tcp://<ip>:5432.
+// All the linked containers must be running.
+func (container *Container) linkEnv() ([]string, error) {
+	var env []string
+	for alias, id := range container.Links {
+		child := container.runtime.Get(id)
+		if child == nil {
+			return nil, fmt.Errorf("No such container: %s (linked as %s)", utils.TruncateID(id), alias)
+		}
+		if !child.State.Running {
+			return nil, fmt.Errorf("Impossible to start container %s: linked container %s is not running", container.ShortID(), child.DisplayName())
+		}
+		prefix := strings.ToUpper(strings.Replace(alias, "-", "_", -1))
+		ip := child.NetworkSettings.IPAddress
+		env = append(env,
+			fmt.Sprintf("%s_NAME=%s", prefix, child.DisplayName()),
+			fmt.Sprintf("%s_IP=%s", prefix, ip),
+		)
+
+		var ports []string
+		for _, proto := range []string{"Tcp", "Udp"} {
+			for private := range child.NetworkSettings.PortMapping[proto] {
+				ports = append(ports, fmt.Sprintf("%s/%s", private, strings.ToLower(proto)))
+			}
+		}
+		sort.Strings(ports)
+		for i, port := range ports {
+			parts := strings.Split(port, "/")
+			url := fmt.Sprintf("%s://%s:%s", parts[1], ip, parts[0])
+			if i == 0 {
+				env = append(env, fmt.Sprintf("%s_PORT=%s", prefix, url))
+			}
+			env = append(env, fmt.Sprintf("%s_PORT_%s_%s=%s", prefix, parts[0], strings.ToUpper(parts[1]), url))
+		}
+	}
+	return env, nil
+}
+
+var validContainerName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+func validateName(name string) error {
+	if !validContainerName.MatchString(name) {
+		return fmt.Errorf("Bad parameter: invalid container name %s, only [a-zA-Z0-9_.-] are allowed", name)
+	}
+	return nil
+}
+
+// parseLink splits a "name:alias" link spec. The alias defaults to the name.
+func parseLink(spec string) (name, alias string, err error) {
+	arr := strings.SplitN(spec, ":", 2)
+	name = arr[0]
+	alias = name
+	if len(arr) == 2 {
+		alias = arr[1]
+	}
+	if name == "" || alias == "" {
+		return "", "", fmt.Errorf("Bad parameter: invalid link %s, expected name:alias", spec)
+	}
+	if err := validateName(alias); err != nil {
+		return "", "", err
+	}
+	return name, alias, nil
+}
+
 func (container *Container) logPath(name string) string {
 	return path.Join(container.root, fmt.Sprintf("%s-%s.log", container.ID, name))
 }
@@ -744,6 +744,78 @@ func TestRestartStdin(t *testing.T) {
 	}
 }
 
+func TestParseRestartPolicy(t *testing.T) {
+	for spec, expected := range map[string]RestartPolicy{
+		"":             {},
+		"no":           {Name: "no"},
+		"always":       {Name: "always"},
+		"on-failure":   {Name: "on-failure"},
+		"on-failure:3": {Name: "on-failure", MaximumRetryCount: 3},
+	} {
+		policy, err := ParseRestartPolicy(spec)
+		if err != nil {
+			t.Fatalf("%s: %s", spec, err)
+		}
+		if policy != expected {
+			t.Errorf("%s: expected %v, got %v", spec, expected, policy)
+		}
+	}
+	for _, spec := range []string{"sometimes", "always:3", "on-failure:-1", "on-failure:x"} {
+		if _, err := ParseRestartPolicy(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestRestartPolicy(t *testing.T) {
+	runtime := mkRuntime(t)
+	defer nuke(runtime)
+	container, err := NewBuilder(runtime).Create(&Config{
+		Image:         GetTestImage(runtime).ID,
+		Cmd:           []string{"false"},
+		RestartPolicy: RestartPolicy{Name: "on-failure", MaximumRetryCount: 2},
+	},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer runtime.Destroy(container)
+
+	hostConfig := &HostConfig{}
+	if err := container.Start(hostConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	// 2 restarts, after 100ms and 200ms
+	setTimeout(t, "The restart policy was not applied in time", 10*time.Second, func() {
+		for {
+			container.State.Lock()
+			done := container.State.RestartCount == 2 && !container.State.Running && !container.State.Restarting
+			container.State.Unlock()
+			if done {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	})
+	if container.State.ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", container.State.ExitCode)
+	}
+
+	// Once stopped by the operator, the container isn't restarted anymore
+	container.Config.RestartPolicy = RestartPolicy{Name: "always"}
+	if err := container.Start(hostConfig); err != nil {
+		t.Fatal(err)
+	}
+	if err := container.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	if container.State.Running || container.State.Restarting {
+		t.Errorf("A killed container should not be restarted: %s", container.State.String())
+	}
+}
+
 func TestUser(t *testing.T) {
 	runtime := mkRuntime(t)
 	defer nuke(runtime)
@@ -0,0 +1,174 @@
+package docker
+
+import (
+	"fmt"
+	"github.com/dotcloud/docker/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Delay before the first automatic restart, doubled after each quick exit
+	restartMinDelay = 100 * time.Millisecond
+	restartMaxDelay = time.Minute
+	// A container running for longer than this is considered healthy again, the backoff is reset
+	restartResetAfter = 10 * time.Second
+)
+
+// RestartPolicy tells the runtime what to do when the process of a container exits.
+//
+//   - "no" (or empty): never restart the container
+//   - "on-failure": restart the container if it exits with a non-zero code, at most
+//     MaximumRetryCount times (0 means no limit)
+//   - "always": always restart the container, whatever its exit code
+//
+// A container stopped or killed by the operator is never restarted.
+type RestartPolicy struct {
+	Name              string
+	MaximumRetryCount int
+}
+
+// ParseRestartPolicy parses the command line form of a restart policy: no, always, on-failure[:max-retry]
+func ParseRestartPolicy(spec string) (RestartPolicy, error) {
+	policy := RestartPolicy{}
+	if spec == "" {
+		return policy, nil
+	}
+	parts := strings.SplitN(spec, ":", 2)
+	policy.Name = parts[0]
+	if len(parts) == 2 {
+		if policy.Name != "on-failure" {
+			return policy, fmt.Errorf("Bad parameter: maximum retry count can only be used with the on-failure restart policy")
+		}
+		count, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return policy, fmt.Errorf("Bad parameter: invalid maximum retry count %s", parts[1])
+		}
+		policy.MaximumRetryCount = count
+	}
+	return policy, policy.Validate()
+}
+
+func (policy RestartPolicy) Validate() error {
+	switch policy.Name {
+	case "", "no", "always", "on-failure":
+	default:
+		return fmt.Errorf("Bad parameter: invalid restart policy %s", policy.Name)
+	}
+	if policy.MaximumRetryCount < 0 {
+		return fmt.Errorf("Bad parameter: maximum retry count must be positive")
+	}
+	if policy.MaximumRetryCount > 0 && policy.Name != "on-failure" {
+		return fmt.Errorf("Bad parameter: maximum retry count can only be used with the on-failure restart policy")
+	}
+	return nil
+}
+
+// IsNone returns true if the policy never restarts the container
+func (policy RestartPolicy) IsNone() bool {
+	return policy.Name == "" || policy.Name == "no"
+}
+
+func (policy RestartPolicy) String() string {
+	if policy.IsNone() {
+		return "no"
+	}
+	if policy.MaximumRetryCount > 0 {
+		return fmt.Sprintf("%s:%d", policy.Name, policy.MaximumRetryCount)
+	}
+	return policy.Name
+}
+
+// shouldRestart returns true if the policy allows another restart after the process exited
+// with exitCode, given the number of automatic restarts already done
+func (policy RestartPolicy) shouldRestart(exitCode, restartCount int) bool {
+	switch policy.Name {
+	case "always":
+		return true
+	case "on-failure":
+		if exitCode == 0 {
+			return false
+		}
+		return policy.MaximumRetryCount == 0 || restartCount < policy.MaximumRetryCount
+	}
+	return false
+}
+
+// nextRestartDelay doubles the backoff delay after each quick exit, and resets it once
+// the container has been running long enough.
+func (container *Container) nextRestartDelay(ranFor time.Duration) time.Duration {
+	if ranFor >= restartResetAfter || container.restartDelay == 0 {
+		container.restartDelay = restartMinDelay
+	} else {
+		container.restartDelay *= 2
+		if container.restartDelay > restartMaxDelay {
+			container.restartDelay = restartMaxDelay
+		}
+	}
+	return container.restartDelay
+}
+
+// supervise is called once the process of a container exited. It applies the restart
+// policy of the container, scheduling a restart after the backoff delay.
+func (runtime *Runtime) supervise(container *Container, exitCode int, ranFor time.Duration) {
+	// Stopped by the operator, or already started again
+	if container.manualStop || container.State.Running {
+		return
+	}
+	policy := container.Config.RestartPolicy
+	if !policy.shouldRestart(exitCode, container.State.RestartCount) {
+		if container.State.Restarting {
+			container.State.Restarting = false
+			container.ToDisk()
+		}
+		return
+	}
+	container.State.Restarting = true
+	if err := container.ToDisk(); err != nil {
+		utils.Debugf("%s: Failed to save restarting state: %s", container.ID, err)
+	}
+	delay := container.nextRestartDelay(ranFor)
+	utils.Debugf("%s: Restarting in %s (policy %s)", container.ID, delay, policy)
+	time.AfterFunc(delay, func() { runtime.restartFromPolicy(container) })
+}
+
+func (runtime *Runtime) restartFromPolicy(container *Container) {
+	container.State.Lock()
+	restarting := container.State.Restarting && !container.State.Running
+	container.State.Unlock()
+
+	// The restart was cancelled, or the container removed in the meantime
+	if !restarting || runtime.Get(container.ID) != container {
+		return
+	}
+	container.State.RestartCount++
+	if err := container.Start(&HostConfig{}); err != nil {
+		utils.Debugf("%s: Failed to restart: %s", container.ID, err)
+		runtime.supervise(container, -1, 0)
+		return
+	}
+	runtime.LogEvent("restart", container.ShortID(), runtime.repositories.ImageName(container.Image))
+}
+
+// cancelRestart flags the container as stopped by the operator, so that its restart
+// policy doesn't apply anymore. The caller must hold the state lock.
+func (container *Container) cancelRestart() {
+	container.manualStop = true
+	if container.State.Restarting {
+		container.State.Restarting = false
+		container.ToDisk()
+	}
+}
+
+// applyRestartPolicies schedules the restart of the containers which were running or waiting
+// for a restart when the daemon stopped. It is called once all the containers are registered,
+// so that links can be resolved.
+func (runtime *Runtime) applyRestartPolicies() {
+	for _, container := range runtime.List() {
+		if container.State.Restarting && !container.State.Running {
+			utils.Debugf("%s: Re-applying restart policy %s", container.ID, container.Config.RestartPolicy)
+			runtime.supervise(container, container.State.ExitCode, 0)
+		}
+	}
+}
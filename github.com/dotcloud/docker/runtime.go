@@ -27,6 +27,7 @@ type Runtime struct {
 	graph          *Graph
 	repositories   *TagStore
 	idIndex        *utils.TruncIndex
+	names          map[string]string // container name -> container ID
 	capabilities   *Capabilities
 	kernelVersion  *utils.KernelVersionInfo
 	autoRestart    bool
@@ -60,9 +61,12 @@ func (runtime *Runtime) getContainerElement(id string) *list.Element {
 }
 
 func (runtime *Runtime) Get(name string) *Container {
-	id, err := runtime.idIndex.Get(name)
-	if err != nil {
-		return nil
+	id, exists := runtime.names[name]
+	if !exists {
+		var err error
+		if id, err = runtime.idIndex.Get(name); err != nil {
+			return nil
+		}
 	}
 	e := runtime.getContainerElement(id)
 	if e == nil {
@@ -104,6 +108,11 @@ func (runtime *Runtime) Register(container *Container) error {
 	if err := validateID(container.ID); err != nil {
 		return err
 	}
+	if container.Name != "" {
+		if id, exists := runtime.names[container.Name]; exists {
+			return fmt.Errorf("Conflict, the name %s is already assigned to %s", container.Name, utils.TruncateID(id))
+		}
+	}
 
 	// init the wait lock
 	container.waitLock = make(chan struct{})
@@ -122,6 +131,9 @@ func (runtime *Runtime) Register(container *Container) error {
 	// done
 	runtime.containers.PushBack(container)
 	runtime.idIndex.Add(container.ID)
+	if container.Name != "" {
+		runtime.names[container.Name] = container.ID
+	}
 
 	// When we actually restart, Start() do the monitoring.
 	// However, when we simply 'reattach', we have to restart a monitor
@@ -149,6 +161,8 @@ func (runtime *Runtime) Register(container *Container) error {
 			} else {
 				utils.Debugf("Marking as stopped")
 				container.State.setStopped(-127)
+				// The restart policy is re-applied once all the containers are loaded
+				container.State.Restarting = container.Config.RestartPolicy.shouldRestart(-127, container.State.RestartCount)
 				if err := container.ToDisk(); err != nil {
 					return err
 				}
@@ -167,6 +181,13 @@ func (runtime *Runtime) Register(container *Container) error {
 	return nil
 }
 
+// LogEvent forwards a lifecycle event to the server, if the runtime is attached to one
+func (runtime *Runtime) LogEvent(action, id, from string) {
+	if runtime.srv != nil {
+		runtime.srv.LogEvent(action, id, from)
+	}
+}
+
 func (runtime *Runtime) LogToDisk(src *utils.WriteBroadcaster, dst string) error {
 	log, err := os.OpenFile(dst, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
 	if err != nil {
@@ -185,6 +206,9 @@ func (runtime *Runtime) Destroy(container *Container) error {
 	if element == nil {
 		return fmt.Errorf("Container %v not found - maybe it was already destroyed?", container.ID)
 	}
+	if parents := runtime.LinkedBy(container); len(parents) > 0 {
+		return fmt.Errorf("Impossible to remove container %s: it is linked by %s", container.ShortID(), strings.Join(parents, ", "))
+	}
 
 	if err := container.Stop(3); err != nil {
 		return err
@@ -198,10 +222,14 @@ func (runtime *Runtime) Destroy(container *Container) error {
 	}
 	// Deregister the container before removing its directory, to avoid race conditions
 	runtime.idIndex.Delete(container.ID)
+	if container.Name != "" {
+		delete(runtime.names, container.Name)
+	}
 	runtime.containers.Remove(element)
 	if err := os.RemoveAll(container.root); err != nil {
 		return fmt.Errorf("Unable to remove filesystem for %v: %v", container.ID, err)
 	}
+	runtime.LogEvent("destroy", container.ShortID(), runtime.repositories.ImageName(container.Image))
 	return nil
 }
 
@@ -219,6 +247,7 @@ func (runtime *Runtime) restore() error {
 		}
 		utils.Debugf("Loaded container %v", container.ID)
 	}
+	runtime.applyRestartPolicies()
 	return nil
 }
 
@@ -243,6 +272,43 @@ func (runtime *Runtime) UpdateCapabilities(quiet bool) {
 	}
 }
 
+// LinkedBy returns the names (or short IDs) of the containers linking to the given container
+func (runtime *Runtime) LinkedBy(container *Container) []string {
+	var parents []string
+	for _, c := range runtime.List() {
+		for _, id := range c.Links {
+			if id == container.ID {
+				parents = append(parents, c.DisplayName())
+				break
+			}
+		}
+	}
+	return parents
+}
+
+// resolveLinks turns a list of "name:alias" link specs into an alias -> container ID map
+func (runtime *Runtime) resolveLinks(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	links := make(map[string]string)
+	for _, spec := range specs {
+		name, alias, err := parseLink(spec)
+		if err != nil {
+			return nil, err
+		}
+		child := runtime.Get(name)
+		if child == nil {
+			return nil, fmt.Errorf("No such container: %s", name)
+		}
+		if _, exists := links[alias]; exists {
+			return nil, fmt.Errorf("Conflict, the alias %s is used by more than one link", alias)
+		}
+		links[alias] = child.ID
+	}
+	return links, nil
+}
+
 // FIXME: harmonize with NewGraph()
 func NewRuntime(flGraphPath string, autoRestart bool, dns []string) (*Runtime, error) {
 	runtime, err := NewRuntimeFromDirectory(flGraphPath, autoRestart)
@@ -297,6 +363,7 @@ func NewRuntimeFromDirectory(root string, autoRestart bool) (*Runtime, error) {
 		graph:          g,
 		repositories:   repositories,
 		idIndex:        utils.NewTruncIndex(),
+		names:          make(map[string]string),
 		capabilities:   &Capabilities{},
 		autoRestart:    autoRestart,
 		volumes:        volumes,
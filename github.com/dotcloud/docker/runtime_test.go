@@ -394,6 +394,87 @@ func TestAllocateTCPPortLocalhost(t *testing.T) {
 	t.Fatal("No reply from the container")
 }
 
+func TestNamesAndLinks(t *testing.T) {
+	runtime := mkRuntime(t)
+	defer nuke(runtime)
+
+	builder := NewBuilder(runtime)
+
+	db, err := builder.Create(&Config{
+		Image:     GetTestImage(runtime).ID,
+		Cmd:       []string{"cat"},
+		OpenStdin: true,
+		PortSpecs: []string{"5432"},
+		Name:      "db",
+	},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer runtime.Destroy(db)
+
+	if runtime.Get("db") != db {
+		t.Fatalf("Get(db) returned %v while expecting %v", runtime.Get("db"), db)
+	}
+
+	if _, err := builder.Create(&Config{
+		Image: GetTestImage(runtime).ID,
+		Cmd:   []string{"ls", "-al"},
+		Name:  "db",
+	},
+	); err == nil {
+		t.Fatal("Creating a container with an existing name should fail")
+	}
+
+	web, err := builder.Create(&Config{
+		Image: GetTestImage(runtime).ID,
+		Cmd:   []string{"env"},
+		Links: []string{"db:database"},
+	},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if web.Links["database"] != db.ID {
+		t.Fatalf("Expected link database -> %s, got %v", db.ID, web.Links)
+	}
+
+	// The linked container is not running yet
+	if err := web.Start(&HostConfig{}); err == nil {
+		t.Fatal("Starting a container linked to a stopped container should fail")
+	}
+
+	hostConfig := &HostConfig{}
+	if err := db.Start(hostConfig); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Kill()
+
+	output, err := web.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"DATABASE_NAME=db",
+		"DATABASE_IP=" + db.NetworkSettings.IPAddress,
+		"DATABASE_PORT_5432_TCP=tcp://" + db.NetworkSettings.IPAddress + ":5432",
+	} {
+		if !strings.Contains(string(output), expected) {
+			t.Errorf("Expected %s in the environment, got %s", expected, output)
+		}
+	}
+
+	if err := runtime.Destroy(db); err == nil {
+		t.Fatal("Destroying a linked container should fail")
+	}
+	if err := runtime.Destroy(web); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.Get("db") != db {
+		t.Fatal("The db container should still be registered")
+	}
+}
+
 // Run a container with an UDP port allocated, and test that it can receive connections on localhost
 func TestAllocateUDPPortLocalhost(t *testing.T) {
 	runtime, container, port := startEchoServerContainer(t, "udp")
@@ -2,6 +2,7 @@ package docker
 
 import (
 	"bufio"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/dotcloud/docker/auth"
@@ -18,6 +19,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 )
 
 func (srv *Server) DockerVersion() APIVersion {
@@ -33,6 +35,7 @@ func (srv *Server) ContainerKill(name string) error {
 		if err := container.Kill(); err != nil {
 			return fmt.Errorf("Error restarting container %s: %s", name, err)
 		}
+		srv.LogEvent("kill", container.ShortID(), srv.runtime.repositories.ImageName(container.Image))
 	} else {
 		return fmt.Errorf("No such container: %s", name)
 	}
@@ -296,7 +299,7 @@ func (srv *Server) Containers(all, size bool, n int, since, before string) []API
 	retContainers := []APIContainers{}
 
 	for _, container := range srv.runtime.List() {
-		if !container.State.Running && !all && n == -1 && since == "" && before == "" {
+		if !container.State.Running && !container.State.Restarting && !all && n == -1 && since == "" && before == "" {
 			continue
 		}
 		if before != "" {
@@ -317,7 +320,8 @@ func (srv *Server) Containers(all, size bool, n int, since, before string) []API
 		displayed++
 
 		c := APIContainers{
-			ID: container.ID,
+			ID:   container.ID,
+			Name: container.Name,
 		}
 		c.Image = srv.runtime.repositories.ImageName(container.Image)
 		c.Command = fmt.Sprintf("%s %s", container.Path, strings.Join(container.Args, " "))
@@ -532,9 +536,8 @@ func (srv *Server) ImagePull(localName string, tag string, out io.Writer, sf *ut
 		if err := srv.pullImage(r, out, remoteName, endpoint, nil, sf); err != nil {
 			return err
 		}
-		return nil
 	}
-
+	srv.LogEvent("pull", localName, "")
 	return nil
 }
 
@@ -788,6 +791,202 @@ func (srv *Server) ImageImport(src, repo, tag string, in io.Reader, out io.Write
 	return nil
 }
 
+// ImageExport writes to `out` a tar archive of the image `name` and all its parents,
+// along with the repository tags referring to them.
+// If `name` is a repository, all its tags are exported.
+//
+// The archive contains, for each layer, a directory named after the image id holding
+// the image metadata (json), the filesystem layer (layer.tar) and the version of the
+// archive format (VERSION), and a `repositories` file at the root, in the same format
+// as the TagStore.
+func (srv *Server) ImageExport(name string, out io.Writer) error {
+	tempdir, err := ioutil.TempDir("", "docker-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempdir)
+
+	repositories := make(map[string]Repository)
+	var heads []string
+
+	if repo, err := srv.runtime.repositories.Get(name); err != nil {
+		return err
+	} else if repo != nil {
+		repositories[name] = repo
+		for _, id := range repo {
+			heads = append(heads, id)
+		}
+	} else {
+		img, err := srv.runtime.repositories.LookupImage(name)
+		if err != nil {
+			return fmt.Errorf("No such image: %s", name)
+		}
+		repoName, tag := utils.ParseRepositoryTag(name)
+		if tag == "" {
+			tag = DEFAULTTAG
+		}
+		if id, exists := srv.runtime.repositories.Repositories[repoName][tag]; exists && id == img.ID {
+			repositories[repoName] = Repository{tag: img.ID}
+		}
+		heads = append(heads, img.ID)
+	}
+
+	exported := make(map[string]struct{})
+	for _, id := range heads {
+		img, err := srv.runtime.graph.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := img.WalkHistory(func(img *Image) error {
+			if _, exists := exported[img.ID]; exists {
+				return nil
+			}
+			exported[img.ID] = struct{}{}
+			return srv.exportImage(img, tempdir)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(repositories) > 0 {
+		reposJSON, err := json.Marshal(repositories)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path.Join(tempdir, "repositories"), reposJSON, 0600); err != nil {
+			return err
+		}
+	}
+
+	archive, err := Tar(tempdir, Uncompressed)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, archive); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (srv *Server) exportImage(img *Image, tempdir string) error {
+	utils.Debugf("Exporting image %s", img.ID)
+	root, err := img.root()
+	if err != nil {
+		return err
+	}
+	dir := path.Join(tempdir, img.ID)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(dir, "VERSION"), []byte("1.0"), 0644); err != nil {
+		return err
+	}
+	jsonData, err := ioutil.ReadFile(jsonPath(root))
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path.Join(dir, "json"), jsonData, 0644); err != nil {
+		return err
+	}
+	layer, err := img.TarLayer(Uncompressed)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path.Join(dir, "layer.tar"), os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, layer); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ImageLoad reads an archive produced by ImageExport from `in`, registers every image
+// which doesn't exist yet in the graph, keeping its original id, and restores the tags.
+func (srv *Server) ImageLoad(in io.Reader, out io.Writer, sf *utils.StreamFormatter) error {
+	tempdir, err := ioutil.TempDir("", "docker-load-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempdir)
+
+	if err := Untar(in, tempdir); err != nil {
+		return err
+	}
+
+	dirs, err := ioutil.ReadDir(tempdir)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirs {
+		if d.IsDir() {
+			if err := srv.loadImage(tempdir, d.Name(), out, sf); err != nil {
+				return err
+			}
+		}
+	}
+
+	reposJSON, err := ioutil.ReadFile(path.Join(tempdir, "repositories"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	repositories := make(map[string]Repository)
+	if err := json.Unmarshal(reposJSON, &repositories); err != nil {
+		return err
+	}
+	for repoName, repo := range repositories {
+		for tag, id := range repo {
+			if err := srv.runtime.repositories.Set(repoName, tag, id, true); err != nil {
+				return err
+			}
+			out.Write(sf.FormatStatus("Tagged %s as %s:%s", utils.TruncateID(id), repoName, tag))
+		}
+	}
+	return nil
+}
+
+// loadImage registers the image `id` stored in `tempdir`, after its parents.
+func (srv *Server) loadImage(tempdir, id string, out io.Writer, sf *utils.StreamFormatter) error {
+	if srv.runtime.graph.Exists(id) {
+		return nil
+	}
+	jsonData, err := ioutil.ReadFile(path.Join(tempdir, id, "json"))
+	if err != nil {
+		return fmt.Errorf("Failed to read image %s metadata: %s", id, err)
+	}
+	img, err := NewImgJSON(jsonData)
+	if err != nil {
+		return fmt.Errorf("Failed to parse json: %s", err)
+	}
+	if img.ID != id {
+		return fmt.Errorf("Image stored at '%s' has wrong id '%s'", id, img.ID)
+	}
+	if img.Parent != "" && !srv.runtime.graph.Exists(img.Parent) {
+		if err := srv.loadImage(tempdir, img.Parent, out, sf); err != nil {
+			return err
+		}
+	}
+	layer, err := os.Open(path.Join(tempdir, id, "layer.tar"))
+	if err != nil {
+		return fmt.Errorf("Failed to read image %s layer: %s", id, err)
+	}
+	defer layer.Close()
+
+	out.Write(sf.FormatStatus("Loading %s", utils.TruncateID(id)))
+	// The size is computed again when the layer is stored
+	img.Size = 0
+	if err := srv.runtime.graph.Register(layer, false, img); err != nil {
+		return err
+	}
+	srv.LogEvent("load", img.ShortID(), "")
+	return nil
+}
+
 func (srv *Server) ContainerCreate(config *Config) (string, error) {
 
 	if config.Memory != 0 && config.Memory < 524288 {
@@ -814,9 +1013,11 @@ func (srv *Server) ContainerCreate(config *Config) (string, error) {
 
 func (srv *Server) ContainerRestart(name string, t int) error {
 	if container := srv.runtime.Get(name); container != nil {
+		container.State.RestartCount = 0
 		if err := container.Restart(t); err != nil {
 			return fmt.Errorf("Error restarting container %s: %s", name, err)
 		}
+		srv.LogEvent("restart", container.ShortID(), srv.runtime.repositories.ImageName(container.Image))
 	} else {
 		return fmt.Errorf("No such container: %s", name)
 	}
@@ -903,6 +1104,7 @@ func (srv *Server) deleteImageAndChildren(id string, imgs *[]APIRmi) error {
 			return err
 		}
 		*imgs = append(*imgs, APIRmi{Deleted: utils.TruncateID(id)})
+		srv.LogEvent("delete", utils.TruncateID(id), "")
 		return nil
 	}
 	return nil
@@ -932,6 +1134,7 @@ func (srv *Server) deleteImage(img *Image, repoName, tag string) ([]APIRmi, erro
 	}
 	if tagDeleted {
 		imgs = append(imgs, APIRmi{Untagged: img.ShortID()})
+		srv.LogEvent("untag", img.ShortID(), "")
 	}
 	if len(srv.runtime.repositories.ByID()[img.ID]) == 0 {
 		if err := srv.deleteImageAndChildren(img.ID, &imgs); err != nil {
@@ -1001,6 +1204,7 @@ func (srv *Server) ImageGetCached(imgID string, config *Config) (*Image, error)
 
 func (srv *Server) ContainerStart(name string, hostConfig *HostConfig) error {
 	if container := srv.runtime.Get(name); container != nil {
+		container.State.RestartCount = 0
 		if err := container.Start(hostConfig); err != nil {
 			return fmt.Errorf("Error starting container %s: %s", name, err)
 		}
@@ -1015,6 +1219,7 @@ func (srv *Server) ContainerStop(name string, t int) error {
 		if err := container.Stop(t); err != nil {
 			return fmt.Errorf("Error stopping container %s: %s", name, err)
 		}
+		srv.LogEvent("stop", container.ShortID(), srv.runtime.repositories.ImageName(container.Image))
 	} else {
 		return fmt.Errorf("No such container: %s", name)
 	}
@@ -1132,10 +1337,68 @@ func NewServer(flGraphPath string, autoRestart, enableCors bool, dns ListOpts) (
 	return srv, nil
 }
 
+// Number of past events kept in memory, replayed to new listeners of /events
+const eventsBufferSize = 64
+
 type Server struct {
 	sync.Mutex
 	runtime     *Runtime
 	enableCors  bool
 	pullingPool map[string]struct{}
 	pushingPool map[string]struct{}
+	events      []utils.JSONMessage
+	listeners   map[string]chan utils.JSONMessage
+}
+
+// LogEvent records a lifecycle event of the container or image `id`, and
+// broadcasts it to all the listeners of /events.
+// Listeners which are too slow to consume their events miss them.
+func (srv *Server) LogEvent(action, id, from string) {
+	event := utils.JSONMessage{Status: action, ID: id, From: from, Time: time.Now().Unix()}
+
+	srv.Lock()
+	defer srv.Unlock()
+
+	if len(srv.events) == eventsBufferSize {
+		copy(srv.events, srv.events[1:])
+		srv.events[len(srv.events)-1] = event
+	} else {
+		srv.events = append(srv.events, event)
+	}
+	for _, listener := range srv.listeners {
+		select {
+		case listener <- event:
+		default:
+		}
+	}
+}
+
+// AddEventListener returns the events recorded since `since` (unix timestamp),
+// and a channel receiving all the events logged from now on. Past events are
+// returned only if `since` is not 0.
+// The channel must be released with RemoveEventListener.
+func (srv *Server) AddEventListener(name string, since int64) ([]utils.JSONMessage, chan utils.JSONMessage) {
+	srv.Lock()
+	defer srv.Unlock()
+
+	var past []utils.JSONMessage
+	if since != 0 {
+		for _, event := range srv.events {
+			if event.Time >= since {
+				past = append(past, event)
+			}
+		}
+	}
+	if srv.listeners == nil {
+		srv.listeners = make(map[string]chan utils.JSONMessage)
+	}
+	listener := make(chan utils.JSONMessage, eventsBufferSize)
+	srv.listeners[name] = listener
+	return past, listener
+}
+
+func (srv *Server) RemoveEventListener(name string) {
+	srv.Lock()
+	defer srv.Unlock()
+	delete(srv.listeners, name)
 }
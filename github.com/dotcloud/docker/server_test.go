@@ -1,7 +1,12 @@
 package docker
 
 import (
+	"bytes"
+	"fmt"
+	"github.com/dotcloud/docker/utils"
+	"io/ioutil"
 	"testing"
+	"time"
 )
 
 func TestContainerTagImageDelete(t *testing.T) {
@@ -163,3 +168,98 @@ func TestRunWithTooLowMemoryLimit(t *testing.T) {
 	}
 
 }
+
+func TestImageExportLoad(t *testing.T) {
+	runtime := mkRuntime(t)
+	defer nuke(runtime)
+
+	srv := &Server{runtime: runtime}
+
+	archive, err := fakeTar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := &Image{
+		ID:      GenerateID(),
+		Parent:  unitTestImageID,
+		Comment: "Test save/load",
+	}
+	if err := runtime.graph.Register(archive, false, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := runtime.repositories.Set("utest", "save", img.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := srv.ImageExport("utest:save", buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := srv.ImageDelete("utest:save", true); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.graph.Exists(img.ID) {
+		t.Fatalf("Image %s should have been deleted", img.ID)
+	}
+
+	if err := srv.ImageLoad(buf, ioutil.Discard, utils.NewStreamFormatter(false)); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := runtime.repositories.LookupImage("utest:save")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ID != img.ID {
+		t.Errorf("Expected loaded image id %s, got %s", img.ID, loaded.ID)
+	}
+	if loaded.Parent != unitTestImageID {
+		t.Errorf("Expected loaded image parent %s, got %s", unitTestImageID, loaded.Parent)
+	}
+	if loaded.Comment != img.Comment {
+		t.Errorf("Expected loaded image comment %q, got %q", img.Comment, loaded.Comment)
+	}
+}
+
+func TestLogEvent(t *testing.T) {
+	srv := &Server{}
+
+	for i := 0; i < eventsBufferSize+10; i++ {
+		srv.LogEvent("fakeaction", fmt.Sprintf("fakeid%d", i), "fakeimage")
+	}
+	if len(srv.events) != eventsBufferSize {
+		t.Fatalf("Expected %d events in buffer, found %d", eventsBufferSize, len(srv.events))
+	}
+	if srv.events[0].ID != "fakeid10" {
+		t.Errorf("Expected oldest event to be fakeid10, found %s", srv.events[0].ID)
+	}
+
+	past, listener := srv.AddEventListener("test", 0)
+	defer srv.RemoveEventListener("test")
+	if len(past) != 0 {
+		t.Errorf("Expected no past events without since, found %d", len(past))
+	}
+
+	past, _ = srv.AddEventListener("past", 1)
+	defer srv.RemoveEventListener("past")
+	if len(past) != eventsBufferSize {
+		t.Errorf("Expected %d past events, found %d", eventsBufferSize, len(past))
+	}
+
+	srv.LogEvent("die", "fakeid", "fakeimage")
+	select {
+	case event := <-listener:
+		if event.Status != "die" || event.ID != "fakeid" || event.From != "fakeimage" {
+			t.Errorf("Unexpected event %v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for event")
+	}
+
+	past, _ = srv.AddEventListener("future", time.Now().Unix()+10)
+	defer srv.RemoveEventListener("future")
+	if len(past) != 0 {
+		t.Errorf("Expected no past events, found %d", len(past))
+	}
+}
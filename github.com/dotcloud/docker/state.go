@@ -14,6 +14,10 @@ type State struct {
 	ExitCode  int
 	StartedAt time.Time
 	Ghost     bool
+
+	// Restarting is set while waiting for an automatic restart, see RestartPolicy
+	Restarting   bool
+	RestartCount int // Automatic restarts since the container was last started by the operator
 }
 
 // String returns a human-readable description of the state
@@ -22,14 +26,21 @@ func (s *State) String() string {
 		if s.Ghost {
 			return fmt.Sprintf("Ghost")
 		}
+		if s.RestartCount > 0 {
+			return fmt.Sprintf("Up %s (restarted %d times)", utils.HumanDuration(time.Now().Sub(s.StartedAt)), s.RestartCount)
+		}
 		return fmt.Sprintf("Up %s", utils.HumanDuration(time.Now().Sub(s.StartedAt)))
 	}
+	if s.Restarting {
+		return fmt.Sprintf("Restarting (Exit %d)", s.ExitCode)
+	}
 	return fmt.Sprintf("Exit %d", s.ExitCode)
 }
 
 func (s *State) setRunning(pid int) {
 	s.Running = true
 	s.Ghost = false
+	s.Restarting = false
 	s.ExitCode = 0
 	s.Pid = pid
 	s.StartedAt = time.Now()
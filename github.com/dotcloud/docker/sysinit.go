@@ -22,6 +22,16 @@ func setupNetworking(gw string) {
 	}
 }
 
+// Move to the working directory of the container
+func changeDir(dir string) {
+	if dir == "" {
+		return
+	}
+	if err := os.Chdir(dir); err != nil {
+		log.Fatalf("Unable to change to working directory %v: %v", dir, err)
+	}
+}
+
 // Takes care of dropping privileges to the desired user
 func changeUser(u string) {
 	if u == "" {
@@ -86,6 +96,7 @@ func SysInit() {
 	}
 	var u = flag.String("u", "", "username or uid")
 	var gw = flag.String("g", "", "gateway address")
+	var workdir = flag.String("w", "", "working directory")
 
 	var flEnv ListOpts
 	flag.Var(&flEnv, "e", "Set environment variables")
@@ -94,6 +105,7 @@ func SysInit() {
 
 	cleanupEnv(flEnv)
 	setupNetworking(*gw)
+	changeDir(*workdir)
 	changeUser(*u)
 	executeProgram(flag.Arg(0), flag.Args())
 }
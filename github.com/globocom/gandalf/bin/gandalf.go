@@ -103,7 +103,9 @@ func validateCmd() error {
 // Executes the SSH_ORIGINAL_COMMAND based on the condition
 // defined by the `f` parameter.
 // Also receives a custom error message to print to the end user and a
-// stdout object, where the SSH_ORIGINAL_COMMAND output is going to be written
+// stdout object, where the SSH_ORIGINAL_COMMAND output is going to be written.
+// The name of the user is exported to the command, and so to the
+// repository hooks, in the GANDALF_USER environment variable.
 func executeAction(f func(*user.User, *repository.Repository) bool, errMsg string, stdout io.Writer) {
 	var u user.User
 	if err := db.Session.User().Find(bson.M{"_id": os.Args[1]}).One(&u); err != nil {
@@ -126,6 +128,7 @@ func executeAction(f func(*user.User, *repository.Repository) bool, errMsg strin
 		}
 		log.Info("Executing " + strings.Join(c, " "))
 		cmd := exec.Command(c[0], c[1:]...)
+		cmd.Env = append(os.Environ(), "GANDALF_USER="+u.Name)
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = stdout
 		stderr := &bytes.Buffer{}
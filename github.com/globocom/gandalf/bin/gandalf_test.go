@@ -192,6 +192,21 @@ func (s *S) TestExecuteActionShouldExecuteGitReceivePackWhenUserHasWritePermissi
 	c.Assert(stdout.String(), gocheck.Equals, expected)
 }
 
+func (s *S) TestExecuteActionShouldExportTheUserName(c *gocheck.C) {
+	dir, err := commandmocker.Add("git-receive-pack", "$GANDALF_USER")
+	c.Check(err, gocheck.IsNil)
+	defer commandmocker.Remove(dir)
+	os.Args = []string{"gandalf", s.user.Name}
+	os.Setenv("SSH_ORIGINAL_COMMAND", "git-receive-pack 'myapp.git'")
+	defer func() {
+		os.Args = []string{}
+		os.Setenv("SSH_ORIGINAL_COMMAND", "")
+	}()
+	stdout := &bytes.Buffer{}
+	executeAction(hasWritePermission, "You don't have access to write in this repository.", stdout)
+	c.Assert(stdout.String(), gocheck.Equals, s.user.Name)
+}
+
 func (s *S) TestExecuteActionShouldNotCallSSH_ORIGINAL_COMMANDWhenUserDoesNotExists(c *gocheck.C) {
 	dir, err := commandmocker.Add("git-receive-pack", "$*")
 	c.Check(err, gocheck.IsNil)
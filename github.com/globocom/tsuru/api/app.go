@@ -52,8 +52,60 @@ func cloneRepository(w http.ResponseWriter, r *http.Request, t *auth.Token) erro
 	if err != nil {
 		return &errors.HTTP{Code: http.StatusNotFound, Message: fmt.Sprintf("App %s not found.", instance.Name)}
 	}
+	// The post-receive hook sends the user who pushed, as given by the git
+	// server. Otherwise, the deploy is recorded with the token's user.
+	user := r.PostFormValue("user")
+	if user == "" {
+		user = t.UserEmail
+	}
 	logger := app.LogWriter{App: instance, Writer: w}
-	return app.Provisioner.Deploy(instance, version, &logger)
+	return instance.Deploy(version, user, &logger)
+}
+
+func deployList(w http.ResponseWriter, r *http.Request, t *auth.Token) error {
+	u, err := t.User()
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	rec.Log(u.Email, "deploy-list", appName)
+	instance, err := getApp(appName, u)
+	if err != nil {
+		return err
+	}
+	deploys, err := instance.Deploys()
+	if err != nil {
+		return err
+	}
+	if len(deploys) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return json.NewEncoder(w).Encode(deploys)
+}
+
+func rollback(w http.ResponseWriter, r *http.Request, t *auth.Token) error {
+	version := r.PostFormValue("version")
+	if version == "" {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Missing parameter version"}
+	}
+	u, err := t.User()
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	rec.Log(u.Email, "rollback", "app="+appName, "version="+version)
+	instance, err := getApp(appName, u)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text")
+	logger := app.LogWriter{App: &instance, Writer: w}
+	err = instance.Rollback(version, u.Email, &logger)
+	if e, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: e.Message}
+	}
+	return err
 }
 
 func appIsAvailable(w http.ResponseWriter, r *http.Request, t *auth.Token) error {
@@ -0,0 +1,108 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"github.com/globocom/tsuru/app"
+	"github.com/globocom/tsuru/auth"
+	"github.com/globocom/tsuru/errors"
+	"github.com/globocom/tsuru/rec"
+	"net/http"
+)
+
+func getAutoScale(w http.ResponseWriter, r *http.Request, t *auth.Token) error {
+	u, err := t.User()
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	rec.Log(u.Email, "get-autoscale", appName)
+	instance, err := getApp(appName, u)
+	if err != nil {
+		return err
+	}
+	if instance.AutoScale == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return json.NewEncoder(w).Encode(instance.AutoScale)
+}
+
+func setAutoScale(w http.ResponseWriter, r *http.Request, t *auth.Token) error {
+	var policy app.AutoScale
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid autoscale policy"}
+	}
+	u, err := t.User()
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	rec.Log(u.Email, "set-autoscale", "app="+appName, "metric="+policy.Metric)
+	instance, err := getApp(appName, u)
+	if err != nil {
+		return err
+	}
+	err = instance.SetAutoScale(&policy)
+	if e, ok := err.(*errors.ValidationError); ok {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: e.Message}
+	}
+	return err
+}
+
+func unsetAutoScale(w http.ResponseWriter, r *http.Request, t *auth.Token) error {
+	u, err := t.User()
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	rec.Log(u.Email, "unset-autoscale", appName)
+	instance, err := getApp(appName, u)
+	if err != nil {
+		return err
+	}
+	return instance.SetAutoScale(nil)
+}
+
+func autoScaleEvents(w http.ResponseWriter, r *http.Request, t *auth.Token) error {
+	u, err := t.User()
+	if err != nil {
+		return err
+	}
+	appName := r.URL.Query().Get(":app")
+	rec.Log(u.Email, "autoscale-events", appName)
+	instance, err := getApp(appName, u)
+	if err != nil {
+		return err
+	}
+	events, err := instance.ScaleEvents()
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+	return json.NewEncoder(w).Encode(events)
+}
+
+// addMetrics receives samples of metrics of the units of an app, reported by
+// the units themselves or by a monitoring service. They are used by the
+// collector to apply the autoscale policy of the app.
+func addMetrics(w http.ResponseWriter, r *http.Request, t *auth.Token) error {
+	instance := app.App{Name: r.URL.Query().Get(":app")}
+	err := instance.Get()
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	var metrics []app.Metric
+	if err := json.NewDecoder(r.Body).Decode(&metrics); err != nil {
+		return &errors.HTTP{Code: http.StatusBadRequest, Message: "Invalid metrics"}
+	}
+	return instance.AddMetrics(metrics)
+}
@@ -0,0 +1,217 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/globocom/tsuru/app"
+	"github.com/globocom/tsuru/errors"
+	"github.com/globocom/tsuru/testing"
+	"labix.org/v2/mgo/bson"
+	"launchpad.net/gocheck"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func (s *S) TestGetAutoScale(c *gocheck.C) {
+	policy := &app.AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 4, ScaleUp: 80, ScaleDown: 20, Cooldown: 300}
+	a := app.App{Name: "stress", Teams: []string{s.team.Name}, AutoScale: policy}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	url := fmt.Sprintf("/apps/%s/autoscale?:app=%s", a.Name, a.Name)
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = getAutoScale(recorder, request, s.token)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(recorder.Code, gocheck.Equals, http.StatusOK)
+	var got app.AutoScale
+	err = json.NewDecoder(recorder.Body).Decode(&got)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(&got, gocheck.DeepEquals, policy)
+	action := testing.Action{Action: "get-autoscale", User: s.user.Email, Extra: []interface{}{a.Name}}
+	c.Assert(action, testing.IsRecorded)
+}
+
+func (s *S) TestGetAutoScaleReturnsNoContentWithoutPolicy(c *gocheck.C) {
+	a := app.App{Name: "stress", Teams: []string{s.team.Name}}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	url := fmt.Sprintf("/apps/%s/autoscale?:app=%s", a.Name, a.Name)
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = getAutoScale(recorder, request, s.token)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(recorder.Code, gocheck.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestGetAutoScaleReturns403IfTheUserDoesNotHaveAccessToTheApp(c *gocheck.C) {
+	a := app.App{Name: "nightmist"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	url := fmt.Sprintf("/apps/%s/autoscale?:app=%s", a.Name, a.Name)
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = getAutoScale(recorder, request, s.token)
+	c.Assert(err, gocheck.NotNil)
+	e, ok := err.(*errors.HTTP)
+	c.Assert(ok, gocheck.Equals, true)
+	c.Assert(e.Code, gocheck.Equals, http.StatusForbidden)
+}
+
+func (s *S) TestSetAutoScale(c *gocheck.C) {
+	a := app.App{Name: "stress", Teams: []string{s.team.Name}}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	body := strings.NewReader(`{"Metric":"requests","MinUnits":2,"MaxUnits":6,"ScaleUp":500,"ScaleDown":100,"Cooldown":600}`)
+	url := fmt.Sprintf("/apps/%s/autoscale?:app=%s", a.Name, a.Name)
+	request, err := http.NewRequest("PUT", url, body)
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = setAutoScale(recorder, request, s.token)
+	c.Assert(err, gocheck.IsNil)
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	expected := &app.AutoScale{Metric: "requests", MinUnits: 2, MaxUnits: 6, ScaleUp: 500, ScaleDown: 100, Cooldown: 600}
+	c.Assert(a.AutoScale, gocheck.DeepEquals, expected)
+	action := testing.Action{
+		Action: "set-autoscale",
+		User:   s.user.Email,
+		Extra:  []interface{}{"app=" + a.Name, "metric=requests"},
+	}
+	c.Assert(action, testing.IsRecorded)
+}
+
+func (s *S) TestSetAutoScaleInvalidPolicy(c *gocheck.C) {
+	a := app.App{Name: "stress", Teams: []string{s.team.Name}}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	body := strings.NewReader(`{"Metric":"cpu","MinUnits":3,"MaxUnits":2,"ScaleUp":80}`)
+	url := fmt.Sprintf("/apps/%s/autoscale?:app=%s", a.Name, a.Name)
+	request, err := http.NewRequest("PUT", url, body)
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = setAutoScale(recorder, request, s.token)
+	c.Assert(err, gocheck.NotNil)
+	e, ok := err.(*errors.HTTP)
+	c.Assert(ok, gocheck.Equals, true)
+	c.Assert(e.Code, gocheck.Equals, http.StatusBadRequest)
+	c.Assert(e.Message, gocheck.Equals, "The maximum number of units must not be lower than the minimum.")
+}
+
+func (s *S) TestSetAutoScaleInvalidBody(c *gocheck.C) {
+	request, err := http.NewRequest("PUT", "/apps/stress/autoscale?:app=stress", strings.NewReader("{"))
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = setAutoScale(recorder, request, s.token)
+	c.Assert(err, gocheck.NotNil)
+	e, ok := err.(*errors.HTTP)
+	c.Assert(ok, gocheck.Equals, true)
+	c.Assert(e.Code, gocheck.Equals, http.StatusBadRequest)
+}
+
+func (s *S) TestUnsetAutoScale(c *gocheck.C) {
+	policy := &app.AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 4, ScaleUp: 80, ScaleDown: 20}
+	a := app.App{Name: "stress", Teams: []string{s.team.Name}, AutoScale: policy}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	url := fmt.Sprintf("/apps/%s/autoscale?:app=%s", a.Name, a.Name)
+	request, err := http.NewRequest("DELETE", url, nil)
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = unsetAutoScale(recorder, request, s.token)
+	c.Assert(err, gocheck.IsNil)
+	a.AutoScale = nil
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.AutoScale, gocheck.IsNil)
+	action := testing.Action{Action: "unset-autoscale", User: s.user.Email, Extra: []interface{}{a.Name}}
+	c.Assert(action, testing.IsRecorded)
+}
+
+func (s *S) TestAutoScaleEvents(c *gocheck.C) {
+	a := app.App{Name: "stress", Teams: []string{s.team.Name}}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	defer s.conn.ScaleEvents().RemoveAll(bson.M{"appname": a.Name})
+	err = a.LogScaleEvent(&app.ScaleEvent{Date: time.Now().Add(-time.Hour), From: 1, To: 2, Reason: "cpu average 90.00 is above 80.00"})
+	c.Assert(err, gocheck.IsNil)
+	err = a.LogScaleEvent(&app.ScaleEvent{From: 2, To: 1, Reason: "cpu average 10.00 is below 20.00"})
+	c.Assert(err, gocheck.IsNil)
+	url := fmt.Sprintf("/apps/%s/autoscale/events?:app=%s", a.Name, a.Name)
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = autoScaleEvents(recorder, request, s.token)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(recorder.Code, gocheck.Equals, http.StatusOK)
+	var events []app.ScaleEvent
+	err = json.NewDecoder(recorder.Body).Decode(&events)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(events, gocheck.HasLen, 2)
+	c.Assert(events[0].To, gocheck.Equals, 1)
+	c.Assert(events[1].To, gocheck.Equals, 2)
+	action := testing.Action{Action: "autoscale-events", User: s.user.Email, Extra: []interface{}{a.Name}}
+	c.Assert(action, testing.IsRecorded)
+}
+
+func (s *S) TestAutoScaleEventsReturnsNoContentWithoutEvents(c *gocheck.C) {
+	a := app.App{Name: "stress", Teams: []string{s.team.Name}}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	url := fmt.Sprintf("/apps/%s/autoscale/events?:app=%s", a.Name, a.Name)
+	request, err := http.NewRequest("GET", url, nil)
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = autoScaleEvents(recorder, request, s.token)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(recorder.Code, gocheck.Equals, http.StatusNoContent)
+}
+
+func (s *S) TestAddMetrics(c *gocheck.C) {
+	a := app.App{Name: "stress"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	defer s.conn.Metrics().RemoveAll(bson.M{"appname": a.Name})
+	body := strings.NewReader(`[{"Unit":"stress/0","Name":"cpu","Value":40},{"Unit":"stress/1","Name":"cpu","Value":60}]`)
+	request, err := http.NewRequest("POST", "/apps/stress/metrics?:app=stress", body)
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = addMetrics(recorder, request, s.token)
+	c.Assert(err, gocheck.IsNil)
+	avg, n, err := a.AverageMetric("cpu", time.Now().Add(-time.Minute))
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(n, gocheck.Equals, 2)
+	c.Assert(avg, gocheck.Equals, 50.0)
+}
+
+func (s *S) TestAddMetricsInvalidBody(c *gocheck.C) {
+	a := app.App{Name: "stress"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	request, err := http.NewRequest("POST", "/apps/stress/metrics?:app=stress", strings.NewReader("cpu=40"))
+	c.Assert(err, gocheck.IsNil)
+	recorder := httptest.NewRecorder()
+	err = addMetrics(recorder, request, s.token)
+	c.Assert(err, gocheck.NotNil)
+	e, ok := err.(*errors.HTTP)
+	c.Assert(ok, gocheck.Equals, true)
+	c.Assert(e.Code, gocheck.Equals, http.StatusBadRequest)
+}
@@ -82,6 +82,8 @@ func RunServer(flags map[string]interface{}) {
 	m.Del("/apps/:app/cname", authorizationRequiredHandler(unsetCName))
 	m.Post("/apps/:app/run", authorizationRequiredHandler(runCommand))
 	m.Get("/apps/:app/restart", authorizationRequiredHandler(restart))
+	m.Get("/apps/:app/deploys", authorizationRequiredHandler(deployList))
+	m.Post("/apps/:app/rollback", authorizationRequiredHandler(rollback))
 	m.Get("/apps/:app/env", authorizationRequiredHandler(getEnv))
 	m.Post("/apps/:app/env", authorizationRequiredHandler(setEnv))
 	m.Del("/apps/:app/env", authorizationRequiredHandler(unsetEnv))
@@ -89,10 +91,15 @@ func RunServer(flags map[string]interface{}) {
 	m.Post("/apps", authorizationRequiredHandler(createApp))
 	m.Put("/apps/:app/units", authorizationRequiredHandler(addUnits))
 	m.Del("/apps/:app/units", authorizationRequiredHandler(removeUnits))
+	m.Get("/apps/:app/autoscale", authorizationRequiredHandler(getAutoScale))
+	m.Put("/apps/:app/autoscale", authorizationRequiredHandler(setAutoScale))
+	m.Del("/apps/:app/autoscale", authorizationRequiredHandler(unsetAutoScale))
+	m.Get("/apps/:app/autoscale/events", authorizationRequiredHandler(autoScaleEvents))
 	m.Put("/apps/:app/:team", authorizationRequiredHandler(grantAppAccess))
 	m.Del("/apps/:app/:team", authorizationRequiredHandler(revokeAppAccess))
 	m.Get("/apps/:app/log", authorizationRequiredHandler(appLog))
 	m.Post("/apps/:app/log", authorizationRequiredHandler(addLog))
+	m.Post("/apps/:app/metrics", authorizationRequiredHandler(addMetrics))
 
 	m.Get("/platforms", authorizationRequiredHandler(platformList))
 
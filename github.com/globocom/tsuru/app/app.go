@@ -44,16 +44,17 @@ var (
 // This struct holds information about the app: its name, address, list of
 // teams that have access to it, used platform, etc.
 type App struct {
-	Env      map[string]bind.EnvVar
-	Platform string `bson:"framework"`
-	Name     string
-	Ip       string
-	CName    string
-	Units    []Unit
-	Teams    []string
-	Owner    string
-	State    string
-	conf     *conf
+	Env       map[string]bind.EnvVar
+	Platform  string `bson:"framework"`
+	Name      string
+	Ip        string
+	CName     string
+	Units     []Unit
+	Teams     []string
+	Owner     string
+	State     string
+	AutoScale *AutoScale `bson:",omitempty"`
+	conf      *conf
 }
 
 // MarshalJSON marshals the app in json format. It returns a JSON object with
@@ -0,0 +1,203 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"fmt"
+	"github.com/globocom/tsuru/db"
+	"github.com/globocom/tsuru/errors"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"time"
+)
+
+// AutoScaleMetrics is the list of metrics that may be used in autoscale
+// policies.
+var AutoScaleMetrics = []string{"cpu", "requests"}
+
+// MinAutoScaleCooldown is the shortest cooldown, in seconds, allowed in
+// autoscale policies. It is the interval in which the collector applies the
+// policies, so that the metrics reflect a change before the next one.
+const MinAutoScaleCooldown = 60
+
+// AutoScale is the autoscale policy of an app.
+//
+// The average of the given metric, as reported by the units of the app, is
+// compared with the thresholds: when it is above ScaleUp, a unit is added to
+// the app, and when it is below ScaleDown, a unit is removed from the app. The
+// number of units of the app is always kept between MinUnits and MaxUnits.
+// After scaling the app, new changes are held for Cooldown seconds, so the
+// metrics can reflect the new units.
+type AutoScale struct {
+	Metric    string
+	MinUnits  uint
+	MaxUnits  uint
+	ScaleUp   float64
+	ScaleDown float64
+	Cooldown  uint
+}
+
+func (a *AutoScale) validate() error {
+	valid := false
+	for _, m := range AutoScaleMetrics {
+		if a.Metric == m {
+			valid = true
+			break
+		}
+	}
+	var msg string
+	switch {
+	case !valid:
+		msg = fmt.Sprintf("Invalid metric %q. Valid metrics are: %v.", a.Metric, AutoScaleMetrics)
+	case a.MinUnits == 0:
+		msg = "The minimum number of units must be at least 1."
+	case a.MaxUnits < a.MinUnits:
+		msg = "The maximum number of units must not be lower than the minimum."
+	case a.ScaleDown < 0 || a.ScaleDown >= a.ScaleUp:
+		msg = "The scale down threshold must be positive and lower than the scale up threshold."
+	case a.Cooldown < MinAutoScaleCooldown:
+		msg = fmt.Sprintf("The cooldown must be at least %d seconds.", MinAutoScaleCooldown)
+	default:
+		return nil
+	}
+	return &errors.ValidationError{Message: msg}
+}
+
+// SetAutoScale validates and stores the autoscale policy of the app. A nil
+// policy disables autoscaling.
+func (app *App) SetAutoScale(policy *AutoScale) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var update bson.M
+	if policy == nil {
+		update = bson.M{"$unset": bson.M{"autoscale": ""}}
+	} else {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"autoscale": policy}}
+	}
+	if err := conn.Apps().Update(bson.M{"name": app.Name}, update); err != nil {
+		return err
+	}
+	app.AutoScale = policy
+	return nil
+}
+
+// Metric is a sample of a metric of a unit, reported by the unit itself or by
+// a monitoring service.
+type Metric struct {
+	AppName string
+	Unit    string
+	Name    string
+	Value   float64
+	Date    time.Time
+}
+
+// AddMetrics stores samples of metrics of the units of the app.
+func (app *App) AddMetrics(metrics []Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	now := time.Now().In(time.UTC)
+	docs := make([]interface{}, len(metrics))
+	for i, m := range metrics {
+		m.AppName = app.Name
+		m.Date = now
+		docs[i] = m
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Metrics().Insert(docs...)
+}
+
+// AverageMetric returns the average of the samples of the given metric
+// reported since the given time, and the number of samples.
+func (app *App) AverageMetric(name string, since time.Time) (float64, int, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Close()
+	q := bson.M{"appname": app.Name, "name": name, "date": bson.M{"$gte": since}}
+	var metrics []Metric
+	if err := conn.Metrics().Find(q).All(&metrics); err != nil {
+		return 0, 0, err
+	}
+	if len(metrics) == 0 {
+		return 0, 0, nil
+	}
+	var sum float64
+	for _, m := range metrics {
+		sum += m.Value
+	}
+	return sum / float64(len(metrics)), len(metrics), nil
+}
+
+// ScaleEvent is the record of an attempt to scale an app by its autoscale
+// policy. From and To are the number of units before and after scaling, and
+// Error holds the reason of a failure.
+type ScaleEvent struct {
+	AppName string
+	Date    time.Time
+	From    int
+	To      int
+	Reason  string
+	Error   string
+}
+
+// LogScaleEvent stores the given event in the autoscale audit log of the app.
+func (app *App) LogScaleEvent(e *ScaleEvent) error {
+	e.AppName = app.Name
+	if e.Date.IsZero() {
+		e.Date = time.Now().In(time.UTC)
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.ScaleEvents().Insert(e)
+}
+
+// ScaleEvents returns the autoscale audit log of the app, most recent first.
+func (app *App) ScaleEvents() ([]ScaleEvent, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var events []ScaleEvent
+	err = conn.ScaleEvents().Find(bson.M{"appname": app.Name}).Sort("-date").All(&events)
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// LastScaleEvent returns the most recent autoscale event of the app, or nil
+// if the app has never been scaled.
+func (app *App) LastScaleEvent() (*ScaleEvent, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var e ScaleEvent
+	err = conn.ScaleEvents().Find(bson.M{"appname": app.Name}).Sort("-date").One(&e)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"github.com/globocom/tsuru/errors"
+	"labix.org/v2/mgo/bson"
+	"launchpad.net/gocheck"
+	"time"
+)
+
+func (s *S) TestSetAutoScale(c *gocheck.C) {
+	a := App{Name: "autoapp"}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	policy := AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 5, ScaleUp: 80, ScaleDown: 20, Cooldown: 300}
+	err = a.SetAutoScale(&policy)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.AutoScale, gocheck.DeepEquals, &policy)
+	var stored App
+	err = s.conn.Apps().Find(bson.M{"name": a.Name}).One(&stored)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(stored.AutoScale, gocheck.DeepEquals, &policy)
+}
+
+func (s *S) TestSetAutoScaleNilDisablesAutoScale(c *gocheck.C) {
+	a := App{Name: "autoapp", AutoScale: &AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 5, ScaleUp: 80}}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Apps().Remove(bson.M{"name": a.Name})
+	err = a.SetAutoScale(nil)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.AutoScale, gocheck.IsNil)
+	n, err := s.conn.Apps().Find(bson.M{"name": a.Name, "autoscale": bson.M{"$exists": true}}).Count()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(n, gocheck.Equals, 0)
+}
+
+func (s *S) TestSetAutoScaleValidation(c *gocheck.C) {
+	a := App{Name: "autoapp"}
+	var tests = []struct {
+		policy AutoScale
+		msg    string
+	}{
+		{AutoScale{Metric: "memory", MinUnits: 1, MaxUnits: 2, ScaleUp: 80}, `^Invalid metric "memory".*`},
+		{AutoScale{Metric: "cpu", MaxUnits: 2, ScaleUp: 80}, "^The minimum number of units must be at least 1.$"},
+		{AutoScale{Metric: "cpu", MinUnits: 3, MaxUnits: 2, ScaleUp: 80}, "^The maximum number of units must not be lower than the minimum.$"},
+		{AutoScale{Metric: "requests", MinUnits: 1, MaxUnits: 2, ScaleUp: 10, ScaleDown: 10}, "^The scale down threshold .*"},
+		{AutoScale{Metric: "requests", MinUnits: 1, MaxUnits: 2, ScaleUp: 10, ScaleDown: -1}, "^The scale down threshold .*"},
+		{AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 2, ScaleUp: 80, Cooldown: 30}, "^The cooldown must be at least 60 seconds.$"},
+	}
+	for _, t := range tests {
+		err := a.SetAutoScale(&t.policy)
+		c.Assert(err, gocheck.ErrorMatches, t.msg)
+		_, ok := err.(*errors.ValidationError)
+		c.Assert(ok, gocheck.Equals, true)
+	}
+	c.Assert(a.AutoScale, gocheck.IsNil)
+}
+
+func (s *S) TestAddMetricsAndAverageMetric(c *gocheck.C) {
+	a := App{Name: "autoapp"}
+	defer s.conn.Metrics().RemoveAll(bson.M{"appname": a.Name})
+	err := a.AddMetrics([]Metric{
+		{Unit: "autoapp/0", Name: "cpu", Value: 30},
+		{Unit: "autoapp/1", Name: "cpu", Value: 60},
+		{Unit: "autoapp/0", Name: "requests", Value: 1000},
+	})
+	c.Assert(err, gocheck.IsNil)
+	old := Metric{AppName: a.Name, Unit: "autoapp/0", Name: "cpu", Value: 100, Date: time.Now().Add(-time.Hour)}
+	err = s.conn.Metrics().Insert(old)
+	c.Assert(err, gocheck.IsNil)
+	avg, n, err := a.AverageMetric("cpu", time.Now().Add(-5*time.Minute))
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(n, gocheck.Equals, 2)
+	c.Assert(avg, gocheck.Equals, 45.0)
+	avg, n, err = a.AverageMetric("memory", time.Now().Add(-5*time.Minute))
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(n, gocheck.Equals, 0)
+	c.Assert(avg, gocheck.Equals, 0.0)
+}
+
+func (s *S) TestScaleEvents(c *gocheck.C) {
+	a := App{Name: "autoapp"}
+	defer s.conn.ScaleEvents().RemoveAll(bson.M{"appname": a.Name})
+	last, err := a.LastScaleEvent()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(last, gocheck.IsNil)
+	now := time.Now().In(time.UTC)
+	err = a.LogScaleEvent(&ScaleEvent{Date: now.Add(-time.Minute), From: 1, To: 2, Reason: "cpu above 80"})
+	c.Assert(err, gocheck.IsNil)
+	err = a.LogScaleEvent(&ScaleEvent{From: 2, To: 1, Reason: "cpu below 20"})
+	c.Assert(err, gocheck.IsNil)
+	events, err := a.ScaleEvents()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(events, gocheck.HasLen, 2)
+	c.Assert(events[0].AppName, gocheck.Equals, a.Name)
+	c.Assert(events[0].Reason, gocheck.Equals, "cpu below 20")
+	c.Assert(events[1].Reason, gocheck.Equals, "cpu above 80")
+	last, err = a.LastScaleEvent()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(last.Reason, gocheck.Equals, "cpu below 20")
+}
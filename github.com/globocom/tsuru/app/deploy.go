@@ -0,0 +1,134 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/globocom/tsuru/db"
+	"github.com/globocom/tsuru/errors"
+	"github.com/globocom/tsuru/log"
+	"io"
+	"labix.org/v2/mgo/bson"
+	"regexp"
+	"time"
+)
+
+// maxDeployOutput is the maximum number of bytes of the deploy output that
+// are stored in the database. Longer outputs are truncated at the beginning,
+// keeping the end, where errors usually are.
+const maxDeployOutput = 64 << 10
+
+// Deploy represents a deploy of an app: the version of the code that was
+// deployed, who deployed it, when, how long it took and the output of the
+// provisioner.
+type Deploy struct {
+	App       string
+	Commit    string
+	User      string
+	Timestamp time.Time
+	Duration  time.Duration
+	Success   bool
+	Output    string
+}
+
+// Deploy deploys the given version of the app using the provisioner, writing
+// the output to w, and records the deploy in the database.
+//
+// The user is the email of the user that triggered the deploy. For deploys
+// triggered by git pushes, it is the pushing user that gandalf gives to the
+// post-receive hook, or the user of the hook's token when gandalf gives none.
+func (app *App) Deploy(version, user string, w io.Writer) error {
+	var buf bytes.Buffer
+	start := time.Now()
+	err := Provisioner.Deploy(app, version, io.MultiWriter(w, &buf))
+	output := buf.Bytes()
+	if len(output) > maxDeployOutput {
+		output = output[len(output)-maxDeployOutput:]
+	}
+	d := Deploy{
+		App:       app.Name,
+		Commit:    version,
+		User:      user,
+		Timestamp: start.In(time.UTC),
+		Duration:  time.Since(start),
+		Success:   err == nil,
+		Output:    string(output),
+	}
+	if rerr := saveDeploy(&d); rerr != nil {
+		log.Printf("Failed to record deploy of the app %q: %s", app.Name, rerr)
+	}
+	return err
+}
+
+func saveDeploy(d *Deploy) error {
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Deploys().Insert(d)
+}
+
+// Deploys returns the list of deploys of the app, most recent first.
+func (app *App) Deploys() ([]Deploy, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var deploys []Deploy
+	err = conn.Deploys().Find(bson.M{"app": app.Name}).Sort("-timestamp").All(&deploys)
+	if err != nil {
+		return nil, err
+	}
+	return deploys, nil
+}
+
+// Rollback deploys again a version of the app that has already been deployed
+// successfully. The version may be the full commit or a prefix of it, as long
+// as it matches only one of the deployed commits.
+//
+// It returns a ValidationError if the version does not match any successful
+// deploy of the app, or if it matches more than one.
+func (app *App) Rollback(version, user string, w io.Writer) error {
+	if version == "" {
+		return &errors.ValidationError{Message: "You must provide the version to roll back to."}
+	}
+	conn, err := db.Conn()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	q := bson.M{
+		"app":     app.Name,
+		"success": true,
+		"commit":  bson.RegEx{Pattern: "^" + regexp.QuoteMeta(version)},
+	}
+	var commits []string
+	if err := conn.Deploys().Find(q).Distinct("commit", &commits); err != nil {
+		return err
+	}
+	commit := ""
+	for _, c := range commits {
+		if c == version {
+			commit = c
+			break
+		}
+	}
+	if commit == "" {
+		switch len(commits) {
+		case 0:
+			msg := fmt.Sprintf("Version %q has never been successfully deployed in the app %q.", version, app.Name)
+			return &errors.ValidationError{Message: msg}
+		case 1:
+			commit = commits[0]
+		default:
+			msg := fmt.Sprintf("Version %q is ambiguous, it matches %d deployed versions.", version, len(commits))
+			return &errors.ValidationError{Message: msg}
+		}
+	}
+	return app.Deploy(commit, user, w)
+}
@@ -0,0 +1,141 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	stderr "errors"
+	"github.com/globocom/tsuru/errors"
+	"labix.org/v2/mgo/bson"
+	"launchpad.net/gocheck"
+	"time"
+)
+
+func (s *S) TestDeploy(c *gocheck.C) {
+	a := App{Name: "otherapp", Platform: "zend", Teams: []string{s.team.Name}}
+	s.provisioner.Provision(&a)
+	defer s.provisioner.Destroy(&a)
+	defer s.conn.Deploys().RemoveAll(bson.M{"app": a.Name})
+	var buf bytes.Buffer
+	err := a.Deploy("a345f3e", "someone@tsuru.io", &buf)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(buf.String(), gocheck.Equals, "Deploy called")
+	c.Assert(s.provisioner.Version(&a), gocheck.Equals, "a345f3e")
+	var deploys []Deploy
+	err = s.conn.Deploys().Find(bson.M{"app": a.Name}).All(&deploys)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(deploys, gocheck.HasLen, 1)
+	c.Assert(deploys[0].Commit, gocheck.Equals, "a345f3e")
+	c.Assert(deploys[0].User, gocheck.Equals, "someone@tsuru.io")
+	c.Assert(deploys[0].Success, gocheck.Equals, true)
+	c.Assert(deploys[0].Output, gocheck.Equals, "Deploy called")
+	c.Assert(time.Since(deploys[0].Timestamp) < time.Minute, gocheck.Equals, true)
+}
+
+func (s *S) TestDeployFailure(c *gocheck.C) {
+	a := App{Name: "otherapp", Platform: "zend", Teams: []string{s.team.Name}}
+	s.provisioner.Provision(&a)
+	defer s.provisioner.Destroy(&a)
+	defer s.conn.Deploys().RemoveAll(bson.M{"app": a.Name})
+	s.provisioner.PrepareFailure("Deploy", stderr.New("deploy failed"))
+	var buf bytes.Buffer
+	err := a.Deploy("a345f3e", "", &buf)
+	c.Assert(err, gocheck.ErrorMatches, "deploy failed")
+	var deploys []Deploy
+	err = s.conn.Deploys().Find(bson.M{"app": a.Name}).All(&deploys)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(deploys, gocheck.HasLen, 1)
+	c.Assert(deploys[0].Commit, gocheck.Equals, "a345f3e")
+	c.Assert(deploys[0].Success, gocheck.Equals, false)
+}
+
+func (s *S) TestDeploys(c *gocheck.C) {
+	a := App{Name: "otherapp"}
+	defer s.conn.Deploys().RemoveAll(bson.M{"app": a.Name})
+	now := time.Now().In(time.UTC)
+	err := s.conn.Deploys().Insert(
+		Deploy{App: a.Name, Commit: "abc", Timestamp: now.Add(-time.Hour), Success: true},
+		Deploy{App: a.Name, Commit: "def", Timestamp: now, Success: true},
+		Deploy{App: "anotherapp", Commit: "ghi", Timestamp: now, Success: true},
+	)
+	c.Assert(err, gocheck.IsNil)
+	defer s.conn.Deploys().RemoveAll(bson.M{"app": "anotherapp"})
+	deploys, err := a.Deploys()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(deploys, gocheck.HasLen, 2)
+	c.Assert(deploys[0].Commit, gocheck.Equals, "def")
+	c.Assert(deploys[1].Commit, gocheck.Equals, "abc")
+}
+
+func (s *S) TestDeploysWithoutDeploys(c *gocheck.C) {
+	a := App{Name: "otherapp"}
+	deploys, err := a.Deploys()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(deploys, gocheck.HasLen, 0)
+}
+
+func (s *S) insertDeploys(c *gocheck.C, a *App) {
+	err := s.conn.Deploys().Insert(
+		Deploy{App: a.Name, Commit: "a345f3e98ccf", Success: true},
+		Deploy{App: a.Name, Commit: "a345f3e98ccf", Success: true},
+		Deploy{App: a.Name, Commit: "a3996cd12f0a", Success: true},
+		Deploy{App: a.Name, Commit: "b12c5e9a8d00", Success: false},
+	)
+	c.Assert(err, gocheck.IsNil)
+}
+
+func (s *S) TestRollback(c *gocheck.C) {
+	a := App{Name: "otherapp", Platform: "zend", Teams: []string{s.team.Name}}
+	s.provisioner.Provision(&a)
+	defer s.provisioner.Destroy(&a)
+	defer s.conn.Deploys().RemoveAll(bson.M{"app": a.Name})
+	s.insertDeploys(c, &a)
+	var buf bytes.Buffer
+	err := a.Rollback("a345", "someone@tsuru.io", &buf)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(buf.String(), gocheck.Equals, "Deploy called")
+	c.Assert(s.provisioner.Version(&a), gocheck.Equals, "a345f3e98ccf")
+	n, err := s.conn.Deploys().Find(bson.M{"app": a.Name, "user": "someone@tsuru.io"}).Count()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(n, gocheck.Equals, 1)
+}
+
+func (s *S) TestRollbackFullCommit(c *gocheck.C) {
+	a := App{Name: "otherapp", Platform: "zend", Teams: []string{s.team.Name}}
+	s.provisioner.Provision(&a)
+	defer s.provisioner.Destroy(&a)
+	defer s.conn.Deploys().RemoveAll(bson.M{"app": a.Name})
+	s.insertDeploys(c, &a)
+	var buf bytes.Buffer
+	err := a.Rollback("a3996cd12f0a", "", &buf)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(s.provisioner.Version(&a), gocheck.Equals, "a3996cd12f0a")
+}
+
+func (s *S) TestRollbackErrors(c *gocheck.C) {
+	a := App{Name: "otherapp", Platform: "zend", Teams: []string{s.team.Name}}
+	s.provisioner.Provision(&a)
+	defer s.provisioner.Destroy(&a)
+	defer s.conn.Deploys().RemoveAll(bson.M{"app": a.Name})
+	s.insertDeploys(c, &a)
+	tests := []struct {
+		version string
+		err     string
+	}{
+		{"", `^You must provide the version to roll back to\.$`},
+		{"a3", `^Version "a3" is ambiguous, it matches 2 deployed versions\.$`},
+		{"b12c", `^Version "b12c" has never been successfully deployed in the app "otherapp"\.$`},
+		{"f00", `^Version "f00" has never been successfully deployed in the app "otherapp"\.$`},
+		{".*", `^Version "\.\*" has never been successfully deployed in the app "otherapp"\.$`},
+	}
+	for _, t := range tests {
+		var buf bytes.Buffer
+		err := a.Rollback(t.version, "", &buf)
+		c.Check(err, gocheck.FitsTypeOf, &errors.ValidationError{})
+		c.Check(err, gocheck.ErrorMatches, t.err)
+		c.Check(buf.String(), gocheck.Equals, "")
+	}
+	c.Assert(s.provisioner.Version(&a), gocheck.Equals, "")
+}
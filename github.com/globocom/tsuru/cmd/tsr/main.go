@@ -16,6 +16,7 @@ func buildManager() *cmd.Manager {
 	m := cmd.NewManager("tsr", "0.1.0", "", os.Stdout, os.Stderr, os.Stdin)
 	m.Register(&apiCmd{})
 	m.Register(&collectorCmd{})
+	m.Register(&routerCmd{})
 	m.Register(&tokenCmd{})
 	return m
 }
@@ -34,6 +34,13 @@ func (s *S) TestCollectorCmdIsRegistered(c *gocheck.C) {
 	c.Assert(create, gocheck.FitsTypeOf, &collectorCmd{})
 }
 
+func (s *S) TestRouterCmdIsRegistered(c *gocheck.C) {
+	manager := buildManager()
+	router, ok := manager.Commands["router"]
+	c.Assert(ok, gocheck.Equals, true)
+	c.Assert(router, gocheck.FitsTypeOf, &routerCmd{})
+}
+
 func (s *S) TestTokenCmdIsRegistered(c *gocheck.C) {
 	manager := buildManager()
 	create, ok := manager.Commands["token"]
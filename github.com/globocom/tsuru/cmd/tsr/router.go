@@ -0,0 +1,45 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/globocom/tsuru/cmd"
+	"github.com/globocom/tsuru/router/builtin"
+	"launchpad.net/gnuflag"
+)
+
+type routerCmd struct {
+	fs     *gnuflag.FlagSet
+	config string
+	dry    bool
+}
+
+func (c *routerCmd) Run(context *cmd.Context, client *cmd.Client) error {
+	flags := map[string]interface{}{}
+	flags["dry"] = c.dry
+	flags["config"] = c.config
+	builtin.RunServer(flags)
+	return nil
+}
+
+func (routerCmd) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "router",
+		Usage:   "router",
+		Desc:    "Starts the tsuru built-in router.",
+		MinArgs: 0,
+	}
+}
+
+func (c *routerCmd) Flags() *gnuflag.FlagSet {
+	if c.fs == nil {
+		c.fs = gnuflag.NewFlagSet("router", gnuflag.ExitOnError)
+		c.fs.BoolVar(&c.dry, "dry", false, "dry-run: does not start the router (for testing purpose)")
+		c.fs.BoolVar(&c.dry, "d", false, "dry-run: does not start the router (for testing purpose)")
+		c.fs.StringVar(&c.config, "config", "/etc/tsuru/tsuru.conf", "tsr router config file.")
+		c.fs.StringVar(&c.config, "c", "/etc/tsuru/tsuru.conf", "tsr router config file.")
+	}
+	return c.fs
+}
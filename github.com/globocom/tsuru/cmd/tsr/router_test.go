@@ -0,0 +1,42 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/globocom/tsuru/cmd"
+	"launchpad.net/gocheck"
+)
+
+func (s *S) TestRouterCmdInfo(c *gocheck.C) {
+	expected := &cmd.Info{
+		Name:    "router",
+		Usage:   "router",
+		Desc:    "Starts the tsuru built-in router.",
+		MinArgs: 0,
+	}
+	c.Assert(routerCmd{}.Info(), gocheck.DeepEquals, expected)
+}
+
+func (s *S) TestRouterCmdIsACommand(c *gocheck.C) {
+	var _ cmd.FlaggedCommand = &routerCmd{}
+}
+
+func (s *S) TestRouterCmdFlags(c *gocheck.C) {
+	command := routerCmd{}
+	flagset := command.Flags()
+	c.Assert(flagset, gocheck.NotNil)
+	flagset.Parse(true, []string{"--dry", "true"})
+	flag := flagset.Lookup("dry")
+	c.Assert(flag, gocheck.NotNil)
+	c.Assert(flag.Usage, gocheck.Equals, "dry-run: does not start the router (for testing purpose)")
+	c.Assert(flag.Value.String(), gocheck.Equals, "true")
+	c.Assert(flag.DefValue, gocheck.Equals, "false")
+	flagset.Parse(true, []string{"-c", "../etc/tsuru.conf"})
+	flag = flagset.Lookup("c")
+	c.Assert(flag, gocheck.NotNil)
+	c.Assert(flag.Usage, gocheck.Equals, "tsr router config file.")
+	c.Assert(flag.Value.String(), gocheck.Equals, "../etc/tsuru.conf")
+	c.Assert(flag.DefValue, gocheck.Equals, "/etc/tsuru/tsuru.conf")
+}
@@ -0,0 +1,133 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/globocom/tsuru/cmd"
+	"github.com/globocom/tsuru/cmd/tsuru-base"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type deploy struct {
+	Commit    string
+	User      string
+	Timestamp time.Time
+	Duration  time.Duration
+	Success   bool
+}
+
+type AppDeployList struct {
+	tsuru.GuessingCommand
+}
+
+func (c *AppDeployList) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "app-deploy-list",
+		Usage: "app-deploy-list [--app appname]",
+		Desc: `list the deploys of an app, most recent first.
+
+If you don't provide the app name, tsuru will try to guess it.`,
+		MinArgs: 0,
+	}
+}
+
+func (c *AppDeployList) Run(context *cmd.Context, client *cmd.Client) error {
+	appName, err := c.Guess()
+	if err != nil {
+		return err
+	}
+	url, err := cmd.GetURL(fmt.Sprintf("/apps/%s/deploys", appName))
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode == http.StatusNoContent {
+		fmt.Fprintf(context.Stdout, "App %q has not been deployed yet.\n", appName)
+		return nil
+	}
+	defer response.Body.Close()
+	result, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	var deploys []deploy
+	err = json.Unmarshal(result, &deploys)
+	if err != nil {
+		return err
+	}
+	table := cmd.NewTable()
+	table.Headers = cmd.Row([]string{"Version", "Date", "Duration", "User", "Status"})
+	for _, d := range deploys {
+		version := d.Commit
+		if len(version) > 7 {
+			version = version[:7]
+		}
+		date := d.Timestamp.In(time.Local).Format("2006-01-02 15:04:05 -0700")
+		duration := (d.Duration / time.Second * time.Second).String()
+		status := "succeeded"
+		if !d.Success {
+			status = "failed"
+		}
+		table.AddRow(cmd.Row([]string{version, date, duration, d.User, status}))
+	}
+	context.Stdout.Write(table.Bytes())
+	return nil
+}
+
+type AppRollback struct {
+	tsuru.GuessingCommand
+}
+
+func (c *AppRollback) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:  "app-rollback",
+		Usage: "app-rollback <version> [--app appname]",
+		Desc: `deploys again a version of an app that has been deployed before.
+
+The version is a commit, or a prefix of it, as listed by app-deploy-list. Only
+versions that have been deployed successfully can be used.
+
+If you don't provide the app name, tsuru will try to guess it.`,
+		MinArgs: 1,
+	}
+}
+
+func (c *AppRollback) Run(context *cmd.Context, client *cmd.Client) error {
+	appName, err := c.Guess()
+	if err != nil {
+		return err
+	}
+	u, err := cmd.GetURL(fmt.Sprintf("/apps/%s/rollback", appName))
+	if err != nil {
+		return err
+	}
+	body := strings.NewReader(url.Values{"version": {context.Args[0]}}.Encode())
+	request, err := http.NewRequest("POST", u, body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, err = io.Copy(context.Stdout, response.Body)
+	return err
+}
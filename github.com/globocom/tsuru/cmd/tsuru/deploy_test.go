@@ -0,0 +1,129 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"github.com/globocom/tsuru/cmd"
+	"github.com/globocom/tsuru/cmd/tsuru-base"
+	"github.com/globocom/tsuru/testing"
+	"io/ioutil"
+	"launchpad.net/gocheck"
+	"net/http"
+	"time"
+)
+
+func (s *S) TestAppDeployListInfo(c *gocheck.C) {
+	expected := &cmd.Info{
+		Name:  "app-deploy-list",
+		Usage: "app-deploy-list [--app appname]",
+		Desc: `list the deploys of an app, most recent first.
+
+If you don't provide the app name, tsuru will try to guess it.`,
+		MinArgs: 0,
+	}
+	c.Assert((&AppDeployList{}).Info(), gocheck.DeepEquals, expected)
+}
+
+func (s *S) TestAppDeployList(c *gocheck.C) {
+	local := time.Local
+	time.Local = time.UTC
+	defer func() { time.Local = local }()
+	var stdout, stderr bytes.Buffer
+	result := `[
+{"Commit":"b12c5e9a8d00f7","User":"","Timestamp":"2013-09-20T14:10:02Z","Duration":95000000000,"Success":false},
+{"Commit":"a345f3e98ccf","User":"someone@tsuru.io","Timestamp":"2013-09-19T09:32:10Z","Duration":62300000000,"Success":true}
+]`
+	expected := `+---------+---------------------------+----------+------------------+-----------+
+| Version | Date                      | Duration | User             | Status    |
++---------+---------------------------+----------+------------------+-----------+
+| b12c5e9 | 2013-09-20 14:10:02 +0000 | 1m35s    |                  | failed    |
+| a345f3e | 2013-09-19 09:32:10 +0000 | 1m2s     | someone@tsuru.io | succeeded |
++---------+---------------------------+----------+------------------+-----------+
+`
+	context := cmd.Context{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &testing.ConditionalTransport{
+		Transport: testing.Transport{Message: result, Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			return req.URL.Path == "/apps/ble/deploys" && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := AppDeployList{}
+	command.Flags().Parse(true, []string{"--app", "ble"})
+	err := command.Run(&context, client)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(stdout.String(), gocheck.Equals, expected)
+}
+
+func (s *S) TestAppDeployListWithoutDeploys(c *gocheck.C) {
+	var stdout, stderr bytes.Buffer
+	context := cmd.Context{
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &testing.ConditionalTransport{
+		Transport: testing.Transport{Message: "", Status: http.StatusNoContent},
+		CondFunc: func(req *http.Request) bool {
+			return req.URL.Path == "/apps/secret/deploys" && req.Method == "GET"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	fake := FakeGuesser{name: "secret"}
+	command := AppDeployList{GuessingCommand: tsuru.GuessingCommand{G: &fake}}
+	command.Flags().Parse(true, nil)
+	err := command.Run(&context, client)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(stdout.String(), gocheck.Equals, `App "secret" has not been deployed yet.`+"\n")
+}
+
+func (s *S) TestAppRollbackInfo(c *gocheck.C) {
+	expected := &cmd.Info{
+		Name:  "app-rollback",
+		Usage: "app-rollback <version> [--app appname]",
+		Desc: `deploys again a version of an app that has been deployed before.
+
+The version is a commit, or a prefix of it, as listed by app-deploy-list. Only
+versions that have been deployed successfully can be used.
+
+If you don't provide the app name, tsuru will try to guess it.`,
+		MinArgs: 1,
+	}
+	c.Assert((&AppRollback{}).Info(), gocheck.DeepEquals, expected)
+}
+
+func (s *S) TestAppRollback(c *gocheck.C) {
+	var (
+		called         bool
+		stdout, stderr bytes.Buffer
+	)
+	context := cmd.Context{
+		Args:   []string{"a345f3e"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	trans := &testing.ConditionalTransport{
+		Transport: testing.Transport{Message: "Deploy called", Status: http.StatusOK},
+		CondFunc: func(req *http.Request) bool {
+			called = true
+			defer req.Body.Close()
+			body, err := ioutil.ReadAll(req.Body)
+			c.Assert(err, gocheck.IsNil)
+			c.Assert(string(body), gocheck.Equals, "version=a345f3e")
+			c.Assert(req.Header.Get("Content-Type"), gocheck.Equals, "application/x-www-form-urlencoded")
+			return req.URL.Path == "/apps/ble/rollback" && req.Method == "POST"
+		},
+	}
+	client := cmd.NewClient(&http.Client{Transport: trans}, nil, manager)
+	command := AppRollback{}
+	command.Flags().Parse(true, []string{"--app", "ble"})
+	err := command.Run(&context, client)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(called, gocheck.Equals, true)
+	c.Assert(stdout.String(), gocheck.Equals, "Deploy called")
+}
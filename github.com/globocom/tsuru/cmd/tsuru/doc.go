@@ -47,6 +47,8 @@ The currently available commands are (grouped by subject):
 	log               shows log for an app
 	run               runs a command in all units of an app
 	restart           restarts the app's application server
+	app-deploy-list   lists the deploys of an app
+	app-rollback      deploys again a previous version of an app
 	set-cname         defines a cname for an app
 	unset-cname       unsets the cname from an app
 
@@ -70,7 +72,8 @@ Use "tsuru help <command>" for more information about a command.
 Guessing app names
 
 In some app-related commands (app-remove, app-info, app-grant, app-revoke, log,
-run, restart, env-get, env-set, env-unset, bind and unbind), there is an
+run, restart, app-deploy-list, app-rollback, env-get, env-set, env-unset, bind
+and unbind), there is an
 optional parameter --app, used to specify the name of the app.
 
 The --app parameter is optional, if omitted, tsuru will try to "guess" the name
@@ -466,6 +469,34 @@ application.
 The --app flag is optional, see "Guessing app names" section for more details.
 
 
+List the deploys of an app
+
+Usage:
+
+	% tsuru app-deploy-list [--app appname]
+
+app-deploy-list will list the deploys of the app, most recent first. For each
+deploy, it displays the deployed version, when the deploy started, how long it
+took, the user that pushed the code (when known) and whether it succeeded.
+
+The --app flag is optional, see "Guessing app names" section for more details.
+
+
+Roll back the app to a previous version
+
+Usage:
+
+	% tsuru app-rollback <version> [--app appname]
+
+app-rollback will deploy again a version of the app that has been deployed
+successfully before, displaying the output of the deploy. The version is a
+commit, as listed by app-deploy-list, or any unambiguous prefix of it. The
+rollback is recorded as a new deploy, so it can be undone with another
+app-rollback.
+
+The --app flag is optional, see "Guessing app names" section for more details.
+
+
 Display environment variables of an application
 
 Usage:
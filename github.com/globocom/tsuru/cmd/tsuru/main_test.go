@@ -76,6 +76,20 @@ func (s *S) TestAppRestartIsRegistered(c *gocheck.C) {
 	c.Assert(restart, gocheck.FitsTypeOf, &tsuru.AppRestart{})
 }
 
+func (s *S) TestAppDeployListIsRegistered(c *gocheck.C) {
+	manager := buildManager("tsuru")
+	list, ok := manager.Commands["app-deploy-list"]
+	c.Assert(ok, gocheck.Equals, true)
+	c.Assert(list, gocheck.FitsTypeOf, &AppDeployList{})
+}
+
+func (s *S) TestAppRollbackIsRegistered(c *gocheck.C) {
+	manager := buildManager("tsuru")
+	rollback, ok := manager.Commands["app-rollback"]
+	c.Assert(ok, gocheck.Equals, true)
+	c.Assert(rollback, gocheck.FitsTypeOf, &AppRollback{})
+}
+
 func (s *S) TestEnvGetIsRegistered(c *gocheck.C) {
 	manager := buildManager("tsuru")
 	get, ok := manager.Commands["env-get"]
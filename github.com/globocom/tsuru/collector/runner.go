@@ -23,6 +23,8 @@ func collect(ticker <-chan time.Time) {
 			continue
 		}
 		update(units)
+		log.Print("Applying autoscale policies")
+		scaleApps()
 	}
 }
 
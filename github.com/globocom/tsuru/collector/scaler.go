@@ -0,0 +1,111 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collector
+
+import (
+	"fmt"
+	"github.com/globocom/tsuru/app"
+	"github.com/globocom/tsuru/db"
+	"github.com/globocom/tsuru/log"
+	"labix.org/v2/mgo/bson"
+	"time"
+)
+
+// metricsWindow is the period of time considered when averaging the metrics
+// of an app.
+const metricsWindow = 5 * time.Minute
+
+// scaleApps applies the autoscale policy of every app that has one.
+func scaleApps() {
+	conn, err := db.Conn()
+	if err != nil {
+		log.Printf("collector failed to connect to the database: %s", err)
+		return
+	}
+	defer conn.Close()
+	var apps []app.App
+	err = conn.Apps().Find(bson.M{"autoscale": bson.M{"$exists": true}}).All(&apps)
+	if err != nil {
+		log.Printf("collector failed to list autoscaled apps: %s", err)
+		return
+	}
+	for i := range apps {
+		if err := scaleApp(&apps[i]); err != nil {
+			log.Printf("collector failed to scale the app %q: %s", apps[i].Name, err)
+		}
+	}
+}
+
+// scaleApp adds or removes units of the app according to its autoscale
+// policy, and records the change in the autoscale log of the app. It returns
+// the error of the scaling itself, if any.
+//
+// The number of units is first brought within the policy's limits. Then, at
+// most one unit is added or removed, based on the average of the metric in
+// the last minutes, leaving out the metrics reported before the last event.
+// Nothing happens within the cooldown of the last event.
+func scaleApp(a *app.App) error {
+	policy := a.AutoScale
+	if policy == nil {
+		return nil
+	}
+	last, err := a.LastScaleEvent()
+	if err != nil {
+		return err
+	}
+	cooldown := time.Duration(policy.Cooldown) * time.Second
+	if last != nil && time.Since(last.Date) < cooldown {
+		return nil
+	}
+	units := uint(len(a.Units))
+	target := units
+	var reason string
+	switch {
+	case units < policy.MinUnits:
+		target = policy.MinUnits
+		reason = fmt.Sprintf("the app has less than %d units", policy.MinUnits)
+	case units > policy.MaxUnits:
+		target = policy.MaxUnits
+		reason = fmt.Sprintf("the app has more than %d units", policy.MaxUnits)
+	default:
+		// Metrics reported before the last event do not reflect the
+		// current units, and would trigger the same change again.
+		since := time.Now().Add(-metricsWindow)
+		if last != nil && last.Date.After(since) {
+			since = last.Date
+		}
+		avg, n, err := a.AverageMetric(policy.Metric, since)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if avg > policy.ScaleUp && units < policy.MaxUnits {
+			target = units + 1
+			reason = fmt.Sprintf("%s average %.2f is above %.2f", policy.Metric, avg, policy.ScaleUp)
+		} else if avg < policy.ScaleDown && units > policy.MinUnits {
+			target = units - 1
+			reason = fmt.Sprintf("%s average %.2f is below %.2f", policy.Metric, avg, policy.ScaleDown)
+		}
+	}
+	if target == units {
+		return nil
+	}
+	if target > units {
+		err = a.AddUnits(target - units)
+	} else {
+		err = a.RemoveUnits(units - target)
+	}
+	event := app.ScaleEvent{From: int(units), To: int(target), Reason: reason}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	log.Printf("collector: scaling %q from %d to %d units: %s", a.Name, units, target, reason)
+	if logErr := a.LogScaleEvent(&event); logErr != nil {
+		log.Printf("collector failed to log scale event of %q: %s", a.Name, logErr)
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package collector
+
+import (
+	"errors"
+	"github.com/globocom/tsuru/app"
+	"labix.org/v2/mgo/bson"
+	"launchpad.net/gocheck"
+	"time"
+)
+
+func (s *S) createScaledApp(c *gocheck.C, units int, policy *app.AutoScale) *app.App {
+	a := &app.App{Name: "scaledapp", Platform: "python", AutoScale: policy}
+	err := s.conn.Apps().Insert(a)
+	c.Assert(err, gocheck.IsNil)
+	s.provisioner.Provision(a)
+	err = a.AddUnits(uint(units))
+	c.Assert(err, gocheck.IsNil)
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	return a
+}
+
+func (s *S) removeScaledApp(a *app.App) {
+	s.provisioner.Destroy(a)
+	s.conn.Metrics().RemoveAll(bson.M{"appname": a.Name})
+	s.conn.ScaleEvents().RemoveAll(bson.M{"appname": a.Name})
+}
+
+func (s *S) TestScaleAppUp(c *gocheck.C) {
+	policy := &app.AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 3, ScaleUp: 80, ScaleDown: 20, Cooldown: 60}
+	a := s.createScaledApp(c, 1, policy)
+	defer s.removeScaledApp(a)
+	err := a.AddMetrics([]app.Metric{{Unit: "scaledapp/1", Name: "cpu", Value: 95}})
+	c.Assert(err, gocheck.IsNil)
+	err = scaleApp(a)
+	c.Assert(err, gocheck.IsNil)
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.Units, gocheck.HasLen, 2)
+	events, err := a.ScaleEvents()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(events, gocheck.HasLen, 1)
+	c.Assert(events[0].From, gocheck.Equals, 1)
+	c.Assert(events[0].To, gocheck.Equals, 2)
+	c.Assert(events[0].Reason, gocheck.Equals, "cpu average 95.00 is above 80.00")
+	c.Assert(events[0].Error, gocheck.Equals, "")
+}
+
+func (s *S) TestScaleAppDown(c *gocheck.C) {
+	policy := &app.AutoScale{Metric: "requests", MinUnits: 1, MaxUnits: 3, ScaleUp: 800, ScaleDown: 100, Cooldown: 60}
+	a := s.createScaledApp(c, 2, policy)
+	defer s.removeScaledApp(a)
+	err := a.AddMetrics([]app.Metric{
+		{Unit: "scaledapp/1", Name: "requests", Value: 10},
+		{Unit: "scaledapp/2", Name: "requests", Value: 30},
+	})
+	c.Assert(err, gocheck.IsNil)
+	err = scaleApp(a)
+	c.Assert(err, gocheck.IsNil)
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.Units, gocheck.HasLen, 1)
+	events, err := a.ScaleEvents()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(events, gocheck.HasLen, 1)
+	c.Assert(events[0].Reason, gocheck.Equals, "requests average 20.00 is below 100.00")
+}
+
+func (s *S) TestScaleAppKeepsUnitsWithinLimits(c *gocheck.C) {
+	policy := &app.AutoScale{Metric: "cpu", MinUnits: 3, MaxUnits: 5, ScaleUp: 80, ScaleDown: 20, Cooldown: 60}
+	a := s.createScaledApp(c, 1, policy)
+	defer s.removeScaledApp(a)
+	err := scaleApp(a)
+	c.Assert(err, gocheck.IsNil)
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.Units, gocheck.HasLen, 3)
+	events, err := a.ScaleEvents()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(events, gocheck.HasLen, 1)
+	c.Assert(events[0].Reason, gocheck.Equals, "the app has less than 3 units")
+}
+
+func (s *S) TestScaleAppWithoutMetrics(c *gocheck.C) {
+	policy := &app.AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 3, ScaleUp: 80, ScaleDown: 20, Cooldown: 60}
+	a := s.createScaledApp(c, 2, policy)
+	defer s.removeScaledApp(a)
+	err := scaleApp(a)
+	c.Assert(err, gocheck.IsNil)
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.Units, gocheck.HasLen, 2)
+	events, err := a.ScaleEvents()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(events, gocheck.HasLen, 0)
+}
+
+func (s *S) TestScaleAppRespectsCooldown(c *gocheck.C) {
+	policy := &app.AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 3, ScaleUp: 80, ScaleDown: 20, Cooldown: 300}
+	a := s.createScaledApp(c, 1, policy)
+	defer s.removeScaledApp(a)
+	err := a.LogScaleEvent(&app.ScaleEvent{Date: time.Now().Add(-time.Minute), From: 2, To: 1})
+	c.Assert(err, gocheck.IsNil)
+	err = a.AddMetrics([]app.Metric{{Unit: "scaledapp/1", Name: "cpu", Value: 95}})
+	c.Assert(err, gocheck.IsNil)
+	err = scaleApp(a)
+	c.Assert(err, gocheck.IsNil)
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.Units, gocheck.HasLen, 1)
+}
+
+func (s *S) TestScaleAppIgnoresMetricsBeforeTheLastEvent(c *gocheck.C) {
+	policy := &app.AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 3, ScaleUp: 80, ScaleDown: 20, Cooldown: 60}
+	a := s.createScaledApp(c, 2, policy)
+	defer s.removeScaledApp(a)
+	spike := app.Metric{AppName: a.Name, Unit: "scaledapp/1", Name: "cpu", Value: 95, Date: time.Now().Add(-3 * time.Minute)}
+	err := s.conn.Metrics().Insert(spike)
+	c.Assert(err, gocheck.IsNil)
+	err = a.LogScaleEvent(&app.ScaleEvent{Date: time.Now().Add(-2 * time.Minute), From: 1, To: 2})
+	c.Assert(err, gocheck.IsNil)
+	for i := 0; i < 2; i++ {
+		err = scaleApp(a)
+		c.Assert(err, gocheck.IsNil)
+	}
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.Units, gocheck.HasLen, 2)
+	events, err := a.ScaleEvents()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(events, gocheck.HasLen, 1)
+}
+
+func (s *S) TestScaleAppRecordsFailures(c *gocheck.C) {
+	policy := &app.AutoScale{Metric: "cpu", MinUnits: 1, MaxUnits: 3, ScaleUp: 80, ScaleDown: 20, Cooldown: 60}
+	a := s.createScaledApp(c, 1, policy)
+	defer s.removeScaledApp(a)
+	err := a.AddMetrics([]app.Metric{{Unit: "scaledapp/1", Name: "cpu", Value: 95}})
+	c.Assert(err, gocheck.IsNil)
+	s.provisioner.PrepareFailure("AddUnits", errors.New("no machines available"))
+	err = scaleApp(a)
+	c.Assert(err, gocheck.NotNil)
+	events, err := a.ScaleEvents()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(events, gocheck.HasLen, 1)
+	c.Assert(events[0].Error, gocheck.Matches, ".*no machines available.*")
+}
+
+func (s *S) TestScaleAppsIgnoresAppsWithoutPolicy(c *gocheck.C) {
+	a := s.createScaledApp(c, 1, nil)
+	defer s.removeScaledApp(a)
+	err := a.AddMetrics([]app.Metric{{Unit: "scaledapp/1", Name: "cpu", Value: 95}})
+	c.Assert(err, gocheck.IsNil)
+	scaleApps()
+	err = a.Get()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(a.Units, gocheck.HasLen, 1)
+}
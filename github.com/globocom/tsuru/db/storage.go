@@ -140,6 +140,33 @@ func (s *Storage) Logs() *mgo.Collection {
 	return c
 }
 
+// Deploys returns the deploys collection from MongoDB.
+func (s *Storage) Deploys() *mgo.Collection {
+	appIndex := mgo.Index{Key: []string{"app"}}
+	c := s.Collection("deploys")
+	c.EnsureIndex(appIndex)
+	return c
+}
+
+// Metrics returns the metrics collection from MongoDB. Metrics expire one
+// hour after they are reported.
+func (s *Storage) Metrics() *mgo.Collection {
+	appIndex := mgo.Index{Key: []string{"appname", "name"}}
+	dateIndex := mgo.Index{Key: []string{"date"}, ExpireAfter: time.Hour}
+	c := s.Collection("metrics")
+	c.EnsureIndex(appIndex)
+	c.EnsureIndex(dateIndex)
+	return c
+}
+
+// ScaleEvents returns the autoscale events collection from MongoDB.
+func (s *Storage) ScaleEvents() *mgo.Collection {
+	appIndex := mgo.Index{Key: []string{"appname"}}
+	c := s.Collection("autoscale_events")
+	c.EnsureIndex(appIndex)
+	return c
+}
+
 // Services returns the services collection from MongoDB.
 func (s *Storage) Services() *mgo.Collection {
 	c := s.Collection("services")
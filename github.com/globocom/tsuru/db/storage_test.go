@@ -256,6 +256,52 @@ func (s *S) TestLogs(c *gocheck.C) {
 	c.Assert(logs, gocheck.DeepEquals, logsc)
 }
 
+func (s *S) TestDeploys(c *gocheck.C) {
+	storage, _ := Open("127.0.0.1:27017", "tsuru_storage_test")
+	defer storage.session.Close()
+	deploys := storage.Deploys()
+	deploysc := storage.Collection("deploys")
+	c.Assert(deploys, gocheck.DeepEquals, deploysc)
+}
+
+func (s *S) TestDeployAppIndex(c *gocheck.C) {
+	storage, _ := Open("127.0.0.1", "tsuru_storage_test")
+	defer storage.session.Close()
+	deploys := storage.Deploys()
+	c.Assert(deploys, HasIndex, []string{"app"})
+}
+
+func (s *S) TestMetrics(c *gocheck.C) {
+	storage, _ := Open("127.0.0.1:27017", "tsuru_storage_test")
+	defer storage.session.Close()
+	metrics := storage.Metrics()
+	metricsc := storage.Collection("metrics")
+	c.Assert(metrics, gocheck.DeepEquals, metricsc)
+}
+
+func (s *S) TestMetricsIndexes(c *gocheck.C) {
+	storage, _ := Open("127.0.0.1", "tsuru_storage_test")
+	defer storage.session.Close()
+	metrics := storage.Metrics()
+	c.Assert(metrics, HasIndex, []string{"appname", "name"})
+	c.Assert(metrics, HasIndex, []string{"date"})
+}
+
+func (s *S) TestScaleEvents(c *gocheck.C) {
+	storage, _ := Open("127.0.0.1:27017", "tsuru_storage_test")
+	defer storage.session.Close()
+	events := storage.ScaleEvents()
+	eventsc := storage.Collection("autoscale_events")
+	c.Assert(events, gocheck.DeepEquals, eventsc)
+}
+
+func (s *S) TestScaleEventsAppIndex(c *gocheck.C) {
+	storage, _ := Open("127.0.0.1", "tsuru_storage_test")
+	defer storage.session.Close()
+	events := storage.ScaleEvents()
+	c.Assert(events, HasIndex, []string{"appname"})
+}
+
 func (s *S) TestServices(c *gocheck.C) {
 	storage, _ := Open("127.0.0.1:27017", "tsuru_storage_test")
 	defer storage.session.Close()
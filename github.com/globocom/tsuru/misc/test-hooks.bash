@@ -101,7 +101,7 @@ else
 fi
 
 echo -n "post-receive... "
-out=`TSURU_HOST=http://127.0.0.1:5000 TSURU_TOKEN=000secret123 hooks/post-receive`
+out=`echo "0000000 a345f3e refs/heads/master" | GANDALF_USER=someone@example.com TSURU_HOST=http://127.0.0.1:5000 TSURU_TOKEN=000secret123 hooks/post-receive`
 gout=`echo $out | grep "Tsuru receiving push"`
 
 if [ $? = 0 ]
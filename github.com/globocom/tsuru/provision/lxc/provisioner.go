@@ -14,6 +14,7 @@ import (
 	"github.com/globocom/tsuru/log"
 	"github.com/globocom/tsuru/provision"
 	"github.com/globocom/tsuru/router"
+	_ "github.com/globocom/tsuru/router/builtin"
 	_ "github.com/globocom/tsuru/router/nginx"
 	"io"
 	"labix.org/v2/mgo"
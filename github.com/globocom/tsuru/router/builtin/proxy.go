@@ -0,0 +1,285 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package builtin
+
+import (
+	"fmt"
+	"github.com/globocom/config"
+	"github.com/globocom/tsuru/log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// route is an address of a backend, with the reverse proxy used to send
+// requests to it and the result of its last health check.
+type route struct {
+	addr    string
+	url     *url.URL
+	proxy   *httputil.ReverseProxy
+	healthy int32
+}
+
+func newRoute(addr string) (*route, error) {
+	raw := addr
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in route %q", addr)
+	}
+	return &route{addr: addr, url: u, proxy: httputil.NewSingleHostReverseProxy(u), healthy: 1}, nil
+}
+
+func (r *route) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+func (r *route) setHealthy(healthy bool) {
+	var v int32
+	if healthy {
+		v = 1
+	}
+	if atomic.SwapInt32(&r.healthy, v) != v {
+		log.Printf("builtin router: route %s is now healthy=%t", r.addr, healthy)
+	}
+}
+
+// routeSet is the list of routes of a host, balanced in round-robin.
+type routeSet struct {
+	routes []*route
+	next   uint32
+}
+
+// pick returns the next healthy route of the set. When none of the routes is
+// healthy, it returns the next route anyway, so a broken health check does
+// not take an app down.
+func (s *routeSet) pick() *route {
+	n := uint32(len(s.routes))
+	if n == 0 {
+		return nil
+	}
+	start := atomic.AddUint32(&s.next, 1) - 1
+	for i := uint32(0); i < n; i++ {
+		if r := s.routes[(start+i)%n]; r.isHealthy() {
+			return r
+		}
+	}
+	return s.routes[start%n]
+}
+
+// Proxy is an HTTP handler that routes each request, based on its Host header,
+// to one of the routes of the matching backend. A backend named "myapp"
+// serves the host "myapp.<domain>", and its CName, if any.
+//
+// The routing table is loaded from the database by Reload, and the routes are
+// health checked by CheckRoutes. Both are called periodically by RunServer.
+type Proxy struct {
+	// Domain is the domain of the apps' hosts.
+	Domain string
+
+	// HealthCheckPath is the path requested in the health checks.
+	HealthCheckPath string
+
+	mut    sync.RWMutex
+	hosts  map[string]*routeSet
+	routes map[string]*route
+	client *http.Client
+}
+
+// NewProxy returns a proxy for the apps in the given domain, with an empty
+// routing table.
+func NewProxy(domain, healthCheckPath string, timeout time.Duration) *Proxy {
+	transport := &http.Transport{
+		Dial: func(network, addr string) (net.Conn, error) {
+			return net.DialTimeout(network, addr, timeout)
+		},
+		ResponseHeaderTimeout: timeout,
+	}
+	return &Proxy{
+		Domain:          domain,
+		HealthCheckPath: healthCheckPath,
+		hosts:           make(map[string]*routeSet),
+		routes:          make(map[string]*route),
+		client:          &http.Client{Transport: transport},
+	}
+}
+
+// Reload loads the backends from the database, replacing the routing table.
+// Routes that were already known keep the result of their last health check.
+func (p *Proxy) Reload() error {
+	bs, err := backends()
+	if err != nil {
+		return err
+	}
+	p.setBackends(bs)
+	return nil
+}
+
+func (p *Proxy) setBackends(bs []backend) {
+	p.mut.RLock()
+	old := p.routes
+	p.mut.RUnlock()
+	hosts := make(map[string]*routeSet, len(bs))
+	routes := make(map[string]*route)
+	for _, b := range bs {
+		set := &routeSet{}
+		for _, addr := range b.Routes {
+			r, ok := routes[addr]
+			if !ok {
+				if r, ok = old[addr]; !ok {
+					var err error
+					if r, err = newRoute(addr); err != nil {
+						log.Printf("builtin router: ignoring route of %s: %s", b.Name, err)
+						continue
+					}
+				}
+				routes[addr] = r
+			}
+			set.routes = append(set.routes, r)
+		}
+		hosts[strings.ToLower(b.Name+"."+p.Domain)] = set
+		if b.CName != "" {
+			hosts[strings.ToLower(b.CName)] = set
+		}
+	}
+	p.mut.Lock()
+	p.hosts = hosts
+	p.routes = routes
+	p.mut.Unlock()
+}
+
+// CheckRoutes checks the health of all routes, concurrently. A route is
+// healthy if it answers a GET request to the HealthCheckPath with a status
+// code lower than 500.
+func (p *Proxy) CheckRoutes() {
+	p.mut.RLock()
+	routes := make([]*route, 0, len(p.routes))
+	for _, r := range p.routes {
+		routes = append(routes, r)
+	}
+	p.mut.RUnlock()
+	var wg sync.WaitGroup
+	for _, r := range routes {
+		wg.Add(1)
+		go func(r *route) {
+			defer wg.Done()
+			r.setHealthy(p.check(r))
+		}(r)
+	}
+	wg.Wait()
+}
+
+func (p *Proxy) check(r *route) bool {
+	u := *r.url
+	u.Path = p.HealthCheckPath
+	u.RawQuery = ""
+	resp, err := p.client.Get(u.String())
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < 500
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := strings.ToLower(r.Host)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	p.mut.RLock()
+	set, ok := p.hosts[host]
+	p.mut.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("No app found for %q.", host), http.StatusNotFound)
+		return
+	}
+	rt := set.pick()
+	if rt == nil {
+		http.Error(w, "The app has no units.", http.StatusServiceUnavailable)
+		return
+	}
+	rt.proxy.ServeHTTP(w, r)
+}
+
+func (p *Proxy) reload(ticker <-chan time.Time) {
+	for _ = range ticker {
+		if err := p.Reload(); err != nil {
+			log.Printf("builtin router: failed to reload backends: %s", err)
+		}
+	}
+}
+
+func (p *Proxy) checkRoutes(ticker <-chan time.Time) {
+	for _ = range ticker {
+		p.CheckRoutes()
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	log.Fatal(err)
+}
+
+func getSeconds(key string, def int) time.Duration {
+	n, err := config.GetInt(key)
+	if err != nil || n <= 0 {
+		n = def
+	}
+	return time.Duration(n) * time.Second
+}
+
+// RunServer starts the proxy of the built-in router, listening in the address
+// defined by the "builtin-router:listen" setting.
+func RunServer(flags map[string]interface{}) {
+	configFile, ok := flags["config"].(string)
+	if !ok {
+		configFile = "/etc/tsuru/tsuru.conf"
+	}
+	err := config.ReadAndWatchConfigFile(configFile)
+	if err != nil {
+		fatal(err)
+	}
+	log.Init()
+	dry, ok := flags["dry"].(bool)
+	if !ok {
+		dry = false
+	}
+	d, err := domain()
+	if err != nil {
+		fatal(err)
+	}
+	listen, err := config.GetString("builtin-router:listen")
+	if err != nil {
+		listen = ":80"
+	}
+	path, err := config.GetString("builtin-router:healthcheck-path")
+	if err != nil {
+		path = "/"
+	}
+	timeout := getSeconds("builtin-router:healthcheck-timeout", 3)
+	fmt.Printf("Routing apps in the domain %q.\n\n", d)
+	if !dry {
+		p := NewProxy(d, path, timeout)
+		if err := p.Reload(); err != nil {
+			fatal(err)
+		}
+		p.CheckRoutes()
+		go p.reload(time.Tick(getSeconds("builtin-router:reload-interval", 5)))
+		go p.checkRoutes(time.Tick(getSeconds("builtin-router:healthcheck-interval", 10)))
+		fmt.Printf("tsuru router listening at %s...\n", listen)
+		fatal(http.ListenAndServe(listen, p))
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package builtin
+
+import (
+	"fmt"
+	"launchpad.net/gocheck"
+	"net/http"
+	"net/http/httptest"
+)
+
+type ProxySuite struct {
+	servers []*httptest.Server
+	status  []int
+}
+
+var _ = gocheck.Suite(&ProxySuite{})
+
+func (s *ProxySuite) SetUpTest(c *gocheck.C) {
+	s.status = []int{http.StatusOK, http.StatusOK, http.StatusOK}
+	s.servers = make([]*httptest.Server, len(s.status))
+	for i := range s.servers {
+		i := i
+		s.servers[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/healthcheck" {
+				w.WriteHeader(s.status[i])
+				return
+			}
+			fmt.Fprintf(w, "server %d: %s", i, r.URL.Path)
+		}))
+	}
+}
+
+func (s *ProxySuite) TearDownTest(c *gocheck.C) {
+	for _, srv := range s.servers {
+		srv.Close()
+	}
+}
+
+func (s *ProxySuite) proxy() *Proxy {
+	p := NewProxy("golang.org", "/healthcheck", 1e9)
+	p.setBackends([]backend{
+		{Name: "tip", Routes: []string{s.servers[0].URL, s.servers[1].URL[len("http://"):]}, CName: "Tip.Example.com"},
+		{Name: "tap", Routes: []string{s.servers[2].URL}},
+		{Name: "empty", Routes: []string{}},
+	})
+	return p
+}
+
+func (s *ProxySuite) get(p *Proxy, host, path string) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest("GET", "http://"+host+path, nil)
+	recorder := httptest.NewRecorder()
+	p.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func (s *ProxySuite) TestServeHTTPRoundRobin(c *gocheck.C) {
+	p := s.proxy()
+	var bodies []string
+	for i := 0; i < 4; i++ {
+		recorder := s.get(p, "tip.golang.org", "/hello")
+		c.Assert(recorder.Code, gocheck.Equals, http.StatusOK)
+		bodies = append(bodies, recorder.Body.String())
+	}
+	c.Assert(bodies, gocheck.DeepEquals, []string{
+		"server 0: /hello", "server 1: /hello", "server 0: /hello", "server 1: /hello",
+	})
+}
+
+func (s *ProxySuite) TestServeHTTPCNameAndPort(c *gocheck.C) {
+	p := s.proxy()
+	recorder := s.get(p, "tip.example.com:8080", "/")
+	c.Assert(recorder.Code, gocheck.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), gocheck.Matches, "server [01]: /")
+	recorder = s.get(p, "TAP.golang.org", "/")
+	c.Assert(recorder.Code, gocheck.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), gocheck.Equals, "server 2: /")
+}
+
+func (s *ProxySuite) TestServeHTTPUnknownHost(c *gocheck.C) {
+	p := s.proxy()
+	recorder := s.get(p, "unknown.golang.org", "/")
+	c.Assert(recorder.Code, gocheck.Equals, http.StatusNotFound)
+}
+
+func (s *ProxySuite) TestServeHTTPWithoutRoutes(c *gocheck.C) {
+	p := s.proxy()
+	recorder := s.get(p, "empty.golang.org", "/")
+	c.Assert(recorder.Code, gocheck.Equals, http.StatusServiceUnavailable)
+}
+
+func (s *ProxySuite) TestCheckRoutesSkipsUnhealthyRoutes(c *gocheck.C) {
+	p := s.proxy()
+	s.status[1] = http.StatusInternalServerError
+	p.CheckRoutes()
+	for i := 0; i < 3; i++ {
+		recorder := s.get(p, "tip.golang.org", "/")
+		c.Assert(recorder.Body.String(), gocheck.Equals, "server 0: /")
+	}
+	s.status[1] = http.StatusOK
+	p.CheckRoutes()
+	recorder := s.get(p, "tip.golang.org", "/")
+	c.Assert(recorder.Body.String(), gocheck.Equals, "server 1: /")
+}
+
+func (s *ProxySuite) TestCheckRoutesUnreachableRoute(c *gocheck.C) {
+	p := s.proxy()
+	s.servers[0].Close()
+	p.CheckRoutes()
+	c.Assert(p.hosts["tip.golang.org"].routes[0].isHealthy(), gocheck.Equals, false)
+	c.Assert(p.hosts["tip.golang.org"].routes[1].isHealthy(), gocheck.Equals, true)
+}
+
+func (s *ProxySuite) TestPickWithoutHealthyRoutes(c *gocheck.C) {
+	p := s.proxy()
+	for i := range s.status {
+		s.status[i] = http.StatusInternalServerError
+	}
+	p.CheckRoutes()
+	recorder := s.get(p, "tap.golang.org", "/")
+	c.Assert(recorder.Code, gocheck.Equals, http.StatusOK)
+	c.Assert(recorder.Body.String(), gocheck.Equals, "server 2: /")
+}
+
+func (s *ProxySuite) TestSetBackendsKeepsHealth(c *gocheck.C) {
+	p := s.proxy()
+	s.status[2] = http.StatusInternalServerError
+	p.CheckRoutes()
+	p.setBackends([]backend{
+		{Name: "tap", Routes: []string{s.servers[2].URL, s.servers[0].URL}},
+	})
+	c.Assert(p.hosts, gocheck.HasLen, 1)
+	c.Assert(p.routes, gocheck.HasLen, 2)
+	routes := p.hosts["tap.golang.org"].routes
+	c.Assert(routes[0].isHealthy(), gocheck.Equals, false)
+	c.Assert(routes[1].isHealthy(), gocheck.Equals, true)
+}
+
+func (s *ProxySuite) TestSetBackendsIgnoresInvalidRoutes(c *gocheck.C) {
+	p := NewProxy("golang.org", "/", 1e9)
+	p.setBackends([]backend{{Name: "tip", Routes: []string{"http://", s.servers[0].URL}}})
+	c.Assert(p.hosts["tip.golang.org"].routes, gocheck.HasLen, 1)
+}
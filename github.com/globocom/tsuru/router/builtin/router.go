@@ -0,0 +1,233 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package builtin provides a router implementation that stores backends,
+// routes and CNames in the tsuru database, and a reverse proxy that routes
+// requests using them, run by "tsr router". It does not need any service
+// other than MongoDB, which makes it suitable for small installations and
+// integration tests.
+//
+// In order to use this router, you need to define the "builtin-router:domain"
+// setting, and set the provisioner's router to "builtin".
+package builtin
+
+import (
+	"errors"
+	"fmt"
+	"github.com/globocom/config"
+	"github.com/globocom/tsuru/db"
+	"github.com/globocom/tsuru/router"
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/bson"
+	"strings"
+)
+
+const collectionName = "router_backends"
+
+var (
+	errBackendExists   = errors.New("Backend already exists")
+	errBackendNotFound = errors.New("Backend not found")
+	errRouteNotFound   = errors.New("Route not found")
+	errCNameExists     = errors.New("CName already in use")
+)
+
+func init() {
+	router.Register("builtin", builtinRouter{})
+}
+
+// backend is the representation of a backend in the database. Name is the
+// name of the app, Routes is the list of addresses of its units and CName is
+// the optional alias of the app.
+type backend struct {
+	Name   string `bson:"_id"`
+	Routes []string
+	CName  string `bson:",omitempty"`
+}
+
+func collection() (*db.Storage, *mgo.Collection, error) {
+	conn, err := db.Conn()
+	if err != nil {
+		return nil, nil, err
+	}
+	cnameIndex := mgo.Index{Key: []string{"cname"}, Unique: true, Sparse: true}
+	c := conn.Collection(collectionName)
+	c.EnsureIndex(cnameIndex)
+	return conn, c, nil
+}
+
+func domain() (string, error) {
+	return config.GetString("builtin-router:domain")
+}
+
+type builtinRouter struct{}
+
+func (builtinRouter) AddBackend(name string) error {
+	conn, coll, err := collection()
+	if err != nil {
+		return &routeError{"add", err}
+	}
+	defer conn.Close()
+	err = coll.Insert(backend{Name: name, Routes: []string{}})
+	if e, ok := err.(*mgo.LastError); ok && e.Code == 11000 {
+		return errBackendExists
+	}
+	if err != nil {
+		return &routeError{"add", err}
+	}
+	return nil
+}
+
+func (builtinRouter) RemoveBackend(name string) error {
+	conn, coll, err := collection()
+	if err != nil {
+		return &routeError{"remove", err}
+	}
+	defer conn.Close()
+	err = coll.RemoveId(name)
+	if err == mgo.ErrNotFound {
+		return errBackendNotFound
+	}
+	if err != nil {
+		return &routeError{"remove", err}
+	}
+	return nil
+}
+
+func (builtinRouter) AddRoute(name, address string) error {
+	conn, coll, err := collection()
+	if err != nil {
+		return &routeError{"add", err}
+	}
+	defer conn.Close()
+	err = coll.UpdateId(name, bson.M{"$addToSet": bson.M{"routes": address}})
+	if err == mgo.ErrNotFound {
+		return errBackendNotFound
+	}
+	if err != nil {
+		return &routeError{"add", err}
+	}
+	return nil
+}
+
+func (builtinRouter) RemoveRoute(name, address string) error {
+	conn, coll, err := collection()
+	if err != nil {
+		return &routeError{"remove", err}
+	}
+	defer conn.Close()
+	err = coll.Update(
+		bson.M{"_id": name, "routes": address},
+		bson.M{"$pull": bson.M{"routes": address}},
+	)
+	if err == mgo.ErrNotFound {
+		if n, err := coll.FindId(name).Count(); err == nil && n == 0 {
+			return errBackendNotFound
+		}
+		return errRouteNotFound
+	}
+	if err != nil {
+		return &routeError{"remove", err}
+	}
+	return nil
+}
+
+func (builtinRouter) SetCName(cname, name string) error {
+	d, err := domain()
+	if err != nil {
+		return &routeError{"setCName", err}
+	}
+	if strings.HasSuffix(cname, "."+d) || cname == d {
+		err := fmt.Errorf("Invalid CNAME %s. You can't use Tsuru's application domain.", cname)
+		return &routeError{"setCName", err}
+	}
+	conn, coll, err := collection()
+	if err != nil {
+		return &routeError{"setCName", err}
+	}
+	defer conn.Close()
+	err = coll.UpdateId(name, bson.M{"$set": bson.M{"cname": cname}})
+	if err == mgo.ErrNotFound {
+		return errBackendNotFound
+	}
+	if e, ok := err.(*mgo.LastError); ok && e.Code == 11000 {
+		return errCNameExists
+	}
+	if err != nil {
+		return &routeError{"setCName", err}
+	}
+	return nil
+}
+
+func (builtinRouter) UnsetCName(cname, name string) error {
+	conn, coll, err := collection()
+	if err != nil {
+		return &routeError{"unsetCName", err}
+	}
+	defer conn.Close()
+	err = coll.Update(bson.M{"_id": name, "cname": cname}, bson.M{"$unset": bson.M{"cname": ""}})
+	if err != nil && err != mgo.ErrNotFound {
+		return &routeError{"unsetCName", err}
+	}
+	return nil
+}
+
+func (builtinRouter) Addr(name string) (string, error) {
+	d, err := domain()
+	if err != nil {
+		return "", &routeError{"get", err}
+	}
+	conn, coll, err := collection()
+	if err != nil {
+		return "", &routeError{"get", err}
+	}
+	defer conn.Close()
+	n, err := coll.FindId(name).Count()
+	if err != nil {
+		return "", &routeError{"get", err}
+	}
+	if n == 0 {
+		return "", errBackendNotFound
+	}
+	return name + "." + d, nil
+}
+
+func (builtinRouter) Routes(name string) ([]string, error) {
+	conn, coll, err := collection()
+	if err != nil {
+		return nil, &routeError{"routes", err}
+	}
+	defer conn.Close()
+	var b backend
+	err = coll.FindId(name).One(&b)
+	if err == mgo.ErrNotFound {
+		return nil, errBackendNotFound
+	}
+	if err != nil {
+		return nil, &routeError{"routes", err}
+	}
+	return b.Routes, nil
+}
+
+// backends returns all backends stored in the database.
+func backends() ([]backend, error) {
+	conn, coll, err := collection()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	var result []backend
+	if err := coll.Find(nil).All(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+type routeError struct {
+	op  string
+	err error
+}
+
+func (e *routeError) Error() string {
+	return fmt.Sprintf("Could not %s route: %s", e.op, e.err)
+}
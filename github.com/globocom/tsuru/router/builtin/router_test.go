@@ -0,0 +1,193 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package builtin
+
+import (
+	"github.com/globocom/config"
+	"github.com/globocom/tsuru/router"
+	"launchpad.net/gocheck"
+)
+
+func (s *S) TestShouldBeRegistered(c *gocheck.C) {
+	r, err := router.Get("builtin")
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(r, gocheck.FitsTypeOf, builtinRouter{})
+}
+
+func (s *S) TestAddBackend(c *gocheck.C) {
+	err := builtinRouter{}.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	var b backend
+	err = s.conn.Collection(collectionName).FindId("tip").One(&b)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(b.Routes, gocheck.HasLen, 0)
+	c.Assert(b.CName, gocheck.Equals, "")
+}
+
+func (s *S) TestAddBackendTwice(c *gocheck.C) {
+	err := builtinRouter{}.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = builtinRouter{}.AddBackend("tip")
+	c.Assert(err, gocheck.Equals, errBackendExists)
+}
+
+func (s *S) TestRemoveBackend(c *gocheck.C) {
+	err := builtinRouter{}.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = builtinRouter{}.RemoveBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	n, err := s.conn.Collection(collectionName).FindId("tip").Count()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(n, gocheck.Equals, 0)
+}
+
+func (s *S) TestRemoveUnknownBackend(c *gocheck.C) {
+	err := builtinRouter{}.RemoveBackend("tip")
+	c.Assert(err, gocheck.Equals, errBackendNotFound)
+}
+
+func (s *S) TestAddRoute(c *gocheck.C) {
+	r := builtinRouter{}
+	err := r.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.AddRoute("tip", "http://10.10.10.10:8080")
+	c.Assert(err, gocheck.IsNil)
+	err = r.AddRoute("tip", "10.10.10.11")
+	c.Assert(err, gocheck.IsNil)
+	err = r.AddRoute("tip", "10.10.10.11")
+	c.Assert(err, gocheck.IsNil)
+	routes, err := r.Routes("tip")
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(routes, gocheck.DeepEquals, []string{"http://10.10.10.10:8080", "10.10.10.11"})
+}
+
+func (s *S) TestAddRouteToUnknownBackend(c *gocheck.C) {
+	err := builtinRouter{}.AddRoute("tip", "10.10.10.10")
+	c.Assert(err, gocheck.Equals, errBackendNotFound)
+}
+
+func (s *S) TestRemoveRoute(c *gocheck.C) {
+	r := builtinRouter{}
+	err := r.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.AddRoute("tip", "10.10.10.10")
+	c.Assert(err, gocheck.IsNil)
+	err = r.AddRoute("tip", "10.10.10.11")
+	c.Assert(err, gocheck.IsNil)
+	err = r.RemoveRoute("tip", "10.10.10.10")
+	c.Assert(err, gocheck.IsNil)
+	routes, err := r.Routes("tip")
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(routes, gocheck.DeepEquals, []string{"10.10.10.11"})
+}
+
+func (s *S) TestRemoveUnknownRoute(c *gocheck.C) {
+	r := builtinRouter{}
+	err := r.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.RemoveRoute("tip", "10.10.10.10")
+	c.Assert(err, gocheck.Equals, errRouteNotFound)
+	err = r.RemoveRoute("tap", "10.10.10.10")
+	c.Assert(err, gocheck.Equals, errBackendNotFound)
+}
+
+func (s *S) TestSetCName(c *gocheck.C) {
+	r := builtinRouter{}
+	err := r.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.SetCName("mycname.com", "tip")
+	c.Assert(err, gocheck.IsNil)
+	var b backend
+	err = s.conn.Collection(collectionName).FindId("tip").One(&b)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(b.CName, gocheck.Equals, "mycname.com")
+	err = r.SetCName("othercname.com", "tip")
+	c.Assert(err, gocheck.IsNil)
+	err = s.conn.Collection(collectionName).FindId("tip").One(&b)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(b.CName, gocheck.Equals, "othercname.com")
+}
+
+func (s *S) TestSetCNameInUse(c *gocheck.C) {
+	r := builtinRouter{}
+	err := r.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.AddBackend("tap")
+	c.Assert(err, gocheck.IsNil)
+	err = r.SetCName("mycname.com", "tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.SetCName("mycname.com", "tap")
+	c.Assert(err, gocheck.Equals, errCNameExists)
+}
+
+func (s *S) TestSetCNameSubdomainOfTheDomain(c *gocheck.C) {
+	r := builtinRouter{}
+	err := r.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.SetCName("tip.golang.org", "tip")
+	c.Assert(err, gocheck.ErrorMatches, "Could not setCName route: Invalid CNAME tip.golang.org. You can't use Tsuru's application domain.")
+}
+
+func (s *S) TestSetCNameUnknownBackend(c *gocheck.C) {
+	err := builtinRouter{}.SetCName("mycname.com", "tip")
+	c.Assert(err, gocheck.Equals, errBackendNotFound)
+}
+
+func (s *S) TestUnsetCName(c *gocheck.C) {
+	r := builtinRouter{}
+	err := r.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.SetCName("mycname.com", "tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.UnsetCName("othercname.com", "tip")
+	c.Assert(err, gocheck.IsNil)
+	var b backend
+	err = s.conn.Collection(collectionName).FindId("tip").One(&b)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(b.CName, gocheck.Equals, "mycname.com")
+	err = r.UnsetCName("mycname.com", "tip")
+	c.Assert(err, gocheck.IsNil)
+	err = s.conn.Collection(collectionName).FindId("tip").One(&b)
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(b.CName, gocheck.Equals, "")
+}
+
+func (s *S) TestAddr(c *gocheck.C) {
+	r := builtinRouter{}
+	err := r.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	addr, err := r.Addr("tip")
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(addr, gocheck.Equals, "tip.golang.org")
+}
+
+func (s *S) TestAddrUnknownBackend(c *gocheck.C) {
+	_, err := builtinRouter{}.Addr("tip")
+	c.Assert(err, gocheck.Equals, errBackendNotFound)
+}
+
+func (s *S) TestAddrWithoutDomain(c *gocheck.C) {
+	old, _ := config.Get("builtin-router:domain")
+	defer config.Set("builtin-router:domain", old)
+	config.Unset("builtin-router:domain")
+	_, err := builtinRouter{}.Addr("tip")
+	c.Assert(err, gocheck.ErrorMatches, "^Could not get route: .*")
+}
+
+func (s *S) TestReload(c *gocheck.C) {
+	r := builtinRouter{}
+	err := r.AddBackend("tip")
+	c.Assert(err, gocheck.IsNil)
+	err = r.AddRoute("tip", "10.10.10.10")
+	c.Assert(err, gocheck.IsNil)
+	err = r.SetCName("mycname.com", "tip")
+	c.Assert(err, gocheck.IsNil)
+	p := NewProxy("golang.org", "/", 1e9)
+	err = p.Reload()
+	c.Assert(err, gocheck.IsNil)
+	c.Assert(p.hosts, gocheck.HasLen, 2)
+	c.Assert(p.hosts["tip.golang.org"].routes[0].addr, gocheck.Equals, "10.10.10.10")
+	c.Assert(p.hosts["mycname.com"], gocheck.Equals, p.hosts["tip.golang.org"])
+}
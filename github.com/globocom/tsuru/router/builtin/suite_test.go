@@ -0,0 +1,38 @@
+// Copyright 2013 tsuru authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package builtin
+
+import (
+	"github.com/globocom/config"
+	"github.com/globocom/tsuru/db"
+	"launchpad.net/gocheck"
+	"testing"
+)
+
+func Test(t *testing.T) { gocheck.TestingT(t) }
+
+type S struct {
+	conn *db.Storage
+}
+
+var _ = gocheck.Suite(&S{})
+
+func (s *S) SetUpSuite(c *gocheck.C) {
+	config.Set("database:url", "127.0.0.1:27017")
+	config.Set("database:name", "router_builtin_tests")
+	config.Set("builtin-router:domain", "golang.org")
+	var err error
+	s.conn, err = db.Conn()
+	c.Assert(err, gocheck.IsNil)
+}
+
+func (s *S) TearDownTest(c *gocheck.C) {
+	s.conn.Collection(collectionName).RemoveAll(nil)
+}
+
+func (s *S) TearDownSuite(c *gocheck.C) {
+	s.conn.Collection(collectionName).Database.DropDatabase()
+	s.conn.Close()
+}
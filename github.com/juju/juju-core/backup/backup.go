@@ -0,0 +1,203 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The backup package reads and writes backups of a juju environment's
+// state server. A backup is a gzipped tar archive holding a dump of
+// the state database, in the format written by mongodump, together
+// with the agent configurations and certificates of the state server:
+//
+//	juju-backup/dump/juju/machines.bson
+//	juju-backup/dump/juju/services.bson
+//	...
+//	juju-backup/agents/machine-0/agent.conf
+//	juju-backup/server.pem
+//
+// Only the database dump is used by Archive.Restore; the agent
+// configurations and certificates are kept for manual recovery.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"launchpad.net/juju-core/state"
+)
+
+const (
+	topDir  = "juju-backup/"
+	dumpDir = topDir + "dump/juju/"
+)
+
+// Archive holds the contents of a backup.
+type Archive struct {
+	// Collections holds the documents of each collection in the
+	// state database, keyed by collection name, as returned by
+	// state.DumpDatabase.
+	Collections map[string][]byte
+
+	// Files holds the contents of the state server's files, keyed
+	// by their slash-separated path relative to the data directory.
+	Files map[string][]byte
+}
+
+// stateServerFiles returns the paths, relative to dataDir, of the state
+// server's files that are included in a backup. It fails if dataDir
+// holds no agent configuration.
+func stateServerFiles(dataDir string) ([]string, error) {
+	confs, err := filepath.Glob(filepath.Join(dataDir, "agents", "*", "agent.conf"))
+	if err != nil {
+		return nil, err
+	}
+	if len(confs) == 0 {
+		return nil, fmt.Errorf("no agent configuration found in %q", dataDir)
+	}
+	var files []string
+	for _, conf := range confs {
+		rel, err := filepath.Rel(dataDir, conf)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "server.pem")); err == nil {
+		files = append(files, "server.pem")
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return files, nil
+}
+
+// New returns a backup of the state held in st, and of the state
+// server files in dataDir, which must hold at least one agent
+// configuration. If dataDir is empty, no files are included.
+func New(st *state.State, dataDir string) (*Archive, error) {
+	collections, err := st.DumpDatabase()
+	if err != nil {
+		return nil, err
+	}
+	a := &Archive{
+		Collections: collections,
+		Files:       make(map[string][]byte),
+	}
+	if dataDir == "" {
+		return a, nil
+	}
+	files, err := stateServerFiles(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find state server files: %v", err)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dataDir, filepath.FromSlash(file)))
+		if err != nil {
+			return nil, err
+		}
+		a.Files[file] = data
+	}
+	return a, nil
+}
+
+// Write writes the archive to w in gzipped tar format.
+func (a *Archive) Write(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	now := time.Now()
+	add := func(name string, mode int64, data []byte) error {
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     mode,
+			Size:     int64(len(data)),
+			ModTime:  now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	for _, name := range sortedKeys(a.Collections) {
+		if err := add(dumpDir+name+".bson", 0600, a.Collections[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range sortedKeys(a.Files) {
+		if err := add(topDir+name, 0600, a.Files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Read reads an archive in the format written by Write.
+func Read(r io.Reader) (*Archive, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read backup: %v", err)
+	}
+	defer zr.Close()
+	a := &Archive{
+		Collections: make(map[string][]byte),
+		Files:       make(map[string][]byte),
+	}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read backup: %v", err)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || !strings.HasPrefix(name, topDir) {
+			return nil, fmt.Errorf("bad file %q in backup", hdr.Name)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read backup: %v", err)
+		}
+		if strings.HasPrefix(name, dumpDir) {
+			coll := name[len(dumpDir):]
+			if !strings.HasSuffix(coll, ".bson") || strings.Contains(coll, "/") {
+				return nil, fmt.Errorf("bad file %q in backup", hdr.Name)
+			}
+			a.Collections[coll[:len(coll)-len(".bson")]] = data
+		} else {
+			a.Files[name[len(topDir):]] = data
+		}
+	}
+	if _, ok := a.Collections["machines"]; !ok {
+		return nil, fmt.Errorf("backup contains no state database")
+	}
+	return a, nil
+}
+
+// Restore replaces the state held in st with the state in the
+// archive. See state.RestoreDatabase for details.
+func (a *Archive) Restore(st *state.State) error {
+	return st.RestoreDatabase(a.Collections)
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
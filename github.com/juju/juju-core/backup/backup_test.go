@@ -0,0 +1,135 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backup_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	stdtesting "testing"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/backup"
+	"launchpad.net/juju-core/juju/testing"
+	coretesting "launchpad.net/juju-core/testing"
+)
+
+func Test(t *stdtesting.T) {
+	coretesting.MgoTestPackage(t)
+}
+
+type BackupSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = Suite(&BackupSuite{})
+
+func (s *BackupSuite) writeFile(c *C, name, data string) {
+	path := filepath.Join(s.DataDir(), name)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(path, []byte(data), 0600)
+	c.Assert(err, IsNil)
+}
+
+func (s *BackupSuite) TestNew(c *C) {
+	s.writeFile(c, "agents/machine-0/agent.conf", "machine conf")
+	s.writeFile(c, "agents/unit-wordpress-0/agent.conf", "unit conf")
+	s.writeFile(c, "agents/machine-0/other", "ignored")
+	s.writeFile(c, "server.pem", "pem")
+
+	a, err := backup.New(s.State, s.DataDir())
+	c.Assert(err, IsNil)
+	c.Assert(a.Files, DeepEquals, map[string][]byte{
+		"agents/machine-0/agent.conf":        []byte("machine conf"),
+		"agents/unit-wordpress-0/agent.conf": []byte("unit conf"),
+		"server.pem":                         []byte("pem"),
+	})
+	dump, err := s.State.DumpDatabase()
+	c.Assert(err, IsNil)
+	c.Assert(a.Collections, DeepEquals, dump)
+}
+
+func (s *BackupSuite) TestNewWithoutStateServerFiles(c *C) {
+	s.writeFile(c, "server.pem", "pem")
+	_, err := backup.New(s.State, s.DataDir())
+	c.Assert(err, ErrorMatches, `cannot find state server files: no agent configuration found in ".*"`)
+}
+
+func (s *BackupSuite) TestNewWithoutDataDir(c *C) {
+	a, err := backup.New(s.State, "")
+	c.Assert(err, IsNil)
+	c.Assert(a.Files, HasLen, 0)
+	c.Assert(a.Collections["machines"], NotNil)
+}
+
+func (s *BackupSuite) TestWriteRead(c *C) {
+	s.writeFile(c, "agents/machine-0/agent.conf", "machine conf")
+	a, err := backup.New(s.State, s.DataDir())
+	c.Assert(err, IsNil)
+	var buf bytes.Buffer
+	err = a.Write(&buf)
+	c.Assert(err, IsNil)
+	a1, err := backup.Read(&buf)
+	c.Assert(err, IsNil)
+	c.Assert(a1, DeepEquals, a)
+}
+
+func (s *BackupSuite) TestRestore(c *C) {
+	_, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	a, err := backup.New(s.State, "")
+	c.Assert(err, IsNil)
+	_, err = s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+
+	err = a.Restore(s.State)
+	c.Assert(err, IsNil)
+	services, err := s.State.AllServices()
+	c.Assert(err, IsNil)
+	c.Assert(services, HasLen, 1)
+	c.Assert(services[0].Name(), Equals, "wordpress")
+}
+
+var readErrorTests = []struct {
+	about string
+	files []*coretesting.TarFile
+	err   string
+}{{
+	about: "no database",
+	files: []*coretesting.TarFile{
+		coretesting.NewTarFile("juju-backup/server.pem", 0600, "pem"),
+	},
+	err: "backup contains no state database",
+}, {
+	about: "file outside the backup",
+	files: []*coretesting.TarFile{
+		coretesting.NewTarFile("juju-backup/../etc/passwd", 0600, ""),
+	},
+	err: `bad file "juju-backup/../etc/passwd" in backup`,
+}, {
+	about: "unexpected dump file",
+	files: []*coretesting.TarFile{
+		coretesting.NewTarFile("juju-backup/dump/juju/machines.json", 0600, ""),
+	},
+	err: `bad file "juju-backup/dump/juju/machines.json" in backup`,
+}, {
+	about: "symlink",
+	files: []*coretesting.TarFile{
+		coretesting.NewTarFile("juju-backup/server.pem", os.ModeSymlink, ""),
+	},
+	err: `bad file "juju-backup/server.pem" in backup`,
+}}
+
+func (s *BackupSuite) TestReadErrors(c *C) {
+	for i, t := range readErrorTests {
+		c.Logf("test %d: %s", i, t.about)
+		_, err := backup.Read(bytes.NewReader(coretesting.TarGz(t.files...)))
+		c.Check(err, ErrorMatches, t.err)
+	}
+	_, err := backup.Read(bytes.NewReader([]byte("not gzipped")))
+	c.Check(err, ErrorMatches, "cannot read backup: .*")
+}
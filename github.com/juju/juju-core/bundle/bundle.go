@@ -0,0 +1,129 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The bundle package describes the topology of a juju environment: its
+// services, how they are configured and how they are related. An
+// environment bundle is unrelated to a charm bundle, which is a packed
+// charm.
+//
+// Bundles are written in YAML, for instance:
+//
+//	services:
+//	  wordpress:
+//	    charm: cs:precise/wordpress-3
+//	    num_units: 2
+//	    options:
+//	      blog-title: My blog
+//	    constraints: mem=2G
+//	    expose: true
+//	  mysql:
+//	    charm: cs:precise/mysql
+//	    num_units: 1
+//	relations:
+//	- [wordpress:db, mysql:server]
+package bundle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/state"
+)
+
+// Bundle describes the services of an environment and the relations
+// between them.
+type Bundle struct {
+	Services map[string]*Service `yaml:"services"`
+	// Relations holds the relations between services. Each relation
+	// holds two endpoints, in the form "service" or "service:relation".
+	Relations [][]string `yaml:"relations,omitempty"`
+}
+
+// Service describes a service in a Bundle.
+type Service struct {
+	// Charm holds the URL of the charm of the service. If it has no
+	// revision, the latest revision is used.
+	Charm string `yaml:"charm"`
+	// NumUnits holds the number of units of the service. It must be
+	// zero for subordinate services.
+	NumUnits int `yaml:"num_units,omitempty"`
+	// Options holds charm configuration settings for the service.
+	Options map[string]interface{} `yaml:"options,omitempty"`
+	// Constraints holds the constraints of the service, in the format
+	// accepted by constraints.Parse.
+	Constraints string `yaml:"constraints,omitempty"`
+	// Expose holds whether the service is exposed.
+	Expose bool `yaml:"expose,omitempty"`
+}
+
+// Parse parses and verifies a bundle in YAML format.
+func Parse(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := goyaml.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("cannot parse bundle: %v", err)
+	}
+	if err := b.Verify(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Marshal returns the bundle in YAML format.
+func (b *Bundle) Marshal() ([]byte, error) {
+	return goyaml.Marshal(b)
+}
+
+// Verify checks that the bundle is well formed: that service names,
+// charm URLs and constraints are valid, and that relations are
+// between services in the bundle.
+func (b *Bundle) Verify() error {
+	if len(b.Services) == 0 {
+		return fmt.Errorf("bundle has no services")
+	}
+	for _, name := range b.ServiceNames() {
+		svc := b.Services[name]
+		if !state.IsServiceName(name) {
+			return fmt.Errorf("invalid service name %q", name)
+		}
+		if svc == nil || svc.Charm == "" {
+			return fmt.Errorf("service %q has no charm", name)
+		}
+		if _, err := charm.ParseURL(svc.Charm); err != nil {
+			return fmt.Errorf("service %q: %v", name, err)
+		}
+		if svc.NumUnits < 0 {
+			return fmt.Errorf("service %q has negative number of units", name)
+		}
+		if _, err := constraints.Parse(svc.Constraints); err != nil {
+			return fmt.Errorf("service %q: %v", name, err)
+		}
+	}
+	for _, rel := range b.Relations {
+		if len(rel) != 2 {
+			return fmt.Errorf("relation %q must have two endpoints", rel)
+		}
+		for _, ep := range rel {
+			name := strings.SplitN(ep, ":", 2)[0]
+			if b.Services[name] == nil {
+				return fmt.Errorf("relation %q refers to unknown service %q", rel, name)
+			}
+		}
+	}
+	return nil
+}
+
+// ServiceNames returns the names of the services in the bundle, in
+// alphabetical order.
+func (b *Bundle) ServiceNames() []string {
+	names := make([]string, 0, len(b.Services))
+	for name := range b.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,134 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package bundle_test
+
+import (
+	stdtesting "testing"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/testing"
+)
+
+func Test(t *stdtesting.T) {
+	TestingT(t)
+}
+
+type BundleSuite struct {
+	testing.LoggingSuite
+}
+
+var _ = Suite(&BundleSuite{})
+
+const wordpressBundle = `
+services:
+  wordpress:
+    charm: cs:precise/wordpress-3
+    num_units: 2
+    options:
+      blog-title: My blog
+      skip: 3
+    constraints: mem=2G
+    expose: true
+  mysql:
+    charm: cs:precise/mysql
+    num_units: 1
+  logging:
+    charm: cs:precise/logging
+relations:
+- [wordpress:db, mysql:server]
+- [wordpress, logging]
+`
+
+func (*BundleSuite) TestParse(c *C) {
+	b, err := bundle.Parse([]byte(wordpressBundle))
+	c.Assert(err, IsNil)
+	c.Assert(b, DeepEquals, &bundle.Bundle{
+		Services: map[string]*bundle.Service{
+			"wordpress": {
+				Charm:    "cs:precise/wordpress-3",
+				NumUnits: 2,
+				Options: map[string]interface{}{
+					"blog-title": "My blog",
+					"skip":       3,
+				},
+				Constraints: "mem=2G",
+				Expose:      true,
+			},
+			"mysql": {
+				Charm:    "cs:precise/mysql",
+				NumUnits: 1,
+			},
+			"logging": {
+				Charm: "cs:precise/logging",
+			},
+		},
+		Relations: [][]string{
+			{"wordpress:db", "mysql:server"},
+			{"wordpress", "logging"},
+		},
+	})
+	c.Assert(b.ServiceNames(), DeepEquals, []string{"logging", "mysql", "wordpress"})
+}
+
+func (*BundleSuite) TestMarshalRoundTrip(c *C) {
+	b, err := bundle.Parse([]byte(wordpressBundle))
+	c.Assert(err, IsNil)
+	data, err := b.Marshal()
+	c.Assert(err, IsNil)
+	b1, err := bundle.Parse(data)
+	c.Assert(err, IsNil)
+	c.Assert(b1, DeepEquals, b)
+}
+
+var verifyErrorTests = []struct {
+	about    string
+	bundle   string
+	errMatch string
+}{{
+	about:    "invalid yaml",
+	bundle:   "services: [",
+	errMatch: "cannot parse bundle: .*",
+}, {
+	about:    "no services",
+	bundle:   "relations: []",
+	errMatch: "bundle has no services",
+}, {
+	about:    "bad service name",
+	bundle:   "services: {Foo: {charm: cs:precise/mysql}}",
+	errMatch: `invalid service name "Foo"`,
+}, {
+	about:    "no charm",
+	bundle:   "services: {mysql: {num_units: 1}}",
+	errMatch: `service "mysql" has no charm`,
+}, {
+	about:    "bad charm url",
+	bundle:   "services: {mysql: {charm: 'cs:mysql'}}",
+	errMatch: `service "mysql": charm URL without series: "cs:mysql"`,
+}, {
+	about:    "negative units",
+	bundle:   "services: {mysql: {charm: 'cs:precise/mysql', num_units: -1}}",
+	errMatch: `service "mysql" has negative number of units`,
+}, {
+	about:    "bad constraints",
+	bundle:   "services: {mysql: {charm: 'cs:precise/mysql', constraints: 'foo=bar'}}",
+	errMatch: `service "mysql": unknown constraint "foo"`,
+}, {
+	about:    "relation with one endpoint",
+	bundle:   "services: {mysql: {charm: 'cs:precise/mysql'}}\nrelations: [[mysql]]",
+	errMatch: `relation \["mysql"\] must have two endpoints`,
+}, {
+	about:    "relation to unknown service",
+	bundle:   "services: {mysql: {charm: 'cs:precise/mysql'}}\nrelations: [[mysql, 'wordpress:db']]",
+	errMatch: `relation \["mysql" "wordpress:db"\] refers to unknown service "wordpress"`,
+}}
+
+func (*BundleSuite) TestVerifyErrors(c *C) {
+	for i, test := range verifyErrorTests {
+		c.Logf("test %d: %s", i, test.about)
+		_, err := bundle.Parse([]byte(test.bundle))
+		c.Check(err, ErrorMatches, test.errMatch)
+	}
+}
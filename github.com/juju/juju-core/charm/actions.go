@@ -0,0 +1,180 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/schema"
+)
+
+// ActionParam describes a single parameter accepted by an action.
+// The supported types are the same as those of config options.
+type ActionParam struct {
+	Type        string
+	Description string
+	Default     interface{}
+}
+
+// ActionSpec describes a single action, as declared in a charm's
+// actions.yaml file.
+type ActionSpec struct {
+	Description string
+	Params      map[string]ActionParam
+	// Required holds the names of the parameters that must
+	// be supplied when the action is invoked.
+	Required []string
+}
+
+// Actions represents the actions supported by a charm, as declared
+// in its actions.yaml file.
+type Actions struct {
+	ActionSpecs map[string]ActionSpec
+}
+
+// NewActions returns a new Actions without any actions.
+func NewActions() *Actions {
+	return &Actions{map[string]ActionSpec{}}
+}
+
+var (
+	validActionName = regexp.MustCompile("^[a-z][a-z0-9]*(-[a-z0-9]+)*$")
+	validParamName  = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_-]*$")
+)
+
+var actionParamSchema = schema.FieldMap(
+	schema.Fields{
+		"type": schema.OneOf(
+			schema.Const("string"),
+			schema.Const("int"),
+			schema.Const("float"),
+			schema.Const("boolean"),
+		),
+		"description": schema.String(),
+		"default":     schema.Any(),
+	},
+	schema.Defaults{
+		"type":        "string",
+		"description": "",
+		"default":     schema.Omit,
+	},
+)
+
+var actionsSchema = schema.StringMap(schema.FieldMap(
+	schema.Fields{
+		"description": schema.String(),
+		"params":      schema.StringMap(actionParamSchema),
+		"required":    schema.List(schema.String()),
+	},
+	schema.Defaults{
+		"description": "",
+		"params":      schema.Omit,
+		"required":    schema.Omit,
+	},
+))
+
+// ReadActions reads an Actions in YAML format.
+func ReadActions(r io.Reader) (*Actions, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	raw := make(map[interface{}]interface{})
+	if err := goyaml.Unmarshal(data, raw); err != nil {
+		return nil, err
+	}
+	for name, spec := range raw {
+		// An action need not declare anything beyond its name.
+		if spec == nil {
+			raw[name] = map[interface{}]interface{}{}
+		}
+	}
+	v, err := actionsSchema.Coerce(raw, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid actions: %v", err)
+	}
+	actions := NewActions()
+	for name, rawSpec := range v.(map[string]interface{}) {
+		if !validActionName.MatchString(name) {
+			return nil, fmt.Errorf("invalid actions: bad action name %q", name)
+		}
+		spec, err := parseActionSpec(name, rawSpec.(map[string]interface{}))
+		if err != nil {
+			return nil, fmt.Errorf("invalid actions: %v", err)
+		}
+		actions.ActionSpecs[name] = spec
+	}
+	return actions, nil
+}
+
+func parseActionSpec(name string, m map[string]interface{}) (ActionSpec, error) {
+	spec := ActionSpec{
+		Description: m["description"].(string),
+		Params:      make(map[string]ActionParam),
+	}
+	if params, ok := m["params"].(map[string]interface{}); ok {
+		for pname, rawParam := range params {
+			if !validParamName.MatchString(pname) {
+				return ActionSpec{}, fmt.Errorf("action %q has bad parameter name %q", name, pname)
+			}
+			p := rawParam.(map[string]interface{})
+			param := ActionParam{
+				Type:        p["type"].(string),
+				Description: p["description"].(string),
+			}
+			if def := p["default"]; def != nil {
+				var err error
+				param.Default, err = optionTypeCheckers[param.Type].Coerce(def, nil)
+				if err != nil {
+					return ActionSpec{}, fmt.Errorf("action %q parameter %q default expected %s, got %#v", name, pname, param.Type, def)
+				}
+			}
+			spec.Params[pname] = param
+		}
+	}
+	if required, ok := m["required"].([]interface{}); ok {
+		for _, r := range required {
+			pname := r.(string)
+			if _, ok := spec.Params[pname]; !ok {
+				return ActionSpec{}, fmt.Errorf("action %q requires unknown parameter %q", name, pname)
+			}
+			spec.Required = append(spec.Required, pname)
+		}
+	}
+	return spec, nil
+}
+
+// ValidateParams returns a copy of the supplied parameters with a
+// consistent type for each value, and with the defaults of any
+// parameters not supplied filled in. It returns an error if the
+// parameters contain unknown names or invalid values, or if a
+// required parameter is missing.
+func (spec ActionSpec) ValidateParams(params map[string]interface{}) (map[string]interface{}, error) {
+	fields := make(schema.Fields)
+	defaults := make(schema.Defaults)
+	for name, param := range spec.Params {
+		fields[name] = optionTypeCheckers[param.Type]
+		if param.Default != nil {
+			defaults[name] = param.Default
+		} else {
+			defaults[name] = schema.Omit
+		}
+	}
+	for _, name := range spec.Required {
+		delete(defaults, name)
+	}
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	v, err := schema.StrictFieldMap(fields, defaults).Coerce(params, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %v", err)
+	}
+	return v.(map[string]interface{}), nil
+}
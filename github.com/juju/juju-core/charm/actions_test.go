@@ -0,0 +1,145 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package charm_test
+
+import (
+	"bytes"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/charm"
+)
+
+type ActionsSuite struct{}
+
+var _ = Suite(&ActionsSuite{})
+
+const sampleActions = `
+snapshot:
+  description: Take a snapshot of the database.
+  params:
+    outfile:
+      description: The file to write out to.
+      type: string
+      default: foo.bz2
+    level:
+      type: int
+    ratio:
+      type: float
+      default: 0.5
+  required: [level]
+rotate-logs:
+`
+
+func readActions(c *C, data string) *charm.Actions {
+	actions, err := charm.ReadActions(bytes.NewBufferString(data))
+	c.Assert(err, IsNil)
+	return actions
+}
+
+func (s *ActionsSuite) TestReadSample(c *C) {
+	actions := readActions(c, sampleActions)
+	c.Assert(actions.ActionSpecs, DeepEquals, map[string]charm.ActionSpec{
+		"snapshot": {
+			Description: "Take a snapshot of the database.",
+			Params: map[string]charm.ActionParam{
+				"outfile": {
+					Type:        "string",
+					Description: "The file to write out to.",
+					Default:     "foo.bz2",
+				},
+				"level": {
+					Type: "int",
+				},
+				"ratio": {
+					Type:    "float",
+					Default: 0.5,
+				},
+			},
+			Required: []string{"level"},
+		},
+		"rotate-logs": {
+			Params: map[string]charm.ActionParam{},
+		},
+	})
+}
+
+func (s *ActionsSuite) TestReadEmpty(c *C) {
+	actions := readActions(c, "")
+	c.Assert(actions.ActionSpecs, HasLen, 0)
+}
+
+var readActionsErrorTests = []struct {
+	about  string
+	yaml   string
+	expect string
+}{{
+	about:  "bad action name",
+	yaml:   "Snapshot:\n",
+	expect: `invalid actions: bad action name "Snapshot"`,
+}, {
+	about:  "bad parameter name",
+	yaml:   "snapshot:\n  params:\n    out.file: {type: string}\n",
+	expect: `invalid actions: action "snapshot" has bad parameter name "out.file"`,
+}, {
+	about:  "unknown parameter type",
+	yaml:   "snapshot:\n  params:\n    outfile: {type: file}\n",
+	expect: `invalid actions: snapshot.params.outfile.type: unexpected value "file"`,
+}, {
+	about:  "bad default",
+	yaml:   "snapshot:\n  params:\n    level: {type: int, default: high}\n",
+	expect: `invalid actions: action "snapshot" parameter "level" default expected int, got "high"`,
+}, {
+	about:  "unknown required parameter",
+	yaml:   "snapshot:\n  required: [outfile]\n",
+	expect: `invalid actions: action "snapshot" requires unknown parameter "outfile"`,
+}}
+
+func (s *ActionsSuite) TestReadErrors(c *C) {
+	for i, t := range readActionsErrorTests {
+		c.Logf("test %d: %s", i, t.about)
+		_, err := charm.ReadActions(bytes.NewBufferString(t.yaml))
+		c.Check(err, ErrorMatches, t.expect)
+	}
+}
+
+var validateParamsTests = []struct {
+	about  string
+	params map[string]interface{}
+	expect map[string]interface{}
+	err    string
+}{{
+	about:  "defaults are filled in",
+	params: map[string]interface{}{"level": 3},
+	expect: map[string]interface{}{"outfile": "foo.bz2", "level": int64(3), "ratio": 0.5},
+}, {
+	about:  "supplied values override defaults",
+	params: map[string]interface{}{"level": 3, "outfile": "bar.bz2", "ratio": 1.0},
+	expect: map[string]interface{}{"outfile": "bar.bz2", "level": int64(3), "ratio": 1.0},
+}, {
+	about: "missing required parameter",
+	err:   `invalid parameters: level: expected int, got nothing`,
+}, {
+	about:  "bad value",
+	params: map[string]interface{}{"level": "high"},
+	err:    `invalid parameters: level: expected int, got "high"`,
+}, {
+	about:  "unknown parameter",
+	params: map[string]interface{}{"level": 3, "colour": "blue"},
+	err:    `invalid parameters: colour: expected nothing, got "blue"`,
+}}
+
+func (s *ActionsSuite) TestValidateParams(c *C) {
+	spec := readActions(c, sampleActions).ActionSpecs["snapshot"]
+	for i, t := range validateParamsTests {
+		c.Logf("test %d: %s", i, t.about)
+		params, err := spec.ValidateParams(t.params)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+		} else {
+			c.Check(err, IsNil)
+			c.Check(params, DeepEquals, t.expect)
+		}
+	}
+}
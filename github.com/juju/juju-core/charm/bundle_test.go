@@ -43,9 +43,10 @@ func (s *BundleSuite) TestReadBundleWithoutConfig(c *C) {
 	bundle, err := charm.ReadBundle(path)
 	c.Assert(err, IsNil)
 
-	// A lacking config.yaml file still causes a proper
-	// Config value to be returned.
+	// Lacking config.yaml and actions.yaml files still cause
+	// proper Config and Actions values to be returned.
 	c.Assert(bundle.Config().Options, HasLen, 0)
+	c.Assert(bundle.Actions().ActionSpecs, HasLen, 0)
 }
 
 func (s *BundleSuite) TestReadBundleBytes(c *C) {
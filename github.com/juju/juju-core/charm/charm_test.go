@@ -70,6 +70,7 @@ func checkDummy(c *C, f charm.Charm, path string) {
 	c.Assert(f.Revision(), Equals, 1)
 	c.Assert(f.Meta().Name, Equals, "dummy")
 	c.Assert(f.Config().Options["title"].Default, Equals, "My Title")
+	c.Assert(f.Actions().ActionSpecs["snapshot"].Params["outfile"].Default, Equals, "foo.bz2")
 	switch f := f.(type) {
 	case *charm.Bundle:
 		c.Assert(f.Path, Equals, path)
@@ -37,9 +37,10 @@ func (s *DirSuite) TestReadDirWithoutConfig(c *C) {
 	dir, err := charm.ReadDir(path)
 	c.Assert(err, IsNil)
 
-	// A lacking config.yaml file still causes a proper
-	// Config value to be returned.
+	// Lacking config.yaml and actions.yaml files still cause
+	// proper Config and Actions values to be returned.
 	c.Assert(dir.Config().Options, HasLen, 0)
+	c.Assert(dir.Actions().ActionSpecs, HasLen, 0)
 }
 
 func (s *DirSuite) TestBundleTo(c *C) {
@@ -0,0 +1,36 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cmd
+
+import (
+	"strings"
+
+	"launchpad.net/gnuflag"
+)
+
+// StringsValue implements gnuflag.Value for a comma separated list of
+// strings.  This allows flags to be created where the target is []string,
+// and the caller is after comma separated values.
+type StringsValue []string
+
+var _ gnuflag.Value = (*StringsValue)(nil)
+
+// NewStringsValue is used to create the type passed into the gnuflag.FlagSet Var function.
+// f.Var(cmd.NewStringsValue(defaultValue, &someMember), "name", "help")
+func NewStringsValue(defaultValue []string, target *[]string) *StringsValue {
+	value := (*StringsValue)(target)
+	*value = defaultValue
+	return value
+}
+
+// Set stores the comma separated values in the target slice.
+func (v *StringsValue) Set(s string) error {
+	*v = strings.Split(s, ",")
+	return nil
+}
+
+// String returns the values joined with commas.
+func (v *StringsValue) String() string {
+	return strings.Join(*v, ",")
+}
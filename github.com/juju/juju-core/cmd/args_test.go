@@ -0,0 +1,39 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cmd_test
+
+import (
+	"launchpad.net/gnuflag"
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+)
+
+type ArgsSuite struct{}
+
+var _ = Suite(&ArgsSuite{})
+
+func (*ArgsSuite) TestStringsValue(c *C) {
+	for i, test := range []struct {
+		args     []string
+		expected []string
+	}{{
+		args:     nil,
+		expected: []string{"default"},
+	}, {
+		args:     []string{"--value", "foo"},
+		expected: []string{"foo"},
+	}, {
+		args:     []string{"--value", "foo,bar,baz"},
+		expected: []string{"foo", "bar", "baz"},
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		var values []string
+		f := gnuflag.NewFlagSet("test", gnuflag.ContinueOnError)
+		f.Var(cmd.NewStringsValue([]string{"default"}, &values), "value", "help")
+		err := f.Parse(false, test.args)
+		c.Assert(err, IsNil)
+		c.Assert(values, DeepEquals, test.expected)
+	}
+}
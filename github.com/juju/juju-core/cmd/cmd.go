@@ -20,6 +20,23 @@ import (
 // code 1 without producing error output.
 var ErrSilent = errors.New("cmd: error out silently")
 
+// RcPassthroughError can be returned from Run by a command that has
+// already produced its output, to signal that Main should exit silently
+// with the given code.
+type RcPassthroughError struct {
+	Code int
+}
+
+func (e *RcPassthroughError) Error() string {
+	return fmt.Sprintf("rc: %v", e.Code)
+}
+
+// IsRcPassthroughError returns whether the error is an RcPassthroughError.
+func IsRcPassthroughError(err error) bool {
+	_, ok := err.(*RcPassthroughError)
+	return ok
+}
+
 // Command is implemented by types that interpret command-line arguments.
 type Command interface {
 	// Info returns information about the Command.
@@ -160,6 +177,9 @@ func Main(c Command, ctx *Context, args []string) int {
 		return rc
 	}
 	if err := c.Run(ctx); err != nil {
+		if IsRcPassthroughError(err) {
+			return err.(*RcPassthroughError).Code
+		}
 		if err != ErrSilent {
 			fmt.Fprintf(ctx.Stderr, "error: %v\n", err)
 		}
@@ -71,6 +71,14 @@ func (s *CmdSuite) TestMainRunError(c *C) {
 	c.Assert(bufferString(ctx.Stderr), Equals, "error: BAM!\n")
 }
 
+func (s *CmdSuite) TestMainRunRcPassthroughError(c *C) {
+	ctx := testing.Context(c)
+	result := cmd.Main(&TestCommand{Name: "verb"}, ctx, []string{"--option", "rc-passthrough"})
+	c.Assert(result, Equals, 42)
+	c.Assert(bufferString(ctx.Stdout), Equals, "passed through\n")
+	c.Assert(bufferString(ctx.Stderr), Equals, "")
+}
+
 func (s *CmdSuite) TestMainRunSilentError(c *C) {
 	ctx := testing.Context(c)
 	result := cmd.Main(&TestCommand{Name: "verb"}, ctx, []string{"--option", "silent-error"})
@@ -0,0 +1,175 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"launchpad.net/gnuflag"
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// DoCommand queues an action for a unit to run.
+type DoCommand struct {
+	EnvCommandBase
+	UnitName   string
+	ActionName string
+	Params     map[string]interface{}
+}
+
+const doDoc = `
+Queue an action, defined in the actions.yaml file of the unit's charm,
+for the unit to run. Parameters are given as key=value pairs, with each
+value interpreted as YAML, so that for example "compress=true" passes a
+boolean; parameters that are not given take their defaults. For example:
+
+    juju do mysql/0 snapshot outfile=backup.bz2
+
+The id of the queued action is printed, and can be given to
+"juju action-results" to check on the action's progress and results.
+`
+
+func (c *DoCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "do",
+		Args:    "<unit> <action> [key=value ...]",
+		Purpose: "queue an action for a unit to run",
+		Doc:     doDoc,
+	}
+}
+
+func (c *DoCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no unit specified")
+	}
+	if !state.IsUnitName(args[0]) {
+		return fmt.Errorf("invalid unit name %q", args[0])
+	}
+	if len(args) == 1 {
+		return errors.New("no action specified")
+	}
+	c.UnitName, c.ActionName = args[0], args[1]
+	c.Params = make(map[string]interface{})
+	for _, kv := range args[2:] {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return fmt.Errorf(`expected "key=value", got %q`, kv)
+		}
+		var value interface{}
+		if err := goyaml.Unmarshal([]byte(parts[1]), &value); err != nil {
+			return fmt.Errorf("invalid value for %q: %v", parts[0], err)
+		}
+		c.Params[parts[0]] = value
+	}
+	return nil
+}
+
+func (c *DoCommand) Run(ctx *cmd.Context) error {
+	data, err := goyaml.Marshal(c.Params)
+	if err != nil {
+		return err
+	}
+	conn, err := newAPIConn(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	id, err := conn.State.Client().QueueAction(c.UnitName, c.ActionName, string(data))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.Stdout, id)
+	return nil
+}
+
+// ActionResultsCommand reports the status and results of actions.
+type ActionResultsCommand struct {
+	EnvCommandBase
+	out cmd.Output
+	Ids []string
+}
+
+const actionResultsDoc = `
+Report the status of the actions with the given ids, as printed by
+"juju do", along with their parameters and, once they have finished,
+the results they recorded with action-set.
+`
+
+func (c *ActionResultsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "action-results",
+		Args:    "<action id> ...",
+		Purpose: "report the status and results of actions",
+		Doc:     actionResultsDoc,
+	}
+}
+
+func (c *ActionResultsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+		"json": cmd.FormatJson,
+	})
+}
+
+func (c *ActionResultsCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no action ids specified")
+	}
+	for _, id := range args {
+		if !state.IsActionId(id) {
+			return fmt.Errorf("invalid action id %q", id)
+		}
+	}
+	c.Ids = args
+	return nil
+}
+
+func (c *ActionResultsCommand) Run(ctx *cmd.Context) error {
+	conn, err := newAPIConn(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	results, err := conn.State.Client().ActionResults(c.Ids)
+	if err != nil {
+		return err
+	}
+	return c.out.Write(ctx, convertActionResults(results))
+}
+
+// convertActionResults returns the given action results in a form
+// suitable for formatting as YAML or JSON, keyed by action id.
+func convertActionResults(results []params.ActionResult) map[string]interface{} {
+	out := make(map[string]interface{})
+	for _, r := range results {
+		if r.Error != nil {
+			out[r.Id] = map[string]interface{}{"error": r.Error.Message}
+			continue
+		}
+		m := map[string]interface{}{
+			"unit":     r.UnitName,
+			"action":   r.Name,
+			"status":   r.Status,
+			"enqueued": r.Enqueued.String(),
+		}
+		if len(r.Params) > 0 {
+			m["params"] = r.Params
+		}
+		if r.Message != "" {
+			m["message"] = r.Message
+		}
+		if len(r.Results) > 0 {
+			m["results"] = r.Results
+		}
+		out[r.Id] = m
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"strings"
+
+	. "launchpad.net/gocheck"
+	"launchpad.net/goyaml"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/testing"
+)
+
+type ActionSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&ActionSuite{})
+
+var doInitTests = []struct {
+	args   []string
+	err    string
+	params map[string]interface{}
+}{{
+	err: "no unit specified",
+}, {
+	args: []string{"dummy"},
+	err:  `invalid unit name "dummy"`,
+}, {
+	args: []string{"dummy/0"},
+	err:  "no action specified",
+}, {
+	args: []string{"dummy/0", "snapshot", "outfile"},
+	err:  `expected "key=value", got "outfile"`,
+}, {
+	args:   []string{"dummy/0", "snapshot"},
+	params: map[string]interface{}{},
+}, {
+	args: []string{"dummy/0", "snapshot", "outfile=a=b.bz2", "compress=true", "level=3"},
+	params: map[string]interface{}{
+		"outfile":  "a=b.bz2",
+		"compress": true,
+		"level":    3,
+	},
+}}
+
+func (s *ActionSuite) TestDoInit(c *C) {
+	for i, t := range doInitTests {
+		c.Logf("test %d: %q", i, t.args)
+		com := &DoCommand{}
+		err := testing.InitCommand(com, t.args)
+		if t.err != "" {
+			c.Check(err, ErrorMatches, t.err)
+			continue
+		}
+		c.Assert(err, IsNil)
+		c.Check(com.UnitName, Equals, t.args[0])
+		c.Check(com.ActionName, Equals, t.args[1])
+		c.Check(com.Params, DeepEquals, t.params)
+	}
+}
+
+func (s *ActionSuite) TestActionResultsInit(c *C) {
+	err := testing.InitCommand(&ActionResultsCommand{}, nil)
+	c.Assert(err, ErrorMatches, "no action ids specified")
+	err = testing.InitCommand(&ActionResultsCommand{}, []string{"dummy/0#a#1", "dummy/0"})
+	c.Assert(err, ErrorMatches, `invalid action id "dummy/0"`)
+	com := &ActionResultsCommand{}
+	err = testing.InitCommand(com, []string{"dummy/0#a#1", "dummy/0#a#2"})
+	c.Assert(err, IsNil)
+	c.Assert(com.Ids, DeepEquals, []string{"dummy/0#a#1", "dummy/0#a#2"})
+}
+
+func (s *ActionSuite) TestDoAndActionResults(c *C) {
+	testing.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "dummy")
+	c.Assert(err, IsNil)
+
+	ctx, err := testing.RunCommand(c, &DoCommand{}, []string{"dummy/0", "snapshot", "compress=true"})
+	c.Assert(err, IsNil)
+	id := strings.TrimSpace(testing.Stdout(ctx))
+	action, err := s.State.Action(id)
+	c.Assert(err, IsNil)
+	c.Assert(action.Params(), DeepEquals, map[string]interface{}{
+		"outfile":  "foo.bz2",
+		"compress": true,
+	})
+	err = action.Begin()
+	c.Assert(err, IsNil)
+	err = action.Complete(map[string]interface{}{"size": "10M"})
+	c.Assert(err, IsNil)
+
+	missing := "dummy/0#a#42"
+	ctx, err = testing.RunCommand(c, &ActionResultsCommand{}, []string{id, missing})
+	c.Assert(err, IsNil)
+	var out map[string]map[string]interface{}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(ctx)), &out)
+	c.Assert(err, IsNil)
+	c.Assert(out[id]["enqueued"], Not(Equals), "")
+	delete(out[id], "enqueued")
+	c.Assert(out, DeepEquals, map[string]map[string]interface{}{
+		id: {
+			"unit":   "dummy/0",
+			"action": "snapshot",
+			"status": "completed",
+			"params": map[interface{}]interface{}{
+				"outfile":  "foo.bz2",
+				"compress": true,
+			},
+			"results": map[interface{}]interface{}{"size": "10M"},
+		},
+		missing: {
+			"error": `action "dummy/0#a#42" not found`,
+		},
+	})
+}
+
+func (s *ActionSuite) TestDoUndefinedAction(c *C) {
+	testing.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "dummy")
+	c.Assert(err, IsNil)
+	_, err = testing.RunCommand(c, &DoCommand{}, []string{"dummy/0", "backup"})
+	c.Assert(err, ErrorMatches, `cannot add action "backup" to unit "dummy/0": action not defined by charm "local:precise/dummy-1"`)
+}
@@ -0,0 +1,316 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/backup"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/environs"
+	coreerrors "launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/state/api"
+	"launchpad.net/juju-core/utils"
+)
+
+// BackupCommand writes a backup of the environment's state server.
+type BackupCommand struct {
+	EnvCommandBase
+	Filename string
+}
+
+const backupDoc = `
+Write a backup of the environment's state server: a dump of the state
+database, and the agent configurations and certificates of the state
+server. The backup is written to a gzipped tar archive, by default named
+after the current time, which "juju restore" can use to replace a lost
+state server.
+
+"juju restore" only uses the database dump. The agent configurations and
+certificates are kept in the archive for manual recovery.
+`
+
+func (c *BackupCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "backup",
+		Purpose: "back up the environment's state server",
+		Doc:     backupDoc,
+	}
+}
+
+func (c *BackupCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.StringVar(&c.Filename, "o", "", "write the backup to the named file")
+	f.StringVar(&c.Filename, "output", "", "")
+}
+
+func (c *BackupCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *BackupCommand) Run(ctx *cmd.Context) error {
+	conn, err := newAPIConn(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	data, err := conn.State.Client().Backup()
+	if err != nil {
+		return err
+	}
+	filename := c.Filename
+	if filename == "" {
+		filename = time.Now().UTC().Format("juju-backup-20060102-150405.tgz")
+	}
+	if err := ioutil.WriteFile(ctx.AbsPath(filename), data, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintln(ctx.Stdout, filename)
+	return nil
+}
+
+// RestoreCommand replaces a lost state server with a new one holding
+// the state of a backup.
+type RestoreCommand struct {
+	EnvCommandBase
+	Constraints constraints.Value
+	Filename    string
+}
+
+const restoreDoc = `
+Replace the environment's state server, which must no longer be running,
+with a new one holding the state in a backup written by "juju backup".
+The environment is bootstrapped again, the state database is restored
+from the backup, and the agents on the other machines of the environment
+are pointed at the new state server and restarted. The new state server
+gets fresh agent configurations and certificates from bootstrap; the ones
+in the backup are not used.
+
+The agents are reconfigured over ssh, so the machines must be reachable
+with the environment's authorized keys.
+`
+
+func (c *RestoreCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "restore",
+		Args:    "<backup file>",
+		Purpose: "restore a lost state server from a backup",
+		Doc:     restoreDoc,
+	}
+}
+
+func (c *RestoreCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	f.Var(constraints.ConstraintsValue{&c.Constraints}, "constraints", "set the constraints of the new state server")
+}
+
+func (c *RestoreCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no backup file specified")
+	}
+	c.Filename = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *RestoreCommand) Run(ctx *cmd.Context) error {
+	data, err := ioutil.ReadFile(ctx.AbsPath(c.Filename))
+	if err != nil {
+		return err
+	}
+	// Check the backup locally to report errors before bootstrapping.
+	if _, err := backup.Read(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	environ, err := environs.NewFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	if err := removeLostBootstrapState(environ); err != nil {
+		return err
+	}
+	log.Infof("bootstrapping new state server")
+	if err := bootstrap(environ, c.Constraints, false, nil); err != nil {
+		return err
+	}
+	conn, err := juju.NewAPIConn(environ, api.DefaultDialOpts())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	log.Infof("restoring state database")
+	if err := conn.State.Client().Restore(data); err != nil {
+		return err
+	}
+	status, err := conn.State.Client().Status()
+	if err != nil {
+		return err
+	}
+	return reconnectAgents(ctx, environ, status)
+}
+
+// removeLostBootstrapState checks that the state server recorded in the
+// environment's provider state is no longer running, and removes the
+// provider state so that the environment can be bootstrapped again.
+func removeLostBootstrapState(environ environs.Environ) error {
+	bootstrapState, err := environs.LoadState(environ.Storage())
+	if coreerrors.IsNotFoundError(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot query old bootstrap state: %v", err)
+	}
+	_, err = environ.Instances(bootstrapState.StateInstances)
+	if err != nil && err != environs.ErrNoInstances && err != environs.ErrPartialInstances {
+		return err
+	}
+	// Some providers, such as the local one, report every instance they
+	// are asked about, so an instance that is still there only counts as
+	// a running state server if its API server can be reached.
+	if err != environs.ErrNoInstances && apiServerReachable(environ) {
+		return fmt.Errorf("old state server is still running")
+	}
+	return environ.Storage().Remove(environs.StateFile)
+}
+
+// apiServerReachable reports whether the API server of the environment
+// accepts connections.
+func apiServerReachable(environ environs.Environ) bool {
+	_, apiInfo, err := environ.StateInfo()
+	if err != nil {
+		return false
+	}
+	for _, addr := range apiInfo.Addrs {
+		conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+		if err == nil {
+			conn.Close()
+			return true
+		}
+	}
+	return false
+}
+
+// stateServerMachineId holds the id of the machine that runs the state
+// server of a bootstrapped environment.
+const stateServerMachineId = "0"
+
+// restartAgentsScript restarts all the juju agents on a machine.
+const restartAgentsScript = `
+for job in /etc/init/jujud-*.conf; do
+	job=$(basename "$job" .conf)
+	restart "$job" || start "$job"
+done
+`
+
+// updateAgentsScript returns a script that points the agents on a
+// machine, whose data directory is dataDir, at the given state and API
+// server addresses, and restarts them. The addresses in each agent's
+// configuration are replaced with those that have the same port.
+func updateAgentsScript(dataDir, stateAddr, apiAddr string) (string, error) {
+	var sedArgs []string
+	for _, addr := range []string{stateAddr, apiAddr} {
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return "", err
+		}
+		sedArgs = append(sedArgs, fmt.Sprintf(`-e 's|^\(\s*- \)[^ ]*:%s$|\1%s|'`, port, addr))
+	}
+	return fmt.Sprintf(`set -e
+for conf in %s/agents/*/agent.conf; do
+	sed -i %s "$conf"
+done
+`, utils.ShQuote(dataDir), strings.Join(sedArgs, " ")) + restartAgentsScript, nil
+}
+
+// runViaSSH runs the script with bash, as root, on the machine with the
+// given address. It is a variable so that it can be replaced in tests.
+var runViaSSH = func(host, script string) error {
+	ps := exec.Command(
+		"ssh",
+		"-l", "ubuntu",
+		"-o", "StrictHostKeyChecking no",
+		"-o", "PasswordAuthentication no",
+		host,
+		"sudo -n bash -s",
+	)
+	ps.Stdin = strings.NewReader(script)
+	out, err := ps.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v (%s)", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// reconnectAgents restarts the agents of the new state server, so that
+// they pick up the restored state, and points the agents of every other
+// machine in the environment at the new state server. Machines that
+// cannot be updated are reported, and do not stop the others from being
+// updated.
+func reconnectAgents(ctx *cmd.Context, environ environs.Environ, status *api.Status) error {
+	stateInfo, apiInfo, err := environ.StateInfo()
+	if err != nil {
+		return err
+	}
+	// The other machines keep their agents in the data directory
+	// they were provisioned with.
+	updateScript, err := updateAgentsScript(environs.DataDir, stateInfo.Addrs[0], apiInfo.Addrs[0])
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for id := range status.Machines {
+		if id != stateServerMachineId {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	// The new state server goes first, so that the other agents
+	// find the restored state when they reconnect.
+	if _, ok := status.Machines[stateServerMachineId]; ok {
+		ids = append([]string{stateServerMachineId}, ids...)
+	}
+	failed := 0
+	for _, id := range ids {
+		script := updateScript
+		if id == stateServerMachineId {
+			script = restartAgentsScript
+		}
+		if err := reconnectMachine(environ, status.Machines[id], script); err != nil {
+			fmt.Fprintf(ctx.Stderr, "cannot reconnect machine %s: %v\n", id, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d machines not reconnected", failed, len(ids))
+	}
+	return nil
+}
+
+func reconnectMachine(environ environs.Environ, m api.MachineInfo, script string) error {
+	if m.InstanceId == "" {
+		return errors.New("machine is not provisioned")
+	}
+	insts, err := environ.Instances([]instance.Id{instance.Id(m.InstanceId)})
+	if err != nil {
+		return err
+	}
+	host, err := insts[0].WaitDNSName()
+	if err != nil {
+		return err
+	}
+	log.Infof("reconnecting agents on %s", host)
+	return runViaSSH(host, script)
+}
@@ -0,0 +1,208 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/backup"
+	envtesting "launchpad.net/juju-core/environs/testing"
+	"launchpad.net/juju-core/juju"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing"
+)
+
+type BackupSuite struct {
+	jujutesting.JujuConnSuite
+	oldRunViaSSH func(host, script string) error
+	ssh          map[string]string
+}
+
+var _ = Suite(&BackupSuite{})
+
+func (s *BackupSuite) SetUpTest(c *C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.oldRunViaSSH = runViaSSH
+	s.ssh = make(map[string]string)
+	runViaSSH = func(host, script string) error {
+		s.ssh[host] = script
+		return nil
+	}
+}
+
+func (s *BackupSuite) TearDownTest(c *C) {
+	runViaSSH = s.oldRunViaSSH
+	s.JujuConnSuite.TearDownTest(c)
+}
+
+func (s *BackupSuite) TestBackupInit(c *C) {
+	err := testing.InitCommand(&BackupCommand{}, []string{"foo"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["foo"\]`)
+	com := &BackupCommand{}
+	err = testing.InitCommand(com, []string{"-o", "env.tgz"})
+	c.Assert(err, IsNil)
+	c.Assert(com.Filename, Equals, "env.tgz")
+}
+
+func (s *BackupSuite) TestRestoreInit(c *C) {
+	err := testing.InitCommand(&RestoreCommand{}, nil)
+	c.Assert(err, ErrorMatches, "no backup file specified")
+	err = testing.InitCommand(&RestoreCommand{}, []string{"one.tgz", "two.tgz"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["two.tgz"\]`)
+	com := &RestoreCommand{}
+	err = testing.InitCommand(com, []string{"--constraints", "mem=4G", "env.tgz"})
+	c.Assert(err, IsNil)
+	c.Assert(com.Filename, Equals, "env.tgz")
+	c.Assert(com.Constraints.String(), Equals, "mem=4096M")
+}
+
+// backup writes a backup of the environment, whose state server has a
+// single agent, and returns its path.
+func (s *BackupSuite) backup(c *C) string {
+	agentDir := filepath.Join(s.DataDir(), "agents", "machine-0")
+	err := os.MkdirAll(agentDir, 0755)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(filepath.Join(agentDir, "agent.conf"), []byte("conf"), 0600)
+	c.Assert(err, IsNil)
+	path := filepath.Join(c.MkDir(), "env.tgz")
+	ctx, err := testing.RunCommand(c, &BackupCommand{}, []string{"-o", path})
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, path+"\n")
+	return path
+}
+
+func (s *BackupSuite) TestBackup(c *C) {
+	_, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+
+	f, err := os.Open(s.backup(c))
+	c.Assert(err, IsNil)
+	defer f.Close()
+	a, err := backup.Read(f)
+	c.Assert(err, IsNil)
+	c.Assert(a.Files, DeepEquals, map[string][]byte{
+		"agents/machine-0/agent.conf": []byte("conf"),
+	})
+	c.Assert(a.Collections["services"], Not(HasLen), 0)
+}
+
+func (s *BackupSuite) TestRestore(c *C) {
+	_, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	m, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	inst, md := jujutesting.StartInstance(c, s.Conn.Environ, m.Id())
+	err = m.SetProvisioned(inst.Id(), "fake_nonce", md)
+	c.Assert(err, IsNil)
+	path := s.backup(c)
+
+	// Lose the state server.
+	err = s.Conn.Environ.Destroy(nil)
+	c.Assert(err, IsNil)
+	envtesting.UploadFakeTools(c, s.Conn.Environ.Storage())
+
+	_, err = testing.RunCommand(c, &RestoreCommand{}, []string{path})
+	c.Assert(err, IsNil)
+
+	conn, err := juju.NewConnFromName("")
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	_, err = conn.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	_, err = conn.State.Machine(m.Id())
+	c.Assert(err, IsNil)
+
+	addr, err := inst.DNSName()
+	c.Assert(err, IsNil)
+	c.Assert(s.ssh, HasLen, 1)
+	script := s.ssh[addr]
+	stateInfo, apiInfo, err := conn.Environ.StateInfo()
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(script, stateInfo.Addrs[0]), Equals, true)
+	c.Assert(strings.Contains(script, apiInfo.Addrs[0]), Equals, true)
+	c.Assert(strings.Contains(script, restartAgentsScript), Equals, true)
+}
+
+func (s *BackupSuite) TestRestoreWithRunningStateServer(c *C) {
+	path := s.backup(c)
+	_, err := testing.RunCommand(c, &RestoreCommand{}, []string{path})
+	c.Assert(err, ErrorMatches, "old state server is still running")
+}
+
+func (s *BackupSuite) TestRestoreBadBackup(c *C) {
+	path := filepath.Join(c.MkDir(), "env.tgz")
+	err := ioutil.WriteFile(path, []byte("bad"), 0600)
+	c.Assert(err, IsNil)
+	_, err = testing.RunCommand(c, &RestoreCommand{}, []string{path})
+	c.Assert(err, ErrorMatches, "cannot read backup: .*")
+}
+
+var updateAgentsScriptTests = []struct {
+	about  string
+	conf   string
+	expect string
+}{{
+	about: "addresses are replaced",
+	conf: `
+stateinfo:
+  addrs:
+  - 10.0.0.1:37017
+  tag: machine-1
+apiinfo:
+  addrs:
+  - 10.0.0.1:17070
+`,
+	expect: `
+stateinfo:
+  addrs:
+  - example.com:37017
+  tag: machine-1
+apiinfo:
+  addrs:
+  - example.com:17070
+`,
+}, {
+	about: "other settings are untouched",
+	conf: `
+oldpassword: foo:37017
+stateport: 37017
+`,
+	expect: `
+oldpassword: foo:37017
+stateport: 37017
+`,
+}}
+
+func (s *BackupSuite) TestUpdateAgentsScriptAddresses(c *C) {
+	script, err := updateAgentsScript("/var/lib/juju", "example.com:37017", "example.com:17070")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(script, `for conf in '/var/lib/juju'/agents/*/agent.conf;`), Equals, true)
+	// Extract the sed expressions, and check them against
+	// sample agent configurations.
+	start := strings.Index(script, "sed -i ")
+	end := strings.Index(script[start:], ` "$conf"`)
+	sedArgs := script[start+len("sed -i ") : start+end]
+	for i, t := range updateAgentsScriptTests {
+		c.Logf("test %d: %s", i, t.about)
+		path := filepath.Join(c.MkDir(), "agent.conf")
+		err := ioutil.WriteFile(path, []byte(t.conf), 0600)
+		c.Assert(err, IsNil)
+		runSed(c, sedArgs, path)
+		data, err := ioutil.ReadFile(path)
+		c.Assert(err, IsNil)
+		c.Check(string(data), Equals, t.expect)
+	}
+}
+
+func runSed(c *C, sedArgs, path string) {
+	out, err := exec.Command("bash", "-c", "sed -i "+sedArgs+" "+path).CombinedOutput()
+	c.Assert(err, IsNil, Commentf("%s", out))
+}
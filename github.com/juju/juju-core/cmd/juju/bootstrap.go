@@ -64,21 +64,28 @@ func (c *BootstrapCommand) Run(context *cmd.Context) error {
 		}
 		return err
 	}
+	return bootstrap(environ, c.Constraints, c.UploadTools, c.Series)
+}
+
+// bootstrap bootstraps the environment with the given constraints,
+// first uploading the local tools for the given series if upload
+// is true or the environment uses the local provider.
+func bootstrap(environ environs.Environ, cons constraints.Value, upload bool, series []string) error {
 	// TODO: if in verbose mode, write out to Stdout if a new cert was created.
-	_, err = environs.EnsureCertificate(environ, environs.WriteCertAndKey)
+	_, err := environs.EnsureCertificate(environ, environs.WriteCertAndKey)
 	if err != nil {
 		return err
 	}
 	// If we are using a local provider, always upload tools.
 	if environ.Config().Type() == provider.Local {
-		c.UploadTools = true
+		upload = true
 	}
-	if c.UploadTools {
+	if upload {
 		// Force version.Current, for consistency with subsequent upgrade-juju
 		// (see UpgradeJujuCommand).
 		forceVersion := uploadVersion(version.Current.Number, nil)
 		cfg := environ.Config()
-		series := getUploadSeries(cfg, c.Series)
+		series = getUploadSeries(cfg, series)
 		tools, err := uploadTools(environ.Storage(), &forceVersion, series...)
 		if err != nil {
 			return err
@@ -93,7 +100,7 @@ func (c *BootstrapCommand) Run(context *cmd.Context) error {
 			return fmt.Errorf("failed to update environment configuration: %v", err)
 		}
 	}
-	return environs.Bootstrap(environ, c.Constraints)
+	return environs.Bootstrap(environ, cons)
 }
 
 type seriesVar struct {
@@ -0,0 +1,156 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"launchpad.net/gnuflag"
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state/api"
+)
+
+// DeployBundleCommand deploys the services and relations described
+// in a bundle file.
+type DeployBundleCommand struct {
+	EnvCommandBase
+	BundlePath string
+}
+
+const deployBundleDoc = `
+Deploy the services and relations described in a bundle file, such as
+one written by "juju export-bundle". For example:
+
+    services:
+      wordpress:
+        charm: cs:precise/wordpress
+        num_units: 2
+        options:
+          blog-title: My blog
+        constraints: mem=2G
+        expose: true
+      mysql:
+        charm: cs:precise/mysql
+        num_units: 1
+    relations:
+    - [wordpress:db, mysql:server]
+
+Services already in the environment are updated with the options,
+constraints and exposure given in the bundle, and gain units if they
+have fewer than the bundle asks for; missing relations are added.
+Nothing not mentioned in the bundle is changed or removed, so the same
+bundle can be deployed repeatedly. Only charm store charms are supported.
+`
+
+func (c *DeployBundleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "deploy-bundle",
+		Args:    "<bundle file>",
+		Purpose: "deploy the services and relations of a bundle",
+		Doc:     deployBundleDoc,
+	}
+}
+
+func (c *DeployBundleCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no bundle file specified")
+	}
+	c.BundlePath = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *DeployBundleCommand) Run(ctx *cmd.Context) error {
+	data, err := ioutil.ReadFile(ctx.AbsPath(c.BundlePath))
+	if err != nil {
+		return err
+	}
+	// Check the bundle locally to report errors before connecting.
+	if _, err := bundle.Parse(data); err != nil {
+		return err
+	}
+	conn, err := newAPIConn(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	changes, err := conn.State.Client().DeployBundle(string(data))
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(ctx.Stdout, "environment already matches bundle")
+	}
+	for _, change := range changes {
+		fmt.Fprintln(ctx.Stdout, change)
+	}
+	return nil
+}
+
+// ExportBundleCommand writes a bundle describing the environment.
+type ExportBundleCommand struct {
+	EnvCommandBase
+	out cmd.Output
+}
+
+const exportBundleDoc = `
+Write a bundle describing the services of the environment, their
+configuration, constraints and number of units, and the relations
+between them. The bundle can be given to "juju deploy-bundle" to
+reproduce the environment.
+`
+
+func (c *ExportBundleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "export-bundle",
+		Purpose: "write a bundle describing the environment",
+		Doc:     exportBundleDoc,
+	}
+}
+
+func (c *ExportBundleCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.EnvCommandBase.SetFlags(f)
+	c.out.AddFlags(f, "yaml", map[string]cmd.Formatter{
+		"yaml": cmd.FormatYaml,
+	})
+}
+
+func (c *ExportBundleCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+func (c *ExportBundleCommand) Run(ctx *cmd.Context) error {
+	conn, err := newAPIConn(c.EnvName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	data, err := conn.State.Client().ExportBundle()
+	if err != nil {
+		return err
+	}
+	// The bundle is not verified, as an environment without services
+	// is still worth reporting.
+	var b bundle.Bundle
+	if err := goyaml.Unmarshal([]byte(data), &b); err != nil {
+		return err
+	}
+	return c.out.Write(ctx, &b)
+}
+
+// newAPIConn returns a connection to the API server of the named
+// environment.
+func newAPIConn(envName string) (*juju.APIConn, error) {
+	environ, err := environs.NewFromName(envName)
+	if err != nil {
+		return nil, err
+	}
+	return juju.NewAPIConn(environ, api.DefaultDialOpts())
+}
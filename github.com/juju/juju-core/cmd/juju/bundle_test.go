@@ -0,0 +1,62 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/testing"
+)
+
+type BundleSuite struct {
+	jujutesting.RepoSuite
+}
+
+var _ = Suite(&BundleSuite{})
+
+func (s *BundleSuite) TestDeployBundleInit(c *C) {
+	_, err := initDeployBundleCommand()
+	c.Assert(err, ErrorMatches, "no bundle file specified")
+	_, err = initDeployBundleCommand("one.yaml", "two.yaml")
+	c.Assert(err, ErrorMatches, `unrecognized args: \["two.yaml"\]`)
+	com, err := initDeployBundleCommand("bundle.yaml")
+	c.Assert(err, IsNil)
+	c.Assert(com.BundlePath, Equals, "bundle.yaml")
+}
+
+func initDeployBundleCommand(args ...string) (*DeployBundleCommand, error) {
+	com := &DeployBundleCommand{}
+	return com, testing.InitCommand(com, args)
+}
+
+func (s *BundleSuite) TestDeployBundleInvalid(c *C) {
+	path := filepath.Join(c.MkDir(), "bundle.yaml")
+	err := ioutil.WriteFile(path, []byte("services:\n  wordpress: {num_units: 1}\n"), 0644)
+	c.Assert(err, IsNil)
+	_, err = testing.RunCommand(c, &DeployBundleCommand{}, []string{path})
+	c.Assert(err, ErrorMatches, `service "wordpress" has no charm`)
+}
+
+func (s *BundleSuite) TestExportBundleInit(c *C) {
+	err := testing.InitCommand(&ExportBundleCommand{}, []string{"foo"})
+	c.Assert(err, ErrorMatches, `unrecognized args: \["foo"\]`)
+}
+
+func (s *BundleSuite) TestExportBundle(c *C) {
+	testing.Charms.BundlePath(s.SeriesPath, "dummy")
+	err := runDeploy(c, "local:dummy", "some-service-name")
+	c.Assert(err, IsNil)
+	ctx, err := testing.RunCommand(c, &ExportBundleCommand{}, nil)
+	c.Assert(err, IsNil)
+	c.Assert(testing.Stdout(ctx), Equals, `
+services:
+  some-service-name:
+    charm: local:precise/dummy-1
+    num_units: 1
+`[1:])
+}
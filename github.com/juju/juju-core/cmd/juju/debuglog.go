@@ -4,42 +4,131 @@
 package main
 
 import (
+	"fmt"
+
 	"launchpad.net/gnuflag"
+	"launchpad.net/loggo"
+
 	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api"
+	"launchpad.net/juju-core/state/api/params"
 )
 
+// DebugLogCommand follows the consolidated log of the environment
+// through the API.
 type DebugLogCommand struct {
-	// The debug log command simply invokes juju ssh with the required arguments.
-	sshCmd cmd.Command
+	EnvCommandBase
+	params params.DebugLog
 }
 
 const debuglogDoc = `
-Launch an ssh shell on the state server machine and tail the consolidated log file.
-The consolidated log file contains log messages from all nodes in the environment.
+Stream the consolidated log of the environment, which contains log
+messages from all the agents in the environment, filtered on the
+state server.
+
+The --include and --exclude options filter the messages by the agent
+that logged them, and may be given machine ids, unit names or service
+names (meaning all the units of the service). Agent tags such as
+"unit-mysql-0" are also accepted, and may contain wildcards.
+
+The --include-module and --exclude-module options filter the messages
+by logging module. A module includes its submodules, so "juju.worker"
+matches "juju.worker.uniter".
+
+By default the last 10 matching lines are shown before following new
+messages; use --lines to change this, or --replay to show the whole log.
+
+Examples:
+
+    juju debug-log --include mysql --level WARNING
+    juju debug-log -x 0 --include-module juju.worker --replay
 `
 
 func (c *DebugLogCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "debug-log",
-		Args:    "[<ssh args>...]",
 		Purpose: "display the consolidated log file",
 		Doc:     debuglogDoc,
 	}
 }
 
 func (c *DebugLogCommand) SetFlags(f *gnuflag.FlagSet) {
-	c.sshCmd.SetFlags(f)
+	c.EnvCommandBase.SetFlags(f)
+	f.Var(cmd.NewStringsValue(nil, &c.params.IncludeEntity), "i", "only show messages from these agents")
+	f.Var(cmd.NewStringsValue(nil, &c.params.IncludeEntity), "include", "")
+	f.Var(cmd.NewStringsValue(nil, &c.params.ExcludeEntity), "x", "do not show messages from these agents")
+	f.Var(cmd.NewStringsValue(nil, &c.params.ExcludeEntity), "exclude", "")
+	f.Var(cmd.NewStringsValue(nil, &c.params.IncludeModule), "include-module", "only show messages from these logging modules")
+	f.Var(cmd.NewStringsValue(nil, &c.params.ExcludeModule), "exclude-module", "do not show messages from these logging modules")
+	f.StringVar(&c.params.Level, "l", "", "only show messages of this level or above")
+	f.StringVar(&c.params.Level, "level", "", "")
+	f.UintVar(&c.params.Backlog, "n", 10, "show this many matching lines from the existing log")
+	f.UintVar(&c.params.Backlog, "lines", 10, "")
+	f.BoolVar(&c.params.Replay, "replay", false, "show the whole existing log")
 }
 
 func (c *DebugLogCommand) Init(args []string) error {
-	args = append([]string{"0"}, args...)
-	args = append(args, "tail -f /var/log/juju/all-machines.log")
-	return c.sshCmd.Init(args)
+	if c.params.Level != "" {
+		level, ok := loggo.ParseLevel(c.params.Level)
+		if !ok || level == loggo.UNSPECIFIED {
+			return fmt.Errorf("level value %q is not one of %q, %q, %q, %q, %q",
+				c.params.Level, loggo.TRACE, loggo.DEBUG, loggo.INFO, loggo.WARNING, loggo.ERROR)
+		}
+	}
+	c.params.IncludeEntity = entityPatterns(c.params.IncludeEntity)
+	c.params.ExcludeEntity = entityPatterns(c.params.ExcludeEntity)
+	return cmd.CheckEmpty(args)
 }
 
-// Run uses "juju ssh" to log into the state server node
-// and tails the consolidated log file which captures log
-// messages from all nodes.
+// entityPatterns converts machine ids, unit names and service names
+// to patterns matching the tags of their agents. Other values are
+// assumed to be tag patterns already.
+func entityPatterns(entities []string) []string {
+	var patterns []string
+	for _, entity := range entities {
+		switch {
+		case state.IsMachineId(entity):
+			entity = state.MachineTag(entity)
+		case state.IsUnitName(entity):
+			entity = state.UnitTag(entity)
+		case state.IsServiceName(entity):
+			// Match the unit number explicitly, so that "mysql" does
+			// not also match the units of "mysql-slave".
+			entity = state.UnitTag(entity+"/") + "[0-9]*"
+		}
+		patterns = append(patterns, entity)
+	}
+	return patterns
+}
+
+// Run follows the consolidated log until the command is interrupted
+// or the connection to the API server is lost.
 func (c *DebugLogCommand) Run(ctx *cmd.Context) error {
-	return c.sshCmd.Run(ctx)
+	environ, err := environs.NewFromName(c.EnvName)
+	if err != nil {
+		return err
+	}
+	conn, err := juju.NewAPIConn(environ, api.DefaultDialOpts())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	watcher, err := conn.State.Client().WatchDebugLog(c.params)
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	for {
+		lines, err := watcher.Next()
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			fmt.Fprintln(ctx.Stdout, line)
+		}
+	}
 }
@@ -5,41 +5,76 @@ package main
 
 import (
 	. "launchpad.net/gocheck"
-	"launchpad.net/juju-core/cmd"
+
+	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/testing"
 )
 
 type DebugLogSuite struct {
+	testing.LoggingSuite
+	home *testing.FakeHome
 }
 
 var _ = Suite(&DebugLogSuite{})
 
-func runDebugLog(c *C, args ...string) (*DebugLogCommand, error) {
-	cmd := &DebugLogCommand{
-		sshCmd: &dummySSHCommand{},
-	}
-	_, err := testing.RunCommand(c, cmd, args)
-	return cmd, err
+func (s *DebugLogSuite) SetUpTest(c *C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.home = testing.MakeEmptyFakeHome(c)
 }
 
-type dummySSHCommand struct {
-	SSHCommand
-	runCalled bool
+func (s *DebugLogSuite) TearDownTest(c *C) {
+	s.home.Restore()
+	s.LoggingSuite.TearDownTest(c)
 }
 
-func (c *dummySSHCommand) Run(ctx *cmd.Context) error {
-	c.runCalled = true
-	return nil
-}
-
-// debug-log is implemented by invoking juju ssh with the correct arguments.
-// This test checks for the expected invocation.
-func (s *DebugLogSuite) TestDebugLogInvokesSSHCommand(c *C) {
-	defer testing.MakeEmptyFakeHome(c).Restore()
-	debugLogCmd, err := runDebugLog(c)
-	c.Assert(err, IsNil)
-	debugCmd := debugLogCmd.sshCmd.(*dummySSHCommand)
-	c.Assert(debugCmd.runCalled, Equals, true)
-	c.Assert(debugCmd.Target, Equals, "0")
-	c.Assert([]string{"tail -f /var/log/juju/all-machines.log"}, DeepEquals, debugCmd.Args)
+func (s *DebugLogSuite) TestArgParsing(c *C) {
+	for i, test := range []struct {
+		args     []string
+		expected params.DebugLog
+		errMatch string
+	}{{
+		expected: params.DebugLog{
+			Backlog: 10,
+		},
+	}, {
+		args: []string{"-n", "0", "--include", "0,mysql/0", "--exclude", "wordpress,unit-foo-*"},
+		expected: params.DebugLog{
+			IncludeEntity: []string{"machine-0", "unit-mysql-0"},
+			ExcludeEntity: []string{"unit-wordpress-[0-9]*", "unit-foo-*"},
+		},
+	}, {
+		args: []string{"-i", "1/lxc/2", "-x", "mysql-slave", "--lines", "50"},
+		expected: params.DebugLog{
+			IncludeEntity: []string{"machine-1-lxc-2"},
+			ExcludeEntity: []string{"unit-mysql-slave-[0-9]*"},
+			Backlog:       50,
+		},
+	}, {
+		args: []string{"--include-module", "juju.provider,juju.worker", "--exclude-module", "juju.worker.uniter", "--replay"},
+		expected: params.DebugLog{
+			IncludeModule: []string{"juju.provider", "juju.worker"},
+			ExcludeModule: []string{"juju.worker.uniter"},
+			Backlog:       10,
+			Replay:        true,
+		},
+	}, {
+		args: []string{"--level", "WARNING"},
+		expected: params.DebugLog{
+			Level:   "WARNING",
+			Backlog: 10,
+		},
+	}, {
+		args:     []string{"-l", "foo"},
+		errMatch: `level value "foo" is not one of "TRACE", "DEBUG", "INFO", "WARNING", "ERROR"`,
+	}, {
+		args:     []string{"tail -f"},
+		errMatch: `unrecognized args: \["tail -f"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		command := &DebugLogCommand{}
+		testing.TestInit(c, command, test.args, test.errMatch)
+		if test.errMatch == "" {
+			c.Check(command.params, DeepEquals, test.expected)
+		}
+	}
 }
@@ -52,6 +52,7 @@ func Main(args []string) {
 	juju.Register(&BootstrapCommand{})
 	juju.Register(&AddMachineCommand{})
 	juju.Register(&DeployCommand{})
+	juju.Register(&DeployBundleCommand{})
 	juju.Register(&AddRelationCommand{})
 	juju.Register(&AddUnitCommand{})
 
@@ -62,15 +63,23 @@ func Main(args []string) {
 	juju.Register(&DestroyUnitCommand{})
 	juju.Register(&DestroyEnvironmentCommand{})
 
+	// Backup commands.
+	juju.Register(&BackupCommand{})
+	juju.Register(&RestoreCommand{})
+
 	// Reporting commands.
 	juju.Register(&StatusCommand{})
+	juju.Register(&ExportBundleCommand{})
 	juju.Register(&SwitchCommand{})
 
 	// Error resolution commands.
 	juju.Register(&SCPCommand{})
 	juju.Register(&SSHCommand{})
 	juju.Register(&ResolvedCommand{})
-	juju.Register(&DebugLogCommand{sshCmd: &SSHCommand{}})
+	juju.Register(&RunCommand{})
+	juju.Register(&DoCommand{})
+	juju.Register(&ActionResultsCommand{})
+	juju.Register(&DebugLogCommand{})
 
 	// Configuration commands.
 	juju.Register(&InitCommand{})
@@ -211,18 +211,23 @@ func (s *MainSuite) TestActualRunJujuArgsAfterCommand(c *C) {
 }
 
 var commandNames = []string{
+	"action-results",
 	"add-machine",
 	"add-relation",
 	"add-unit",
+	"backup",
 	"bootstrap",
 	"debug-log",
 	"deploy",
+	"deploy-bundle",
 	"destroy-environment",
 	"destroy-machine",
 	"destroy-relation",
 	"destroy-service",
 	"destroy-unit",
+	"do",
 	"env", // alias for switch
+	"export-bundle",
 	"expose",
 	"generate-config", // alias for init
 	"get",
@@ -236,6 +241,8 @@ var commandNames = []string{
 	"remove-relation", // alias for destroy-relation
 	"remove-unit",     // alias for destroy-unit
 	"resolved",
+	"restore",
+	"run",
 	"scp",
 	"set",
 	"set-constraints",
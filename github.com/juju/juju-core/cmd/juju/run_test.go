@@ -0,0 +1,210 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils/exec"
+)
+
+type RunSuite struct {
+	testing.LoggingSuite
+	home *testing.FakeHome
+}
+
+var _ = Suite(&RunSuite{})
+
+func (s *RunSuite) SetUpTest(c *C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.home = testing.MakeEmptyFakeHome(c)
+}
+
+func (s *RunSuite) TearDownTest(c *C) {
+	s.home.Restore()
+	s.LoggingSuite.TearDownTest(c)
+}
+
+func (*RunSuite) TestTargetArgParsing(c *C) {
+	for i, test := range []struct {
+		message  string
+		args     []string
+		all      bool
+		machines []string
+		units    []string
+		services []string
+		commands string
+		errMatch string
+	}{{
+		message:  "no args",
+		errMatch: "no commands specified",
+	}, {
+		message:  "no target",
+		args:     []string{"sudo reboot"},
+		errMatch: "You must specify a target, either through --all, --machine, --service or --unit",
+	}, {
+		message:  "too many args",
+		args:     []string{"--all", "sudo reboot", "oops"},
+		errMatch: `unrecognized args: \["oops"\]`,
+	}, {
+		message:  "command to all machines",
+		args:     []string{"--all", "sudo reboot"},
+		all:      true,
+		commands: "sudo reboot",
+	}, {
+		message:  "all and defined machines",
+		args:     []string{"--all", "--machine=1,2", "sudo reboot"},
+		errMatch: `You cannot specify --all and individual machines`,
+	}, {
+		message:  "command to machines 1, 2, and 1/kvm/0",
+		args:     []string{"--machine=1,2,1/kvm/0", "sudo reboot"},
+		commands: "sudo reboot",
+		machines: []string{"1", "2", "1/kvm/0"},
+	}, {
+		message: "bad machine names",
+		args:    []string{"--machine=foo,machine-2", "sudo reboot"},
+		errMatch: "" +
+			"The following run targets are not valid:\n" +
+			"  \"foo\" is not a valid machine id\n" +
+			"  \"machine-2\" is not a valid machine id",
+	}, {
+		message:  "all and defined services",
+		args:     []string{"--all", "--service=wordpress,mysql", "sudo reboot"},
+		errMatch: `You cannot specify --all and individual services`,
+	}, {
+		message:  "command to services wordpress and mysql",
+		args:     []string{"--service=wordpress,mysql", "sudo reboot"},
+		commands: "sudo reboot",
+		services: []string{"wordpress", "mysql"},
+	}, {
+		message: "bad service names",
+		args:    []string{"--service", "foo,2,foo/0", "sudo reboot"},
+		errMatch: "" +
+			"The following run targets are not valid:\n" +
+			"  \"2\" is not a valid service name\n" +
+			"  \"foo/0\" is not a valid service name",
+	}, {
+		message:  "all and defined units",
+		args:     []string{"--all", "--unit=wordpress/0,mysql/1", "sudo reboot"},
+		errMatch: `You cannot specify --all and individual units`,
+	}, {
+		message:  "command to valid units",
+		args:     []string{"--unit=wordpress/0,wordpress/1,mysql/0", "sudo reboot"},
+		commands: "sudo reboot",
+		units:    []string{"wordpress/0", "wordpress/1", "mysql/0"},
+	}, {
+		message: "bad unit names",
+		args:    []string{"--unit", "foo,2,foo/0", "sudo reboot"},
+		errMatch: "" +
+			"The following run targets are not valid:\n" +
+			"  \"foo\" is not a valid unit name\n" +
+			"  \"2\" is not a valid unit name",
+	}, {
+		message:  "command to mixed valid targets",
+		args:     []string{"--machine=0", "--unit=wordpress/0,wordpress/1", "--service=mysql", "sudo reboot"},
+		commands: "sudo reboot",
+		machines: []string{"0"},
+		services: []string{"mysql"},
+		units:    []string{"wordpress/0", "wordpress/1"},
+	}} {
+		c.Logf("%v: %s", i, test.message)
+		runCmd := &RunCommand{}
+		testing.TestInit(c, runCmd, test.args, test.errMatch)
+		if test.errMatch == "" {
+			c.Check(runCmd.all, Equals, test.all)
+			c.Check(runCmd.machines, DeepEquals, test.machines)
+			c.Check(runCmd.services, DeepEquals, test.services)
+			c.Check(runCmd.units, DeepEquals, test.units)
+			c.Check(runCmd.commands, Equals, test.commands)
+		}
+	}
+}
+
+func (*RunSuite) TestTimeoutArgParsing(c *C) {
+	for i, test := range []struct {
+		message  string
+		args     []string
+		errMatch string
+		timeout  time.Duration
+	}{{
+		message: "default time",
+		args:    []string{"--all", "sudo reboot"},
+		timeout: 5 * time.Minute,
+	}, {
+		message:  "invalid time",
+		args:     []string{"--timeout=foo", "--all", "sudo reboot"},
+		errMatch: `invalid value "foo" for flag --timeout: .*`,
+	}, {
+		message: "two hours",
+		args:    []string{"--timeout=2h", "--all", "sudo reboot"},
+		timeout: 2 * time.Hour,
+	}} {
+		c.Logf("%v: %s", i, test.message)
+		runCmd := &RunCommand{}
+		testing.TestInit(c, runCmd, test.args, test.errMatch)
+		if test.errMatch == "" {
+			c.Check(runCmd.timeout, Equals, test.timeout)
+		}
+	}
+}
+
+func (*RunSuite) TestConvertRunResults(c *C) {
+	for i, test := range []struct {
+		message  string
+		results  []params.RunResult
+		expected interface{}
+	}{{
+		message:  "empty",
+		expected: []interface{}{},
+	}, {
+		message: "minimum is machine id and stdout",
+		results: []params.RunResult{{
+			MachineId: "1",
+		}},
+		expected: []interface{}{
+			map[string]interface{}{
+				"MachineId": "1",
+				"Stdout":    "",
+			}},
+	}, {
+		message: "other fields are copied if there",
+		results: []params.RunResult{{
+			MachineId:    "1",
+			UnitId:       "unit/0",
+			ExecResponse: exec.ExecResponse{Stdout: []byte("stdout"), Stderr: []byte("stderr"), Code: 42},
+			Error:        "error",
+		}},
+		expected: []interface{}{
+			map[string]interface{}{
+				"MachineId":  "1",
+				"Stdout":     "stdout",
+				"Stderr":     "stderr",
+				"UnitId":     "unit/0",
+				"ReturnCode": 42,
+				"Error":      "error",
+			}},
+	}, {
+		message: "stdout and stderr are base64 encoded if not valid utf8",
+		results: []params.RunResult{{
+			MachineId:    "1",
+			ExecResponse: exec.ExecResponse{Stdout: []byte{0xff}, Stderr: []byte{0xfe}},
+		}},
+		expected: []interface{}{
+			map[string]interface{}{
+				"MachineId":       "1",
+				"Stdout":          "/w==",
+				"Stdout.encoding": "base64",
+				"Stderr":          "/g==",
+				"Stderr.encoding": "base64",
+			}},
+	}} {
+		c.Logf("%v: %s", i, test.message)
+		result := ConvertRunResults(test.results)
+		c.Check(result, DeepEquals, test.expected)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"launchpad.net/gnuflag"
 	"launchpad.net/tomb"
 
+	"launchpad.net/juju-core/agent/tools"
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/cmd"
 	localstorage "launchpad.net/juju-core/environs/local/storage"
@@ -35,6 +36,10 @@ const bootstrapMachineId = "0"
 
 var retryDelay = 3 * time.Second
 
+// jujuRun is the path of the juju-run symlink created by the machine
+// agent, through which commands are run on the machine.
+var jujuRun = "/usr/local/bin/juju-run"
+
 // MachineAgent is a cmd.Command responsible for running a machine agent.
 type MachineAgent struct {
 	cmd.CommandBase
@@ -92,6 +97,9 @@ func (a *MachineAgent) Run(_ *cmd.Context) error {
 		return err
 	}
 	charm.CacheDir = filepath.Join(a.Conf.DataDir, "charmcache")
+	if err := a.ensureJujuRunSymlink(); err != nil {
+		log.Warningf("cannot create juju-run symlink: %v", err)
+	}
 
 	// ensureStateWorker ensures that there is a worker that
 	// connects to the state that runs within itself all the workers
@@ -128,6 +136,23 @@ func (a *MachineAgent) Run(_ *cmd.Context) error {
 	return err
 }
 
+// ensureJujuRunSymlink makes juju-run, which the API server uses to run
+// commands on the machine, point at the agent's jujud.
+func (a *MachineAgent) ensureJujuRunSymlink() error {
+	jujud := filepath.Join(tools.ToolsDir(a.Conf.DataDir, a.Tag()), "jujud")
+	if target, err := os.Readlink(jujuRun); err == nil && target == jujud {
+		return nil
+	}
+	// Replace any stale link left by a previous installation.
+	if err := os.Remove(jujuRun); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(jujuRun), 0755); err != nil {
+		return err
+	}
+	return os.Symlink(jujud, jujuRun)
+}
+
 func allFatal(error) bool {
 	return true
 }
@@ -243,7 +268,7 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 				if len(a.Conf.StateServerCert) == 0 || len(a.Conf.StateServerKey) == 0 {
 					return nil, &fatalError{"configuration does not have state server cert/key"}
 				}
-				return apiserver.NewServer(st, fmt.Sprintf(":%d", a.Conf.APIPort), a.Conf.StateServerCert, a.Conf.StateServerKey)
+				return apiserver.NewServer(st, fmt.Sprintf(":%d", a.Conf.APIPort), a.Conf.StateServerCert, a.Conf.StateServerKey, a.Conf.DataDir)
 			})
 			runner.StartWorker("cleaner", func() (worker.Worker, error) {
 				return cleaner.NewCleaner(st), nil
@@ -5,6 +5,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"time"
@@ -34,6 +35,7 @@ type MachineSuite struct {
 	agentSuite
 	lxc.TestSuite
 	oldCacheDir string
+	oldJujuRun  string
 }
 
 var _ = Suite(&MachineSuite{})
@@ -53,9 +55,12 @@ func (s *MachineSuite) TearDownSuite(c *C) {
 func (s *MachineSuite) SetUpTest(c *C) {
 	s.agentSuite.SetUpTest(c)
 	s.TestSuite.SetUpTest(c)
+	s.oldJujuRun = jujuRun
+	jujuRun = filepath.Join(c.MkDir(), "juju-run")
 }
 
 func (s *MachineSuite) TearDownTest(c *C) {
+	jujuRun = s.oldJujuRun
 	s.TestSuite.TearDownTest(c)
 	s.agentSuite.TearDownTest(c)
 }
@@ -128,6 +133,9 @@ func (s *MachineSuite) TestRunStop(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(<-done, IsNil)
 	c.Assert(charm.CacheDir, Equals, filepath.Join(ac.DataDir, "charmcache"))
+	link, err := os.Readlink(jujuRun)
+	c.Assert(err, IsNil)
+	c.Assert(link, Equals, filepath.Join(ac.DataDir, "tools", m.Tag(), "jujud"))
 }
 
 func (s *MachineSuite) TestWithDeadMachine(c *C) {
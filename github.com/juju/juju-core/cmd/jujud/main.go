@@ -27,6 +27,9 @@ The jujud command can also forward invocations over RPC for execution by the
 juju unit agent. When used in this way, it expects to be called via a symlink
 named for the desired remote command, and expects JUJU_AGENT_SOCKET and
 JUJU_CONTEXT_ID be set in its environment.
+
+When called via a symlink named juju-run, it runs commands in the hook
+context of a unit on the local machine; see "juju-run --help".
 `
 
 func getenv(name string) (string, error) {
@@ -111,6 +114,8 @@ func Main(args []string) {
 	commandName := filepath.Base(args[0])
 	if commandName == "jujud" {
 		code, err = jujuDMain(args)
+	} else if commandName == "juju-run" {
+		code = cmd.Main(&RunCommand{}, cmd.DefaultContext(), args[1:])
 	} else if commandName == "jujuc" {
 		fmt.Fprint(os.Stderr, jujudDoc)
 		code = 2
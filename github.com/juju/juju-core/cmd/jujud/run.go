@@ -0,0 +1,137 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"fmt"
+	"net/rpc"
+	"os"
+	"path/filepath"
+
+	"launchpad.net/gnuflag"
+
+	"launchpad.net/juju-core/agent/tools"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/utils/exec"
+	"launchpad.net/juju-core/utils/fslock"
+	"launchpad.net/juju-core/worker/uniter"
+)
+
+// runDataDir is the juju data directory of the machine juju-run is
+// invoked on.
+var runDataDir = "/var/lib/juju"
+
+// RunCommand runs commands on the local machine, either in the hook
+// context of one of its units, or with the hook execution lock held.
+type RunCommand struct {
+	cmd.CommandBase
+	unit      string
+	commands  string
+	noContext bool
+}
+
+const runCommandDoc = `
+Run the specified commands in the hook context for the unit.
+
+unit-name can be either the unit tag:
+ i.e.  unit-ubuntu-0
+or the unit id:
+ i.e.  ubuntu/0
+
+If --no-context is specified, the <unit-name> positional
+argument is not needed, and the commands are run with the
+hook execution lock held but without a hook context.
+
+The commands are executed with '/bin/bash -s', and the output returned.
+`
+
+// Info returns usage information for the command.
+func (c *RunCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "juju-run",
+		Args:    "<unit-name> <commands>",
+		Purpose: "run commands in a unit's hook context",
+		Doc:     runCommandDoc,
+	}
+}
+
+func (c *RunCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.BoolVar(&c.noContext, "no-context", false, "do not run the command in a unit context")
+}
+
+func (c *RunCommand) Init(args []string) error {
+	// make sure we aren't in an existing hook context
+	if contextId := os.Getenv("JUJU_CONTEXT_ID"); contextId != "" {
+		return fmt.Errorf("juju-run cannot be called from within a hook, have context %q", contextId)
+	}
+	if !c.noContext {
+		if len(args) < 1 {
+			return fmt.Errorf("missing unit-name")
+		}
+		c.unit, args = args[0], args[1:]
+		// If the command line param is a unit id (like service/2) we need to
+		// change it to the unit tag as that is the format of the agent directory
+		// on disk (unit-service-2).
+		if state.IsUnitName(c.unit) {
+			c.unit = state.UnitTag(c.unit)
+		}
+	}
+	if len(args) < 1 {
+		return fmt.Errorf("missing commands")
+	}
+	c.commands, args = args[0], args[1:]
+	return cmd.CheckEmpty(args)
+}
+
+func (c *RunCommand) Run(ctx *cmd.Context) error {
+	var result *exec.ExecResponse
+	var err error
+	if c.noContext {
+		result, err = c.executeNoContext()
+	} else {
+		result, err = c.executeInUnitContext()
+	}
+	if err != nil {
+		return err
+	}
+
+	ctx.Stdout.Write(result.Stdout)
+	ctx.Stderr.Write(result.Stderr)
+	return &cmd.RcPassthroughError{result.Code}
+}
+
+func (c *RunCommand) executeInUnitContext() (*exec.ExecResponse, error) {
+	unitDir := tools.Dir(runDataDir, c.unit)
+	if _, err := os.Stat(unitDir); err != nil {
+		return nil, fmt.Errorf("unit %q not found on this machine", state.UnitNameFromTag(c.unit))
+	}
+	socketPath := filepath.Join(unitDir, uniter.RunListenerFile)
+	client, err := rpc.Dial("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	var result exec.ExecResponse
+	err = client.Call(uniter.JujuRunEndpoint, c.commands, &result)
+	return &result, err
+}
+
+func (c *RunCommand) executeNoContext() (*exec.ExecResponse, error) {
+	// Acquire the uniter hook execution lock to make sure we don't
+	// stomp on each other.
+	lock, err := fslock.NewLock(filepath.Join(runDataDir, "locks"), "uniter-hook-execution")
+	if err != nil {
+		return nil, err
+	}
+	if err := lock.Lock("juju-run"); err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	return exec.RunCommands(exec.RunParams{
+		Commands: c.commands,
+	})
+}
@@ -0,0 +1,123 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils/exec"
+	"launchpad.net/juju-core/worker/uniter"
+)
+
+type RunTestSuite struct {
+	testing.LoggingSuite
+	oldRunDataDir string
+}
+
+var _ = Suite(&RunTestSuite{})
+
+func (s *RunTestSuite) SetUpTest(c *C) {
+	s.LoggingSuite.SetUpTest(c)
+	s.oldRunDataDir = runDataDir
+	runDataDir = c.MkDir()
+}
+
+func (s *RunTestSuite) TearDownTest(c *C) {
+	runDataDir = s.oldRunDataDir
+	s.LoggingSuite.TearDownTest(c)
+}
+
+func (*RunTestSuite) TestArgParsing(c *C) {
+	for i, test := range []struct {
+		title     string
+		args      []string
+		errMatch  string
+		unit      string
+		commands  string
+		noContext bool
+	}{{
+		title:    "no args",
+		errMatch: "missing unit-name",
+	}, {
+		title:    "one arg",
+		args:     []string{"foo"},
+		errMatch: "missing commands",
+	}, {
+		title:    "more than two arg",
+		args:     []string{"foo", "bar", "baz"},
+		errMatch: `unrecognized args: \["baz"\]`,
+	}, {
+		title:    "unit and command assignment",
+		args:     []string{"unit-name-2", "command"},
+		unit:     "unit-name-2",
+		commands: "command",
+	}, {
+		title:    "unit id converted to tag",
+		args:     []string{"foo/1", "command"},
+		unit:     "unit-foo-1",
+		commands: "command",
+	}, {
+		title:     "no context",
+		args:      []string{"--no-context", "command"},
+		commands:  "command",
+		noContext: true,
+	}} {
+		c.Logf("%d: %s", i, test.title)
+		runCommand := &RunCommand{}
+		err := testing.InitCommand(runCommand, test.args)
+		if test.errMatch == "" {
+			c.Assert(err, IsNil)
+			c.Assert(runCommand.unit, Equals, test.unit)
+			c.Assert(runCommand.commands, Equals, test.commands)
+			c.Assert(runCommand.noContext, Equals, test.noContext)
+		} else {
+			c.Assert(err, ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (*RunTestSuite) TestInsideContext(c *C) {
+	defer os.Setenv("JUJU_CONTEXT_ID", os.Getenv("JUJU_CONTEXT_ID"))
+	os.Setenv("JUJU_CONTEXT_ID", "fake-id")
+	runCommand := &RunCommand{}
+	err := testing.InitCommand(runCommand, []string{"foo", "bar"})
+	c.Assert(err, ErrorMatches, "juju-run cannot be called from within a hook.*")
+}
+
+func (*RunTestSuite) TestMissingAgent(c *C) {
+	_, err := testing.RunCommand(c, &RunCommand{}, []string{"foo/2", "bar"})
+	c.Assert(err, ErrorMatches, `unit "foo/2" not found on this machine`)
+}
+
+func (*RunTestSuite) TestNoContext(c *C) {
+	ctx, err := testing.RunCommand(c, &RunCommand{}, []string{"--no-context", "echo done; echo oops >&2; exit 3"})
+	c.Assert(err, DeepEquals, &cmd.RcPassthroughError{3})
+	c.Assert(testing.Stdout(ctx), Equals, "done\n")
+	c.Assert(testing.Stderr(ctx), Equals, "oops\n")
+}
+
+type echoRunner struct{}
+
+func (echoRunner) RunCommands(commands string) (*exec.ExecResponse, error) {
+	return &exec.ExecResponse{Stdout: []byte(commands), Code: 1}, nil
+}
+
+func (*RunTestSuite) TestRunInUnitContext(c *C) {
+	agentDir := filepath.Join(runDataDir, "agents", "unit-foo-1")
+	err := os.MkdirAll(agentDir, 0755)
+	c.Assert(err, IsNil)
+	listener, err := uniter.NewRunListener(echoRunner{}, "unix", filepath.Join(agentDir, uniter.RunListenerFile))
+	c.Assert(err, IsNil)
+	go listener.Run()
+	defer listener.Close()
+
+	ctx, err := testing.RunCommand(c, &RunCommand{}, []string{"foo/1", "hostname"})
+	c.Assert(err, DeepEquals, &cmd.RcPassthroughError{1})
+	c.Assert(testing.Stdout(ctx), Equals, "hostname")
+}
@@ -13,6 +13,7 @@ import (
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/utils"
+	"launchpad.net/juju-core/utils/ssh"
 )
 
 // Default data directory.
@@ -89,6 +90,13 @@ func FinishMachineConfig(mcfg *cloudinit.MachineConfig, cfg *config.Config, cons
 		return fmt.Errorf("environment configuration has no admin-secret")
 	}
 	passwordHash := utils.PasswordHash(password)
+
+	// The state server connects to the other machines with its own key,
+	// which they accept through the environment's authorized keys.
+	if cfg, mcfg.SystemPrivateSSHKey, err = AddSystemIdentity(cfg); err != nil {
+		return err
+	}
+	mcfg.AuthorizedKeys = cfg.AuthorizedKeys()
 	mcfg.APIInfo = &api.Info{Password: passwordHash, CACert: caCert}
 	mcfg.StateInfo = &state.Info{Password: passwordHash, CACert: caCert}
 	mcfg.StatePort = cfg.StatePort()
@@ -108,6 +116,24 @@ func FinishMachineConfig(mcfg *cloudinit.MachineConfig, cfg *config.Config, cons
 	return nil
 }
 
+// AddSystemIdentity generates the ssh key pair that a state server uses to
+// connect to the machines of the environment. It returns a copy of cfg whose
+// authorized keys also hold the public key, and the private key in PEM
+// format, to be saved in the SystemIdentity file of the state server.
+func AddSystemIdentity(cfg *config.Config) (*config.Config, string, error) {
+	private, public, err := ssh.GenerateKey("juju-system-key")
+	if err != nil {
+		return nil, "", err
+	}
+	cfg, err = cfg.Apply(map[string]interface{}{
+		"authorized-keys": cfg.AuthorizedKeys() + "\n" + public,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, private, nil
+}
+
 // ComposeUserData puts together a binary (gzipped) blob of user data.
 // The additionalScripts are additional command lines that you need cloudinit
 // to run on the instance.  Use with care.
@@ -29,6 +29,11 @@ import (
 // is bootstrapping.
 const BootstrapStateURLFile = "/tmp/provider-state-url"
 
+// SystemIdentity is the name of the file, in the data directory of a state
+// server, holding the private ssh key the state server uses to connect to
+// the machines of the environment.
+const SystemIdentity = "system-identity"
+
 // MachineConfig represents initialization information for a new juju machine.
 type MachineConfig struct {
 	// StateServer specifies whether the new machine will run the
@@ -41,6 +46,12 @@ type MachineConfig struct {
 	StateServerCert []byte
 	StateServerKey  []byte
 
+	// SystemPrivateSSHKey holds the private ssh key, in PEM format,
+	// that the state server uses to connect to the machines of the
+	// environment. The machines accept it through AuthorizedKeys.
+	// It is ignored unless StateServer is set.
+	SystemPrivateSSHKey string
+
 	// StatePort specifies the TCP port that will be used
 	// by the MongoDB server. It must be non-zero
 	// if StateServer is true.
@@ -180,6 +191,9 @@ func Configure(cfg *MachineConfig, c *cloudinit.Config) (*cloudinit.Config, erro
 		c.AddPackage("mongodb-server")
 		certKey := string(cfg.StateServerCert) + string(cfg.StateServerKey)
 		addFile(c, cfg.dataFile("server.pem"), certKey, 0600)
+		if cfg.SystemPrivateSSHKey != "" {
+			addFile(c, cfg.dataFile(SystemIdentity), cfg.SystemPrivateSSHKey, 0600)
+		}
 		if err := cfg.addMongoToBoot(c); err != nil {
 			return nil, err
 		}
@@ -482,7 +482,7 @@ func (e *environ) Bootstrap(cons constraints.Value) error {
 		if err != nil {
 			panic(err)
 		}
-		e.state.apiServer, err = apiserver.NewServer(st, "localhost:0", []byte(testing.ServerCert), []byte(testing.ServerKey))
+		e.state.apiServer, err = apiserver.NewServer(st, "localhost:0", []byte(testing.ServerCert), []byte(testing.ServerKey), environs.DataDir)
 		if err != nil {
 			panic(err)
 		}
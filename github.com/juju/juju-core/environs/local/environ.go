@@ -18,6 +18,7 @@ import (
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/container/lxc"
 	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/environs/cloudinit"
 	"launchpad.net/juju-core/environs/config"
 	"launchpad.net/juju-core/environs/localstorage"
 	"launchpad.net/juju-core/instance"
@@ -141,9 +142,21 @@ func (env *localEnviron) Bootstrap(cons constraints.Value) error {
 		return err
 	}
 
+	// The state server connects to the containers with its own key, which
+	// they accept through the environment's authorized keys.
+	cfg, privateKey, err := environs.AddSystemIdentity(env.config.Config)
+	if err != nil {
+		return err
+	}
+	identityFile := filepath.Join(env.config.rootDir(), cloudinit.SystemIdentity)
+	if err := ioutil.WriteFile(identityFile, []byte(privateKey), 0600); err != nil {
+		logger.Errorf("failed to write the system identity: %v", err)
+		return err
+	}
+
 	// Have to initialize the state configuration with localhost so we get
 	// "special" permissions.
-	stateConnection, err := env.initialStateConfiguration(boostrapInstanceId, cons)
+	stateConnection, err := env.initialStateConfiguration(cfg, boostrapInstanceId, cons)
 	if err != nil {
 		return err
 	}
@@ -527,10 +540,9 @@ func (env *localEnviron) writeBootstrapAgentConfFile(cert, key []byte) error {
 	return nil
 }
 
-func (env *localEnviron) initialStateConfiguration(addr string, cons constraints.Value) (*state.State, error) {
+func (env *localEnviron) initialStateConfiguration(cfg *config.Config, addr string, cons constraints.Value) (*state.State, error) {
 	// We don't check the existance of the CACert here as if it wasn't set, we
 	// wouldn't get this far.
-	cfg := env.config.Config
 	caCert, _ := cfg.CACert()
 	addr = fmt.Sprintf("%s:%d", addr, cfg.StatePort())
 	info := &state.Info{
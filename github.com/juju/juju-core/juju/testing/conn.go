@@ -34,7 +34,8 @@ import (
 //         which uses the "dummy" environment type.
 //     RootDir/var/lib/juju
 //         An empty directory returned as DataDir - the
-//         root of the juju data storage space.
+//         root of the juju data storage space, and the
+//         data directory of the API server.
 // $HOME is set to point to RootDir/home/ubuntu.
 type JujuConnSuite struct {
 	// TODO: JujuConnSuite should not be concerned both with JUJU_HOME and with
@@ -51,6 +52,7 @@ type JujuConnSuite struct {
 	RootDir      string       // The faked-up root directory.
 	oldHome      string
 	oldJujuHome  string
+	oldDataDir   string
 	environ      environs.Environ
 }
 
@@ -197,6 +199,9 @@ func (s *JujuConnSuite) setUpConn(c *C) {
 	dataDir := filepath.Join(s.RootDir, "/var/lib/juju")
 	err = os.MkdirAll(dataDir, 0777)
 	c.Assert(err, IsNil)
+	// The dummy environment's API server uses it as its data directory.
+	s.oldDataDir = environs.DataDir
+	environs.DataDir = dataDir
 
 	yaml := []byte(fmt.Sprintf(envConfig, version.Current.Number))
 	err = ioutil.WriteFile(config.JujuHomePath("environments.yaml"), yaml, 0600)
@@ -248,6 +253,8 @@ func (s *JujuConnSuite) tearDownConn(c *C) {
 	s.State = nil
 	os.Setenv("HOME", s.oldHome)
 	s.oldHome = ""
+	environs.DataDir = s.oldDataDir
+	s.oldDataDir = ""
 	s.RootDir = ""
 }
 
@@ -53,9 +53,11 @@ $ModLoad imudp
 $UDPServerRun 514
 
 # Messages received from remote rsyslog machines contain a leading space so we
-# need to account for that.
-$template JujuLogFormatLocal,"%HOSTNAME%:%msg:::drop-last-lf%\n"
-$template JujuLogFormat,"%HOSTNAME%:%msg:2:2048:drop-last-lf%\n"
+# need to account for that. Each line starts with the tag of the agent that
+# logged it, without the "juju-" or "local-juju-" prefix, so that the log can
+# be filtered by agent.
+$template JujuLogFormatLocal,"%syslogtag:12:$% %msg:::drop-last-lf%\n"
+$template JujuLogFormat,"%syslogtag:6:$% %msg:2:2048:drop-last-lf%\n"
 
 :syslogtag, startswith, "juju-" /var/log/juju/all-machines.log;JujuLogFormat
 :syslogtag, startswith, "local-juju-" /var/log/juju/all-machines.log;JujuLogFormatLocal
@@ -0,0 +1,308 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"labix.org/v2/mgo"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/utils"
+)
+
+// ActionStatus describes the progress of an action.
+type ActionStatus string
+
+const (
+	// ActionPending is the status of an action that has been
+	// queued but not yet started by the unit agent.
+	ActionPending ActionStatus = "pending"
+
+	// ActionRunning is the status of an action that the unit
+	// agent has started.
+	ActionRunning ActionStatus = "running"
+
+	// ActionCompleted is the status of an action that ran
+	// successfully.
+	ActionCompleted ActionStatus = "completed"
+
+	// ActionFailed is the status of an action that failed
+	// to run, or could not be run at all.
+	ActionFailed ActionStatus = "failed"
+)
+
+// actionMarker separates the name of the unit an action is queued
+// for from the action's sequence number in the action's id.
+const actionMarker = "#a#"
+
+// actionDoc represents the internal state of an action in MongoDB.
+type actionDoc struct {
+	Id       string `bson:"_id"`
+	Unit     string
+	Seq      int
+	Name     string
+	Params   map[string]interface{}
+	Status   ActionStatus
+	Message  string
+	Results  map[string]interface{}
+	Enqueued time.Time
+}
+
+// Action represents an operation queued for a unit to run, and
+// records its progress and results.
+type Action struct {
+	st  *State
+	doc actionDoc
+}
+
+func newAction(st *State, doc *actionDoc) *Action {
+	return &Action{st: st, doc: *doc}
+}
+
+// IsActionId returns whether id is a valid action id.
+func IsActionId(id string) bool {
+	i := strings.Index(id, actionMarker)
+	if i < 0 || !IsUnitName(id[:i]) {
+		return false
+	}
+	seq := id[i+len(actionMarker):]
+	return seq != "" && strings.Trim(seq, "0123456789") == ""
+}
+
+// Id returns the id of the action, which identifies the unit it was
+// queued for and is unique within the environment.
+func (a *Action) Id() string {
+	return a.doc.Id
+}
+
+// String returns the id of the action.
+func (a *Action) String() string {
+	return a.doc.Id
+}
+
+// UnitName returns the name of the unit the action was queued for.
+func (a *Action) UnitName() string {
+	return a.doc.Unit
+}
+
+// Name returns the name of the action, as defined by the unit's charm.
+func (a *Action) Name() string {
+	return a.doc.Name
+}
+
+// Params returns the validated parameters of the action, including
+// the defaults of any parameters that were not supplied.
+func (a *Action) Params() map[string]interface{} {
+	return copyMap(a.doc.Params)
+}
+
+// Status returns the status of the action.
+func (a *Action) Status() ActionStatus {
+	return a.doc.Status
+}
+
+// Message returns the reason the action failed, if it did.
+func (a *Action) Message() string {
+	return a.doc.Message
+}
+
+// Results returns the results recorded by the action when it finished.
+func (a *Action) Results() map[string]interface{} {
+	return copyMap(a.doc.Results)
+}
+
+// Enqueued returns the time the action was queued.
+func (a *Action) Enqueued() time.Time {
+	return a.doc.Enqueued
+}
+
+// Refresh refreshes the contents of the action from the underlying
+// state.
+func (a *Action) Refresh() error {
+	err := a.st.actions.FindId(a.doc.Id).One(&a.doc)
+	if err == mgo.ErrNotFound {
+		return errors.NotFoundf("action %q", a)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot refresh action %q: %v", a, err)
+	}
+	return nil
+}
+
+// setStatus moves the action from status from to status to,
+// recording the supplied message and results.
+func (a *Action) setStatus(from, to ActionStatus, message string, results map[string]interface{}) error {
+	ops := []txn.Op{{
+		C:      a.st.actions.Name,
+		Id:     a.doc.Id,
+		Assert: D{{"status", from}},
+		Update: D{{"$set", D{
+			{"status", to},
+			{"message", message},
+			{"results", results},
+		}}},
+	}}
+	if err := a.st.runTransaction(ops); err == txn.ErrAborted {
+		if err := a.Refresh(); err != nil {
+			return err
+		}
+		return fmt.Errorf("action is %s, not %s", a.doc.Status, from)
+	} else if err != nil {
+		return err
+	}
+	a.doc.Status = to
+	a.doc.Message = message
+	a.doc.Results = results
+	return nil
+}
+
+// Begin marks a pending action as running.
+func (a *Action) Begin() (err error) {
+	defer utils.ErrorContextf(&err, "cannot begin action %q", a)
+	return a.setStatus(ActionPending, ActionRunning, "", nil)
+}
+
+// Complete marks a running action as completed, with the supplied
+// results.
+func (a *Action) Complete(results map[string]interface{}) (err error) {
+	defer utils.ErrorContextf(&err, "cannot complete action %q", a)
+	return a.setStatus(ActionRunning, ActionCompleted, "", results)
+}
+
+// Fail marks a pending or running action as failed, recording the
+// reason for the failure and any results it reported.
+func (a *Action) Fail(message string, results map[string]interface{}) (err error) {
+	defer utils.ErrorContextf(&err, "cannot fail action %q", a)
+	from := a.doc.Status
+	if from != ActionPending && from != ActionRunning {
+		return fmt.Errorf("action is %s", from)
+	}
+	return a.setStatus(from, ActionFailed, message, results)
+}
+
+// Action returns the action with the given id.
+func (st *State) Action(id string) (*Action, error) {
+	if !IsActionId(id) {
+		return nil, fmt.Errorf("%q is not a valid action id", id)
+	}
+	doc := &actionDoc{}
+	err := st.actions.FindId(id).One(doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("action %q", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get action %q: %v", id, err)
+	}
+	return newAction(st, doc), nil
+}
+
+// AddAction queues the named action, defined by the unit's charm, for
+// the unit to run with the supplied parameters. The parameters are
+// validated against the action's definition.
+func (u *Unit) AddAction(name string, params map[string]interface{}) (action *Action, err error) {
+	defer utils.ErrorContextf(&err, "cannot add action %q to unit %q", name, u)
+	if u.doc.Life != Alive {
+		return nil, stderrors.New("unit is not alive")
+	}
+	curl, ok := u.CharmURL()
+	if !ok {
+		svc, err := u.Service()
+		if err != nil {
+			return nil, err
+		}
+		curl, _ = svc.CharmURL()
+	}
+	ch, err := u.st.Charm(curl)
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := ch.Actions().ActionSpecs[name]
+	if !ok {
+		return nil, fmt.Errorf("action not defined by charm %q", curl)
+	}
+	if params, err = spec.ValidateParams(params); err != nil {
+		return nil, err
+	}
+	seq, err := u.st.sequence("action")
+	if err != nil {
+		return nil, err
+	}
+	doc := &actionDoc{
+		Id:       fmt.Sprintf("%s%s%d", u.doc.Name, actionMarker, seq),
+		Unit:     u.doc.Name,
+		Seq:      seq,
+		Name:     name,
+		Params:   params,
+		Status:   ActionPending,
+		Enqueued: time.Now(),
+	}
+	ops := []txn.Op{{
+		C:      u.st.units.Name,
+		Id:     u.doc.Name,
+		Assert: isAliveDoc,
+	}, {
+		C:      u.st.actions.Name,
+		Id:     doc.Id,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	if err := u.st.runTransaction(ops); err == txn.ErrAborted {
+		return nil, stderrors.New("unit is not alive")
+	} else if err != nil {
+		return nil, err
+	}
+	return newAction(u.st, doc), nil
+}
+
+// Actions returns all the actions queued for the unit, in the order
+// they were queued.
+func (u *Unit) Actions() ([]*Action, error) {
+	return u.findActions(D{{"unit", u.doc.Name}})
+}
+
+// PendingActions returns the actions queued for the unit that have
+// not yet been started, in the order they were queued.
+func (u *Unit) PendingActions() ([]*Action, error) {
+	return u.findActions(D{{"unit", u.doc.Name}, {"status", ActionPending}})
+}
+
+func (u *Unit) findActions(sel D) ([]*Action, error) {
+	var docs []actionDoc
+	if err := u.st.actions.Find(sel).Sort("seq").All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get actions for unit %q: %v", u, err)
+	}
+	actions := make([]*Action, len(docs))
+	for i := range docs {
+		actions[i] = newAction(u.st, &docs[i])
+	}
+	return actions, nil
+}
+
+// removeActionsOps returns the operations necessary to remove all the
+// actions queued for the named unit. Actions cannot be added to units
+// that are not alive, so no actions will be missed when the unit is
+// being removed.
+func removeActionsOps(st *State, unitName string) ([]txn.Op, error) {
+	var docs []struct {
+		Id string `bson:"_id"`
+	}
+	sel := D{{"unit", unitName}}
+	if err := st.actions.Find(sel).Select(D{{"_id", 1}}).All(&docs); err != nil {
+		return nil, fmt.Errorf("cannot get actions for unit %q: %v", unitName, err)
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      st.actions.Name,
+			Id:     doc.Id,
+			Remove: true,
+		}
+	}
+	return ops, nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/testing"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type ActionSuite struct {
+	ConnSuite
+	service *state.Service
+	unit    *state.Unit
+}
+
+var _ = Suite(&ActionSuite{})
+
+func (s *ActionSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.service, err = s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	s.unit, err = s.service.AddUnit()
+	c.Assert(err, IsNil)
+}
+
+func (s *ActionSuite) TestIsActionId(c *C) {
+	c.Assert(state.IsActionId("dummy/0#a#1"), Equals, true)
+	c.Assert(state.IsActionId("dummy/0#a#"), Equals, false)
+	c.Assert(state.IsActionId("dummy/0#a#x"), Equals, false)
+	c.Assert(state.IsActionId("dummy#a#1"), Equals, false)
+	c.Assert(state.IsActionId("dummy/0"), Equals, false)
+}
+
+func (s *ActionSuite) TestAddAction(c *C) {
+	action, err := s.unit.AddAction("snapshot", map[string]interface{}{"compress": true})
+	c.Assert(err, IsNil)
+	c.Assert(state.IsActionId(action.Id()), Equals, true)
+	c.Assert(action.UnitName(), Equals, "dummy/0")
+	c.Assert(action.Name(), Equals, "snapshot")
+	c.Assert(action.Params(), DeepEquals, map[string]interface{}{
+		"outfile":  "foo.bz2",
+		"compress": true,
+	})
+	c.Assert(action.Status(), Equals, state.ActionPending)
+
+	action1, err := s.State.Action(action.Id())
+	c.Assert(err, IsNil)
+	c.Assert(action1.Name(), Equals, "snapshot")
+	c.Assert(action1.Params(), DeepEquals, action.Params())
+	c.Assert(action1.Enqueued().Unix(), Equals, action.Enqueued().Unix())
+}
+
+func (s *ActionSuite) TestAddActionErrors(c *C) {
+	_, err := s.unit.AddAction("backup", nil)
+	c.Assert(err, ErrorMatches, `cannot add action "backup" to unit "dummy/0": action not defined by charm "local:series/dummy-1"`)
+	_, err = s.unit.AddAction("snapshot", map[string]interface{}{"compress": "yes"})
+	c.Assert(err, ErrorMatches, `cannot add action "snapshot" to unit "dummy/0": invalid parameters: compress: expected bool, got "yes"`)
+
+	err = s.unit.Destroy()
+	c.Assert(err, IsNil)
+	_, err = s.unit.AddAction("snapshot", nil)
+	c.Assert(err, ErrorMatches, `cannot add action "snapshot" to unit "dummy/0": unit is not alive`)
+}
+
+func (s *ActionSuite) TestActionNotFound(c *C) {
+	_, err := s.State.Action("dummy/0#a#42")
+	c.Assert(err, ErrorMatches, `action "dummy/0#a#42" not found`)
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+	_, err = s.State.Action("42")
+	c.Assert(err, ErrorMatches, `"42" is not a valid action id`)
+}
+
+func (s *ActionSuite) TestActionLifecycle(c *C) {
+	action, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	err = action.Complete(nil)
+	c.Assert(err, ErrorMatches, `cannot complete action ".*": action is pending, not running`)
+
+	err = action.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(action.Status(), Equals, state.ActionRunning)
+	err = action.Complete(map[string]interface{}{"size": "10M"})
+	c.Assert(err, IsNil)
+
+	err = action.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(action.Status(), Equals, state.ActionCompleted)
+	c.Assert(action.Results(), DeepEquals, map[string]interface{}{"size": "10M"})
+	err = action.Begin()
+	c.Assert(err, ErrorMatches, `cannot begin action ".*": action is completed, not pending`)
+	err = action.Fail("too late", nil)
+	c.Assert(err, ErrorMatches, `cannot fail action ".*": action is completed`)
+
+	action, err = s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	err = action.Fail("unit is busy", nil)
+	c.Assert(err, IsNil)
+	err = action.Refresh()
+	c.Assert(err, IsNil)
+	c.Assert(action.Status(), Equals, state.ActionFailed)
+	c.Assert(action.Message(), Equals, "unit is busy")
+}
+
+func (s *ActionSuite) TestActionBeginRace(c *C) {
+	action, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	other, err := s.State.Action(action.Id())
+	c.Assert(err, IsNil)
+	err = other.Fail("cancelled", nil)
+	c.Assert(err, IsNil)
+	err = action.Begin()
+	c.Assert(err, ErrorMatches, `cannot begin action ".*": action is failed, not pending`)
+}
+
+func actionIds(actions []*state.Action) []string {
+	ids := make([]string, len(actions))
+	for i, action := range actions {
+		ids[i] = action.Id()
+	}
+	return ids
+}
+
+func (s *ActionSuite) TestUnitActions(c *C) {
+	var ids []string
+	for i := 0; i < 3; i++ {
+		action, err := s.unit.AddAction("snapshot", nil)
+		c.Assert(err, IsNil)
+		ids = append(ids, action.Id())
+	}
+	action, err := s.State.Action(ids[1])
+	c.Assert(err, IsNil)
+	err = action.Begin()
+	c.Assert(err, IsNil)
+
+	actions, err := s.unit.Actions()
+	c.Assert(err, IsNil)
+	c.Assert(actionIds(actions), DeepEquals, ids)
+	actions, err = s.unit.PendingActions()
+	c.Assert(err, IsNil)
+	c.Assert(actionIds(actions), DeepEquals, []string{ids[0], ids[2]})
+
+	other, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+	actions, err = other.Actions()
+	c.Assert(err, IsNil)
+	c.Assert(actions, HasLen, 0)
+}
+
+func (s *ActionSuite) TestRemoveUnitRemovesActions(c *C) {
+	action, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	err = s.unit.EnsureDead()
+	c.Assert(err, IsNil)
+	err = s.unit.Remove()
+	c.Assert(err, IsNil)
+	_, err = s.State.Action(action.Id())
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+}
+
+func (s *ActionSuite) TestWatchActions(c *C) {
+	action0, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	action1, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	err = action1.Begin()
+	c.Assert(err, IsNil)
+
+	w := s.unit.WatchActions()
+	defer testing.AssertStop(c, w)
+	wc := testing.NewStringsWatcherC(c, s.State, w)
+	wc.AssertChange(action0.Id())
+	wc.AssertNoChange()
+
+	// Changes to existing actions are not reported.
+	err = action0.Begin()
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	// New actions are.
+	action2, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	wc.AssertChange(action2.Id())
+	wc.AssertNoChange()
+
+	// Actions for other units are not.
+	other, err := s.service.AddUnit()
+	c.Assert(err, IsNil)
+	_, err = other.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	wc.AssertNoChange()
+
+	testing.AssertStop(c, w)
+	wc.AssertClosed()
+}
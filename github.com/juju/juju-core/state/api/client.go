@@ -4,6 +4,8 @@
 package api
 
 import (
+	"time"
+
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/state/api/params"
@@ -162,6 +164,79 @@ func (c *Client) SetServiceConstraints(service string, constraints constraints.V
 	return c.st.Call("Client", "", "SetServiceConstraints", params, nil)
 }
 
+// Run the Commands specified on the machines identified through the ids
+// provided in the machines, services and units slices.
+func (c *Client) Run(run params.RunParams) ([]params.RunResult, error) {
+	var results params.RunResults
+	err := c.st.Call("Client", "", "Run", run, &results)
+	return results.Results, err
+}
+
+// RunOnAllMachines runs the command on all the machines with the specified
+// timeout.
+func (c *Client) RunOnAllMachines(commands string, timeout time.Duration) ([]params.RunResult, error) {
+	var results params.RunResults
+	args := params.RunParams{Commands: commands, Timeout: timeout}
+	err := c.st.Call("Client", "", "RunOnAllMachines", args, &results)
+	return results.Results, err
+}
+
+// DeployBundle deploys the services and relations of the given bundle,
+// in YAML format, and updates existing services to match it. It
+// returns a description of the changes made.
+func (c *Client) DeployBundle(bundleYAML string) ([]string, error) {
+	var results params.DeployBundleResults
+	args := params.DeployBundle{BundleYAML: bundleYAML}
+	err := c.st.Call("Client", "", "DeployBundle", args, &results)
+	return results.Changes, err
+}
+
+// ExportBundle returns a bundle, in YAML format, describing the
+// services of the environment and the relations between them.
+func (c *Client) ExportBundle() (string, error) {
+	var results params.ExportBundleResults
+	err := c.st.Call("Client", "", "ExportBundle", nil, &results)
+	return results.BundleYAML, err
+}
+
+// QueueAction queues the named action, defined by the unit's charm,
+// for the unit to run with the given parameters, in YAML format. It
+// returns the id of the queued action.
+func (c *Client) QueueAction(unitName, actionName, paramsYAML string) (string, error) {
+	var result params.QueueActionResult
+	args := params.QueueAction{
+		UnitName:   unitName,
+		ActionName: actionName,
+		ParamsYAML: paramsYAML,
+	}
+	err := c.st.Call("Client", "", "QueueAction", args, &result)
+	return result.ActionId, err
+}
+
+// ActionResults returns the status and results of the actions with
+// the given ids.
+func (c *Client) ActionResults(ids []string) ([]params.ActionResult, error) {
+	var results params.ActionResults
+	args := params.ActionIds{Ids: ids}
+	err := c.st.Call("Client", "", "ActionResults", args, &results)
+	return results.Results, err
+}
+
+// Backup returns a backup of the environment's state server, in the
+// format written by the backup package.
+func (c *Client) Backup() ([]byte, error) {
+	var result params.BackupResult
+	err := c.st.Call("Client", "", "Backup", nil, &result)
+	return result.Archive, err
+}
+
+// Restore replaces the state of the environment with the state held
+// in a backup made by Backup.
+func (c *Client) Restore(archive []byte) error {
+	args := params.Restore{Archive: archive}
+	return c.st.Call("Client", "", "Restore", args, nil)
+}
+
 // CharmInfo holds information about a charm.
 type CharmInfo struct {
 	Revision int
@@ -210,6 +285,16 @@ func (c *Client) WatchAll() (*AllWatcher, error) {
 	return newAllWatcher(c, &info.AllWatcherId), nil
 }
 
+// WatchDebugLog returns a DebugLogWatcher, from which you can request the
+// Next lines of the consolidated log of the environment that match args.
+func (c *Client) WatchDebugLog(args params.DebugLog) (*DebugLogWatcher, error) {
+	info := new(params.DebugLogWatcherId)
+	if err := c.st.Call("Client", "", "WatchDebugLog", args, info); err != nil {
+		return nil, err
+	}
+	return newDebugLogWatcher(c, info.DebugLogWatcherId), nil
+}
+
 // GetAnnotations returns annotations that have been set on the given entity.
 func (c *Client) GetAnnotations(tag string) (map[string]string, error) {
 	args := params.GetAnnotations{tag}
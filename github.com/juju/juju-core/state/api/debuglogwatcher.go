@@ -0,0 +1,31 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api
+
+import (
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// DebugLogWatcher follows the consolidated log of the environment.
+type DebugLogWatcher struct {
+	client *Client
+	id     string
+}
+
+func newDebugLogWatcher(client *Client, id string) *DebugLogWatcher {
+	return &DebugLogWatcher{client, id}
+}
+
+// Next returns the log lines written since the last call to Next,
+// blocking until there are some.
+func (watcher *DebugLogWatcher) Next() ([]string, error) {
+	info := new(params.DebugLogNextResults)
+	err := watcher.client.st.Call("DebugLogWatcher", watcher.id, "Next", nil, info)
+	return info.Lines, err
+}
+
+// Stop stops the watcher.
+func (watcher *DebugLogWatcher) Stop() error {
+	return watcher.client.st.Call("DebugLogWatcher", watcher.id, "Stop", nil, nil)
+}
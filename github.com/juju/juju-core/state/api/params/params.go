@@ -10,6 +10,8 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/constraints"
 	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/utils/exec"
+	"time"
 )
 
 // ErrorResults holds the results of calling a bulk operation which
@@ -174,6 +176,136 @@ type CharmInfo struct {
 	CharmURL string
 }
 
+// RunParams is used to provide the parameters to the Run method.
+// Commands and Timeout are expected to have values, and one or more
+// values should be in the Machines, Services, or Units slices.
+type RunParams struct {
+	Commands string
+	Timeout  time.Duration
+	Machines []string
+	Services []string
+	Units    []string
+}
+
+// RunResult contains the result from an individual run call on a machine.
+// UnitId is populated if the command was run inside the unit context.
+type RunResult struct {
+	exec.ExecResponse
+	MachineId string
+	UnitId    string
+	Error     string
+}
+
+// RunResults is used to return the slice of results.  API server side calls
+// need to return single structure values.
+type RunResults struct {
+	Results []RunResult
+}
+
+// DeployBundle holds the parameters for making a Client.DeployBundle call.
+type DeployBundle struct {
+	// BundleYAML holds the bundle in the format read by bundle.Parse.
+	BundleYAML string
+}
+
+// DeployBundleResults holds the results of a Client.DeployBundle call.
+type DeployBundleResults struct {
+	// Changes describes the changes made to the environment.
+	Changes []string
+}
+
+// ExportBundleResults holds the results of a Client.ExportBundle call.
+type ExportBundleResults struct {
+	BundleYAML string
+}
+
+// QueueAction holds the parameters for making a Client.QueueAction call.
+type QueueAction struct {
+	UnitName   string
+	ActionName string
+	// ParamsYAML holds the parameters of the action as a YAML map,
+	// so that their types survive the trip to the server.
+	ParamsYAML string
+}
+
+// QueueActionResult holds the results of a Client.QueueAction call.
+type QueueActionResult struct {
+	ActionId string
+}
+
+// ActionIds holds the parameters for making a Client.ActionResults call.
+type ActionIds struct {
+	Ids []string
+}
+
+// ActionResult holds the details of an action, or the error that
+// prevented them from being fetched.
+type ActionResult struct {
+	Id       string
+	UnitName string
+	Name     string
+	Params   map[string]interface{}
+	Status   string
+	Message  string
+	Results  map[string]interface{}
+	Enqueued time.Time
+	Error    *Error
+}
+
+// ActionResults holds the results of a Client.ActionResults call.
+type ActionResults struct {
+	Results []ActionResult
+}
+
+// BackupResult holds the results of a Client.Backup call.
+type BackupResult struct {
+	// Archive holds the backup in the format written by
+	// the backup package.
+	Archive []byte
+}
+
+// Restore holds the parameters for making a Client.Restore call.
+type Restore struct {
+	Archive []byte
+}
+
+// DebugLog holds the parameters for making a Client.WatchDebugLog call.
+type DebugLog struct {
+	// IncludeEntity lists the tags of the agents whose messages are
+	// included, as patterns matched with path.Match, for instance
+	// "unit-mysql-*". If empty, messages from all agents are included.
+	IncludeEntity []string
+	// ExcludeEntity lists patterns for agent tags whose messages are
+	// excluded.
+	ExcludeEntity []string
+	// IncludeModule lists the logging modules whose messages are
+	// included, along with their submodules. If empty, messages from
+	// all modules are included.
+	IncludeModule []string
+	// ExcludeModule lists logging modules whose messages, and those of
+	// their submodules, are excluded.
+	ExcludeModule []string
+	// Level is the minimum severity of the included messages, for
+	// instance "WARNING". If empty, messages of all levels are included.
+	Level string
+	// Backlog is the number of matching lines before the end of the
+	// log to start from.
+	Backlog uint
+	// Replay starts from the beginning of the log, ignoring Backlog.
+	Replay bool
+}
+
+// DebugLogWatcherId holds the id of a DebugLogWatcher.
+type DebugLogWatcherId struct {
+	DebugLogWatcherId string
+}
+
+// DebugLogNextResults holds the lines returned from calling
+// DebugLogWatcher.Next().
+type DebugLogNextResults struct {
+	Lines []string
+}
+
 // AllWatcherId holds the id of an AllWatcher.
 type AllWatcherId struct {
 	AllWatcherId string
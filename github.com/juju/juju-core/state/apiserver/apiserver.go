@@ -20,16 +20,18 @@ import (
 
 // Server holds the server side of the API.
 type Server struct {
-	tomb  tomb.Tomb
-	wg    sync.WaitGroup
-	state *state.State
-	addr  net.Addr
+	tomb    tomb.Tomb
+	wg      sync.WaitGroup
+	state   *state.State
+	addr    net.Addr
+	dataDir string
 }
 
 // Serve serves the given state by accepting requests on the given
 // listener, using the given certificate and key (in PEM format) for
-// authentication.
-func NewServer(s *state.State, addr string, cert, key []byte) (*Server, error) {
+// authentication. The dataDir is the data directory of the state
+// server's agent.
+func NewServer(s *state.State, addr string, cert, key []byte, dataDir string) (*Server, error) {
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
@@ -40,8 +42,9 @@ func NewServer(s *state.State, addr string, cert, key []byte) (*Server, error) {
 		return nil, err
 	}
 	srv := &Server{
-		state: s,
-		addr:  lis.Addr(),
+		state:   s,
+		addr:    lis.Addr(),
+		dataDir: dataDir,
 	}
 	// TODO(rog) check that *srvRoot is a valid type for using
 	// as an RPC server.
@@ -0,0 +1,62 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+
+	"launchpad.net/goyaml"
+
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/apiserver/common"
+)
+
+// QueueAction queues an action, defined by the unit's charm, for the
+// unit to run, and returns the id of the queued action.
+func (c *Client) QueueAction(args params.QueueAction) (params.QueueActionResult, error) {
+	var actionParams map[string]interface{}
+	if err := goyaml.Unmarshal([]byte(args.ParamsYAML), &actionParams); err != nil {
+		return params.QueueActionResult{}, fmt.Errorf("invalid action parameters: %v", err)
+	}
+	unit, err := c.api.state.Unit(args.UnitName)
+	if err != nil {
+		return params.QueueActionResult{}, err
+	}
+	action, err := unit.AddAction(args.ActionName, actionParams)
+	if err != nil {
+		return params.QueueActionResult{}, err
+	}
+	return params.QueueActionResult{ActionId: action.Id()}, nil
+}
+
+// ActionResults returns the status and results of the actions with the
+// given ids.
+func (c *Client) ActionResults(args params.ActionIds) (params.ActionResults, error) {
+	results := params.ActionResults{
+		Results: make([]params.ActionResult, len(args.Ids)),
+	}
+	for i, id := range args.Ids {
+		action, err := c.api.state.Action(id)
+		if err != nil {
+			results.Results[i] = params.ActionResult{Id: id, Error: common.ServerError(err)}
+			continue
+		}
+		results.Results[i] = actionResult(action)
+	}
+	return results, nil
+}
+
+func actionResult(action *state.Action) params.ActionResult {
+	return params.ActionResult{
+		Id:       action.Id(),
+		UnitName: action.UnitName(),
+		Name:     action.Name(),
+		Params:   action.Params(),
+		Status:   string(action.Status()),
+		Message:  action.Message(),
+		Results:  action.Results(),
+		Enqueued: action.Enqueued(),
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+type actionSuite struct {
+	baseSuite
+	unit *state.Unit
+}
+
+var _ = Suite(&actionSuite{})
+
+func (s *actionSuite) SetUpTest(c *C) {
+	s.baseSuite.SetUpTest(c)
+	svc, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	s.unit, err = svc.AddUnit()
+	c.Assert(err, IsNil)
+}
+
+func (s *actionSuite) TestQueueAction(c *C) {
+	id, err := s.APIState.Client().QueueAction("dummy/0", "snapshot", "compress: true")
+	c.Assert(err, IsNil)
+	action, err := s.State.Action(id)
+	c.Assert(err, IsNil)
+	c.Assert(action.UnitName(), Equals, "dummy/0")
+	c.Assert(action.Name(), Equals, "snapshot")
+	c.Assert(action.Params(), DeepEquals, map[string]interface{}{
+		"outfile":  "foo.bz2",
+		"compress": true,
+	})
+	c.Assert(action.Status(), Equals, state.ActionPending)
+}
+
+var queueActionErrorTests = []struct {
+	unitName   string
+	actionName string
+	paramsYAML string
+	err        string
+}{{
+	unitName:   "dummy/1",
+	actionName: "snapshot",
+	err:        `unit "dummy/1" not found`,
+}, {
+	unitName:   "dummy/0",
+	actionName: "backup",
+	err:        `cannot add action "backup" to unit "dummy/0": action not defined by charm ".*"`,
+}, {
+	unitName:   "dummy/0",
+	actionName: "snapshot",
+	paramsYAML: "level: 3",
+	err:        `cannot add action "snapshot" to unit "dummy/0": invalid parameters: level: expected nothing, got 3`,
+}, {
+	unitName:   "dummy/0",
+	actionName: "snapshot",
+	paramsYAML: "[compress]",
+	err:        `invalid action parameters: .*`,
+}}
+
+func (s *actionSuite) TestQueueActionErrors(c *C) {
+	for i, t := range queueActionErrorTests {
+		c.Logf("test %d: %s %s %q", i, t.unitName, t.actionName, t.paramsYAML)
+		_, err := s.APIState.Client().QueueAction(t.unitName, t.actionName, t.paramsYAML)
+		c.Check(err, ErrorMatches, t.err)
+	}
+}
+
+func (s *actionSuite) TestActionResults(c *C) {
+	action, err := s.unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	err = action.Begin()
+	c.Assert(err, IsNil)
+	err = action.Complete(map[string]interface{}{"size": "10M"})
+	c.Assert(err, IsNil)
+
+	results, err := s.APIState.Client().ActionResults([]string{action.Id(), "dummy/0#a#42", "foo"})
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 3)
+	c.Assert(results[0].Enqueued.Unix(), Equals, action.Enqueued().Unix())
+	results[0].Enqueued = action.Enqueued()
+	c.Assert(results[0], DeepEquals, params.ActionResult{
+		Id:       action.Id(),
+		UnitName: "dummy/0",
+		Name:     "snapshot",
+		Params:   map[string]interface{}{"outfile": "foo.bz2"},
+		Status:   "completed",
+		Results:  map[string]interface{}{"size": "10M"},
+		Enqueued: action.Enqueued(),
+	})
+	c.Assert(results[1].Error, ErrorMatches, `action "dummy/0#a#42" not found`)
+	c.Assert(results[1].Error.Code, Equals, params.CodeNotFound)
+	c.Assert(results[2].Error, ErrorMatches, `"foo" is not a valid action id`)
+}
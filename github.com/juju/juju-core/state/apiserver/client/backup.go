@@ -0,0 +1,37 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"bytes"
+
+	"launchpad.net/juju-core/backup"
+	"launchpad.net/juju-core/state/api/params"
+)
+
+// Backup returns a backup of the state database and of the agent
+// configurations and certificates in the state server's data directory.
+func (c *Client) Backup() (params.BackupResult, error) {
+	a, err := backup.New(c.api.state, c.api.dataDir)
+	if err != nil {
+		return params.BackupResult{}, err
+	}
+	var buf bytes.Buffer
+	if err := a.Write(&buf); err != nil {
+		return params.BackupResult{}, err
+	}
+	return params.BackupResult{Archive: buf.Bytes()}, nil
+}
+
+// Restore replaces the state database with the one held in a backup
+// made by Backup. The identity of the running state server is kept,
+// so that it can take the place of the state server that was backed
+// up.
+func (c *Client) Restore(args params.Restore) error {
+	a, err := backup.Read(bytes.NewReader(args.Archive))
+	if err != nil {
+		return err
+	}
+	return a.Restore(c.api.state)
+}
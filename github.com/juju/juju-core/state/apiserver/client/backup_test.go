@@ -0,0 +1,80 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/backup"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type backupSuite struct {
+	baseSuite
+}
+
+var _ = Suite(&backupSuite{})
+
+func (s *backupSuite) SetUpTest(c *C) {
+	s.baseSuite.SetUpTest(c)
+	agentDir := filepath.Join(s.DataDir(), "agents", "machine-0")
+	err := os.MkdirAll(agentDir, 0755)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(filepath.Join(agentDir, "agent.conf"), []byte("conf"), 0600)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(filepath.Join(s.DataDir(), "server.pem"), []byte("pem"), 0600)
+	c.Assert(err, IsNil)
+}
+
+func (s *backupSuite) TestBackup(c *C) {
+	_, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	data, err := s.APIState.Client().Backup()
+	c.Assert(err, IsNil)
+	a, err := backup.Read(bytes.NewReader(data))
+	c.Assert(err, IsNil)
+	c.Assert(a.Files, DeepEquals, map[string][]byte{
+		"agents/machine-0/agent.conf": []byte("conf"),
+		"server.pem":                  []byte("pem"),
+	})
+	c.Assert(a.Collections["services"], Not(HasLen), 0)
+}
+
+func (s *backupSuite) TestBackupWithoutStateServerFiles(c *C) {
+	err := os.RemoveAll(filepath.Join(s.DataDir(), "agents"))
+	c.Assert(err, IsNil)
+	_, err = s.APIState.Client().Backup()
+	c.Assert(err, ErrorMatches, "cannot find state server files: no agent configuration found in .*")
+}
+
+func (s *backupSuite) TestRestore(c *C) {
+	_, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	data, err := s.APIState.Client().Backup()
+	c.Assert(err, IsNil)
+	svc, err := s.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	err = svc.Destroy()
+	c.Assert(err, IsNil)
+	_, err = s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+
+	err = s.APIState.Client().Restore(data)
+	c.Assert(err, IsNil)
+	_, err = s.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	_, err = s.State.Service("mysql")
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+}
+
+func (s *backupSuite) TestRestoreBadArchive(c *C) {
+	err := s.APIState.Client().Restore([]byte("bad"))
+	c.Assert(err, ErrorMatches, `cannot read backup: .*`)
+}
@@ -0,0 +1,209 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/statecmd"
+)
+
+// DeployBundle brings the environment in line with the given bundle.
+// Missing services are deployed, and existing ones are updated with
+// the bundle's configuration, constraints, exposure and number of units;
+// missing relations are added. Nothing in the environment that is not
+// mentioned in the bundle is changed or removed, so deploying the same
+// bundle again has no effect. As with ServiceDeploy, local charms are
+// not supported.
+func (c *Client) DeployBundle(args params.DeployBundle) (params.DeployBundleResults, error) {
+	b, err := bundle.Parse([]byte(args.BundleYAML))
+	if err != nil {
+		return params.DeployBundleResults{}, err
+	}
+	for _, name := range b.ServiceNames() {
+		curl, _ := charm.ParseURL(b.Services[name].Charm)
+		if curl.Schema != "cs" {
+			return params.DeployBundleResults{}, fmt.Errorf("service %q: charm url has unsupported schema %q", name, curl.Schema)
+		}
+	}
+	conn, err := juju.NewConnFromState(c.api.state)
+	if err != nil {
+		return params.DeployBundleResults{}, err
+	}
+	var changes []string
+	for _, name := range b.ServiceNames() {
+		svcChanges, err := deployBundleService(conn, name, b.Services[name])
+		changes = append(changes, svcChanges...)
+		if err != nil {
+			return params.DeployBundleResults{}, fmt.Errorf("cannot deploy service %q: %v", name, err)
+		}
+	}
+	for _, rel := range b.Relations {
+		added, err := ensureRelation(c.api.state, rel)
+		if err != nil {
+			return params.DeployBundleResults{}, fmt.Errorf("cannot add relation %q: %v", rel, err)
+		}
+		if added != "" {
+			changes = append(changes, fmt.Sprintf("added relation %s", added))
+		}
+	}
+	return params.DeployBundleResults{Changes: changes}, nil
+}
+
+// ExportBundle returns a bundle describing the environment.
+func (c *Client) ExportBundle() (params.ExportBundleResults, error) {
+	b, err := statecmd.ExportBundle(c.api.state)
+	if err != nil {
+		return params.ExportBundleResults{}, err
+	}
+	data, err := b.Marshal()
+	if err != nil {
+		return params.ExportBundleResults{}, err
+	}
+	return params.ExportBundleResults{BundleYAML: string(data)}, nil
+}
+
+// deployBundleService deploys the named service as described by bsvc,
+// or updates it to match if it already exists, and returns a
+// description of the changes made.
+func deployBundleService(conn *juju.Conn, name string, bsvc *bundle.Service) ([]string, error) {
+	curl, err := charm.ParseURL(bsvc.Charm)
+	if err != nil {
+		return nil, err
+	}
+	cons, err := constraints.Parse(bsvc.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	svc, err := conn.State.Service(name)
+	if errors.IsNotFoundError(err) {
+		ch, err := conn.PutCharm(curl, CharmStore, false)
+		if err != nil {
+			return nil, err
+		}
+		svc, err = conn.DeployService(juju.DeployServiceParams{
+			ServiceName:    name,
+			Charm:          ch,
+			NumUnits:       bsvc.NumUnits,
+			ConfigSettings: bsvc.Options,
+			Constraints:    cons,
+		})
+		if err != nil {
+			return nil, err
+		}
+		changes := []string{fmt.Sprintf("deployed service %q from %q with %d units", name, ch.URL(), bsvc.NumUnits)}
+		if bsvc.Expose {
+			if err := svc.SetExposed(); err != nil {
+				return changes, err
+			}
+			changes = append(changes, fmt.Sprintf("exposed service %q", name))
+		}
+		return changes, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	current, _ := svc.CharmURL()
+	if curl.Revision < 0 {
+		current = current.WithRevision(-1)
+	}
+	if *current != *curl {
+		return nil, fmt.Errorf("service uses charm %q, not %q", current, curl)
+	}
+	if len(bsvc.Options) > 0 {
+		ch, _, err := svc.Charm()
+		if err != nil {
+			return nil, err
+		}
+		settings, err := ch.Config().ValidateSettings(bsvc.Options)
+		if err != nil {
+			return nil, err
+		}
+		oldSettings, err := svc.ConfigSettings()
+		if err != nil {
+			return nil, err
+		}
+		changed := make(charm.Settings)
+		var names []string
+		for name, value := range settings {
+			if !reflect.DeepEqual(oldSettings[name], value) {
+				changed[name] = value
+				names = append(names, name)
+			}
+		}
+		if len(changed) > 0 {
+			if err := svc.UpdateConfigSettings(changed); err != nil {
+				return changes, err
+			}
+			sort.Strings(names)
+			changes = append(changes, fmt.Sprintf("set options %s of service %q", strings.Join(names, ", "), name))
+		}
+	}
+	if bsvc.Constraints != "" {
+		oldCons, err := svc.Constraints()
+		if err != nil {
+			return changes, err
+		}
+		if oldCons.String() != cons.String() {
+			if err := svc.SetConstraints(cons); err != nil {
+				return changes, err
+			}
+			changes = append(changes, fmt.Sprintf("set constraints of service %q to %q", name, cons))
+		}
+	}
+	if bsvc.Expose && !svc.IsExposed() {
+		if err := svc.SetExposed(); err != nil {
+			return changes, err
+		}
+		changes = append(changes, fmt.Sprintf("exposed service %q", name))
+	}
+	units, err := svc.AllUnits()
+	if err != nil {
+		return changes, err
+	}
+	alive := 0
+	for _, unit := range units {
+		if unit.Life() == state.Alive {
+			alive++
+		}
+	}
+	if n := bsvc.NumUnits - alive; n > 0 {
+		if _, err := conn.AddUnits(svc, n, ""); err != nil {
+			return changes, err
+		}
+		changes = append(changes, fmt.Sprintf("added %d units to service %q", n, name))
+	}
+	return changes, nil
+}
+
+// ensureRelation adds the relation between the given endpoints if it
+// does not already exist. It returns the key of the relation if it
+// was added, and an empty string otherwise.
+func ensureRelation(st *state.State, endpoints []string) (string, error) {
+	eps, err := st.InferEndpoints(endpoints)
+	if err != nil {
+		return "", err
+	}
+	if _, err := st.EndpointsRelation(eps...); err == nil {
+		return "", nil
+	} else if !errors.IsNotFoundError(err) {
+		return "", err
+	}
+	rel, err := st.AddRelation(eps...)
+	if err != nil {
+		return "", err
+	}
+	return rel.String(), nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"fmt"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/constraints"
+)
+
+type bundleSuite struct {
+	baseSuite
+}
+
+var _ = Suite(&bundleSuite{})
+
+// addBundleCharms adds the charms used by testBundle to a mock charm
+// store, and returns the URLs of the wordpress and mysql charms.
+func (s *bundleSuite) addBundleCharms(c *C) (restore func(), wordpressURL, mysqlURL *charm.URL) {
+	store, restore := makeMockCharmStore()
+	wordpressURL, _ = addCharm(c, store, "wordpress")
+	mysqlURL, _ = addCharm(c, store, "mysql")
+	return restore, wordpressURL, mysqlURL
+}
+
+const testBundle = `
+services:
+  wordpress:
+    charm: cs:precise/wordpress
+    num_units: 2
+    options:
+      blog-title: My blog
+    constraints: mem=2G
+    expose: true
+  mysql:
+    charm: cs:precise/mysql
+    num_units: 1
+relations:
+- [wordpress, mysql]
+`
+
+func (s *bundleSuite) TestDeployBundle(c *C) {
+	restore, wordpressURL, mysqlURL := s.addBundleCharms(c)
+	defer restore()
+
+	changes, err := s.APIState.Client().DeployBundle(testBundle)
+	c.Assert(err, IsNil)
+	c.Assert(changes, DeepEquals, []string{
+		fmt.Sprintf(`deployed service "mysql" from %q with 1 units`, mysqlURL),
+		fmt.Sprintf(`deployed service "wordpress" from %q with 2 units`, wordpressURL),
+		`exposed service "wordpress"`,
+		`added relation wordpress:db mysql:server`,
+	})
+
+	wordpress, err := s.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	curl, _ := wordpress.CharmURL()
+	c.Assert(curl, DeepEquals, wordpressURL)
+	settings, err := wordpress.ConfigSettings()
+	c.Assert(err, IsNil)
+	c.Assert(settings, DeepEquals, charm.Settings{"blog-title": "My blog"})
+	cons, err := wordpress.Constraints()
+	c.Assert(err, IsNil)
+	c.Assert(cons, DeepEquals, constraints.MustParse("mem=2G"))
+	c.Assert(wordpress.IsExposed(), Equals, true)
+	units, err := wordpress.AllUnits()
+	c.Assert(err, IsNil)
+	c.Assert(units, HasLen, 2)
+	rels, err := wordpress.Relations()
+	c.Assert(err, IsNil)
+	c.Assert(rels, HasLen, 1)
+
+	// Deploying the same bundle again changes nothing.
+	changes, err = s.APIState.Client().DeployBundle(testBundle)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+}
+
+func (s *bundleSuite) TestDeployBundleUpdatesServices(c *C) {
+	restore, _, _ := s.addBundleCharms(c)
+	defer restore()
+	_, err := s.APIState.Client().DeployBundle(`
+services:
+  wordpress:
+    charm: cs:precise/wordpress
+    num_units: 1
+  mysql:
+    charm: cs:precise/mysql
+    num_units: 3
+`)
+	c.Assert(err, IsNil)
+
+	changes, err := s.APIState.Client().DeployBundle(testBundle)
+	c.Assert(err, IsNil)
+	c.Assert(changes, DeepEquals, []string{
+		`set options blog-title of service "wordpress"`,
+		`set constraints of service "wordpress" to "mem=2048M"`,
+		`exposed service "wordpress"`,
+		`added 1 units to service "wordpress"`,
+		`added relation wordpress:db mysql:server`,
+	})
+	// Units are never removed.
+	mysql, err := s.State.Service("mysql")
+	c.Assert(err, IsNil)
+	units, err := mysql.AllUnits()
+	c.Assert(err, IsNil)
+	c.Assert(units, HasLen, 3)
+}
+
+func (s *bundleSuite) TestDeployBundleCharmMismatch(c *C) {
+	restore, _, _ := s.addBundleCharms(c)
+	defer restore()
+	_, err := s.APIState.Client().DeployBundle(`
+services:
+  wordpress:
+    charm: cs:precise/mysql
+`)
+	c.Assert(err, IsNil)
+	_, err = s.APIState.Client().DeployBundle(testBundle)
+	c.Assert(err, ErrorMatches, `cannot deploy service "wordpress": service uses charm "cs:precise/mysql", not "cs:precise/wordpress"`)
+}
+
+func (s *bundleSuite) TestDeployBundleErrors(c *C) {
+	_, err := s.APIState.Client().DeployBundle("services: {}")
+	c.Assert(err, ErrorMatches, "bundle has no services")
+	_, err = s.APIState.Client().DeployBundle("services: {wordpress: {charm: 'local:precise/wordpress'}}")
+	c.Assert(err, ErrorMatches, `service "wordpress": charm url has unsupported schema "local"`)
+}
+
+func (s *bundleSuite) TestExportBundle(c *C) {
+	s.setUpScenario(c)
+	data, err := s.APIState.Client().ExportBundle()
+	c.Assert(err, IsNil)
+	b, err := bundle.Parse([]byte(data))
+	c.Assert(err, IsNil)
+	c.Assert(b, DeepEquals, &bundle.Bundle{
+		Services: map[string]*bundle.Service{
+			"logging": {
+				Charm: "local:series/logging-1",
+			},
+			"mysql": {
+				Charm: "local:series/mysql-1",
+			},
+			"wordpress": {
+				Charm:    "local:series/wordpress-3",
+				NumUnits: 2,
+			},
+		},
+		Relations: [][]string{
+			{"logging:logging-directory", "wordpress:logging-dir"},
+		},
+	})
+}
+
+func (s *bundleSuite) TestExportDeployRoundTrip(c *C) {
+	restore, _, _ := s.addBundleCharms(c)
+	defer restore()
+	_, err := s.APIState.Client().DeployBundle(testBundle)
+	c.Assert(err, IsNil)
+	data, err := s.APIState.Client().ExportBundle()
+	c.Assert(err, IsNil)
+
+	changes, err := s.APIState.Client().DeployBundle(data)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+
+	// Remove a unit, and check that deploying the export restores it.
+	wordpress, err := s.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	units, err := wordpress.AllUnits()
+	c.Assert(err, IsNil)
+	err = units[0].Destroy()
+	c.Assert(err, IsNil)
+	changes, err = s.APIState.Client().DeployBundle(data)
+	c.Assert(err, IsNil)
+	c.Assert(changes, DeepEquals, []string{`added 1 units to service "wordpress"`})
+}
@@ -19,6 +19,7 @@ type API struct {
 	auth      common.Authorizer
 	resources *common.Resources
 	client    *Client
+	dataDir   string
 }
 
 // Client serves client-specific API methods.
@@ -26,12 +27,14 @@ type Client struct {
 	api *API
 }
 
-// NewAPI creates a new instance of the Client API.
-func NewAPI(st *state.State, resources *common.Resources, authorizer common.Authorizer) *API {
+// NewAPI creates a new instance of the Client API. The dataDir
+// is the data directory of the state server running it.
+func NewAPI(st *state.State, resources *common.Resources, authorizer common.Authorizer, dataDir string) *API {
 	r := &API{
 		state:     st,
 		auth:      authorizer,
 		resources: resources,
+		dataDir:   dataDir,
 	}
 	r.client = &Client{
 		api: r,
@@ -0,0 +1,136 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"launchpad.net/loggo"
+
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/utils/tailer"
+)
+
+// DebugLogPath holds the path of the consolidated log of the
+// environment, which rsyslog accumulates on the state server.
+var DebugLogPath = "/var/log/juju/all-machines.log"
+
+// WatchDebugLog starts following the consolidated log of the
+// environment, and returns the id of a DebugLogWatcher that reports
+// the lines matching args.
+func (c *Client) WatchDebugLog(args params.DebugLog) (params.DebugLogWatcherId, error) {
+	filter, err := newDebugLogFilter(args)
+	if err != nil {
+		return params.DebugLogWatcherId{}, err
+	}
+	f, err := os.Open(DebugLogPath)
+	if err != nil {
+		return params.DebugLogWatcherId{}, fmt.Errorf("cannot open debug log: %v", err)
+	}
+	backlog := int(args.Backlog)
+	if args.Replay {
+		backlog = -1
+	}
+	t := tailer.NewTailer(f, backlog, filter.match)
+	go func() {
+		t.Wait()
+		f.Close()
+	}()
+	return params.DebugLogWatcherId{
+		DebugLogWatcherId: c.api.resources.Register(t),
+	}, nil
+}
+
+// debugLogFilter selects lines of the consolidated log, which look like
+//
+//	machine-1: 2013-10-01 12:00:00 INFO juju.worker file.go:12 message
+type debugLogFilter struct {
+	includeEntity []string
+	excludeEntity []string
+	includeModule []string
+	excludeModule []string
+	level         loggo.Level
+}
+
+func newDebugLogFilter(args params.DebugLog) (*debugLogFilter, error) {
+	f := &debugLogFilter{
+		includeEntity: args.IncludeEntity,
+		excludeEntity: args.ExcludeEntity,
+		includeModule: args.IncludeModule,
+		excludeModule: args.ExcludeModule,
+	}
+	for _, pattern := range append(args.IncludeEntity, args.ExcludeEntity...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid entity pattern %q", pattern)
+		}
+	}
+	if args.Level != "" {
+		level, ok := loggo.ParseLevel(args.Level)
+		if !ok || level == loggo.UNSPECIFIED {
+			return nil, fmt.Errorf("unknown log level %q", args.Level)
+		}
+		f.level = level
+	}
+	return f, nil
+}
+
+// parseDebugLogLine returns the tag of the agent that logged the line,
+// the level of the message and its module. Lines not written by
+// loggo have an unspecified level and no module.
+func parseDebugLogLine(line string) (entity string, level loggo.Level, module string) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return "", loggo.UNSPECIFIED, ""
+	}
+	entity = line[:i]
+	fields := strings.Fields(line[i+1:])
+	if len(fields) < 4 {
+		return entity, loggo.UNSPECIFIED, ""
+	}
+	level, ok := loggo.ParseLevel(fields[2])
+	if !ok {
+		return entity, loggo.UNSPECIFIED, ""
+	}
+	return entity, level, fields[3]
+}
+
+func (f *debugLogFilter) match(line []byte) bool {
+	entity, level, module := parseDebugLogLine(string(line))
+	if len(f.includeEntity) > 0 && !matchEntity(f.includeEntity, entity) {
+		return false
+	}
+	if matchEntity(f.excludeEntity, entity) {
+		return false
+	}
+	if len(f.includeModule) > 0 && !matchModule(f.includeModule, module) {
+		return false
+	}
+	if matchModule(f.excludeModule, module) {
+		return false
+	}
+	return level >= f.level
+}
+
+func matchEntity(patterns []string, entity string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, entity); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchModule returns whether module is one of modules or a
+// submodule of one of them.
+func matchModule(modules []string, module string) bool {
+	for _, m := range modules {
+		if module == m || strings.HasPrefix(module, m+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/state/api"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/apiserver/client"
+	coretesting "launchpad.net/juju-core/testing"
+)
+
+type debugLogSuite struct {
+	baseSuite
+	oldPath string
+}
+
+var _ = Suite(&debugLogSuite{})
+
+var debugLogLines = []string{
+	"machine-0: 2013-10-01 12:00:00 INFO juju.cmd.jujud supercommand.go:12 machine agent starting",
+	"machine-1: 2013-10-01 12:00:01 DEBUG juju.worker.machiner machiner.go:20 machine 1 is alive",
+	"unit-mysql-0: 2013-10-01 12:00:02 WARNING juju.worker.uniter uniter.go:30 hook failed",
+	"unit-mysql-slave-0: 2013-10-01 12:00:03 INFO juju.worker.uniter uniter.go:40 hook ran",
+	"unit-mysql-1: 2013-10-01 12:00:04 ERROR juju.charm.hook hook.go:50 cannot run hook",
+	"unit-mysql-1: not a loggo message",
+}
+
+func (s *debugLogSuite) SetUpTest(c *C) {
+	s.baseSuite.SetUpTest(c)
+	s.oldPath = client.DebugLogPath
+	client.DebugLogPath = filepath.Join(c.MkDir(), "all-machines.log")
+	data := ""
+	for _, line := range debugLogLines {
+		data += line + "\n"
+	}
+	err := ioutil.WriteFile(client.DebugLogPath, []byte(data), 0644)
+	c.Assert(err, IsNil)
+}
+
+func (s *debugLogSuite) TearDownTest(c *C) {
+	client.DebugLogPath = s.oldPath
+	s.baseSuite.TearDownTest(c)
+}
+
+// nextLines reads from w until it has received n lines.
+func nextLines(c *C, w *api.DebugLogWatcher, n int) []string {
+	var lines []string
+	done := make(chan error)
+	go func() {
+		for len(lines) < n {
+			next, err := w.Next()
+			if err != nil {
+				done <- err
+				return
+			}
+			lines = append(lines, next...)
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		c.Assert(err, IsNil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for debug log lines")
+	}
+	c.Assert(lines, HasLen, n)
+	return lines
+}
+
+func (s *debugLogSuite) TestWatchDebugLogFilters(c *C) {
+	for i, test := range []struct {
+		about    string
+		args     params.DebugLog
+		expected []int
+	}{{
+		about:    "backlog",
+		args:     params.DebugLog{Backlog: 2},
+		expected: []int{4, 5},
+	}, {
+		about:    "replay",
+		args:     params.DebugLog{Replay: true, Backlog: 2},
+		expected: []int{0, 1, 2, 3, 4, 5},
+	}, {
+		about:    "include entity",
+		args:     params.DebugLog{Replay: true, IncludeEntity: []string{"unit-mysql-[0-9]*", "machine-1"}},
+		expected: []int{1, 2, 4, 5},
+	}, {
+		about:    "exclude entity",
+		args:     params.DebugLog{Replay: true, ExcludeEntity: []string{"unit-*"}},
+		expected: []int{0, 1},
+	}, {
+		about:    "include module",
+		args:     params.DebugLog{Replay: true, IncludeModule: []string{"juju.worker"}},
+		expected: []int{1, 2, 3},
+	}, {
+		about:    "exclude module",
+		args:     params.DebugLog{Replay: true, ExcludeModule: []string{"juju.worker.uniter", "juju.cmd"}},
+		expected: []int{1, 4, 5},
+	}, {
+		about:    "level",
+		args:     params.DebugLog{Replay: true, Level: "WARNING"},
+		expected: []int{2, 4},
+	}, {
+		about:    "backlog counts matching lines",
+		args:     params.DebugLog{Backlog: 2, Level: "INFO"},
+		expected: []int{3, 4},
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		w, err := s.APIState.Client().WatchDebugLog(test.args)
+		c.Assert(err, IsNil)
+		var expected []string
+		for _, j := range test.expected {
+			expected = append(expected, debugLogLines[j])
+		}
+		c.Check(nextLines(c, w, len(expected)), DeepEquals, expected)
+		c.Check(w.Stop(), IsNil)
+	}
+}
+
+func (s *debugLogSuite) TestWatchDebugLogFollows(c *C) {
+	w, err := s.APIState.Client().WatchDebugLog(params.DebugLog{
+		IncludeEntity: []string{"machine-2"},
+	})
+	c.Assert(err, IsNil)
+	defer w.Stop()
+
+	// Give the tailer time to find the end of the log.
+	time.Sleep(coretesting.ShortWait)
+	line := "machine-2: 2013-10-01 12:01:00 INFO juju.worker.machiner machiner.go:20 machine 2 is alive"
+	data := "unit-mysql-0: 2013-10-01 12:01:00 INFO juju.worker.uniter uniter.go:30 ignored\n" + line + "\n"
+	err = ioutil.WriteFile(client.DebugLogPath, append(mustReadFile(c, client.DebugLogPath), data...), 0644)
+	c.Assert(err, IsNil)
+	c.Assert(nextLines(c, w, 1), DeepEquals, []string{line})
+}
+
+func (s *debugLogSuite) TestWatchDebugLogErrors(c *C) {
+	_, err := s.APIState.Client().WatchDebugLog(params.DebugLog{Level: "foo"})
+	c.Assert(err, ErrorMatches, `unknown log level "foo"`)
+
+	_, err = s.APIState.Client().WatchDebugLog(params.DebugLog{IncludeEntity: []string{"unit-["}})
+	c.Assert(err, ErrorMatches, `invalid entity pattern "unit-\["`)
+
+	client.DebugLogPath = filepath.Join(c.MkDir(), "missing.log")
+	_, err = s.APIState.Client().WatchDebugLog(params.DebugLog{})
+	c.Assert(err, ErrorMatches, "cannot open debug log: .*")
+}
+
+func mustReadFile(c *C, path string) []byte {
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	return data
+}
@@ -4,12 +4,18 @@
 package client_test
 
 import (
+	"io/ioutil"
 	. "launchpad.net/gocheck"
 	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/environs"
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/api"
 	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/apiserver/client"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
 type permSuite struct {
@@ -112,6 +118,42 @@ var operationPermTests = []struct {
 	about: "Client.DestroyRelation",
 	op:    opClientDestroyRelation,
 	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.WatchDebugLog",
+	op:    opClientWatchDebugLog,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.Run",
+	op:    opClientRun,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.RunOnAllMachines",
+	op:    opClientRunOnAllMachines,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.DeployBundle",
+	op:    opClientDeployBundle,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ExportBundle",
+	op:    opClientExportBundle,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.QueueAction",
+	op:    opClientQueueAction,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.ActionResults",
+	op:    opClientActionResults,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.Backup",
+	op:    opClientBackup,
+	allow: []string{"user-admin", "user-other"},
+}, {
+	about: "Client.Restore",
+	op:    opClientRestore,
+	allow: []string{"user-admin", "user-other"},
 }}
 
 // allowed returns the set of allowed entities given an allow list and a
@@ -185,6 +227,94 @@ func opClientDestroyRelation(c *C, st *api.State, mst *state.State) (func(), err
 	return func() {}, err
 }
 
+func opClientWatchDebugLog(c *C, st *api.State, mst *state.State) (func(), error) {
+	oldPath := client.DebugLogPath
+	client.DebugLogPath = filepath.Join(c.MkDir(), "all-machines.log")
+	err := ioutil.WriteFile(client.DebugLogPath, nil, 0644)
+	c.Assert(err, IsNil)
+	reset := func() {
+		client.DebugLogPath = oldPath
+	}
+	w, err := st.Client().WatchDebugLog(params.DebugLog{})
+	if err != nil {
+		return reset, err
+	}
+	c.Assert(w.Stop(), IsNil)
+	return reset, nil
+}
+
+func opClientRun(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().Run(params.RunParams{
+		Commands: "hostname",
+		Units:    []string{"nosuch/0"},
+	})
+	if params.ErrCode(err) == params.CodeNotFound {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientRunOnAllMachines(c *C, st *api.State, mst *state.State) (func(), error) {
+	// The scenario instances are not known to the environment, so no
+	// commands are actually run.
+	_, err := st.Client().RunOnAllMachines("hostname", time.Minute)
+	return func() {}, err
+}
+
+func opClientDeployBundle(c *C, st *api.State, mst *state.State) (func(), error) {
+	// The bundle is rejected once the caller is authorized,
+	// so nothing is deployed.
+	_, err := st.Client().DeployBundle("services: {}")
+	if err != nil && err.Error() == "bundle has no services" {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientExportBundle(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().ExportBundle()
+	return func() {}, err
+}
+
+func opClientQueueAction(c *C, st *api.State, mst *state.State) (func(), error) {
+	// The wordpress charm defines no actions, so nothing is queued
+	// once the caller is authorized.
+	_, err := st.Client().QueueAction("wordpress/0", "backup", "")
+	if err != nil && strings.Contains(err.Error(), "action not defined by charm") {
+		err = nil
+	}
+	return func() {}, err
+}
+
+func opClientActionResults(c *C, st *api.State, mst *state.State) (func(), error) {
+	_, err := st.Client().ActionResults([]string{"wordpress/0#a#1"})
+	return func() {}, err
+}
+
+func opClientBackup(c *C, st *api.State, mst *state.State) (func(), error) {
+	// The API server's data directory is environs.DataDir.
+	agentDir := filepath.Join(environs.DataDir, "agents", "machine-0")
+	err := os.MkdirAll(agentDir, 0755)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(filepath.Join(agentDir, "agent.conf"), []byte("conf"), 0600)
+	c.Assert(err, IsNil)
+	reset := func() {
+		os.RemoveAll(filepath.Join(environs.DataDir, "agents"))
+	}
+	_, err = st.Client().Backup()
+	return reset, err
+}
+
+func opClientRestore(c *C, st *api.State, mst *state.State) (func(), error) {
+	// The archive is rejected once the caller is authorized,
+	// so nothing is restored.
+	err := st.Client().Restore([]byte("bad"))
+	if err != nil && strings.HasPrefix(err.Error(), "cannot read backup") {
+		err = nil
+	}
+	return func() {}, err
+}
+
 func opClientStatus(c *C, st *api.State, mst *state.State) (func(), error) {
 	status, err := st.Client().Status()
 	if err != nil {
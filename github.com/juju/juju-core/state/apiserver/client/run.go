@@ -0,0 +1,235 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"launchpad.net/juju-core/environs"
+	"launchpad.net/juju-core/environs/cloudinit"
+	"launchpad.net/juju-core/instance"
+	"launchpad.net/juju-core/juju"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/utils"
+	"launchpad.net/juju-core/utils/exec"
+)
+
+// DefaultRunTimeout is used when a Run request does not specify a timeout.
+const DefaultRunTimeout = 5 * time.Minute
+
+// knownHostsFile is the name of the file, in the data directory of the
+// state server, holding the host keys of the machines it connects to.
+const knownHostsFile = "ssh_known_hosts"
+
+// RemoteExec runs command with bash, as root, on the machine with the given
+// instance id and address. It connects as the state server, whose data
+// directory is dataDir. It is a variable so that it can be replaced in tests.
+var RemoteExec = sshExec
+
+func sshExec(dataDir string, instId instance.Id, host, command string, timeout time.Duration) (*exec.ExecResponse, error) {
+	knownHosts := filepath.Join(dataDir, knownHostsFile)
+	// Host keys are recorded by instance id, as addresses can be reused.
+	alias := string(instId)
+	if err := addKnownHost(knownHosts, alias, host); err != nil {
+		return nil, err
+	}
+	ps := osexec.Command(
+		"ssh",
+		"-i", filepath.Join(dataDir, cloudinit.SystemIdentity),
+		"-o", "UserKnownHostsFile "+knownHosts,
+		"-o", "HostKeyAlias "+alias,
+		"-o", "StrictHostKeyChecking yes",
+		"-o", "PasswordAuthentication no",
+		"ubuntu@"+host,
+		"sudo -n bash -s",
+	)
+	ps.Stdin = strings.NewReader(command)
+	return exec.Run(ps, timeout)
+}
+
+// knownHostsMutex serialises the updates of the known hosts files.
+var knownHostsMutex sync.Mutex
+
+// addKnownHost records the host key of the machine at the given address
+// under alias in the knownHosts file, unless a key is already recorded for
+// alias. The first key seen for a machine is trusted, and ssh refuses to
+// connect if it later changes.
+func addKnownHost(knownHosts, alias, host string) error {
+	knownHostsMutex.Lock()
+	defer knownHostsMutex.Unlock()
+	data, err := ioutil.ReadFile(knownHosts)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, alias+" ") {
+			return nil
+		}
+	}
+	out, err := osexec.Command("ssh-keyscan", "-t", "rsa", host).Output()
+	if err != nil {
+		return fmt.Errorf("cannot get the host key of %s: %v", host, err)
+	}
+	var keys []string
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		fields[0] = alias
+		keys = append(keys, strings.Join(fields, " ")+"\n")
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("cannot get the host key of %s", host)
+	}
+	f, err := os.OpenFile(knownHosts, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(strings.Join(keys, ""))
+	return err
+}
+
+// runTarget holds the command to run on a single machine, and identifies
+// where the result came from.
+type runTarget struct {
+	machine *state.Machine
+	unitId  string
+	command string
+}
+
+// Run runs the commands specified on the machines, services and units
+// identified in run. Commands for units are run in the units' hook
+// context; commands for machines are run without one.
+func (c *Client) Run(run params.RunParams) (results params.RunResults, err error) {
+	st := c.api.state
+	quoted := utils.ShQuote(run.Commands)
+	var targets []runTarget
+	for _, machineId := range run.Machines {
+		if !state.IsMachineId(machineId) {
+			return results, fmt.Errorf("invalid machine id %q", machineId)
+		}
+		machine, err := st.Machine(machineId)
+		if err != nil {
+			return results, err
+		}
+		targets = append(targets, runTarget{
+			machine: machine,
+			command: "juju-run --no-context " + quoted,
+		})
+	}
+	unitNames := append([]string(nil), run.Units...)
+	for _, serviceName := range run.Services {
+		service, err := st.Service(serviceName)
+		if err != nil {
+			return results, err
+		}
+		units, err := service.AllUnits()
+		if err != nil {
+			return results, err
+		}
+		for _, unit := range units {
+			unitNames = append(unitNames, unit.Name())
+		}
+	}
+	seen := make(map[string]bool)
+	for _, unitName := range unitNames {
+		if seen[unitName] {
+			continue
+		}
+		seen[unitName] = true
+		unit, err := st.Unit(unitName)
+		if err != nil {
+			return results, err
+		}
+		machineId, err := unit.AssignedMachineId()
+		if err != nil {
+			return results, err
+		}
+		machine, err := st.Machine(machineId)
+		if err != nil {
+			return results, err
+		}
+		targets = append(targets, runTarget{
+			machine: machine,
+			unitId:  unitName,
+			command: fmt.Sprintf("juju-run %s %s", unitName, quoted),
+		})
+	}
+	return c.runOnTargets(targets, run.Timeout)
+}
+
+// RunOnAllMachines runs the commands on every machine in the environment,
+// without a hook context.
+func (c *Client) RunOnAllMachines(run params.RunParams) (params.RunResults, error) {
+	machines, err := c.api.state.AllMachines()
+	if err != nil {
+		return params.RunResults{}, err
+	}
+	quoted := utils.ShQuote(run.Commands)
+	targets := make([]runTarget, len(machines))
+	for i, machine := range machines {
+		targets[i] = runTarget{
+			machine: machine,
+			command: "juju-run --no-context " + quoted,
+		}
+	}
+	return c.runOnTargets(targets, run.Timeout)
+}
+
+// runOnTargets runs the commands on all targets in parallel and collects
+// the results. Failures to run a command are recorded in the result for
+// that target rather than failing the whole request.
+func (c *Client) runOnTargets(targets []runTarget, timeout time.Duration) (params.RunResults, error) {
+	if timeout <= 0 {
+		timeout = DefaultRunTimeout
+	}
+	conn, err := juju.NewConnFromState(c.api.state)
+	if err != nil {
+		return params.RunResults{}, err
+	}
+	results := make([]params.RunResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		results[i].MachineId = target.machine.Id()
+		results[i].UnitId = target.unitId
+		wg.Add(1)
+		go func(target runTarget, result *params.RunResult) {
+			defer wg.Done()
+			response, err := runOnMachine(conn.Environ, c.api.dataDir, target, timeout)
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			result.ExecResponse = *response
+		}(target, &results[i])
+	}
+	wg.Wait()
+	return params.RunResults{Results: results}, nil
+}
+
+func runOnMachine(environ environs.Environ, dataDir string, target runTarget, timeout time.Duration) (*exec.ExecResponse, error) {
+	instId, err := target.machine.InstanceId()
+	if err != nil {
+		return nil, err
+	}
+	insts, err := environ.Instances([]instance.Id{instId})
+	if err != nil {
+		return nil, err
+	}
+	host, err := insts[0].DNSName()
+	if err != nil {
+		return nil, err
+	}
+	return RemoteExec(dataDir, instId, host, target.command, timeout)
+}
@@ -0,0 +1,137 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package client_test
+
+import (
+	"sync"
+	"time"
+
+	. "launchpad.net/gocheck"
+	"launchpad.net/juju-core/instance"
+	jujutesting "launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/state/api/params"
+	"launchpad.net/juju-core/state/apiserver/client"
+	"launchpad.net/juju-core/utils/exec"
+)
+
+type runSuite struct {
+	baseSuite
+	mu       sync.Mutex
+	commands map[string]string
+	dataDir  string
+	restore  func()
+}
+
+var _ = Suite(&runSuite{})
+
+func (s *runSuite) SetUpTest(c *C) {
+	s.baseSuite.SetUpTest(c)
+	s.commands = make(map[string]string)
+	oldRemoteExec := client.RemoteExec
+	client.RemoteExec = func(dataDir string, instId instance.Id, host, command string, timeout time.Duration) (*exec.ExecResponse, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.dataDir = dataDir
+		s.commands[host] = command
+		return &exec.ExecResponse{Stdout: []byte(host), Code: 1}, nil
+	}
+	s.restore = func() { client.RemoteExec = oldRemoteExec }
+}
+
+func (s *runSuite) TearDownTest(c *C) {
+	s.restore()
+	s.baseSuite.TearDownTest(c)
+}
+
+// addMachine adds a machine with a running dummy instance, and returns
+// it with the address the instance can be reached at.
+func (s *runSuite) addMachine(c *C) (*state.Machine, string) {
+	m, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	inst, _ := jujutesting.StartInstance(c, s.Conn.Environ, m.Id())
+	err = m.SetProvisioned(inst.Id(), "fake_nonce", nil)
+	c.Assert(err, IsNil)
+	host, err := inst.DNSName()
+	c.Assert(err, IsNil)
+	return m, host
+}
+
+func (s *runSuite) TestRunOnAllMachines(c *C) {
+	m0, host0 := s.addMachine(c)
+	m1, host1 := s.addMachine(c)
+
+	results, err := s.APIState.Client().RunOnAllMachines("echo 'hi'", time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(results, DeepEquals, []params.RunResult{{
+		ExecResponse: exec.ExecResponse{Stdout: []byte(host0), Code: 1},
+		MachineId:    m0.Id(),
+	}, {
+		ExecResponse: exec.ExecResponse{Stdout: []byte(host1), Code: 1},
+		MachineId:    m1.Id(),
+	}})
+	c.Assert(s.commands, DeepEquals, map[string]string{
+		host0: `juju-run --no-context 'echo '"'"'hi'"'"''`,
+		host1: `juju-run --no-context 'echo '"'"'hi'"'"''`,
+	})
+	c.Assert(s.dataDir, Equals, s.DataDir())
+}
+
+func (s *runSuite) TestRunMachinesAndUnits(c *C) {
+	m0, host0 := s.addMachine(c)
+	m1, host1 := s.addMachine(c)
+	wordpress, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	u, err := wordpress.AddUnit()
+	c.Assert(err, IsNil)
+	err = u.AssignToMachine(m1)
+	c.Assert(err, IsNil)
+
+	results, err := s.APIState.Client().Run(params.RunParams{
+		Commands: "hostname",
+		Machines: []string{m0.Id()},
+		Services: []string{"wordpress"},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(results, DeepEquals, []params.RunResult{{
+		ExecResponse: exec.ExecResponse{Stdout: []byte(host0), Code: 1},
+		MachineId:    m0.Id(),
+	}, {
+		ExecResponse: exec.ExecResponse{Stdout: []byte(host1), Code: 1},
+		MachineId:    m1.Id(),
+		UnitId:       "wordpress/0",
+	}})
+	c.Assert(s.commands, DeepEquals, map[string]string{
+		host0: "juju-run --no-context 'hostname'",
+		host1: "juju-run wordpress/0 'hostname'",
+	})
+}
+
+func (s *runSuite) TestRunUnprovisionedMachine(c *C) {
+	m, err := s.State.AddMachine("series", state.JobHostUnits)
+	c.Assert(err, IsNil)
+	results, err := s.APIState.Client().Run(params.RunParams{
+		Commands: "hostname",
+		Machines: []string{m.Id()},
+	})
+	c.Assert(err, IsNil)
+	c.Assert(results, HasLen, 1)
+	c.Assert(results[0].MachineId, Equals, m.Id())
+	c.Assert(results[0].Error, Matches, ".*not provisioned")
+	c.Assert(s.commands, HasLen, 0)
+}
+
+func (s *runSuite) TestRunInvalidTargets(c *C) {
+	_, err := s.APIState.Client().Run(params.RunParams{
+		Commands: "hostname",
+		Machines: []string{"foo"},
+	})
+	c.Assert(err, ErrorMatches, `invalid machine id "foo"`)
+
+	_, err = s.APIState.Client().Run(params.RunParams{
+		Commands: "hostname",
+		Units:    []string{"nosuch/0"},
+	})
+	c.Assert(err, ErrorMatches, `unit "nosuch/0" not found`)
+}
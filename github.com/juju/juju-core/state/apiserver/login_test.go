@@ -48,6 +48,7 @@ func (s *loginSuite) TestBadLogin(c *C) {
 		"localhost:0",
 		[]byte(coretesting.ServerCert),
 		[]byte(coretesting.ServerKey),
+		c.MkDir(),
 	)
 	c.Assert(err, IsNil)
 	defer func() {
@@ -11,6 +11,7 @@ import (
 	"launchpad.net/juju-core/state/apiserver/machine"
 	"launchpad.net/juju-core/state/apiserver/upgrader"
 	"launchpad.net/juju-core/state/multiwatcher"
+	"launchpad.net/juju-core/utils/tailer"
 )
 
 type clientAPI struct{ *client.API }
@@ -31,7 +32,7 @@ func newSrvRoot(srv *Server, entity state.TaggedAuthenticator) *srvRoot {
 		resources: common.NewResources(),
 		entity:    entity,
 	}
-	r.clientAPI.API = client.NewAPI(srv.state, r.resources, r)
+	r.clientAPI.API = client.NewAPI(srv.state, r.resources, r, srv.dataDir)
 	return r
 }
 
@@ -158,6 +159,24 @@ func (r *srvRoot) AllWatcher(id string) (*srvClientAllWatcher, error) {
 	}, nil
 }
 
+// DebugLogWatcher returns an object that provides API access to methods
+// on a tailer.Tailer following the consolidated log of the environment.
+// Each client has its own current set of watchers, stored in r.resources.
+func (r *srvRoot) DebugLogWatcher(id string) (*srvDebugLogWatcher, error) {
+	if err := r.requireClient(); err != nil {
+		return nil, err
+	}
+	watcher, ok := r.resources.Get(id).(*tailer.Tailer)
+	if !ok {
+		return nil, common.ErrUnknownWatcher
+	}
+	return &srvDebugLogWatcher{
+		watcher:   watcher,
+		id:        id,
+		resources: r.resources,
+	}, nil
+}
+
 // Pinger returns object with a single "Ping" method that does nothing.
 func (r *srvRoot) Pinger(id string) (srvPinger, error) {
 	return srvPinger{}, nil
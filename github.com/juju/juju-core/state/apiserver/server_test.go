@@ -32,7 +32,7 @@ var _ = Suite(&serverSuite{})
 func (s *serverSuite) TestStop(c *C) {
 	// Start our own instance of the server so we have
 	// a handle on it to stop it.
-	srv, err := apiserver.NewServer(s.State, "localhost:0", []byte(coretesting.ServerCert), []byte(coretesting.ServerKey))
+	srv, err := apiserver.NewServer(s.State, "localhost:0", []byte(coretesting.ServerCert), []byte(coretesting.ServerKey), c.MkDir())
 	c.Assert(err, IsNil)
 	defer srv.Stop()
 
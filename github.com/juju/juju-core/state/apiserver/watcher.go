@@ -8,6 +8,7 @@ import (
 	"launchpad.net/juju-core/state/api/params"
 	"launchpad.net/juju-core/state/apiserver/common"
 	"launchpad.net/juju-core/state/multiwatcher"
+	"launchpad.net/juju-core/utils/tailer"
 )
 
 type srvClientAllWatcher struct {
@@ -27,6 +28,32 @@ func (w *srvClientAllWatcher) Stop() error {
 	return w.resources.Stop(w.id)
 }
 
+type srvDebugLogWatcher struct {
+	watcher   *tailer.Tailer
+	id        string
+	resources *common.Resources
+}
+
+// Next returns the lines of the debug log that have been read since
+// the most recent call to Next, blocking until there are some.
+func (w *srvDebugLogWatcher) Next() (params.DebugLogNextResults, error) {
+	if lines, ok := <-w.watcher.Changes(); ok {
+		return params.DebugLogNextResults{
+			Lines: lines,
+		}, nil
+	}
+	err := w.watcher.Err()
+	if err == nil {
+		err = common.ErrStoppedWatcher
+	}
+	return params.DebugLogNextResults{}, err
+}
+
+// Stop stops the watcher.
+func (w *srvDebugLogWatcher) Stop() error {
+	return w.resources.Stop(w.id)
+}
+
 type srvNotifyWatcher struct {
 	watcher   state.NotifyWatcher
 	id        string
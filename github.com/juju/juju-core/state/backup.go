@@ -0,0 +1,210 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"labix.org/v2/mgo/bson"
+	"labix.org/v2/mgo/txn"
+
+	"launchpad.net/juju-core/utils"
+)
+
+// usersCollection holds the MongoDB users of the juju database.
+const usersCollection = "system.users"
+
+// dumpedCollection reports whether the named collection of the juju
+// database is included in a dump. The transaction log is only useful
+// to watchers of the running database, and indexes are created when
+// the state is opened.
+func dumpedCollection(name string) bool {
+	return name != "txns.log" && name != "system.indexes"
+}
+
+// DumpDatabase returns the contents of the juju database, keyed by
+// collection name. The documents of each collection are concatenated
+// in BSON format, as written by mongodump.
+func (st *State) DumpDatabase() (map[string][]byte, error) {
+	names, err := st.db.CollectionNames()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get collection names: %v", err)
+	}
+	dump := make(map[string][]byte)
+	for _, name := range names {
+		if !dumpedCollection(name) {
+			continue
+		}
+		var data []byte
+		var doc bson.Raw
+		iter := st.db.C(name).Find(nil).Iter()
+		for iter.Next(&doc) {
+			data = append(data, doc.Data...)
+		}
+		if err := iter.Close(); err != nil {
+			return nil, fmt.Errorf("cannot dump collection %q: %v", name, err)
+		}
+		dump[name] = data
+	}
+	return dump, nil
+}
+
+// splitDocuments splits concatenated BSON documents.
+func splitDocuments(data []byte) ([]bson.Raw, error) {
+	var docs []bson.Raw
+	for len(data) > 0 {
+		if len(data) < 5 {
+			return nil, fmt.Errorf("truncated document")
+		}
+		size := int(binary.LittleEndian.Uint32(data))
+		if size < 5 || size > len(data) {
+			return nil, fmt.Errorf("bad document size %d", size)
+		}
+		docs = append(docs, bson.Raw{Kind: 0x03, Data: data[:size]})
+		data = data[size:]
+	}
+	return docs, nil
+}
+
+// restoreBatchSize holds the maximum number of documents inserted
+// at once when restoring a collection.
+const restoreBatchSize = 1000
+
+// RestoreDatabase replaces the contents of the juju database with
+// the collections in dump, as returned by DumpDatabase. The running
+// state servers keep their identity: the instance ids, nonces and
+// passwords of the machines with JobManageState are carried over
+// into the restored database, as are their database users. Those
+// machines must also exist in the dump.
+func (st *State) RestoreDatabase(dump map[string][]byte) (err error) {
+	defer utils.ErrorContextf(&err, "cannot restore database")
+	var servers []machineDoc
+	if err := st.machines.Find(D{{"jobs", JobManageState}}).All(&servers); err != nil {
+		return fmt.Errorf("cannot get state server machines: %v", err)
+	}
+	instances := make([]instanceData, len(servers))
+	keepUsers := make([]string, len(servers))
+	for i, m := range servers {
+		if err := st.instanceData.FindId(m.Id).One(&instances[i]); err != nil {
+			return fmt.Errorf("cannot get instance data for machine %q: %v", m.Id, err)
+		}
+		keepUsers[i] = MachineTag(m.Id)
+	}
+
+	// Validate the whole dump before anything is removed.
+	collections := make(map[string][]bson.Raw)
+	for name, data := range dump {
+		if !dumpedCollection(name) {
+			continue
+		}
+		docs, err := splitDocuments(data)
+		if err != nil {
+			return fmt.Errorf("collection %q: %v", name, err)
+		}
+		collections[name] = docs
+	}
+	names, err := st.db.CollectionNames()
+	if err != nil {
+		return fmt.Errorf("cannot get collection names: %v", err)
+	}
+	for _, name := range names {
+		if _, ok := collections[name]; !ok && dumpedCollection(name) {
+			collections[name] = nil
+		}
+	}
+	names = names[:0]
+	for name := range collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := st.restoreCollection(name, collections[name], keepUsers); err != nil {
+			return err
+		}
+	}
+
+	var ops []txn.Op
+	for i, m := range servers {
+		inst := instances[i]
+		ops = append(ops, txn.Op{
+			C:      st.machines.Name,
+			Id:     m.Id,
+			Assert: txn.DocExists,
+			Update: D{{"$set", D{
+				{"nonce", m.Nonce},
+				{"passwordhash", m.PasswordHash},
+				{"instanceid", m.InstanceId},
+			}}},
+		}, txn.Op{
+			C:      st.instanceData.Name,
+			Id:     m.Id,
+			Assert: txn.DocExists,
+			Update: D{{"$set", D{
+				{"instanceid", inst.InstanceId},
+				{"arch", inst.Arch},
+				{"mem", inst.Mem},
+				{"cpucores", inst.CpuCores},
+				{"cpupower", inst.CpuPower},
+			}}},
+		})
+	}
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		return fmt.Errorf("state server machines %v not found in backup", keepUsers)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// restoreCollection replaces the documents of the named collection
+// with docs. Database users named in keepUsers are left untouched.
+func (st *State) restoreCollection(name string, docs []bson.Raw, keepUsers []string) error {
+	coll := st.db.C(name)
+	var sel interface{}
+	if name == usersCollection {
+		sel = D{{"user", D{{"$nin", keepUsers}}}}
+		var kept []bson.Raw
+		for _, doc := range docs {
+			var user struct {
+				User string
+			}
+			if err := doc.Unmarshal(&user); err != nil {
+				return fmt.Errorf("collection %q: %v", name, err)
+			}
+			if !containsString(keepUsers, user.User) {
+				kept = append(kept, doc)
+			}
+		}
+		docs = kept
+	}
+	if _, err := coll.RemoveAll(sel); err != nil {
+		return fmt.Errorf("cannot clear collection %q: %v", name, err)
+	}
+	for len(docs) > 0 {
+		n := len(docs)
+		if n > restoreBatchSize {
+			n = restoreBatchSize
+		}
+		batch := make([]interface{}, n)
+		for i := range batch {
+			batch[i] = docs[i]
+		}
+		if err := coll.Insert(batch...); err != nil {
+			return fmt.Errorf("cannot restore collection %q: %v", name, err)
+		}
+		docs = docs[n:]
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
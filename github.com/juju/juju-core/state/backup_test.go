@@ -0,0 +1,97 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/errors"
+	"launchpad.net/juju-core/state"
+	"launchpad.net/juju-core/testing/checkers"
+)
+
+type BackupSuite struct {
+	ConnSuite
+	server *state.Machine
+}
+
+var _ = Suite(&BackupSuite{})
+
+func (s *BackupSuite) SetUpTest(c *C) {
+	s.ConnSuite.SetUpTest(c)
+	var err error
+	s.server, err = s.State.AddMachine("series", state.JobManageState)
+	c.Assert(err, IsNil)
+	err = s.server.SetProvisioned("i-server", "fake_nonce", nil)
+	c.Assert(err, IsNil)
+	err = s.server.SetPassword("old-password")
+	c.Assert(err, IsNil)
+	err = s.server.SetMongoPassword("old-password")
+	c.Assert(err, IsNil)
+}
+
+func (s *BackupSuite) TestDumpDatabase(c *C) {
+	dump, err := s.State.DumpDatabase()
+	c.Assert(err, IsNil)
+	c.Assert(dump["machines"], Not(HasLen), 0)
+	c.Assert(dump["system.users"], Not(HasLen), 0)
+	_, ok := dump["txns.log"]
+	c.Assert(ok, Equals, false)
+	_, ok = dump["system.indexes"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *BackupSuite) TestRestoreDatabase(c *C) {
+	_, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	dump, err := s.State.DumpDatabase()
+	c.Assert(err, IsNil)
+
+	// Change the environment after the backup, and give the state
+	// server new credentials as a fresh bootstrap would.
+	_, err = s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+	err = s.server.SetPassword("new-password")
+	c.Assert(err, IsNil)
+	err = s.server.SetMongoPassword("new-password")
+	c.Assert(err, IsNil)
+
+	err = s.State.RestoreDatabase(dump)
+	c.Assert(err, IsNil)
+
+	_, err = s.State.Service("wordpress")
+	c.Assert(err, IsNil)
+	_, err = s.State.Service("mysql")
+	c.Assert(err, checkers.Satisfies, errors.IsNotFoundError)
+	m, err := s.State.Machine(s.server.Id())
+	c.Assert(err, IsNil)
+	c.Assert(m.PasswordValid("new-password"), Equals, true)
+	c.Assert(m.CheckProvisioned("fake_nonce"), Equals, true)
+
+	info := state.TestingStateInfo()
+	info.Tag = m.Tag()
+	info.Password = "new-password"
+	st, err := state.Open(info, state.TestingDialOpts())
+	c.Assert(err, IsNil)
+	st.Close()
+
+	// The restored state remains usable.
+	_, err = s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+}
+
+func (s *BackupSuite) TestRestoreDatabaseBadDump(c *C) {
+	err := s.State.RestoreDatabase(map[string][]byte{"machines": []byte("bad")})
+	c.Assert(err, ErrorMatches, `cannot restore database: collection "machines": truncated document`)
+	_, err = s.State.Machine(s.server.Id())
+	c.Assert(err, IsNil)
+}
+
+func (s *BackupSuite) TestRestoreDatabaseMissingStateServer(c *C) {
+	dump, err := s.State.DumpDatabase()
+	c.Assert(err, IsNil)
+	delete(dump, "machines")
+	err = s.State.RestoreDatabase(dump)
+	c.Assert(err, ErrorMatches, `cannot restore database: state server machines \[machine-0\] not found in backup`)
+}
@@ -48,6 +48,14 @@ func (s *CharmSuite) TestCharm(c *C) {
 			Type:        "string",
 		},
 	)
+	actions := dummy.Actions()
+	c.Assert(actions.ActionSpecs["snapshot"].Params["outfile"], Equals,
+		charm.ActionParam{
+			Type:        "string",
+			Description: "The file to write out to.",
+			Default:     "foo.bz2",
+		},
+	)
 }
 
 func (s *CharmSuite) TestCharmNotFound(c *C) {
@@ -169,6 +169,7 @@ var indexes = []struct {
 	{"units", []string{"principal"}},
 	{"units", []string{"machineid"}},
 	{"users", []string{"name"}},
+	{"actions", []string{"unit"}},
 }
 
 // The capped collection used for transaction logs defaults to 10MB.
@@ -231,6 +232,7 @@ func newState(session *mgo.Session, info *Info) (*State, error) {
 		cleanups:       db.C("cleanups"),
 		annotations:    db.C("annotations"),
 		statuses:       db.C("statuses"),
+		actions:        db.C("actions"),
 	}
 	log := db.C("txns.log")
 	logInfo := mgo.CollectionInfo{Capped: true, MaxBytes: logSize}
@@ -221,6 +221,11 @@ func (r *Relation) Id() int {
 	return r.doc.Id
 }
 
+// Endpoints returns the endpoints of the relation.
+func (r *Relation) Endpoints() []Endpoint {
+	return append([]Endpoint(nil), r.doc.Endpoints...)
+}
+
 // Endpoint returns the endpoint of the relation for the named service.
 // If the service is not part of the relation, an error will be returned.
 func (r *Relation) Endpoint(serviceName string) (Endpoint, error) {
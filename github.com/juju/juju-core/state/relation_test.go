@@ -172,6 +172,25 @@ func (s *RelationSuite) TestAddRelationSeriesNeedNotMatch(c *C) {
 	assertOneRelation(c, wordpress, 0, wordpressEP, mysqlEP)
 }
 
+func (s *RelationSuite) TestRelationEndpoints(c *C) {
+	wordpress, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	wordpressEP, err := wordpress.Endpoint("db")
+	c.Assert(err, IsNil)
+	mysql, err := s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+	mysqlEP, err := mysql.Endpoint("server")
+	c.Assert(err, IsNil)
+	rel, err := s.State.AddRelation(wordpressEP, mysqlEP)
+	c.Assert(err, IsNil)
+	eps := rel.Endpoints()
+	c.Assert(eps, DeepEquals, []state.Endpoint{wordpressEP, mysqlEP})
+
+	// Changing the returned slice does not change the relation.
+	eps[0] = mysqlEP
+	c.Assert(rel.Endpoints(), DeepEquals, []state.Endpoint{wordpressEP, mysqlEP})
+}
+
 func (s *RelationSuite) TestAddContainerRelation(c *C) {
 	// Add a relation.
 	wordpress, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
@@ -657,6 +657,11 @@ func (s *Service) removeUnitOps(u *Unit, asserts D) ([]txn.Op, error) {
 		removeStatusOp(s.st, u.globalKey()),
 		annotationRemoveOp(s.st, u.globalKey()),
 	)
+	actionOps, err := removeActionsOps(s.st, u.doc.Name)
+	if err != nil {
+		return nil, err
+	}
+	ops = append(ops, actionOps...)
 	if u.doc.CharmURL != nil {
 		decOps, err := settingsDecRefOps(s.st, s.doc.Name, u.doc.CharmURL)
 		if errors.IsNotFoundError(err) {
@@ -94,6 +94,7 @@ type State struct {
 	cleanups         *mgo.Collection
 	annotations      *mgo.Collection
 	statuses         *mgo.Collection
+	actions          *mgo.Collection
 	runner           *txn.Runner
 	transactionHooks chan ([]transactionHook)
 	watcher          *watcher.Watcher
@@ -674,6 +675,7 @@ func (st *State) AddCharm(ch charm.Charm, curl *charm.URL, bundleURL *url.URL, b
 		URL:          curl,
 		Meta:         ch.Meta(),
 		Config:       ch.Config(),
+		Actions:      ch.Actions(),
 		BundleURL:    bundleURL,
 		BundleSha256: bundleSha256,
 	}
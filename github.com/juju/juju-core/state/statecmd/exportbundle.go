@@ -0,0 +1,90 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Code shared by the CLI and API for the ExportBundle function.
+
+package statecmd
+
+import (
+	"sort"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/state"
+)
+
+// ExportBundle returns a bundle describing the services of the
+// environment, their configuration and the relations between them.
+func ExportBundle(st *state.State) (*bundle.Bundle, error) {
+	services, err := st.AllServices()
+	if err != nil {
+		return nil, err
+	}
+	b := &bundle.Bundle{
+		Services: make(map[string]*bundle.Service),
+	}
+	seen := make(map[int]bool)
+	for _, svc := range services {
+		if svc.Life() != state.Alive {
+			continue
+		}
+		curl, _ := svc.CharmURL()
+		bsvc := &bundle.Service{
+			Charm:  curl.String(),
+			Expose: svc.IsExposed(),
+		}
+		settings, err := svc.ConfigSettings()
+		if err != nil {
+			return nil, err
+		}
+		if len(settings) > 0 {
+			bsvc.Options = settings
+		}
+		if svc.IsPrincipal() {
+			units, err := svc.AllUnits()
+			if err != nil {
+				return nil, err
+			}
+			for _, unit := range units {
+				if unit.Life() == state.Alive {
+					bsvc.NumUnits++
+				}
+			}
+			cons, err := svc.Constraints()
+			if err != nil {
+				return nil, err
+			}
+			bsvc.Constraints = cons.String()
+		}
+		b.Services[svc.Name()] = bsvc
+
+		relations, err := svc.Relations()
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range relations {
+			if seen[rel.Id()] || rel.Life() != state.Alive {
+				continue
+			}
+			seen[rel.Id()] = true
+			eps := rel.Endpoints()
+			// Peer relations are established implicitly.
+			if len(eps) != 2 {
+				continue
+			}
+			b.Relations = append(b.Relations, []string{eps[0].String(), eps[1].String()})
+		}
+	}
+	sort.Sort(relationSlice(b.Relations))
+	return b, nil
+}
+
+type relationSlice [][]string
+
+func (rs relationSlice) Len() int      { return len(rs) }
+func (rs relationSlice) Swap(i, j int) { rs[i], rs[j] = rs[j], rs[i] }
+func (rs relationSlice) Less(i, j int) bool {
+	if rs[i][0] != rs[j][0] {
+		return rs[i][0] < rs[j][0]
+	}
+	return rs[i][1] < rs[j][1]
+}
@@ -0,0 +1,81 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package statecmd_test
+
+import (
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/bundle"
+	"launchpad.net/juju-core/charm"
+	"launchpad.net/juju-core/constraints"
+	"launchpad.net/juju-core/juju/testing"
+	"launchpad.net/juju-core/state/statecmd"
+)
+
+type ExportBundleSuite struct {
+	testing.JujuConnSuite
+}
+
+var _ = Suite(&ExportBundleSuite{})
+
+func (s *ExportBundleSuite) TestExportBundle(c *C) {
+	wordpress, err := s.State.AddService("wordpress", s.AddTestingCharm(c, "wordpress"))
+	c.Assert(err, IsNil)
+	err = wordpress.UpdateConfigSettings(charm.Settings{"blog-title": "My blog"})
+	c.Assert(err, IsNil)
+	err = wordpress.SetConstraints(constraints.MustParse("mem=4G"))
+	c.Assert(err, IsNil)
+	err = wordpress.SetExposed()
+	c.Assert(err, IsNil)
+	for i := 0; i < 2; i++ {
+		_, err := wordpress.AddUnit()
+		c.Assert(err, IsNil)
+	}
+	mysql, err := s.State.AddService("mysql", s.AddTestingCharm(c, "mysql"))
+	c.Assert(err, IsNil)
+	_, err = mysql.AddUnit()
+	c.Assert(err, IsNil)
+	_, err = s.State.AddService("logging", s.AddTestingCharm(c, "logging"))
+	c.Assert(err, IsNil)
+	for _, endpoints := range [][]string{{"wordpress", "mysql"}, {"logging", "wordpress"}} {
+		eps, err := s.State.InferEndpoints(endpoints)
+		c.Assert(err, IsNil)
+		_, err = s.State.AddRelation(eps...)
+		c.Assert(err, IsNil)
+	}
+	// Dying services are left out.
+	riak, err := s.State.AddService("riak", s.AddTestingCharm(c, "riak"))
+	c.Assert(err, IsNil)
+	_, err = riak.AddUnit()
+	c.Assert(err, IsNil)
+	err = riak.Destroy()
+	c.Assert(err, IsNil)
+
+	b, err := statecmd.ExportBundle(s.State)
+	c.Assert(err, IsNil)
+	c.Assert(b, DeepEquals, &bundle.Bundle{
+		Services: map[string]*bundle.Service{
+			"wordpress": {
+				Charm:       "local:series/wordpress-3",
+				NumUnits:    2,
+				Options:     map[string]interface{}{"blog-title": "My blog"},
+				Constraints: "mem=4096M",
+				Expose:      true,
+			},
+			"mysql": {
+				Charm:    "local:series/mysql-1",
+				NumUnits: 1,
+			},
+			"logging": {
+				Charm: "local:series/logging-1",
+			},
+		},
+		Relations: [][]string{
+			{"logging:logging-directory", "wordpress:logging-dir"},
+			{"wordpress:db", "mysql:server"},
+		},
+	})
+	err = b.Verify()
+	c.Assert(err, IsNil)
+}
@@ -383,6 +383,104 @@ func (w *minUnitsWatcher) Changes() <-chan []string {
 	return w.out
 }
 
+// actionsWatcher notifies about actions queued for a unit. The first
+// event emitted contains the ids of all the unit's pending actions;
+// subsequent events contain the ids of actions queued since the
+// previous event.
+type actionsWatcher struct {
+	commonWatcher
+	unitName string
+	prefix   string
+	known    set.Strings
+	out      chan []string
+}
+
+// WatchActions returns a StringsWatcher that notifies of actions
+// queued for u.
+func (u *Unit) WatchActions() StringsWatcher {
+	return newActionsWatcher(u.st, u.doc.Name)
+}
+
+func newActionsWatcher(st *State, unitName string) StringsWatcher {
+	w := &actionsWatcher{
+		commonWatcher: commonWatcher{st: st},
+		unitName:      unitName,
+		prefix:        unitName + actionMarker,
+		out:           make(chan []string),
+	}
+	go func() {
+		defer w.tomb.Done()
+		defer close(w.out)
+		w.tomb.Kill(w.loop())
+	}()
+	return w
+}
+
+func (w *actionsWatcher) initial() (*set.Strings, error) {
+	ids := new(set.Strings)
+	var doc actionDoc
+	iter := w.st.actions.Find(D{{"unit", w.unitName}}).Iter()
+	for iter.Next(&doc) {
+		w.known.Add(doc.Id)
+		if doc.Status == ActionPending {
+			ids.Add(doc.Id)
+		}
+	}
+	return ids, iter.Err()
+}
+
+func (w *actionsWatcher) merge(ids *set.Strings, change watcher.Change) {
+	id := change.Id.(string)
+	if !strings.HasPrefix(id, w.prefix) {
+		return
+	}
+	if change.Revno == -1 {
+		w.known.Remove(id)
+		ids.Remove(id)
+		return
+	}
+	// Actions are always pending when queued, so only ids not
+	// seen before are interesting.
+	if !w.known.Contains(id) {
+		w.known.Add(id)
+		ids.Add(id)
+	}
+}
+
+func (w *actionsWatcher) loop() (err error) {
+	ch := make(chan watcher.Change)
+	w.st.watcher.WatchCollection(w.st.actions.Name, ch)
+	defer w.st.watcher.UnwatchCollection(w.st.actions.Name, ch)
+	ids, err := w.initial()
+	if err != nil {
+		return err
+	}
+	out := w.out
+	for {
+		select {
+		case <-w.tomb.Dying():
+			return tomb.ErrDying
+		case change, ok := <-ch:
+			if !ok {
+				return watcher.MustErr(w.st.watcher)
+			}
+			w.merge(ids, change)
+			if !ids.IsEmpty() {
+				out = w.out
+			}
+		case out <- ids.SortedValues():
+			out = nil
+			ids = new(set.Strings)
+		}
+	}
+	return nil
+}
+
+// Changes returns the event channel for w.
+func (w *actionsWatcher) Changes() <-chan []string {
+	return w.out
+}
+
 // RelationScopeWatcher observes changes to the set of units
 // in a particular relation scope.
 type RelationScopeWatcher struct {
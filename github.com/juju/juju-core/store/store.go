@@ -511,6 +511,7 @@ func (p *CharmPublisher) Revision() int {
 type CharmDir interface {
 	Meta() *charm.Meta
 	Config() *charm.Config
+	Actions() *charm.Actions
 	SetRevision(revision int)
 	BundleTo(w io.Writer) error
 }
@@ -660,6 +661,7 @@ func (w *charmWriter) finish() error {
 		id.(bson.ObjectId),
 		w.charm.Meta(),
 		w.charm.Config(),
+		w.charm.Actions(),
 	}
 	if err = charms.Insert(&charm); err != nil {
 		err = maybeConflict(err)
@@ -677,6 +679,7 @@ type CharmInfo struct {
 	fileId   bson.ObjectId
 	meta     *charm.Meta
 	config   *charm.Config
+	actions  *charm.Actions
 }
 
 // Statically ensure CharmInfo is a charm.Charm.
@@ -713,6 +716,11 @@ func (ci *CharmInfo) Config() *charm.Config {
 	return ci.config
 }
 
+// Actions returns the charm.Actions details for the stored charm.
+func (ci *CharmInfo) Actions() *charm.Actions {
+	return ci.actions
+}
+
 // CharmInfo retrieves the CharmInfo value for the charm at url.
 func (s *Store) CharmInfo(url *charm.URL) (info *CharmInfo, err error) {
 	session := s.session.Copy()
@@ -743,6 +751,7 @@ func (s *Store) CharmInfo(url *charm.URL) (info *CharmInfo, err error) {
 		cdoc.FileId,
 		cdoc.Meta,
 		cdoc.Config,
+		cdoc.Actions,
 	}
 	return info, nil
 }
@@ -793,6 +802,7 @@ type charmDoc struct {
 	FileId   bson.ObjectId
 	Meta     *charm.Meta
 	Config   *charm.Config
+	Actions  *charm.Actions
 }
 
 // LockUpdates acquires a server-side lock for updating a single charm
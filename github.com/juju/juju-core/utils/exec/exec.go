@@ -0,0 +1,96 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The exec package runs shell commands and captures their output and
+// exit code.
+package exec
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"launchpad.net/loggo"
+)
+
+var logger = loggo.GetLogger("juju.utils.exec")
+
+// RunParams holds the information needed to run a set of commands.
+type RunParams struct {
+	// Commands holds the commands, which are passed to bash on stdin.
+	Commands string
+	// WorkingDir is the directory the commands are run in. If empty,
+	// the current directory is used.
+	WorkingDir string
+	// Environment holds the environment of the commands, in the form
+	// returned by os.Environ. If nil, the current environment is used.
+	Environment []string
+}
+
+// ExecResponse holds the output and exit code of some executed commands.
+type ExecResponse struct {
+	Code   int
+	Stdout []byte
+	Stderr []byte
+}
+
+// ErrTimeout is returned by Run when the command does not complete in time.
+var ErrTimeout = errors.New("command timed out")
+
+// RunCommands executes the commands in a bash shell and returns their
+// output and exit code. A non-zero exit code is not an error; an error
+// is only returned if the commands could not be run at all.
+func RunCommands(run RunParams) (*ExecResponse, error) {
+	ps := exec.Command("/bin/bash", "-s")
+	ps.Env = run.Environment
+	ps.Dir = run.WorkingDir
+	ps.Stdin = strings.NewReader(run.Commands)
+	logger.Debugf("running commands in %q", run.WorkingDir)
+	return Run(ps, 0)
+}
+
+// Run runs ps, which must not have its Stdout or Stderr set, and returns
+// its output and exit code. If timeout is non-zero and ps has not finished
+// after that long, it is killed and ErrTimeout is returned. As with
+// RunCommands, a non-zero exit code is not an error.
+func Run(ps *exec.Cmd, timeout time.Duration) (*ExecResponse, error) {
+	var stdout, stderr bytes.Buffer
+	ps.Stdout = &stdout
+	ps.Stderr = &stderr
+	if err := ps.Start(); err != nil {
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- ps.Wait()
+	}()
+	var timedOut <-chan time.Time
+	if timeout > 0 {
+		timedOut = time.After(timeout)
+	}
+	var err error
+	select {
+	case err = <-done:
+	case <-timedOut:
+		ps.Process.Kill()
+		<-done
+		return nil, ErrTimeout
+	}
+	result := &ExecResponse{
+		Stdout: stdout.Bytes(),
+		Stderr: stderr.Bytes(),
+	}
+	if ee, ok := err.(*exec.ExitError); ok && err != nil {
+		status := ee.ProcessState.Sys().(syscall.WaitStatus)
+		if status.Exited() {
+			// A non-zero return code isn't considered an error here.
+			result.Code = status.ExitStatus()
+			err = nil
+		}
+		logger.Debugf("command exited: %v", ee)
+	}
+	return result, err
+}
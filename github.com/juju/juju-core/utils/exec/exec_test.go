@@ -0,0 +1,103 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package exec_test
+
+import (
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "launchpad.net/gocheck"
+	coretesting "launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils/exec"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type execSuite struct {
+	coretesting.LoggingSuite
+}
+
+var _ = Suite(&execSuite{})
+
+func (*execSuite) TestRunCommands(c *C) {
+	newDir := c.MkDir()
+
+	for i, test := range []struct {
+		message     string
+		commands    string
+		workingDir  string
+		environment []string
+		stdout      string
+		stderr      string
+		code        int
+	}{{
+		message:  "test stdout capture",
+		commands: "echo testing stdout",
+		stdout:   "testing stdout\n",
+	}, {
+		message:  "test stderr capture",
+		commands: "echo testing stderr >&2",
+		stderr:   "testing stderr\n",
+	}, {
+		message:  "test return code",
+		commands: "exit 42",
+		code:     42,
+	}, {
+		message:  "test multiple commands",
+		commands: "echo one\necho two; exit 3",
+		stdout:   "one\ntwo\n",
+		code:     3,
+	}, {
+		message:    "test working dir",
+		commands:   "pwd",
+		workingDir: newDir,
+		stdout:     newDir + "\n",
+	}, {
+		message:     "test environment",
+		commands:    "echo $OMG_IT_WORKS",
+		environment: []string{"OMG_IT_WORKS=like magic"},
+		stdout:      "like magic\n",
+	}} {
+		c.Logf("%v: %s", i, test.message)
+
+		result, err := exec.RunCommands(exec.RunParams{
+			Commands:    test.commands,
+			WorkingDir:  test.workingDir,
+			Environment: test.environment,
+		})
+		c.Assert(err, IsNil)
+		c.Assert(string(result.Stdout), Equals, test.stdout)
+		c.Assert(string(result.Stderr), Equals, test.stderr)
+		c.Assert(result.Code, Equals, test.code)
+	}
+}
+
+func (*execSuite) TestRunCommandsBadWorkingDir(c *C) {
+	missing := filepath.Join(c.MkDir(), "missing")
+	_, err := exec.RunCommands(exec.RunParams{
+		Commands:   "pwd",
+		WorkingDir: missing,
+	})
+	c.Assert(err, ErrorMatches, ".*no such file or directory")
+}
+
+func (*execSuite) TestRunTimeout(c *C) {
+	ps := osexec.Command("/bin/sleep", "10")
+	start := time.Now()
+	_, err := exec.Run(ps, 10*time.Millisecond)
+	c.Assert(err, Equals, exec.ErrTimeout)
+	c.Assert(time.Since(start) < 5*time.Second, Equals, true)
+}
+
+func (*execSuite) TestRunNoTimeout(c *C) {
+	ps := osexec.Command("/bin/sh", "-c", "echo done; exit 7")
+	result, err := exec.Run(ps, time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(string(result.Stdout), Equals, "done\n")
+	c.Assert(result.Code, Equals, 7)
+}
@@ -0,0 +1,57 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+// KeyBits is the size of the keys generated by GenerateKey.
+var KeyBits = 2048
+
+// GenerateKey generates a new RSA key pair. The private key is returned in
+// PEM format, as read by ssh -i, and the public key is returned as a line
+// of an authorized_keys file, ending with the given comment.
+func GenerateKey(comment string) (private, public string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, KeyBits)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot generate ssh key: %v", err)
+	}
+	private = string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+	var buf bytes.Buffer
+	writeString(&buf, []byte("ssh-rsa"))
+	writeInt(&buf, big.NewInt(int64(key.E)))
+	writeInt(&buf, key.N)
+	public = "ssh-rsa " + base64.StdEncoding.EncodeToString(buf.Bytes())
+	if comment != "" {
+		public += " " + comment
+	}
+	return private, public, nil
+}
+
+// writeString writes s to buf in the SSH wire format (RFC 4251).
+func writeString(buf *bytes.Buffer, s []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(s)))
+	buf.Write(s)
+}
+
+// writeInt writes the non-negative n to buf as an SSH mpint (RFC 4251).
+func writeInt(buf *bytes.Buffer, n *big.Int) {
+	b := n.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	writeString(buf, b)
+}
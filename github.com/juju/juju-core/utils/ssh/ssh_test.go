@@ -0,0 +1,56 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ssh_test
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/utils/ssh"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+type sshSuite struct{}
+
+var _ = Suite(sshSuite{})
+
+func (sshSuite) SetUpSuite(c *C) {
+	// Small keys keep the tests fast.
+	ssh.KeyBits = 1024
+}
+
+func (sshSuite) TestGenerateKey(c *C) {
+	private, public, err := ssh.GenerateKey("juju-system-key")
+	c.Assert(err, IsNil)
+
+	block, _ := pem.Decode([]byte(private))
+	c.Assert(block, NotNil)
+	c.Assert(block.Type, Equals, "RSA PRIVATE KEY")
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	c.Assert(err, IsNil)
+
+	fields := strings.Fields(public)
+	c.Assert(fields, HasLen, 3)
+	c.Assert(fields[0], Equals, "ssh-rsa")
+	c.Assert(fields[2], Equals, "juju-system-key")
+	data, err := base64.StdEncoding.DecodeString(fields[1])
+	c.Assert(err, IsNil)
+	// The key type, then the public exponent and the modulus.
+	c.Assert(string(data[4:11]), Equals, "ssh-rsa")
+	c.Assert(data[len(data)-len(key.N.Bytes()):], DeepEquals, key.N.Bytes())
+}
+
+func (sshSuite) TestGenerateKeyWithoutComment(c *C) {
+	_, public, err := ssh.GenerateKey("")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Fields(public), HasLen, 2)
+}
@@ -0,0 +1,13 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tailer
+
+import (
+	"io"
+	"time"
+)
+
+func NewTestTailer(readSeeker io.ReadSeeker, backlog int, filter FilterFunc, bufferSize int, pollInterval time.Duration) *Tailer {
+	return newTailer(readSeeker, backlog, filter, bufferSize, pollInterval)
+}
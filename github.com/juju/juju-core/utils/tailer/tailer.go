@@ -0,0 +1,191 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The tailer package follows a growing file, such as a log file,
+// reporting the lines appended to it, like "tail -f".
+package tailer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"time"
+
+	"launchpad.net/tomb"
+)
+
+const (
+	defaultBufferSize   = 4096
+	defaultPollInterval = time.Second
+	maxBatchSize        = 100
+)
+
+// FilterFunc decides whether a line, without its trailing newline,
+// is reported by the tailer.
+type FilterFunc func(line []byte) bool
+
+// Tailer reads lines from a file as they are appended to it, and
+// sends those that pass its filter on its Changes channel.
+type Tailer struct {
+	tomb         tomb.Tomb
+	readSeeker   io.ReadSeeker
+	backlog      int
+	filter       FilterFunc
+	changes      chan []string
+	bufferSize   int
+	pollInterval time.Duration
+}
+
+// NewTailer starts a Tailer reading from readSeeker. Tailing starts
+// backlog matching lines before the current end of the data; if
+// backlog is negative it starts at the beginning. A nil filter
+// passes all lines.
+func NewTailer(readSeeker io.ReadSeeker, backlog int, filter FilterFunc) *Tailer {
+	return newTailer(readSeeker, backlog, filter, defaultBufferSize, defaultPollInterval)
+}
+
+func newTailer(readSeeker io.ReadSeeker, backlog int, filter FilterFunc, bufferSize int, pollInterval time.Duration) *Tailer {
+	t := &Tailer{
+		readSeeker:   readSeeker,
+		backlog:      backlog,
+		filter:       filter,
+		changes:      make(chan []string),
+		bufferSize:   bufferSize,
+		pollInterval: pollInterval,
+	}
+	go func() {
+		defer t.tomb.Done()
+		defer close(t.changes)
+		t.tomb.Kill(t.loop())
+	}()
+	return t
+}
+
+// Changes returns a channel on which batches of lines are sent as
+// they are read. The channel is closed when the tailer stops.
+func (t *Tailer) Changes() <-chan []string {
+	return t.changes
+}
+
+// Stop stops the tailer and returns any error encountered while
+// it was running.
+func (t *Tailer) Stop() error {
+	t.tomb.Kill(nil)
+	return t.tomb.Wait()
+}
+
+// Kill asks the tailer to stop without waiting for it to do so.
+func (t *Tailer) Kill() {
+	t.tomb.Kill(nil)
+}
+
+// Wait waits for the tailer to stop and returns any error
+// encountered while it was running.
+func (t *Tailer) Wait() error {
+	return t.tomb.Wait()
+}
+
+// Err returns any error encountered while the tailer was running.
+func (t *Tailer) Err() error {
+	return t.tomb.Err()
+}
+
+func (t *Tailer) loop() error {
+	if err := t.seekStart(); err != nil {
+		return err
+	}
+	reader := bufio.NewReaderSize(t.readSeeker, t.bufferSize)
+	var partial []byte
+	var pending []string
+	for {
+		data, err := reader.ReadBytes('\n')
+		partial = append(partial, data...)
+		if err == nil {
+			line := bytes.TrimRight(partial, "\n")
+			if t.isValid(line) {
+				pending = append(pending, string(line))
+			}
+			partial = nil
+			if len(pending) < maxBatchSize {
+				continue
+			}
+		} else if err != io.EOF {
+			return err
+		}
+		// Either we have caught up with the end of the data, in
+		// which case we wait for more to be written, or we have a
+		// full batch of lines to deliver first.
+		var out chan []string
+		if len(pending) > 0 {
+			out = t.changes
+		}
+		var poll <-chan time.Time
+		if err == io.EOF {
+			poll = time.After(t.pollInterval)
+		}
+		select {
+		case <-t.tomb.Dying():
+			return tomb.ErrDying
+		case out <- pending:
+			pending = nil
+		case <-poll:
+		}
+	}
+}
+
+func (t *Tailer) isValid(line []byte) bool {
+	return t.filter == nil || t.filter(line)
+}
+
+// seekStart positions the reader at the start of the line from
+// which tailing begins.
+func (t *Tailer) seekStart() error {
+	if t.backlog < 0 {
+		_, err := t.readSeeker.Seek(0, os.SEEK_SET)
+		return err
+	}
+	offset, err := t.readSeeker.Seek(0, os.SEEK_END)
+	if err != nil || t.backlog == 0 {
+		return err
+	}
+	// Read backwards a block at a time. data holds the bytes from
+	// offset up to the end of the earliest line seen so far, which is
+	// either the end of the data or a newline that terminates it.
+	var data []byte
+	found := 0
+	buffer := make([]byte, t.bufferSize)
+	for offset > 0 {
+		size := int64(len(buffer))
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+		if _, err := t.readSeeker.Seek(offset, os.SEEK_SET); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(t.readSeeker, buffer[:size]); err != nil {
+			return err
+		}
+		data = append(append([]byte(nil), buffer[:size]...), data...)
+		// The last byte of data belongs to the line being scanned,
+		// so a newline there does not start a new line.
+		for i := len(data) - 2; i >= 0; i-- {
+			if data[i] != '\n' {
+				continue
+			}
+			if t.isValid(bytes.TrimRight(data[i+1:], "\n")) {
+				found++
+				if found == t.backlog {
+					_, err := t.readSeeker.Seek(offset+int64(i)+1, os.SEEK_SET)
+					return err
+				}
+			}
+			data = data[:i+1]
+		}
+	}
+	// There are fewer matching lines than requested, so start at
+	// the beginning.
+	_, err = t.readSeeker.Seek(0, os.SEEK_SET)
+	return err
+}
@@ -0,0 +1,158 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package tailer_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	stdtesting "testing"
+	"time"
+
+	. "launchpad.net/gocheck"
+
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/utils/tailer"
+)
+
+func Test(t *stdtesting.T) {
+	TestingT(t)
+}
+
+type tailerSuite struct {
+	testing.LoggingSuite
+	file *os.File
+}
+
+var _ = Suite(&tailerSuite{})
+
+func (s *tailerSuite) SetUpTest(c *C) {
+	s.LoggingSuite.SetUpTest(c)
+	var err error
+	s.file, err = os.Create(filepath.Join(c.MkDir(), "test.log"))
+	c.Assert(err, IsNil)
+}
+
+func (s *tailerSuite) TearDownTest(c *C) {
+	s.file.Close()
+	s.LoggingSuite.TearDownTest(c)
+}
+
+func (s *tailerSuite) write(c *C, data string) {
+	_, err := s.file.WriteString(data)
+	c.Assert(err, IsNil)
+}
+
+func (s *tailerSuite) newTailer(c *C, backlog int, filter tailer.FilterFunc) *tailer.Tailer {
+	f, err := os.Open(s.file.Name())
+	c.Assert(err, IsNil)
+	// A small buffer exercises reading across block boundaries.
+	t := tailer.NewTestTailer(f, backlog, filter, 8, 10*time.Millisecond)
+	go func() {
+		t.Wait()
+		f.Close()
+	}()
+	return t
+}
+
+// readLines reads from the tailer until it has received n lines.
+func readLines(c *C, t *tailer.Tailer, n int) []string {
+	var lines []string
+	for len(lines) < n {
+		select {
+		case batch, ok := <-t.Changes():
+			c.Assert(ok, Equals, true)
+			lines = append(lines, batch...)
+		case <-time.After(testing.LongWait):
+			c.Fatalf("timed out waiting for lines; got %q", lines)
+		}
+	}
+	c.Assert(lines, HasLen, n)
+	return lines
+}
+
+func assertNoLines(c *C, t *tailer.Tailer) {
+	select {
+	case lines := <-t.Changes():
+		c.Fatalf("unexpected lines %q", lines)
+	case <-time.After(testing.ShortWait):
+	}
+}
+
+var lines = "first line\nsecond line\nthird line\nfourth line\n"
+
+func (s *tailerSuite) TestBacklog(c *C) {
+	s.write(c, lines)
+	for i, test := range []struct {
+		backlog  int
+		expected []string
+	}{
+		{1, []string{"fourth line"}},
+		{3, []string{"second line", "third line", "fourth line"}},
+		{4, []string{"first line", "second line", "third line", "fourth line"}},
+		{10, []string{"first line", "second line", "third line", "fourth line"}},
+		{-1, []string{"first line", "second line", "third line", "fourth line"}},
+	} {
+		c.Logf("test %d: backlog %d", i, test.backlog)
+		t := s.newTailer(c, test.backlog, nil)
+		c.Check(readLines(c, t, len(test.expected)), DeepEquals, test.expected)
+		assertNoLines(c, t)
+		c.Check(t.Stop(), IsNil)
+	}
+}
+
+func (s *tailerSuite) TestNoBacklog(c *C) {
+	s.write(c, lines)
+	t := s.newTailer(c, 0, nil)
+	defer t.Stop()
+	assertNoLines(c, t)
+	s.write(c, "fifth line\n")
+	c.Assert(readLines(c, t, 1), DeepEquals, []string{"fifth line"})
+}
+
+func (s *tailerSuite) TestFilter(c *C) {
+	s.write(c, lines)
+	filter := func(line []byte) bool {
+		return bytes.HasPrefix(line, []byte("f"))
+	}
+	t := s.newTailer(c, 1, filter)
+	defer t.Stop()
+	c.Assert(readLines(c, t, 1), DeepEquals, []string{"fourth line"})
+
+	s.write(c, "fifth line\nsixth line\nseventh line\n")
+	c.Assert(readLines(c, t, 1), DeepEquals, []string{"fifth line"})
+	assertNoLines(c, t)
+}
+
+func (s *tailerSuite) TestFilterBacklog(c *C) {
+	s.write(c, lines)
+	filter := func(line []byte) bool {
+		return bytes.HasPrefix(line, []byte("f"))
+	}
+	t := s.newTailer(c, 2, filter)
+	defer t.Stop()
+	c.Assert(readLines(c, t, 2), DeepEquals, []string{"first line", "fourth line"})
+}
+
+func (s *tailerSuite) TestPartialLine(c *C) {
+	s.write(c, "first line\n")
+	t := s.newTailer(c, 0, nil)
+	defer t.Stop()
+	// Give the tailer time to find the end of the file.
+	assertNoLines(c, t)
+	s.write(c, "second")
+	assertNoLines(c, t)
+	s.write(c, " line\nthi")
+	c.Assert(readLines(c, t, 1), DeepEquals, []string{"second line"})
+	assertNoLines(c, t)
+	s.write(c, "rd line\n")
+	c.Assert(readLines(c, t, 1), DeepEquals, []string{"third line"})
+}
+
+func (s *tailerSuite) TestStop(c *C) {
+	t := s.newTailer(c, 0, nil)
+	c.Assert(t.Stop(), IsNil)
+	_, ok := <-t.Changes()
+	c.Assert(ok, Equals, false)
+}
@@ -10,6 +10,7 @@ import (
 	"launchpad.net/juju-core/charm"
 	"launchpad.net/juju-core/log"
 	"launchpad.net/juju-core/state"
+	utilexec "launchpad.net/juju-core/utils/exec"
 	"launchpad.net/juju-core/worker/uniter/jujuc"
 	"os"
 	"os/exec"
@@ -49,6 +50,15 @@ type HookContext struct {
 
 	// apiAddrs contains the API server addresses.
 	apiAddrs []string
+
+	// actionName identifies the executing action. It will be empty if the
+	// context is not running an action.
+	actionName string
+
+	// actionParams and actionResults hold the parameters and the results
+	// of the executing action.
+	actionParams  map[string]interface{}
+	actionResults map[string]interface{}
 }
 
 func NewHookContext(unit *state.Unit, id, uuid string, relationId int,
@@ -121,6 +131,38 @@ func (ctx *HookContext) RelationIds() []int {
 	return ids
 }
 
+// SetAction prepares the context to run the named action with the
+// supplied parameters.
+func (ctx *HookContext) SetAction(name string, params map[string]interface{}) {
+	ctx.actionName = name
+	ctx.actionParams = params
+	ctx.actionResults = map[string]interface{}{}
+}
+
+func (ctx *HookContext) ActionParams() (map[string]interface{}, bool) {
+	if ctx.actionName == "" {
+		return nil, false
+	}
+	result := map[string]interface{}{}
+	for name, value := range ctx.actionParams {
+		result[name] = value
+	}
+	return result, true
+}
+
+func (ctx *HookContext) SetActionResult(key string, value interface{}) error {
+	if ctx.actionName == "" {
+		return fmt.Errorf("not running an action")
+	}
+	ctx.actionResults[key] = value
+	return nil
+}
+
+// ActionResults returns the results recorded by the executing action.
+func (ctx *HookContext) ActionResults() map[string]interface{} {
+	return ctx.actionResults
+}
+
 // hookVars returns an os.Environ-style list of strings necessary to run a hook
 // such that it can know what environment it's operating in, and can call back
 // into ctx.
@@ -142,13 +184,41 @@ func (ctx *HookContext) hookVars(charmDir, toolsDir, socketPath string) []string
 		name, _ := ctx.RemoteUnitName()
 		vars = append(vars, "JUJU_REMOTE_UNIT="+name)
 	}
+	if ctx.actionName != "" {
+		vars = append(vars, "JUJU_ACTION_NAME="+ctx.actionName)
+	}
 	return vars
 }
 
 // RunHook executes a hook in an environment which allows it to to call back
 // into ctx to execute jujuc tools.
 func (ctx *HookContext) RunHook(hookName, charmDir, toolsDir, socketPath string) error {
-	ps := exec.Command(filepath.Join(charmDir, "hooks", hookName))
+	err := ctx.runCharmProcess(filepath.Join(charmDir, "hooks", hookName), charmDir, toolsDir, socketPath)
+	if ee, ok := err.(*exec.Error); ok && err != nil {
+		if os.IsNotExist(ee.Err) {
+			// Missing hook is perfectly valid, but worth mentioning.
+			log.Infof("worker/uniter: skipped %q hook (not implemented)", hookName)
+			return nil
+		}
+	}
+	return ctx.finalizeContext(fmt.Sprintf("%q hook", hookName), err)
+}
+
+// RunAction executes the action set by SetAction in an environment which
+// allows it to call back into ctx to execute jujuc tools. Unlike a missing
+// hook, a missing action is an error.
+func (ctx *HookContext) RunAction(charmDir, toolsDir, socketPath string) error {
+	if ctx.actionName == "" {
+		return fmt.Errorf("not running an action")
+	}
+	err := ctx.runCharmProcess(filepath.Join(charmDir, "actions", ctx.actionName), charmDir, toolsDir, socketPath)
+	return ctx.finalizeContext(fmt.Sprintf("%q action", ctx.actionName), err)
+}
+
+// runCharmProcess runs the executable at path, logging its output, in an
+// environment which allows it to call back into ctx to execute jujuc tools.
+func (ctx *HookContext) runCharmProcess(path, charmDir, toolsDir, socketPath string) error {
+	ps := exec.Command(path)
 	ps.Env = ctx.hookVars(charmDir, toolsDir, socketPath)
 	ps.Dir = charmDir
 	outReader, outWriter, err := os.Pipe()
@@ -168,20 +238,31 @@ func (ctx *HookContext) RunHook(hookName, charmDir, toolsDir, socketPath string)
 		err = ps.Wait()
 	}
 	logger.stop()
-	if ee, ok := err.(*exec.Error); ok && err != nil {
-		if os.IsNotExist(ee.Err) {
-			// Missing hook is perfectly valid, but worth mentioning.
-			log.Infof("worker/uniter: skipped %q hook (not implemented)", hookName)
-			return nil
-		}
-	}
+	return err
+}
+
+// RunCommands executes the commands in an environment which allows them to
+// call back into ctx to execute jujuc tools, and returns their output and
+// exit code.
+func (ctx *HookContext) RunCommands(commands, charmDir, toolsDir, socketPath string) (*utilexec.ExecResponse, error) {
+	result, err := utilexec.RunCommands(utilexec.RunParams{
+		Commands:    commands,
+		WorkingDir:  charmDir,
+		Environment: ctx.hookVars(charmDir, toolsDir, socketPath),
+	})
+	return result, ctx.finalizeContext("run commands", err)
+}
+
+// finalizeContext writes any relation settings changed by process, if it
+// completed without error, and clears the cached relation state.
+func (ctx *HookContext) finalizeContext(process string, err error) error {
 	write := err == nil
 	for id, rctx := range ctx.relations {
 		if write {
 			if e := rctx.WriteSettings(); e != nil {
 				e = fmt.Errorf(
-					"could not write settings from %q to relation %d: %v",
-					hookName, id, e,
+					"could not write settings from %s to relation %d: %v",
+					process, id, e,
 				)
 				log.Errorf("worker/uniter: %v", e)
 				if err == nil {
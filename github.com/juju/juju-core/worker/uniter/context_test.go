@@ -233,6 +233,46 @@ func (s *RunHookSuite) TestRunHook(c *C) {
 	}
 }
 
+func (s *RunHookSuite) TestRunAction(c *C) {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, IsNil)
+	ctx := s.GetHookContext(c, uuid.String(), -1, "")
+	charmDir, outPath := makeCharm(c, hookSpec{name: "snapshot", perm: 0700})
+	err = os.Rename(filepath.Join(charmDir, "hooks"), filepath.Join(charmDir, "actions"))
+	c.Assert(err, IsNil)
+
+	// No action has been set.
+	toolsDir := c.MkDir()
+	err = ctx.RunAction(charmDir, toolsDir, "/path/to/socket")
+	c.Assert(err, ErrorMatches, "not running an action")
+	_, found := ctx.ActionParams()
+	c.Assert(found, Equals, false)
+	err = ctx.SetActionResult("size", "10M")
+	c.Assert(err, ErrorMatches, "not running an action")
+
+	params := map[string]interface{}{"outfile": "foo.bz2"}
+	ctx.SetAction("snapshot", params)
+	actual, found := ctx.ActionParams()
+	c.Assert(found, Equals, true)
+	c.Assert(actual, DeepEquals, params)
+	err = ctx.SetActionResult("size", "10M")
+	c.Assert(err, IsNil)
+	c.Assert(ctx.ActionResults(), DeepEquals, map[string]interface{}{"size": "10M"})
+
+	err = ctx.RunAction(charmDir, toolsDir, "/path/to/socket")
+	c.Assert(err, IsNil)
+	AssertEnv(c, outPath, charmDir, map[string]string{
+		"PATH":             toolsDir + ":" + os.Getenv("PATH"),
+		"JUJU_UNIT_NAME":   "u/0",
+		"JUJU_ACTION_NAME": "snapshot",
+	}, uuid.String())
+
+	// Unlike a missing hook, a missing action is an error.
+	ctx.SetAction("backup", nil)
+	err = ctx.RunAction(charmDir, toolsDir, "/path/to/socket")
+	c.Assert(err, ErrorMatches, `exec: .*backup": stat .*: no such file or directory`)
+}
+
 // split the line into buffer-sized lengths.
 func splitLine(s string) []string {
 	var ss []string
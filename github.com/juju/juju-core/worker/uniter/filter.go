@@ -37,6 +37,8 @@ type filter struct {
 	outResolvedOn  chan state.ResolvedMode
 	outRelations   chan []int
 	outRelationsOn chan []int
+	outActions     chan struct{}
+	outActionsOn   chan struct{}
 
 	// The want* chans are used to indicate that the filter should send
 	// events if it has them available.
@@ -97,6 +99,8 @@ func newFilter(st *state.State, unitName string) (*filter, error) {
 		outResolvedOn:     make(chan state.ResolvedMode),
 		outRelations:      make(chan []int),
 		outRelationsOn:    make(chan []int),
+		outActions:        make(chan struct{}),
+		outActionsOn:      make(chan struct{}),
 		wantForcedUpgrade: make(chan bool),
 		wantResolved:      make(chan struct{}),
 		discardConfig:     make(chan struct{}),
@@ -159,6 +163,13 @@ func (f *filter) RelationsEvents() <-chan []int {
 	return f.outRelationsOn
 }
 
+// ActionEvents returns a channel that will receive a signal whenever
+// actions are queued for the unit, including any already pending when
+// the filter starts.
+func (f *filter) ActionEvents() <-chan struct{} {
+	return f.outActionsOn
+}
+
 // WantUpgradeEvent controls whether the filter will generate upgrade
 // events for unforced service charm changes.
 func (f *filter) WantUpgradeEvent(mustForce bool) {
@@ -271,6 +282,8 @@ func (f *filter) loop(unitName string) (err error) {
 	}()
 	relationsw := f.service.WatchRelations()
 	defer func() { watcher.Stop(relationsw, &f.tomb) }()
+	actionsw := f.unit.WatchActions()
+	defer watcher.Stop(actionsw, &f.tomb)
 
 	// Config events cannot be meaningfully discarded until one is available;
 	// once we receive the initial change, we unblock discard requests by
@@ -324,6 +337,15 @@ func (f *filter) loop(unitName string) (err error) {
 				}
 			}
 			f.relationsChanged(ids)
+		case ids, ok := <-actionsw.Changes():
+			log.Debugf("worker/uniter/filter: got actions change")
+			if !ok {
+				return watcher.MustErr(actionsw)
+			}
+			if len(ids) > 0 {
+				log.Debugf("worker/uniter/filter: preparing new actions event")
+				f.outActions = f.outActionsOn
+			}
 
 		// Send events on active out chans.
 		case f.outUpgrade <- f.upgrade:
@@ -339,6 +361,9 @@ func (f *filter) loop(unitName string) (err error) {
 			log.Debugf("worker/uniter/filter: sent relations event")
 			f.outRelations = nil
 			f.relations = nil
+		case f.outActions <- nothing:
+			log.Debugf("worker/uniter/filter: sent actions event")
+			f.outActions = nil
 
 		// Handle explicit requests.
 		case curl := <-f.setCharm:
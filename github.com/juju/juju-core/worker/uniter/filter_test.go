@@ -440,6 +440,52 @@ func (s *FilterSuite) TestRelationsEvents(c *C) {
 	assertChange([]int{0, 2})
 }
 
+func (s *FilterSuite) TestActionEvents(c *C) {
+	// The wordpress charm defines no actions, so use the dummy charm.
+	dummy, err := s.State.AddService("dummy", s.AddTestingCharm(c, "dummy"))
+	c.Assert(err, IsNil)
+	unit, err := dummy.AddUnit()
+	c.Assert(err, IsNil)
+	_, err = unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+
+	f, err := newFilter(s.State, unit.Name())
+	c.Assert(err, IsNil)
+	defer f.Stop()
+
+	assertNoChange := func() {
+		s.State.Sync()
+		select {
+		case <-f.ActionEvents():
+			c.Fatalf("unexpected actions event")
+		case <-time.After(coretesting.ShortWait):
+		}
+	}
+	assertChange := func() {
+		s.State.Sync()
+		select {
+		case <-f.ActionEvents():
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out")
+		}
+		assertNoChange()
+	}
+	// Check the initial event for the already pending action.
+	assertChange()
+
+	// Queue another action; check the event.
+	_, err = unit.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	assertChange()
+
+	// Check that actions for other units are not reported.
+	other, err := dummy.AddUnit()
+	c.Assert(err, IsNil)
+	_, err = other.AddAction("snapshot", nil)
+	c.Assert(err, IsNil)
+	assertNoChange()
+}
+
 func (s *FilterSuite) addRelation(c *C) *state.Relation {
 	if s.mysqlcharm == nil {
 		s.mysqlcharm = s.AddTestingCharm(c, "mysql")
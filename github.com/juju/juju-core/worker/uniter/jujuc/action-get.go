@@ -0,0 +1,63 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc
+
+import (
+	"fmt"
+
+	"launchpad.net/gnuflag"
+	"launchpad.net/juju-core/cmd"
+)
+
+// ActionGetCommand implements the action-get command.
+type ActionGetCommand struct {
+	cmd.CommandBase
+	ctx Context
+	Key string // The key to show. If empty, show all.
+	out cmd.Output
+}
+
+func NewActionGetCommand(ctx Context) cmd.Command {
+	return &ActionGetCommand{ctx: ctx}
+}
+
+func (c *ActionGetCommand) Info() *cmd.Info {
+	doc := `
+When no <key> is supplied, all parameters of the running action are printed,
+including the defaults of any parameters not supplied when the action was
+queued.
+`
+	return &cmd.Info{
+		Name:    "action-get",
+		Args:    "[<key>]",
+		Purpose: "print action parameters",
+		Doc:     doc,
+	}
+}
+
+func (c *ActionGetCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "smart", cmd.DefaultFormatters)
+}
+
+func (c *ActionGetCommand) Init(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	c.Key = args[0]
+	return cmd.CheckEmpty(args[1:])
+}
+
+func (c *ActionGetCommand) Run(ctx *cmd.Context) error {
+	params, found := c.ctx.ActionParams()
+	if !found {
+		return fmt.Errorf("not running an action")
+	}
+	var value interface{}
+	if c.Key == "" {
+		value = params
+	} else {
+		value, _ = params[c.Key]
+	}
+	return c.out.Write(ctx, value)
+}
@@ -0,0 +1,59 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type ActionGetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&ActionGetSuite{})
+
+var actionGetTests = []struct {
+	args []string
+	out  string
+}{
+	{[]string{"outfile"}, "foo.bz2\n"},
+	{[]string{"compress"}, "True\n"},
+	{[]string{"--format", "yaml", "compress"}, "true\n"},
+	{[]string{"--format", "json", "outfile"}, `"foo.bz2"` + "\n"},
+	{[]string{"missing"}, ""},
+	{[]string{"--format", "json", "missing"}, "null\n"},
+	{[]string{"--format", "json"}, `{"compress":true,"outfile":"foo.bz2"}` + "\n"},
+}
+
+func (s *ActionGetSuite) TestOutputFormat(c *C) {
+	for i, t := range actionGetTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com, err := jujuc.NewCommand(s.GetActionContext(c), "action-get")
+		c.Assert(err, IsNil)
+		ctx := testing.Context(c)
+		code := cmd.Main(com, ctx, t.args)
+		c.Assert(code, Equals, 0)
+		c.Assert(bufferString(ctx.Stderr), Equals, "")
+		c.Assert(bufferString(ctx.Stdout), Equals, t.out)
+	}
+}
+
+func (s *ActionGetSuite) TestNotRunningAction(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "action-get")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"outfile"})
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: not running an action\n")
+}
+
+func (s *ActionGetSuite) TestUnknownArg(c *C) {
+	com, err := jujuc.NewCommand(s.GetActionContext(c), "action-get")
+	c.Assert(err, IsNil)
+	testing.TestInit(c, com, []string{"multiple", "keys"}, `unrecognized args: \["keys"\]`)
+}
@@ -0,0 +1,60 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package jujuc_test
+
+import (
+	. "launchpad.net/gocheck"
+	"launchpad.net/juju-core/cmd"
+	"launchpad.net/juju-core/testing"
+	"launchpad.net/juju-core/worker/uniter/jujuc"
+)
+
+type ActionSetSuite struct {
+	ContextSuite
+}
+
+var _ = Suite(&ActionSetSuite{})
+
+var actionSetInitTests = []struct {
+	args []string
+	err  string
+}{
+	{nil, `expected "key=value" parameters, got nothing`},
+	{[]string{"size"}, `expected "key=value", got "size"`},
+	{[]string{"=10M"}, `expected "key=value", got "=10M"`},
+}
+
+func (s *ActionSetSuite) TestInit(c *C) {
+	for i, t := range actionSetInitTests {
+		c.Logf("test %d: %#v", i, t.args)
+		com, err := jujuc.NewCommand(s.GetActionContext(c), "action-set")
+		c.Assert(err, IsNil)
+		testing.TestInit(c, com, t.args, t.err)
+	}
+}
+
+func (s *ActionSetSuite) TestRun(c *C) {
+	hctx := s.GetActionContext(c)
+	com, err := jujuc.NewCommand(hctx, "action-set")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"size=10M", "path=/tmp/foo=bar", "empty="})
+	c.Assert(code, Equals, 0)
+	c.Assert(bufferString(ctx.Stderr), Equals, "")
+	c.Assert(hctx.results, DeepEquals, map[string]interface{}{
+		"size":  "10M",
+		"path":  "/tmp/foo=bar",
+		"empty": "",
+	})
+}
+
+func (s *ActionSetSuite) TestNotRunningAction(c *C) {
+	hctx := s.GetHookContext(c, -1, "")
+	com, err := jujuc.NewCommand(hctx, "action-set")
+	c.Assert(err, IsNil)
+	ctx := testing.Context(c)
+	code := cmd.Main(com, ctx, []string{"size=10M"})
+	c.Assert(code, Equals, 1)
+	c.Assert(bufferString(ctx.Stderr), Equals, "error: not running an action\n")
+}
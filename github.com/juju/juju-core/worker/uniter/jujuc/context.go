@@ -50,6 +50,14 @@ type Context interface {
 	// RelationIds returns the ids of all relations the executing unit is
 	// currently participating in.
 	RelationIds() []int
+
+	// ActionParams returns the parameters of the executing action, and
+	// whether the context is running an action.
+	ActionParams() (map[string]interface{}, bool)
+
+	// SetActionResult records a result of the executing action. It returns
+	// an error if the context is not running an action.
+	SetActionResult(key string, value interface{}) error
 }
 
 // ContextRelation expresses the capabilities of a hook with respect to a relation.
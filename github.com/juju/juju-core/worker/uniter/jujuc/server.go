@@ -21,6 +21,8 @@ import (
 
 // newCommands maps Command names to initializers.
 var newCommands = map[string]func(Context) cmd.Command{
+	"action-get":    NewActionGetCommand,
+	"action-set":    NewActionSetCommand,
 	"close-port":    NewClosePortCommand,
 	"config-get":    NewConfigGetCommand,
 	"juju-log":      NewJujuLogCommand,
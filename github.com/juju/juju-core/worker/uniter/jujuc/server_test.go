@@ -190,6 +190,8 @@ var newCommandTests = []struct {
 	name string
 	err  string
 }{
+	{"action-get", ""},
+	{"action-set", ""},
 	{"close-port", ""},
 	{"config-get", ""},
 	{"juju-log", ""},
@@ -61,6 +61,18 @@ func (s *ContextSuite) GetHookContext(c *C, relid int, remote string) *Context {
 	}
 }
 
+// GetActionContext returns a context running an action with
+// parameters "outfile" and "compress".
+func (s *ContextSuite) GetActionContext(c *C) *Context {
+	hctx := s.GetHookContext(c, -1, "")
+	hctx.params = map[string]interface{}{
+		"outfile":  "foo.bz2",
+		"compress": true,
+	}
+	hctx.results = map[string]interface{}{}
+	return hctx
+}
+
 func setSettings(c *C, ru *state.RelationUnit, settings map[string]interface{}) {
 	node, err := ru.Settings()
 	c.Assert(err, IsNil)
@@ -73,10 +85,12 @@ func setSettings(c *C, ru *state.RelationUnit, settings map[string]interface{})
 }
 
 type Context struct {
-	ports  set.Strings
-	relid  int
-	remote string
-	rels   map[int]*ContextRelation
+	ports   set.Strings
+	relid   int
+	remote  string
+	rels    map[int]*ContextRelation
+	params  map[string]interface{}
+	results map[string]interface{}
 }
 
 func (c *Context) UnitName() string {
@@ -132,6 +146,18 @@ func (c *Context) RelationIds() []int {
 	return ids
 }
 
+func (c *Context) ActionParams() (map[string]interface{}, bool) {
+	return c.params, c.params != nil
+}
+
+func (c *Context) SetActionResult(key string, value interface{}) error {
+	if c.params == nil {
+		return fmt.Errorf("not running an action")
+	}
+	c.results[key] = value
+	return nil
+}
+
 type ContextRelation struct {
 	id    int
 	name  string
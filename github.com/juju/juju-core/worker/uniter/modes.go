@@ -50,6 +50,9 @@ func ModeInit(u *Uniter) (next Mode, err error) {
 	if err := u.restoreRelations(); err != nil {
 		return nil, err
 	}
+	if err := u.failInterruptedActions(); err != nil {
+		return nil, err
+	}
 	return ModeContinue, nil
 }
 
@@ -273,6 +276,11 @@ func modeAbideAliveLoop(u *Uniter) (Mode, error) {
 			continue
 		case curl := <-u.f.UpgradeEvents():
 			return ModeUpgrading(curl), nil
+		case <-u.f.ActionEvents():
+			if err := u.runActions(); err != nil {
+				return nil, err
+			}
+			continue
 		}
 		if err := u.runHook(hi); err == errHookFailed {
 			return ModeHookError, nil
@@ -302,7 +310,7 @@ func modeAbideDyingLoop(u *Uniter) (next Mode, err error) {
 		if err := r.SetDying(); err != nil {
 			return nil, err
 		} else if r.IsImplicit() {
-			delete(u.relationers, id)
+			u.setRelationer(id, nil)
 		}
 	}
 	for {
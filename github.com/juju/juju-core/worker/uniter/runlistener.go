@@ -0,0 +1,125 @@
+// Copyright 2013 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// The run listener is a worker go-routine that listens on either a unix
+// socket or a tcp connection for juju-run commands.
+
+package uniter
+
+import (
+	"net"
+	"net/rpc"
+	"os"
+	"sync"
+
+	"launchpad.net/juju-core/log"
+	"launchpad.net/juju-core/utils/exec"
+)
+
+const (
+	// RunListenerFile is the name of the unix socket, within the unit
+	// agent's base directory, that juju-run connects to.
+	RunListenerFile = "run.socket"
+
+	// JujuRunEndpoint is the rpc method juju-run calls to run commands.
+	JujuRunEndpoint = "JujuRunServer.RunCommands"
+)
+
+// A CommandRunner is something that will actually execute the commands and
+// return the results of that execution in the exec.ExecResponse (which
+// contains stdout, stderr, and return code).
+type CommandRunner interface {
+	RunCommands(commands string) (results *exec.ExecResponse, err error)
+}
+
+// RunListener is responsible for listening on the network connection and
+// setting up the rpc server on that net connection. Also starts the go
+// routine that listens and hands off the work.
+type RunListener struct {
+	listener net.Listener
+	server   *rpc.Server
+	closed   chan bool
+	closing  chan bool
+	wg       sync.WaitGroup
+}
+
+// JujuRunServer implements the juju-run command in the form required by
+// net/rpc.
+type JujuRunServer struct {
+	runner CommandRunner
+}
+
+// RunCommands delegates the actual running to the runner and populates the
+// response structure.
+func (r *JujuRunServer) RunCommands(commands string, result *exec.ExecResponse) error {
+	log.Debugf("worker/uniter: run commands: %s", commands)
+	runResult, err := r.runner.RunCommands(commands)
+	if runResult != nil {
+		*result = *runResult
+	}
+	return err
+}
+
+// NewRunListener returns a new RunListener that is listening on the given
+// address of the given network type. It will not actually serve any
+// requests until Run is called.
+func NewRunListener(runner CommandRunner, netType, localAddr string) (*RunListener, error) {
+	server := rpc.NewServer()
+	if err := server.Register(&JujuRunServer{runner}); err != nil {
+		return nil, err
+	}
+	if netType == "unix" {
+		// A socket left behind by an agent that died will stop us
+		// listening, and nothing else can be using it.
+		os.Remove(localAddr)
+	}
+	listener, err := net.Listen(netType, localAddr)
+	if err != nil {
+		log.Errorf("worker/uniter: failed to listen on %s %s: %v", netType, localAddr, err)
+		return nil, err
+	}
+	runListener := &RunListener{
+		listener: listener,
+		server:   server,
+		closed:   make(chan bool),
+		closing:  make(chan bool),
+	}
+	return runListener, nil
+}
+
+// Run accepts new connections until it encounters an error, or until Close
+// is called, and then blocks until all existing connections have been
+// closed.
+func (s *RunListener) Run() (err error) {
+	var conn net.Conn
+	for {
+		conn, err = s.listener.Accept()
+		if err != nil {
+			break
+		}
+		s.wg.Add(1)
+		go func(conn net.Conn) {
+			s.server.ServeConn(conn)
+			s.wg.Done()
+		}(conn)
+	}
+	select {
+	case <-s.closing:
+		// Someone has called Close(), so it is overwhelmingly likely that
+		// the error from Accept is a direct result of the Listener being
+		// closed, and can therefore be safely ignored.
+		err = nil
+	default:
+	}
+	s.wg.Wait()
+	close(s.closed)
+	return
+}
+
+// Close immediately stops accepting connections, and blocks until all
+// existing connections have been closed.
+func (s *RunListener) Close() {
+	close(s.closing)
+	s.listener.Close()
+	<-s.closed
+}
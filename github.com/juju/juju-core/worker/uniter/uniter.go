@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"launchpad.net/tomb"
@@ -23,6 +24,7 @@ import (
 	"launchpad.net/juju-core/state"
 	"launchpad.net/juju-core/state/watcher"
 	"launchpad.net/juju-core/utils"
+	"launchpad.net/juju-core/utils/exec"
 	"launchpad.net/juju-core/utils/fslock"
 	"launchpad.net/juju-core/worker/uniter/charm"
 	"launchpad.net/juju-core/worker/uniter/hook"
@@ -44,6 +46,10 @@ type Uniter struct {
 	relationHooks chan hook.Info
 	uuid          string
 
+	// relationersMu guards changes to relationers, which may be read
+	// by RunCommands outside the uniter's own goroutine.
+	relationersMu sync.Mutex
+
 	dataDir      string
 	baseDir      string
 	toolsDir     string
@@ -55,6 +61,7 @@ type Uniter struct {
 	sf           *StateFile
 	rand         *rand.Rand
 	hookLock     *fslock.Lock
+	runListener  *RunListener
 
 	ranConfigChanged bool
 }
@@ -78,6 +85,7 @@ func (u *Uniter) loop(name string) (err error) {
 	if err = u.init(name); err != nil {
 		return err
 	}
+	defer u.runListener.Close()
 	log.Noticef("worker/uniter: unit %q started", u.unit)
 
 	// Start filtering state change events for consumption by modes.
@@ -167,6 +175,22 @@ func (u *Uniter) init(name string) (err error) {
 	u.deployer = charm.NewDeployer(filepath.Join(u.baseDir, "state", "deployer"))
 	u.sf = NewStateFile(filepath.Join(u.baseDir, "state", "uniter"))
 	u.rand = rand.New(rand.NewSource(time.Now().Unix()))
+	runListenerSocketPath := filepath.Join(u.baseDir, RunListenerFile)
+	log.Debugf("worker/uniter: starting juju-run listener on unix:%s", runListenerSocketPath)
+	u.runListener, err = NewRunListener(u, "unix", runListenerSocketPath)
+	if err != nil {
+		return err
+	}
+	// Commands sent to the socket run as root in the hook context, so
+	// only root may connect to it; juju-run is invoked through sudo.
+	if err := os.Chmod(runListenerSocketPath, 0700); err != nil {
+		return err
+	}
+	go func() {
+		if err := u.runListener.Run(); err != nil {
+			log.Errorf("worker/uniter: juju-run listener failed: %v", err)
+		}
+	}()
 	return nil
 }
 
@@ -297,7 +321,136 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 			return err
 		}
 	}
+	lockMessage := fmt.Sprintf("%s: running hook %q", u.unit.Name(), hookName)
+	if err = u.acquireHookLock(lockMessage); err != nil {
+		return err
+	}
+	defer u.hookLock.Unlock()
+
 	hctxId := fmt.Sprintf("%s:%s:%d", u.unit.Name(), hookName, u.rand.Int63())
+	hctx, err := u.getHookContext(hctxId, relationId, hi.RemoteUnit)
+	if err != nil {
+		return err
+	}
+	srv, socketPath, err := u.startJujucServer(hctx)
+	if err != nil {
+		return err
+	}
+	defer srv.Close()
+
+	// Run the hook.
+	if err := u.writeState(RunHook, Pending, &hi, nil); err != nil {
+		return err
+	}
+	log.Infof("worker/uniter: running %q hook", hookName)
+	if err := hctx.RunHook(hookName, u.charm.Path(), u.toolsDir, socketPath); err != nil {
+		log.Errorf("worker/uniter: hook failed: %s", err)
+		return errHookFailed
+	}
+	if err := u.writeState(RunHook, Done, &hi, nil); err != nil {
+		return err
+	}
+	log.Infof("worker/uniter: ran %q hook", hookName)
+	return u.commitHook(hi)
+}
+
+// runActions runs, in the order they were queued, the actions pending
+// for the unit. The failure of an action is recorded against the action
+// and does not otherwise affect the uniter.
+func (u *Uniter) runActions() error {
+	actions, err := u.unit.PendingActions()
+	if err != nil {
+		return err
+	}
+	for _, action := range actions {
+		if err := u.runAction(action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runAction executes the supplied action in an appropriate hook context,
+// and records its outcome. The action is only marked as running once the
+// hook context is ready; an action that cannot be run is failed, except
+// when the uniter is stopping, which leaves it pending.
+func (u *Uniter) runAction(action *state.Action) error {
+	// The action was validated against the unit's charm when it was
+	// queued, but the charm may have been upgraded since.
+	ch, err := corecharm.ReadDir(u.charm.Path())
+	if err != nil {
+		return action.Fail(err.Error(), nil)
+	}
+	spec, ok := ch.Actions().ActionSpecs[action.Name()]
+	if !ok {
+		return action.Fail("action not defined by charm", nil)
+	}
+	params, err := spec.ValidateParams(action.Params())
+	if err != nil {
+		return action.Fail(err.Error(), nil)
+	}
+
+	lockMessage := fmt.Sprintf("%s: running action %q", u.unit.Name(), action.Name())
+	if err = u.acquireHookLock(lockMessage); err != nil {
+		return err
+	}
+	defer u.hookLock.Unlock()
+
+	hctxId := fmt.Sprintf("%s:%s:%d", u.unit.Name(), action.Name(), u.rand.Int63())
+	hctx, err := u.getHookContext(hctxId, -1, "")
+	if err != nil {
+		return action.Fail(err.Error(), nil)
+	}
+	hctx.SetAction(action.Name(), params)
+	srv, socketPath, err := u.startJujucServer(hctx)
+	if err != nil {
+		return action.Fail(err.Error(), nil)
+	}
+	defer srv.Close()
+
+	if err := action.Begin(); err != nil {
+		// The action may have been started or failed elsewhere
+		// since it was fetched; if so, it is not ours to run.
+		if rerr := action.Refresh(); rerr != nil {
+			return rerr
+		}
+		if action.Status() != state.ActionPending {
+			log.Warningf("worker/uniter: skipping action %q: %v", action, err)
+			return nil
+		}
+		return err
+	}
+	log.Infof("worker/uniter: running action %q", action)
+	if err := hctx.RunAction(u.charm.Path(), u.toolsDir, socketPath); err != nil {
+		log.Errorf("worker/uniter: action %q failed: %s", action, err)
+		return action.Fail(err.Error(), hctx.ActionResults())
+	}
+	log.Infof("worker/uniter: ran action %q", action)
+	return action.Complete(hctx.ActionResults())
+}
+
+// failInterruptedActions marks as failed any of the unit's actions left
+// running by a previous execution of the uniter.
+func (u *Uniter) failInterruptedActions() error {
+	actions, err := u.unit.Actions()
+	if err != nil {
+		return err
+	}
+	for _, action := range actions {
+		if action.Status() != state.ActionRunning {
+			continue
+		}
+		log.Warningf("worker/uniter: action %q was interrupted", action)
+		if err := action.Fail("action interrupted", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// acquireHookLock acquires the machine-wide hook execution lock, giving up
+// if the uniter is stopped while waiting for it.
+func (u *Uniter) acquireHookLock(message string) (err error) {
 	// We want to make sure we don't block forever when locking, but take the
 	// tomb into account.
 	checkTomb := func() error {
@@ -309,54 +462,81 @@ func (u *Uniter) runHook(hi hook.Info) (err error) {
 		}
 		return nil
 	}
-	lockMessage := fmt.Sprintf("%s: running hook %q", u.unit.Name(), hookName)
-	if err = u.hookLock.LockWithFunc(lockMessage, checkTomb); err != nil {
-		return err
-	}
-	defer u.hookLock.Unlock()
+	return u.hookLock.LockWithFunc(message, checkTomb)
+}
 
+// getHookContext returns a hook context for the unit and the relations it
+// is currently a member of. relationId and remoteUnitName identify the
+// relation hook being run, if any.
+func (u *Uniter) getHookContext(hctxId string, relationId int, remoteUnitName string) (*HookContext, error) {
+	apiAddrs, err := u.st.APIAddresses()
+	if err != nil {
+		return nil, err
+	}
 	ctxRelations := map[int]*ContextRelation{}
+	u.relationersMu.Lock()
 	for id, r := range u.relationers {
 		ctxRelations[id] = r.Context()
 	}
-	apiAddrs, err := u.st.APIAddresses()
-	if err != nil {
-		return err
-	}
-	hctx := NewHookContext(u.unit, hctxId, u.uuid, relationId, hi.RemoteUnit,
-		ctxRelations, apiAddrs)
+	u.relationersMu.Unlock()
+	return NewHookContext(u.unit, hctxId, u.uuid, relationId, remoteUnitName,
+		ctxRelations, apiAddrs), nil
+}
 
-	// Prepare server.
+// startJujucServer starts a jujuc server serving hook tool invocations
+// made from within hctx, and returns it with the path of its socket.
+func (u *Uniter) startJujucServer(hctx *HookContext) (*jujuc.Server, string, error) {
 	getCmd := func(ctxId, cmdName string) (cmd.Command, error) {
 		// TODO: switch to long-running server with single context;
 		// use nonce in place of context id.
-		if ctxId != hctxId {
-			return nil, fmt.Errorf("expected context id %q, got %q", hctxId, ctxId)
+		if ctxId != hctx.id {
+			return nil, fmt.Errorf("expected context id %q, got %q", hctx.id, ctxId)
 		}
 		return jujuc.NewCommand(hctx, cmdName)
 	}
 	socketPath := filepath.Join(u.baseDir, "agent.socket")
 	srv, err := jujuc.NewServer(getCmd, socketPath)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 	go srv.Run()
-	defer srv.Close()
+	return srv, socketPath, nil
+}
 
-	// Run the hook.
-	if err := u.writeState(RunHook, Pending, &hi, nil); err != nil {
-		return err
+// RunCommands executes the supplied commands in a hook context, holding the
+// hook execution lock so they cannot run concurrently with a hook.
+func (u *Uniter) RunCommands(commands string) (results *exec.ExecResponse, err error) {
+	log.Infof("worker/uniter: running commands")
+	lockMessage := fmt.Sprintf("%s: running commands", u.unit.Name())
+	if err = u.acquireHookLock(lockMessage); err != nil {
+		return nil, err
 	}
-	log.Infof("worker/uniter: running %q hook", hookName)
-	if err := hctx.RunHook(hookName, u.charm.Path(), u.toolsDir, socketPath); err != nil {
-		log.Errorf("worker/uniter: hook failed: %s", err)
-		return errHookFailed
+	defer u.hookLock.Unlock()
+
+	hctxId := fmt.Sprintf("%s:run-commands:%d", u.unit.Name(), u.rand.Int63())
+	hctx, err := u.getHookContext(hctxId, -1, "")
+	if err != nil {
+		return nil, err
 	}
-	if err := u.writeState(RunHook, Done, &hi, nil); err != nil {
-		return err
+	srv, socketPath, err := u.startJujucServer(hctx)
+	if err != nil {
+		return nil, err
+	}
+	defer srv.Close()
+
+	return hctx.RunCommands(commands, u.charm.Path(), u.toolsDir, socketPath)
+}
+
+// setRelationer records r as the relationer for relation id, or forgets
+// the relation if r is nil.
+func (u *Uniter) setRelationer(id int, r *Relationer) {
+	u.relationersMu.Lock()
+	defer u.relationersMu.Unlock()
+	if r == nil {
+		delete(u.relationers, id)
+	} else {
+		u.relationers[id] = r
 	}
-	log.Infof("worker/uniter: ran %q hook", hookName)
-	return u.commitHook(hi)
 }
 
 // commitHook ensures that state is consistent with the supplied hook, and
@@ -368,7 +548,7 @@ func (u *Uniter) commitHook(hi hook.Info) error {
 			return err
 		}
 		if hi.Kind == hooks.RelationBroken {
-			delete(u.relationers, hi.RelationId)
+			u.setRelationer(hi.RelationId, nil)
 		}
 	}
 	if err := u.charm.Snapshotf("Completed %q hook.", hi.Kind); err != nil {
@@ -432,7 +612,7 @@ func (u *Uniter) updateRelations(ids []int) (added []*Relationer, err error) {
 				if err := r.SetDying(); err != nil {
 					return nil, err
 				} else if r.IsImplicit() {
-					delete(u.relationers, id)
+					u.setRelationer(id, nil)
 				}
 			}
 			continue
@@ -524,7 +704,7 @@ func (u *Uniter) addRelation(rel *state.Relation, dir *relation.StateDir) error
 				return err
 			}
 			log.Infof("worker/uniter: joined relation %q", rel)
-			u.relationers[rel.Id()] = r
+			u.setRelationer(rel.Id(), r)
 			return nil
 		}
 	}
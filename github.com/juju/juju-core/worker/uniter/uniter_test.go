@@ -134,6 +134,7 @@ type context struct {
 	relation      *state.Relation
 	relationUnits map[string]*state.RelationUnit
 	subordinate   *state.Unit
+	action        *state.Action
 }
 
 func (ctx *context) run(c *C, steps []stepper) {
@@ -904,6 +905,70 @@ func (s *UniterSuite) TestUniterSubordinates(c *C) {
 	s.runUniterTests(c, subordinatesTests)
 }
 
+var actionsTests = []uniterTest{
+	ut(
+		"action runs with parameters and records results",
+		createCharm{customize: writeBackupAction(`
+action-set target=$(action-get target) compress=$(action-get compress) name=$JUJU_ACTION_NAME
+`)},
+		serveCharm{},
+		createUniter{},
+		waitUnit{status: params.StatusStarted},
+		waitHooks{"install", "config-changed", "start"},
+		addAction{"backup", map[string]interface{}{"compress": true}},
+		waitAction{
+			status: state.ActionCompleted,
+			results: map[string]interface{}{
+				"target":   "/tmp",
+				"compress": "True",
+				"name":     "backup",
+			},
+		},
+		waitHooks{},
+		verifyRunning{},
+	), ut(
+		"action failure does not affect the unit",
+		createCharm{customize: writeBackupAction(`
+action-set partial=yes
+exit 1
+`)},
+		serveCharm{},
+		createUniter{},
+		waitUnit{status: params.StatusStarted},
+		waitHooks{"install", "config-changed", "start"},
+		addAction{"backup", nil},
+		waitAction{
+			status:  state.ActionFailed,
+			message: "exit status 1",
+			results: map[string]interface{}{"partial": "yes"},
+		},
+		waitUnit{status: params.StatusStarted},
+		changeConfig{"blog-title": "Goodness Gracious Me"},
+		waitHooks{"config-changed"},
+		verifyRunning{},
+	), ut(
+		"actions queued before the charm is installed run once it is started",
+		createCharm{customize: writeBackupAction(`
+action-set target=$(action-get target)
+`)},
+		serveCharm{},
+		createServiceAndUnit{},
+		addAction{"backup", map[string]interface{}{"target": "/srv"}},
+		startUniter{},
+		waitAddresses{},
+		waitUnit{status: params.StatusStarted},
+		waitHooks{"install", "config-changed", "start"},
+		waitAction{
+			status:  state.ActionCompleted,
+			results: map[string]interface{}{"target": "/srv"},
+		},
+	),
+}
+
+func (s *UniterSuite) TestUniterActions(c *C) {
+	s.runUniterTests(c, actionsTests)
+}
+
 func (s *UniterSuite) runUniterTests(c *C, uniterTests []uniterTest) {
 	for i, t := range uniterTests {
 		c.Logf("\ntest %d: %s\n", i, t.summary)
@@ -1601,6 +1666,44 @@ func (removeSubordinate) step(c *C, ctx *context) {
 	ctx.subordinate = nil
 }
 
+type addAction struct {
+	name   string
+	params map[string]interface{}
+}
+
+func (s addAction) step(c *C, ctx *context) {
+	var err error
+	ctx.action, err = ctx.unit.AddAction(s.name, s.params)
+	c.Assert(err, IsNil)
+}
+
+type waitAction struct {
+	status  state.ActionStatus
+	message string
+	results map[string]interface{}
+}
+
+func (s waitAction) step(c *C, ctx *context) {
+	timeout := time.After(worstCase)
+	for {
+		ctx.st.StartSync()
+		select {
+		case <-time.After(coretesting.ShortWait):
+			err := ctx.action.Refresh()
+			c.Assert(err, IsNil)
+			if status := ctx.action.Status(); status != s.status {
+				c.Logf("want action status %q, got %q; still waiting", s.status, status)
+				continue
+			}
+			c.Assert(ctx.action.Message(), Equals, s.message)
+			c.Assert(ctx.action.Results(), DeepEquals, s.results)
+			return
+		case <-timeout:
+			c.Fatalf("never reached desired action status")
+		}
+	}
+}
+
 type assertYaml struct {
 	path   string
 	expect map[string]interface{}
@@ -1672,6 +1775,25 @@ func curl(revision int) *charm.URL {
 	return charm.MustParseURL("cs:series/wordpress").WithRevision(revision)
 }
 
+// writeBackupAction returns a createCharm customization that declares
+// a "backup" action, implemented by the supplied script.
+func writeBackupAction(script string) func(*C, *context, string) {
+	return func(c *C, ctx *context, path string) {
+		actions := `
+backup:
+  params:
+    target: {type: string, default: /tmp}
+    compress: {type: boolean, default: false}
+`[1:]
+		err := ioutil.WriteFile(filepath.Join(path, "actions.yaml"), []byte(actions), 0644)
+		c.Assert(err, IsNil)
+		err = os.Mkdir(filepath.Join(path, "actions"), 0755)
+		c.Assert(err, IsNil)
+		err = ioutil.WriteFile(filepath.Join(path, "actions", "backup"), []byte("#!/bin/bash"+script), 0755)
+		c.Assert(err, IsNil)
+	}
+}
+
 func appendHook(c *C, charm, name, data string) {
 	path := filepath.Join(charm, "hooks", name)
 	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0755)
@@ -64,7 +64,7 @@ WaitLoop:
 
 func (s *stepConnectSSH) Cleanup(map[string]interface{}) {
 	if s.comm != nil {
-		// Close it TODO
+		s.comm.Close()
 		s.comm = nil
 	}
 }
@@ -0,0 +1,193 @@
+package common
+
+import (
+	gossh "code.google.com/p/go.crypto/ssh"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/communicator/ssh"
+	"github.com/mitchellh/packer/packer"
+	"log"
+	"time"
+)
+
+// StepConnectSSH is a multistep Step implementation that waits for SSH
+// to become available and connects to it. It gets the connection
+// information from functions so that builders can look it up from the
+// state when the step runs.
+//
+// Uses:
+//
+//	ui packer.Ui
+//
+// Produces:
+//
+//	communicator packer.Communicator
+type StepConnectSSH struct {
+	// SSHAddress is a function that returns the TCP address to connect
+	// to for SSH, such as "127.0.0.1:22". It is called before every
+	// connection attempt, and an error means SSH isn't available yet.
+	SSHAddress func(map[string]interface{}) (string, error)
+
+	// SSHConfig is a function that returns the client configuration,
+	// with the credentials, to use for the SSH connection.
+	SSHConfig func(map[string]interface{}) (*gossh.ClientConfig, error)
+
+	// SSHWaitTimeout is the total time to wait for SSH to become
+	// available.
+	SSHWaitTimeout time.Duration
+
+	// SSHHandshakeAttempts is the number of failed SSH handshakes after
+	// which the step gives up, so bad credentials fail fast. If zero,
+	// failed handshakes are retried until SSHWaitTimeout, which is needed
+	// when the SSH server rejects logins while the OS is still installing.
+	SSHHandshakeAttempts int
+
+	cancel bool
+	comm   packer.Communicator
+}
+
+func (s *StepConnectSSH) Run(state map[string]interface{}) multistep.StepAction {
+	ui := state["ui"].(packer.Ui)
+
+	var comm packer.Communicator
+	var err error
+
+	waitDone := make(chan bool, 1)
+	go func() {
+		comm, err = s.waitForSSH(state)
+		waitDone <- true
+	}()
+
+	log.Printf("Waiting for SSH, up to timeout: %s", s.SSHWaitTimeout.String())
+	timeout := time.After(s.SSHWaitTimeout)
+WaitLoop:
+	for {
+		// Wait for either SSH to become available, a timeout to occur,
+		// or an interrupt to come through.
+		select {
+		case <-waitDone:
+			if err != nil {
+				err := fmt.Errorf("Error waiting for SSH: %s", err)
+				state["error"] = err
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+
+			s.comm = comm
+			state["communicator"] = comm
+			break WaitLoop
+		case <-timeout:
+			err := errors.New("Timeout waiting for SSH.")
+			state["error"] = err
+			ui.Error(err.Error())
+			s.cancel = true
+			return multistep.ActionHalt
+		case <-time.After(1 * time.Second):
+			if _, ok := state[multistep.StateCancelled]; ok {
+				log.Println("Interrupt detected, quitting waiting for SSH.")
+				s.cancel = true
+				return multistep.ActionHalt
+			}
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepConnectSSH) Cleanup(map[string]interface{}) {
+	if s.comm != nil {
+		s.comm.Close()
+		s.comm = nil
+	}
+}
+
+// This blocks until SSH becomes available, and returns the communicator
+// connected to it.
+func (s *StepConnectSSH) waitForSSH(state map[string]interface{}) (packer.Communicator, error) {
+	ui := state["ui"].(packer.Ui)
+
+	handshakeAttempts := 0
+
+	ui.Say("Waiting for SSH to become available...")
+	var comm packer.Communicator
+	first := true
+	for {
+		// Don't sleep on the first attempt, the machine may already be up
+		if !first {
+			time.Sleep(5 * time.Second)
+		}
+		first = false
+
+		if s.cancel {
+			log.Println("SSH wait cancelled. Exiting loop.")
+			return nil, errors.New("SSH wait cancelled")
+		}
+
+		// First we get the address to connect to...
+		address, err := s.SSHAddress(state)
+		if err != nil {
+			log.Printf("Error getting SSH address: %s", err)
+			continue
+		}
+
+		// ... and the configuration to connect with
+		sshConfig, err := s.SSHConfig(state)
+		if err != nil {
+			return nil, fmt.Errorf("Error getting SSH config: %s", err)
+		}
+
+		// Attempt to connect to SSH port
+		connFunc := ssh.ConnectFunc("tcp", address, 5*time.Minute)
+		nc, err := connFunc()
+		if err != nil {
+			log.Printf("TCP connection to SSH ip/port failed: %s", err)
+			continue
+		}
+		nc.Close()
+
+		// Then we attempt to connect via SSH
+		config := &ssh.Config{
+			Connection: connFunc,
+			SSHConfig:  sshConfig,
+		}
+
+		sshConnectErr := make(chan error, 1)
+		sshComm := make(chan packer.Communicator, 1)
+		go func() {
+			c, err := ssh.New(config)
+			if err != nil {
+				sshConnectErr <- err
+				return
+			}
+
+			sshComm <- c
+		}()
+
+		// Some hypervisors accept the TCP connection before the SSH
+		// server is actually running, so the handshake can hang.
+		select {
+		case comm = <-sshComm:
+		case err := <-sshConnectErr:
+			log.Printf("SSH handshake err: %s", err)
+
+			// Try to connect via SSH again, since the server may still be
+			// starting, but don't wait for the timeout if the number of
+			// attempts is limited and the credentials are wrong.
+			handshakeAttempts += 1
+			if s.SSHHandshakeAttempts == 0 || handshakeAttempts < s.SSHHandshakeAttempts {
+				continue
+			}
+
+			return nil, err
+		case <-time.After(5 * time.Second):
+			log.Printf("SSH handshake timeout. Trying again.")
+			continue
+		}
+
+		ui.Say("Connected via SSH!")
+		break
+	}
+
+	return comm, nil
+}
@@ -135,7 +135,7 @@ ConnectWaitLoop:
 
 func (s *stepConnectSSH) Cleanup(map[string]interface{}) {
 	if s.comm != nil {
-		// TODO: close
+		s.comm.Close()
 		s.comm = nil
 	}
 }
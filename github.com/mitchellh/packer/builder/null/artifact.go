@@ -0,0 +1,32 @@
+package null
+
+import (
+	"fmt"
+)
+
+// Artifact is the result of provisioning an existing host. Nothing is
+// created, so there is nothing to destroy either.
+type Artifact struct {
+	// The host that was provisioned
+	host string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (*Artifact) Files() []string {
+	return nil
+}
+
+func (a *Artifact) Id() string {
+	return a.host
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("The existing host %s was provisioned", a.host)
+}
+
+func (*Artifact) Destroy() error {
+	return nil
+}
@@ -0,0 +1,31 @@
+package null
+
+import (
+	"github.com/mitchellh/packer/packer"
+	"testing"
+)
+
+func TestArtifact_Impl(t *testing.T) {
+	var raw interface{}
+	raw = &Artifact{}
+	if _, ok := raw.(packer.Artifact); !ok {
+		t.Fatalf("Artifact should be artifact")
+	}
+}
+
+func TestArtifact(t *testing.T) {
+	a := &Artifact{"10.0.0.2"}
+
+	if a.Id() != "10.0.0.2" {
+		t.Fatalf("bad id: %s", a.Id())
+	}
+
+	expected := "The existing host 10.0.0.2 was provisioned"
+	if a.String() != expected {
+		t.Fatalf("artifact string should match: %v", expected)
+	}
+
+	if err := a.Destroy(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
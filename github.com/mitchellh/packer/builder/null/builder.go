@@ -0,0 +1,179 @@
+// The null package contains a packer.Builder implementation that doesn't
+// create any machine: it connects to an existing host over SSH and only
+// runs the provisioners on it.
+//
+// This is useful to quickly test provisioners against a local container
+// or virtual machine, or to configure bare-metal machines with Packer.
+package null
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/builder/common"
+	"github.com/mitchellh/packer/packer"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The unique ID for this builder
+const BuilderId = "mitchellh.null"
+
+type config struct {
+	// Connection information of the existing host
+	Host              string `mapstructure:"host"`
+	Port              uint   `mapstructure:"port"`
+	SSHUsername       string `mapstructure:"ssh_username"`
+	SSHPassword       string `mapstructure:"ssh_password"`
+	SSHPrivateKeyFile string `mapstructure:"ssh_private_key_file"`
+
+	PackerDebug   bool   `mapstructure:"packer_debug"`
+	RawSSHTimeout string `mapstructure:"ssh_timeout"`
+
+	// Unexported fields that are calculated from others
+	sshPrivateKey string
+	sshTimeout    time.Duration
+}
+
+type Builder struct {
+	config config
+	runner multistep.Runner
+}
+
+func (b *Builder) Prepare(raws ...interface{}) error {
+	var md mapstructure.Metadata
+	decoderConfig := &mapstructure.DecoderConfig{
+		Metadata: &md,
+		Result:   &b.config,
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, raw := range raws {
+		err := decoder.Decode(raw)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Accumulate any errors
+	errs := make([]error, 0)
+
+	// Unused keys are errors
+	if len(md.Unused) > 0 {
+		sort.Strings(md.Unused)
+		for _, unused := range md.Unused {
+			if unused != "type" && !strings.HasPrefix(unused, "packer_") {
+				errs = append(
+					errs, fmt.Errorf("Unknown configuration key: %s", unused))
+			}
+		}
+	}
+
+	if b.config.Port == 0 {
+		b.config.Port = 22
+	}
+
+	if b.config.RawSSHTimeout == "" {
+		b.config.RawSSHTimeout = "5m"
+	}
+
+	if b.config.Host == "" {
+		errs = append(errs, errors.New("A host must be specified"))
+	}
+
+	if b.config.SSHUsername == "" {
+		errs = append(errs, errors.New("An ssh_username must be specified"))
+	}
+
+	if b.config.SSHPassword == "" && b.config.SSHPrivateKeyFile == "" {
+		errs = append(errs, errors.New("One of ssh_password or ssh_private_key_file must be specified"))
+	}
+
+	if b.config.SSHPrivateKeyFile != "" {
+		keyBytes, err := ioutil.ReadFile(b.config.SSHPrivateKeyFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed reading ssh_private_key_file: %s", err))
+		} else if block, _ := pem.Decode(keyBytes); block == nil {
+			errs = append(errs, fmt.Errorf(
+				"ssh_private_key_file is not a PEM encoded private key: %s",
+				b.config.SSHPrivateKeyFile))
+		} else {
+			b.config.sshPrivateKey = string(keyBytes)
+		}
+	}
+
+	b.config.sshTimeout, err = time.ParseDuration(b.config.RawSSHTimeout)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("Failed parsing ssh_timeout: %s", err))
+	}
+
+	if len(errs) > 0 {
+		return &packer.MultiError{errs}
+	}
+
+	log.Printf("Config: %+v", b.config)
+	return nil
+}
+
+func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packer.Artifact, error) {
+	// Setup the state bag and initial state for the steps
+	state := make(map[string]interface{})
+	state["config"] = &b.config
+	state["hook"] = hook
+	state["ui"] = ui
+
+	// Build the steps
+	steps := []multistep.Step{
+		&common.StepConnectSSH{
+			SSHAddress:           sshAddress,
+			SSHConfig:            sshConfig,
+			SSHWaitTimeout:       b.config.sshTimeout,
+			SSHHandshakeAttempts: 10,
+		},
+		new(stepProvision),
+	}
+
+	// Run!
+	if b.config.PackerDebug {
+		b.runner = &multistep.DebugRunner{
+			Steps:   steps,
+			PauseFn: common.MultistepDebugFn(ui),
+		}
+	} else {
+		b.runner = &multistep.BasicRunner{Steps: steps}
+	}
+
+	b.runner.Run(state)
+
+	// If there was an error, return that
+	if rawErr, ok := state["error"]; ok {
+		return nil, rawErr.(error)
+	}
+
+	// If we were interrupted or cancelled, then just exit.
+	if _, ok := state[multistep.StateCancelled]; ok {
+		return nil, errors.New("Build was cancelled.")
+	}
+
+	if _, ok := state[multistep.StateHalted]; ok {
+		return nil, errors.New("Build was halted.")
+	}
+
+	return &Artifact{b.config.Host}, nil
+}
+
+func (b *Builder) Cancel() {
+	if b.runner != nil {
+		log.Println("Cancelling the step runner...")
+		b.runner.Cancel()
+	}
+}
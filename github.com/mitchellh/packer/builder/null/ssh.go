@@ -0,0 +1,38 @@
+package null
+
+import (
+	gossh "code.google.com/p/go.crypto/ssh"
+	"fmt"
+	"github.com/mitchellh/packer/communicator/ssh"
+)
+
+func sshAddress(state map[string]interface{}) (string, error) {
+	config := state["config"].(*config)
+	return fmt.Sprintf("%s:%d", config.Host, config.Port), nil
+}
+
+func sshConfig(state map[string]interface{}) (*gossh.ClientConfig, error) {
+	config := state["config"].(*config)
+
+	auth := make([]gossh.ClientAuth, 0, 3)
+	if config.sshPrivateKey != "" {
+		keyring := new(ssh.SimpleKeychain)
+		if err := keyring.AddPEMKey(config.sshPrivateKey); err != nil {
+			return nil, fmt.Errorf("Error setting up SSH config: %s", err)
+		}
+
+		auth = append(auth, gossh.ClientAuthKeyring(keyring))
+	}
+
+	if config.SSHPassword != "" {
+		auth = append(auth,
+			gossh.ClientAuthPassword(ssh.Password(config.SSHPassword)),
+			gossh.ClientAuthKeyboardInteractive(
+				ssh.PasswordKeyboardInteractive(config.SSHPassword)))
+	}
+
+	return &gossh.ClientConfig{
+		User: config.SSHUsername,
+		Auth: auth,
+	}, nil
+}
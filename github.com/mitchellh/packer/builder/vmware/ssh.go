@@ -0,0 +1,69 @@
+package vmware
+
+import (
+	gossh "code.google.com/p/go.crypto/ssh"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/packer/communicator/ssh"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+func sshAddress(state map[string]interface{}) (string, error) {
+	config := state["config"].(*config)
+	vmxPath := state["vmx_path"].(string)
+
+	log.Println("Lookup up IP information...")
+	ipLookup, err := dhcpLeaseLookup(vmxPath)
+	if err != nil {
+		return "", fmt.Errorf("Can't lookup via DHCP lease: %s", err)
+	}
+
+	ip, err := ipLookup.GuestIP()
+	if err != nil {
+		return "", fmt.Errorf("IP lookup failed: %s", err)
+	}
+
+	log.Printf("Detected IP: %s", ip)
+	return fmt.Sprintf("%s:%d", ip, config.SSHPort), nil
+}
+
+func sshConfig(state map[string]interface{}) (*gossh.ClientConfig, error) {
+	config := state["config"].(*config)
+
+	return &gossh.ClientConfig{
+		User: config.SSHUser,
+		Auth: []gossh.ClientAuth{
+			gossh.ClientAuthPassword(ssh.Password(config.SSHPassword)),
+			gossh.ClientAuthKeyboardInteractive(
+				ssh.PasswordKeyboardInteractive(config.SSHPassword)),
+		},
+	}, nil
+}
+
+// Reads the network information for lookup via DHCP.
+func dhcpLeaseLookup(vmxPath string) (GuestIPFinder, error) {
+	f, err := os.Open(vmxPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vmxBytes, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	vmxData := ParseVMX(string(vmxBytes))
+
+	var ok bool
+	macAddress := ""
+	if macAddress, ok = vmxData["ethernet0.address"]; !ok || macAddress == "" {
+		if macAddress, ok = vmxData["ethernet0.generatedAddress"]; !ok || macAddress == "" {
+			return nil, errors.New("couldn't find MAC address in VMX")
+		}
+	}
+
+	return &DHCPLeaseGuestLookup{"vmnet8", macAddress}, nil
+}
@@ -9,6 +9,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -22,15 +23,22 @@ func (Command) Help() string {
 func (c Command) Run(env packer.Environment, args []string) int {
 	var cfgDebug bool
 	var cfgForce bool
+	var cfgMachineReadable bool
+	var cfgParallel int
 	var cfgExcept []string
 	var cfgOnly []string
+	userVars := make(map[string]string)
 
 	cmdFlags := flag.NewFlagSet("build", flag.ContinueOnError)
 	cmdFlags.Usage = func() { env.Ui().Say(c.Help()) }
 	cmdFlags.BoolVar(&cfgDebug, "debug", false, "debug mode for builds")
 	cmdFlags.BoolVar(&cfgForce, "force", false, "force a build if artifacts exist")
+	cmdFlags.BoolVar(&cfgMachineReadable, "machine-readable", false, "machine-readable output")
+	cmdFlags.IntVar(&cfgParallel, "parallel", 0, "maximum number of builds run in parallel")
 	cmdFlags.Var((*stringSliceValue)(&cfgExcept), "except", "build all builds except these")
 	cmdFlags.Var((*stringSliceValue)(&cfgOnly), "only", "only build the given builds by name")
+	cmdFlags.Var(packer.UserVariablesFlag(userVars), "var", "specify a user variable")
+	cmdFlags.Var(packer.UserVariablesFileFlag(userVars), "var-file", "read user variables from a JSON file")
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
@@ -41,9 +49,21 @@ func (c Command) Run(env packer.Environment, args []string) int {
 		return 1
 	}
 
+	// All the output goes through the machine-readable UI if asked to
+	ui := env.Ui()
+	if cfgMachineReadable {
+		ui = &packer.MachineReadableUi{Ui: env.Ui()}
+	}
+
 	if len(cfgOnly) > 0 && len(cfgExcept) > 0 {
-		env.Ui().Error("Only one of '-except' or '-only' may be specified.\n")
-		env.Ui().Error(c.Help())
+		ui.Error("Only one of '-except' or '-only' may be specified.\n")
+		ui.Error(c.Help())
+		return 1
+	}
+
+	if cfgParallel < 0 {
+		ui.Error("The '-parallel' limit can't be negative.\n")
+		ui.Error(c.Help())
 		return 1
 	}
 
@@ -51,7 +71,7 @@ func (c Command) Run(env packer.Environment, args []string) int {
 	log.Printf("Reading template: %s", args[0])
 	tplData, err := ioutil.ReadFile(args[0])
 	if err != nil {
-		env.Ui().Error(fmt.Sprintf("Failed to read template file: %s", err))
+		ui.Error(fmt.Sprintf("Failed to read template file: %s", err))
 		return 1
 	}
 
@@ -59,7 +79,13 @@ func (c Command) Run(env packer.Environment, args []string) int {
 	log.Println("Parsing template...")
 	tpl, err := packer.ParseTemplate(tplData)
 	if err != nil {
-		env.Ui().Error(fmt.Sprintf("Failed to parse template: %s", err))
+		ui.Error(fmt.Sprintf("Failed to parse template: %s", err))
+		return 1
+	}
+
+	// Set the user variables given on the command line
+	if err := tpl.SetUserVariables(userVars); err != nil {
+		ui.Error(err.Error())
 		return 1
 	}
 
@@ -108,7 +134,7 @@ func (c Command) Run(env packer.Environment, args []string) int {
 		log.Printf("Creating build: %s", buildName)
 		build, err := tpl.Build(buildName, components)
 		if err != nil {
-			env.Ui().Error(fmt.Sprintf("Failed to create build '%s': \n\n%s", buildName, err))
+			ui.Error(fmt.Sprintf("Failed to create build '%s': \n\n%s", buildName, err))
 			return 1
 		}
 
@@ -116,7 +142,7 @@ func (c Command) Run(env packer.Environment, args []string) int {
 	}
 
 	if cfgDebug {
-		env.Ui().Say("Debug mode enabled. Builds will not be parallelized.")
+		ui.Say("Debug mode enabled. Builds will not be parallelized.")
 	}
 
 	// Compile all the UIs for the builds
@@ -130,20 +156,34 @@ func (c Command) Run(env packer.Environment, args []string) int {
 
 	buildUis := make(map[string]packer.Ui)
 	for i, b := range builds {
-		ui := &packer.ColoredUi{
+		if cfgMachineReadable {
+			buildUi := &packer.MachineReadableUi{
+				Target: b.Name(),
+				Ui:     env.Ui(),
+			}
+
+			buildUis[b.Name()] = buildUi
+			buildUi.Machine("type", tpl.Builders[b.Name()].Type)
+			continue
+		}
+
+		buildUi := &packer.ColoredUi{
 			Color: colors[i%len(colors)],
 			Ui:    env.Ui(),
 		}
 
-		buildUis[b.Name()] = ui
-		ui.Say(fmt.Sprintf("%s output will be in this color.", b.Name()))
+		buildUis[b.Name()] = buildUi
+		buildUi.Say(fmt.Sprintf("%s output will be in this color.", b.Name()))
 	}
 
 	// Add a newline between the color output and the actual output
-	env.Ui().Say("")
+	if !cfgMachineReadable {
+		ui.Say("")
+	}
 
 	log.Printf("Build debug mode: %v", cfgDebug)
 	log.Printf("Force build: %v", cfgForce)
+	log.Printf("Parallel builds limit: %d", cfgParallel)
 
 	// Set the debug and force mode and prepare all the builds
 	for _, b := range builds {
@@ -152,17 +192,34 @@ func (c Command) Run(env packer.Environment, args []string) int {
 		b.SetForce(cfgForce)
 		err := b.Prepare()
 		if err != nil {
-			env.Ui().Error(err.Error())
+			ui.Error(err.Error())
 			return 1
 		}
 	}
 
+	// Limit the number of builds running at the same time, if asked to,
+	// with a semaphore of the size of the limit.
+	var parallelSem chan bool
+	if cfgParallel > 0 {
+		parallelSem = make(chan bool, cfgParallel)
+	}
+
 	// Run all the builds in parallel and wait for them to complete
 	var interruptWg, wg sync.WaitGroup
 	interrupted := false
 	artifacts := make(map[string][]packer.Artifact)
 	errors := make(map[string]error)
 	for _, b := range builds {
+		if parallelSem != nil {
+			log.Printf("Waiting for a slot to run build: %s", b.Name())
+			parallelSem <- true
+
+			if interrupted {
+				log.Println("Interrupted, not going to start any more builds.")
+				break
+			}
+		}
+
 		// Increment the waitgroup so we wait for this item to finish properly
 		wg.Add(1)
 
@@ -184,6 +241,9 @@ func (c Command) Run(env packer.Environment, args []string) int {
 		// Run the build in a goroutine
 		go func(b packer.Build) {
 			defer wg.Done()
+			if parallelSem != nil {
+				defer func() { <-parallelSem }()
+			}
 
 			name := b.Name()
 			log.Printf("Starting build run: %s", name)
@@ -219,19 +279,24 @@ func (c Command) Run(env packer.Environment, args []string) int {
 	interruptWg.Wait()
 
 	if interrupted {
-		env.Ui().Say("Cleanly cancelled builds after being interrupted.")
+		ui.Say("Cleanly cancelled builds after being interrupted.")
 		return 1
 	}
 
+	if cfgMachineReadable {
+		machineReadableResults(env.Ui(), builds, artifacts, errors)
+		return 0
+	}
+
 	if len(errors) > 0 {
-		env.Ui().Error("\n==> Some builds didn't complete successfully and had errors:")
+		ui.Error("\n==> Some builds didn't complete successfully and had errors:")
 		for name, err := range errors {
-			env.Ui().Error(fmt.Sprintf("--> %s: %s", name, err))
+			ui.Error(fmt.Sprintf("--> %s: %s", name, err))
 		}
 	}
 
 	if len(artifacts) > 0 {
-		env.Ui().Say("\n==> Builds finished. The artifacts of successful builds are:")
+		ui.Say("\n==> Builds finished. The artifacts of successful builds are:")
 		for name, buildArtifacts := range artifacts {
 			for _, artifact := range buildArtifacts {
 				var message bytes.Buffer
@@ -243,16 +308,57 @@ func (c Command) Run(env packer.Environment, args []string) int {
 					fmt.Fprint(&message, "<nothing>")
 				}
 
-				env.Ui().Say(message.String())
+				ui.Say(message.String())
 			}
 		}
 	} else {
-		env.Ui().Say("\n==> Builds finished but no artifacts were created.")
+		ui.Say("\n==> Builds finished but no artifacts were created.")
 	}
 
 	return 0
 }
 
+// machineReadableResults outputs the errors and artifacts of the builds as
+// machine-readable records.
+func machineReadableResults(ui packer.Ui, builds []packer.Build, artifacts map[string][]packer.Artifact, errors map[string]error) {
+	for _, b := range builds {
+		name := b.Name()
+		machineUi := &packer.MachineReadableUi{Target: name, Ui: ui}
+
+		if err, ok := errors[name]; ok {
+			machineUi.Machine("error", err.Error())
+			continue
+		}
+
+		buildArtifacts, ok := artifacts[name]
+		if !ok {
+			continue
+		}
+
+		machineUi.Machine("artifact-count", strconv.Itoa(len(buildArtifacts)))
+		for i, artifact := range buildArtifacts {
+			index := strconv.Itoa(i)
+			if artifact == nil {
+				machineUi.Machine("artifact", index, "nil")
+				machineUi.Machine("artifact", index, "end")
+				continue
+			}
+
+			machineUi.Machine("artifact", index, "builder-id", artifact.BuilderId())
+			machineUi.Machine("artifact", index, "id", artifact.Id())
+			machineUi.Machine("artifact", index, "string", artifact.String())
+
+			files := artifact.Files()
+			machineUi.Machine("artifact", index, "files-count", strconv.Itoa(len(files)))
+			for j, file := range files {
+				machineUi.Machine("artifact", index, "file", strconv.Itoa(j), file)
+			}
+
+			machineUi.Machine("artifact", index, "end")
+		}
+	}
+}
+
 func (Command) Synopsis() string {
 	return "build image(s) from template"
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"cgl.tideland.biz/asserts"
 	"github.com/mitchellh/packer/packer"
+	"strings"
 	"testing"
 )
 
@@ -53,3 +54,26 @@ func TestCommand_Run_MissingFile(t *testing.T) {
 	result := command.Run(testEnvironment(), args)
 	assert.Equal(result, 1, "a non-existent file should error")
 }
+
+func TestCommand_Run_MachineReadable(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+	command := new(Command)
+
+	env := testEnvironment()
+	args := []string{"-machine-readable", "i-better-not-exist"}
+	result := command.Run(env, args)
+	assert.Equal(result, 1, "a non-existent file should error")
+
+	output := env.Ui().(*packer.ReaderWriterUi).Writer.(*bytes.Buffer).String()
+	assert.True(strings.Contains(output, ",,ui,error,Failed to read template file"),
+		"should output a machine-readable error")
+}
+
+func TestCommand_Run_NegativeParallel(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+	command := new(Command)
+
+	args := []string{"-parallel=-1", "i-better-not-exist"}
+	result := command.Run(testEnvironment(), args)
+	assert.Equal(result, 1, "a negative parallel limit should error")
+}
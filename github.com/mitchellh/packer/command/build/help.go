@@ -12,4 +12,8 @@ Options:
   -force                     Force a build to continue if artifacts exist, deletes existing artifacts
   -except=foo,bar,baz        Build all builds other than these
   -only=foo,bar,baz          Only build the given builds by name
+  -machine-readable          Output machine-readable records instead of messages
+  -parallel=N                Run at most N builds in parallel, 0 for no limit
+  -var 'key=value'           Variable for templates, can be used multiple times.
+  -var-file=path             JSON file containing user variables.
 `
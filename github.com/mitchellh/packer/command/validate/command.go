@@ -17,10 +17,13 @@ func (Command) Help() string {
 
 func (c Command) Run(env packer.Environment, args []string) int {
 	var cfgSyntaxOnly bool
+	userVars := make(map[string]string)
 
 	cmdFlags := flag.NewFlagSet("validate", flag.ContinueOnError)
 	cmdFlags.Usage = func() { env.Ui().Say(c.Help()) }
 	cmdFlags.BoolVar(&cfgSyntaxOnly, "syntax-only", false, "check syntax only")
+	cmdFlags.Var(packer.UserVariablesFlag(userVars), "var", "specify a user variable")
+	cmdFlags.Var(packer.UserVariablesFileFlag(userVars), "var-file", "read user variables from a JSON file")
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
@@ -47,6 +50,12 @@ func (c Command) Run(env packer.Environment, args []string) int {
 		return 1
 	}
 
+	// Set the user variables given on the command line
+	if err := tpl.SetUserVariables(userVars); err != nil {
+		env.Ui().Error(err.Error())
+		return 1
+	}
+
 	if cfgSyntaxOnly {
 		env.Ui().Say("Syntax-only check passed. Everything looks okay.")
 		return 0
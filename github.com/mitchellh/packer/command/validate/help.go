@@ -13,4 +13,6 @@ Usage: packer validate [options] TEMPLATE
 Options:
 
   -syntax-only        Only check syntax. Do not verify config of the template.
+  -var 'key=value'    Variable for templates, can be used multiple times.
+  -var-file=path      JSON file containing user variables.
 `
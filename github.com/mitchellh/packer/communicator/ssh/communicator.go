@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"bufio"
 	"bytes"
 	"code.google.com/p/go.crypto/ssh"
 	"errors"
@@ -10,12 +11,28 @@ import (
 	"log"
 	"net"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The defaults used to re-establish a dropped connection if they're not
+// set in the configuration.
+const (
+	defaultReconnectAttempts = 10
+	defaultReconnectDelay    = 5 * time.Second
 )
 
 type comm struct {
 	client *ssh.ClientConn
 	config *Config
 	conn   net.Conn
+
+	// l protects the connection, which can be re-established by
+	// concurrent calls.
+	l      sync.Mutex
+	closed bool
 }
 
 // Config is the structure used to configure the SSH communicator.
@@ -27,6 +44,13 @@ type Config struct {
 	// in use will be closed as part of the Close method, or in the
 	// case an error occurs.
 	Connection func() (net.Conn, error)
+
+	// ReconnectAttempts is the number of times to try re-establishing
+	// the connection when it is dropped, for example while the machine
+	// reboots, waiting ReconnectDelay between each attempt. Defaults are
+	// used if these are zero.
+	ReconnectAttempts int
+	ReconnectDelay    time.Duration
 }
 
 // Creates a new packer.Communicator implementation over SSH. This takes
@@ -159,53 +183,176 @@ func (c *comm) Upload(path string, input io.Reader) error {
 	// Wait for the SCP connection to close, meaning it has consumed all
 	// our data and has completed. Or has errored.
 	log.Println("Waiting for SSH session to complete")
-	err = session.Wait()
+	if err = session.Wait(); err != nil {
+		return scpError(err)
+	}
+
+	log.Printf("scp stdout (length %d): %#v", stdout.Len(), stdout.Bytes())
+	log.Printf("scp stderr (length %d): %s", stderr.Len(), stderr.String())
+
+	return nil
+}
+
+func (c *comm) Download(path string, output io.Writer) error {
+	session, err := c.newSession()
 	if err != nil {
-		if exitErr, ok := err.(*ssh.ExitError); ok {
-			// Otherwise, we have an ExitErorr, meaning we can just read
-			// the exit status
-			log.Printf("non-zero exit status: %d", exitErr.ExitStatus())
-
-			// If we exited with status 127, it means SCP isn't available.
-			// Return a more descriptive error for that.
-			if exitErr.ExitStatus() == 127 {
-				return errors.New(
-					"SCP failed to start. This usually means that SCP is not\n" +
-						"properly installed on the remote system.")
+		return err
+	}
+
+	defer session.Close()
+
+	w, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	defer w.Close()
+
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr := new(bytes.Buffer)
+	session.Stderr = stderr
+
+	// Start the source mode on the other side
+	// TODO(mitchellh): There are probably issues with shell escaping the path
+	log.Println("Starting remote scp process in source mode")
+	if err = session.Start("scp -vf " + path); err != nil {
+		return err
+	}
+
+	// Every message sent by the remote side must be confirmed with
+	// a null byte, starting with the first one.
+	reader := bufio.NewReader(r)
+	fmt.Fprint(w, "\x00")
+
+	size, err := scpReadFileHeader(reader)
+	if err != nil {
+		// If the remote side went away, the exit status is more
+		// descriptive than the read error.
+		if err == io.EOF {
+			if waitErr := session.Wait(); waitErr != nil {
+				log.Printf("scp stderr (length %d): %s", stderr.Len(), stderr.String())
+				return scpError(waitErr)
 			}
 		}
 
 		return err
 	}
 
-	log.Printf("scp stdout (length %d): %#v", stdout.Len(), stdout.Bytes())
+	log.Printf("Beginning file download (%d bytes)...", size)
+	fmt.Fprint(w, "\x00")
+	if _, err = io.CopyN(output, reader, size); err != nil {
+		return err
+	}
+
+	// The file contents are followed by a status of the transfer
+	if err = scpReadStatus(reader); err != nil {
+		return err
+	}
+
+	fmt.Fprint(w, "\x00")
+
+	// Unlike an upload, we don't need to wait for the scp process to
+	// exit: the confirmed file has been completely received.
+	log.Println("Download complete")
 	log.Printf("scp stderr (length %d): %s", stderr.Len(), stderr.String())
 
 	return nil
 }
 
-func (c *comm) Download(string, io.Writer) error {
-	panic("not implemented yet")
+// Close closes the SSH connection. The communicator can't be used
+// anymore afterwards.
+func (c *comm) Close() error {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.closed = true
+
+	var err error
+	if c.client != nil {
+		err = c.client.Close()
+		c.client = nil
+	}
+
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+
+	return err
 }
 
+// newSession opens a new session, transparently re-establishing the
+// connection if it was dropped.
 func (c *comm) newSession() (*ssh.Session, error) {
-	log.Println("opening new ssh session")
-	session, err := c.client.NewSession()
-	if err != nil {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if c.closed {
+		return nil, errors.New("the SSH communicator is closed")
+	}
+
+	if c.client != nil {
+		log.Println("opening new ssh session")
+		session, err := c.client.NewSession()
+		if err == nil {
+			return session, nil
+		}
+
 		log.Printf("ssh session open error: '%s', attempting reconnect", err)
-		if err := c.reconnect(); err != nil {
-			return nil, err
+	}
+
+	if err := c.reconnectWithRetry(); err != nil {
+		return nil, err
+	}
+
+	return c.client.NewSession()
+}
+
+// reconnectWithRetry re-establishes the connection, retrying a number of
+// times since the machine may be unavailable for a while, while rebooting
+// for example. The caller must hold the lock.
+func (c *comm) reconnectWithRetry() (err error) {
+	attempts := c.config.ReconnectAttempts
+	if attempts <= 0 {
+		attempts = defaultReconnectAttempts
+	}
+
+	delay := c.config.ReconnectDelay
+	if delay <= 0 {
+		delay = defaultReconnectDelay
+	}
+
+	for i := 1; i <= attempts; i++ {
+		if err = c.reconnect(); err == nil {
+			return
 		}
 
-		return c.client.NewSession()
+		log.Printf("reconnect attempt %d/%d failed: %s", i, attempts, err)
+		if i < attempts {
+			time.Sleep(delay)
+		}
 	}
 
-	return session, nil
+	return fmt.Errorf("error re-establishing SSH connection: %s", err)
 }
 
 func (c *comm) reconnect() (err error) {
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+
 	if c.conn != nil {
 		c.conn.Close()
+		c.conn = nil
 	}
 
 	log.Printf("reconnecting to TCP connection for SSH")
@@ -219,7 +366,79 @@ func (c *comm) reconnect() (err error) {
 	c.client, err = ssh.Client(c.conn, c.config.SSHConfig)
 	if err != nil {
 		log.Printf("handshake error: %s", err)
+		c.conn.Close()
+		c.conn = nil
+		c.client = nil
 	}
 
 	return
 }
+
+// scpReadFileHeader reads the header sent by scp in source mode before
+// the contents of a file, "C<mode> <size> <name>", and returns the size.
+func scpReadFileHeader(r *bufio.Reader) (int64, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return 0, err
+	}
+
+	switch line[0] {
+	case '\x01', '\x02':
+		return 0, fmt.Errorf("scp error: %s", strings.TrimSpace(line[1:]))
+	case 'C':
+	default:
+		return 0, fmt.Errorf("unexpected scp message: %q", line)
+	}
+
+	parts := strings.SplitN(strings.TrimSpace(line[1:]), " ", 3)
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid scp file header: %q", line)
+	}
+
+	size, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid scp file size: %s", parts[1])
+	}
+
+	return size, nil
+}
+
+// scpReadStatus reads a status byte sent by scp, followed by a message
+// if it is a warning (1) or an error (2).
+func scpReadStatus(r *bufio.Reader) error {
+	status, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	if status == 0 {
+		return nil
+	}
+
+	message, err := r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("scp error status: %d", status)
+	}
+
+	return fmt.Errorf("scp error: %s", strings.TrimSpace(message))
+}
+
+// scpError turns the error of a finished scp session into a more
+// descriptive error if possible.
+func scpError(err error) error {
+	if exitErr, ok := err.(*ssh.ExitError); ok {
+		// Otherwise, we have an ExitErorr, meaning we can just read
+		// the exit status
+		log.Printf("non-zero exit status: %d", exitErr.ExitStatus())
+
+		// If we exited with status 127, it means SCP isn't available.
+		// Return a more descriptive error for that.
+		if exitErr.ExitStatus() == 127 {
+			return errors.New(
+				"SCP failed to start. This usually means that SCP is not\n" +
+					"properly installed on the remote system.")
+		}
+	}
+
+	return err
+}
@@ -3,9 +3,12 @@ package ssh
 import (
 	"bytes"
 	"code.google.com/p/go.crypto/ssh"
+	"errors"
 	"github.com/mitchellh/packer/packer"
 	"net"
+	"strconv"
 	"testing"
+	"time"
 )
 
 // private key for mock server
@@ -99,6 +102,137 @@ func newMockLineServer(t *testing.T) string {
 	return l.Addr().String()
 }
 
+// newMockExecServer starts an SSH server accepting any number of
+// connections, and calling the handler with the command of each exec
+// request and the channel of its session.
+func newMockExecServer(t *testing.T, handler func(string, ssh.Channel)) string {
+	l, err := ssh.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		t.Fatalf("unable to newMockExecServer: %s", err)
+	}
+
+	go func() {
+		defer l.Close()
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveMockConn(t, c, handler)
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func serveMockConn(t *testing.T, c *ssh.ServerConn, handler func(string, ssh.Channel)) {
+	defer c.Close()
+
+	if err := c.Handshake(); err != nil {
+		t.Logf("Handshaking error: %v", err)
+		return
+	}
+
+	for {
+		channel, err := c.Accept()
+		if err != nil {
+			return
+		}
+
+		go serveMockChannel(channel, handler)
+	}
+}
+
+func serveMockChannel(channel ssh.Channel, handler func(string, ssh.Channel)) {
+	defer channel.Close()
+
+	if err := channel.Accept(); err != nil {
+		return
+	}
+
+	// Requests, such as the PTY or exec ones, are returned as errors
+	// by Read until the command is executed.
+	buf := make([]byte, 1024)
+	for {
+		_, err := channel.Read(buf)
+		req, ok := err.(ssh.ChannelRequest)
+		if !ok {
+			return
+		}
+
+		if req.WantReply {
+			channel.AckRequest(true)
+		}
+
+		if req.Request == "exec" {
+			// The payload is the length-prefixed command
+			handler(string(req.Payload[4:]), channel)
+			return
+		}
+	}
+}
+
+// mockSCPSource plays the remote side of scp in source mode, sending
+// the given contents as a file.
+func mockSCPSource(t *testing.T, channel ssh.Channel, contents string) {
+	ack := make([]byte, 1)
+	readAck := func() bool {
+		if _, err := channel.Read(ack); err != nil || ack[0] != 0 {
+			t.Errorf("bad scp ack: %v %v", ack, err)
+			return false
+		}
+
+		return true
+	}
+
+	if !readAck() {
+		return
+	}
+
+	channel.Write([]byte("C0644 " + strconv.Itoa(len(contents)) + " foo\n"))
+	if !readAck() {
+		return
+	}
+
+	channel.Write([]byte(contents))
+	channel.Write([]byte{0})
+	readAck()
+}
+
+func testClientConfig() *ssh.ClientConfig {
+	return &ssh.ClientConfig{
+		User: "user",
+		Auth: []ssh.ClientAuth{
+			ssh.ClientAuthPassword(password("pass")),
+		},
+	}
+}
+
+// testComm returns a communicator connected to the given address, along
+// with the number of times it dialed so far. The first failDials dials
+// after the initial connection fail.
+func testComm(t *testing.T, address string, config *Config, failDials int) (*comm, *int) {
+	dials := 0
+	config.SSHConfig = testClientConfig()
+	config.Connection = func() (net.Conn, error) {
+		dials++
+		if dials > 1 && failDials > 0 {
+			failDials--
+			return nil, errors.New("connection refused")
+		}
+
+		return net.Dial("tcp", address)
+	}
+
+	client, err := New(config)
+	if err != nil {
+		t.Fatalf("error connecting to SSH: %s", err)
+	}
+
+	return client, &dials
+}
+
 func TestCommIsCommunicator(t *testing.T) {
 	var raw interface{}
 	raw = &comm{}
@@ -167,3 +301,145 @@ func TestStart(t *testing.T) {
 
 	client.Start(&cmd)
 }
+
+func TestStart_Reconnect(t *testing.T) {
+	address := newMockExecServer(t, func(string, ssh.Channel) {})
+	config := &Config{ReconnectDelay: 10 * time.Millisecond}
+	client, dials := testComm(t, address, config, 2)
+	defer client.Close()
+
+	// Drop the connection, as if the machine rebooted
+	client.conn.Close()
+
+	var cmd packer.RemoteCmd
+	cmd.Command = "true"
+	if err := client.Start(&cmd); err != nil {
+		t.Fatalf("should reconnect: %s", err)
+	}
+
+	// The initial connection, two failed attempts and a successful one
+	if *dials != 4 {
+		t.Fatalf("bad number of dials: %d", *dials)
+	}
+
+	cmd.Wait()
+}
+
+func TestStart_ReconnectGivesUp(t *testing.T) {
+	address := newMockExecServer(t, func(string, ssh.Channel) {})
+	config := &Config{
+		ReconnectAttempts: 3,
+		ReconnectDelay:    10 * time.Millisecond,
+	}
+	client, dials := testComm(t, address, config, 10)
+	defer client.Close()
+
+	client.conn.Close()
+
+	var cmd packer.RemoteCmd
+	cmd.Command = "true"
+	if err := client.Start(&cmd); err == nil {
+		t.Fatal("should error")
+	}
+
+	if *dials != 4 {
+		t.Fatalf("bad number of dials: %d", *dials)
+	}
+}
+
+func TestClose(t *testing.T) {
+	address := newMockExecServer(t, func(string, ssh.Channel) {})
+	client, dials := testComm(t, address, &Config{}, 0)
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var cmd packer.RemoteCmd
+	cmd.Command = "true"
+	if err := client.Start(&cmd); err == nil {
+		t.Fatal("should error once closed")
+	}
+
+	if err := client.Upload("/tmp/foo", new(bytes.Buffer)); err == nil {
+		t.Fatal("should error once closed")
+	}
+
+	// Closing doesn't reconnect
+	if *dials != 1 {
+		t.Fatalf("bad number of dials: %d", *dials)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("closing twice should be fine: %s", err)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	commands := make(chan string, 1)
+	address := newMockExecServer(t, func(command string, channel ssh.Channel) {
+		commands <- command
+		mockSCPSource(t, channel, "hello, world\n")
+	})
+
+	client, _ := testComm(t, address, &Config{}, 0)
+	defer client.Close()
+
+	output := new(bytes.Buffer)
+	if err := client.Download("/tmp/foo", output); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if command := <-commands; command != "scp -vf /tmp/foo" {
+		t.Fatalf("bad command: %s", command)
+	}
+
+	if output.String() != "hello, world\n" {
+		t.Fatalf("bad contents: %#v", output.String())
+	}
+}
+
+func TestDownload_Reconnect(t *testing.T) {
+	address := newMockExecServer(t, func(command string, channel ssh.Channel) {
+		mockSCPSource(t, channel, "foo")
+	})
+
+	config := &Config{ReconnectDelay: 10 * time.Millisecond}
+	client, dials := testComm(t, address, config, 0)
+	defer client.Close()
+
+	client.conn.Close()
+
+	output := new(bytes.Buffer)
+	if err := client.Download("/tmp/foo", output); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if output.String() != "foo" {
+		t.Fatalf("bad contents: %#v", output.String())
+	}
+
+	if *dials != 2 {
+		t.Fatalf("bad number of dials: %d", *dials)
+	}
+}
+
+func TestDownload_RemoteError(t *testing.T) {
+	address := newMockExecServer(t, func(command string, channel ssh.Channel) {
+		ack := make([]byte, 1)
+		channel.Read(ack)
+		channel.Write([]byte("\x01scp: /tmp/foo: No such file or directory\n"))
+	})
+
+	client, _ := testComm(t, address, &Config{}, 0)
+	defer client.Close()
+
+	err := client.Download("/tmp/foo", new(bytes.Buffer))
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	if err.Error() != "scp error: scp: /tmp/foo: No such file or directory" {
+		t.Fatalf("bad error: %s", err)
+	}
+}
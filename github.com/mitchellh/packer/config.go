@@ -21,6 +21,7 @@ const defaultConfig = `
 	"builders": {
 		"amazon-ebs": "packer-builder-amazon-ebs",
 		"digitalocean": "packer-builder-digitalocean",
+		"null": "packer-builder-null",
 		"virtualbox": "packer-builder-virtualbox",
 		"vmware": "packer-builder-vmware"
 	},
@@ -32,6 +33,8 @@ const defaultConfig = `
 	},
 
 	"post-processors": {
+		"checksum": "packer-post-processor-checksum",
+		"compress": "packer-post-processor-compress",
 		"vagrant": "packer-post-processor-vagrant"
 	},
 
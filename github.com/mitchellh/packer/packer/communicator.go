@@ -55,6 +55,11 @@ type Communicator interface {
 	// with the contents writing to the given writer. This method will
 	// block until it completes.
 	Download(string, io.Writer) error
+
+	// Close closes the connection to the machine. It is called by the
+	// builder once the machine doesn't need to be communicated with
+	// anymore, provisioners should never call it.
+	Close() error
 }
 
 // Wait waits for the remote command to complete.
@@ -0,0 +1,190 @@
+package packer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// ConfigTemplate processes the template functions that can be used in any
+// string of the builder, provisioner and post-processor configurations:
+//
+//	{{user `name`}}  the value of the user variable "name"
+//	{{timestamp}}    the UNIX timestamp of this Packer run, the same for all builds
+//	{{uuid}}         a new random UUID
+//
+// Actions referring to data, such as "{{.CreateTime}}" or "{{ .HTTPIP }}", are
+// left untouched since they're processed later by the builders themselves.
+type ConfigTemplate struct {
+	UserVars map[string]string
+
+	timestamp time.Time
+}
+
+// NewConfigTemplate creates a ConfigTemplate with the given user variables.
+func NewConfigTemplate(userVars map[string]string) *ConfigTemplate {
+	return &ConfigTemplate{
+		UserVars:  userVars,
+		timestamp: time.Now().UTC(),
+	}
+}
+
+// Process processes a single string.
+func (t *ConfigTemplate) Process(s string) (string, error) {
+	return t.process(s, false)
+}
+
+// ProcessConfig returns a copy of the raw configuration v, which is
+// typically decoded from JSON, with all of its strings processed.
+func (t *ConfigTemplate) ProcessConfig(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return t.Process(v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			processed, err := t.ProcessConfig(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", key, err)
+			}
+
+			result[key] = processed
+		}
+
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			processed, err := t.ProcessConfig(value)
+			if err != nil {
+				return nil, err
+			}
+
+			result[i] = processed
+		}
+
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+// processVariable processes the default value of a user variable, where
+// environment variables can also be read with {{env `NAME`}}.
+func (t *ConfigTemplate) processVariable(s string) (string, error) {
+	return t.process(s, true)
+}
+
+func (t *ConfigTemplate) process(s string, allowEnv bool) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	funcs := t.funcs(allowEnv)
+	tpl, err := template.New("config").Funcs(funcs).Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	// Only execute the top level nodes that don't refer to any data, and
+	// keep the others as-is for the builders to process.
+	var result bytes.Buffer
+	for _, node := range tpl.Tree.Root.Nodes {
+		if node.Type() == parse.NodeText || usesData(node) {
+			result.WriteString(node.String())
+			continue
+		}
+
+		nodeTpl, err := template.New("node").Funcs(funcs).Parse(node.String())
+		if err != nil {
+			return "", err
+		}
+
+		if err := nodeTpl.Execute(&result, nil); err != nil {
+			return "", err
+		}
+	}
+
+	return result.String(), nil
+}
+
+func (t *ConfigTemplate) funcs(allowEnv bool) template.FuncMap {
+	return template.FuncMap{
+		"env": func(name string) (string, error) {
+			if !allowEnv {
+				return "", fmt.Errorf("env vars can only be read in the default values of variables")
+			}
+
+			return os.Getenv(name), nil
+		},
+		"timestamp": func() string {
+			return strconv.FormatInt(t.timestamp.Unix(), 10)
+		},
+		"user": func(name string) (string, error) {
+			value, ok := t.UserVars[name]
+			if !ok {
+				return "", fmt.Errorf("unknown user variable: %s", name)
+			}
+
+			return value, nil
+		},
+		"uuid": uuid,
+	}
+}
+
+// usesData returns true if the node refers to the data the template is
+// executed with. Unknown node types are assumed to refer to data.
+func usesData(node parse.Node) bool {
+	switch n := node.(type) {
+	case *parse.ActionNode:
+		return usesData(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+
+		if len(n.Decl) > 0 {
+			return true
+		}
+
+		for _, cmd := range n.Cmds {
+			if usesData(cmd) {
+				return true
+			}
+		}
+
+		return false
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if usesData(arg) {
+				return true
+			}
+		}
+
+		return false
+	case *parse.IdentifierNode, *parse.StringNode, *parse.NumberNode,
+		*parse.BoolNode, *parse.NilNode, *parse.TextNode:
+		return false
+	default:
+		return true
+	}
+}
+
+func uuid() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	// Random (version 4) UUID, RFC 4122 variant
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,101 @@
+package packer
+
+import (
+	"cgl.tideland.biz/asserts"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestConfigTemplateProcess_timestamp(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	tpl := NewConfigTemplate(nil)
+	result, err := tpl.Process(`{{timestamp}}`)
+	assert.Nil(err, "should not error")
+
+	val, err := strconv.ParseInt(result, 10, 64)
+	assert.Nil(err, "should be an integer")
+
+	currentTime := time.Now().UTC().Unix()
+	assert.True(currentTime-val <= 2, "should be the current time")
+
+	again, err := tpl.Process(`{{timestamp}}`)
+	assert.Nil(err, "should not error")
+	assert.Equal(again, result, "should be the same for the whole run")
+}
+
+func TestConfigTemplateProcess_user(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	tpl := NewConfigTemplate(map[string]string{"foo": "bar"})
+	result, err := tpl.Process(`prefix-{{user "foo"}}`)
+	assert.Nil(err, "should not error")
+	assert.Equal(result, "prefix-bar", "should have the variable value")
+
+	_, err = tpl.Process(`{{user "unknown"}}`)
+	assert.NotNil(err, "should error on unknown variables")
+}
+
+func TestConfigTemplateProcess_uuid(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	tpl := NewConfigTemplate(nil)
+	result, err := tpl.Process(`{{uuid}}`)
+	assert.Nil(err, "should not error")
+
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	assert.True(re.MatchString(result), "should be a uuid")
+
+	again, err := tpl.Process(`{{uuid}}`)
+	assert.Nil(err, "should not error")
+	assert.True(again != result, "should be different every time")
+}
+
+func TestConfigTemplateProcess_keepsData(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	tpl := NewConfigTemplate(map[string]string{"name": "web"})
+	result, err := tpl.Process(`{{user "name"}} {{.CreateTime}} <wait>{{ .HTTPIP }}:{{ .HTTPPort }}`)
+	assert.Nil(err, "should not error")
+	assert.Equal(result, "web {{.CreateTime}} <wait>{{.HTTPIP}}:{{.HTTPPort}}", "should keep data references")
+}
+
+func TestConfigTemplateProcess_env(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	os.Setenv("PACKER_TEST_ENV", "foo")
+	defer os.Setenv("PACKER_TEST_ENV", "")
+
+	tpl := NewConfigTemplate(nil)
+	_, err := tpl.Process(`{{env "PACKER_TEST_ENV"}}`)
+	assert.NotNil(err, "should not read env vars outside of variables")
+
+	result, err := tpl.processVariable(`{{env "PACKER_TEST_ENV"}}`)
+	assert.Nil(err, "should not error")
+	assert.Equal(result, "foo", "should read the env var")
+}
+
+func TestConfigTemplateProcessConfig(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	raw := map[string]interface{}{
+		"name":   "{{user `name`}}",
+		"inline": []interface{}{"echo {{user `name`}}"},
+		"nested": map[string]interface{}{"count": 3.0},
+	}
+
+	tpl := NewConfigTemplate(map[string]string{"name": "web"})
+	result, err := tpl.ProcessConfig(raw)
+	assert.Nil(err, "should not error")
+
+	expected := map[string]interface{}{
+		"name":   "web",
+		"inline": []interface{}{"echo web"},
+		"nested": map[string]interface{}{"count": 3.0},
+	}
+	assert.Equal(result, expected, "should process all strings")
+	assert.Equal(raw["name"], "{{user `name`}}", "should not modify the original")
+}
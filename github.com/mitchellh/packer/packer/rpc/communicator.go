@@ -147,6 +147,10 @@ func (c *communicator) Download(path string, w io.Writer) (err error) {
 	return
 }
 
+func (c *communicator) Close() error {
+	return c.client.Call("Communicator.Close", new(interface{}), new(interface{}))
+}
+
 func (c *CommunicatorServer) Start(args *CommunicatorStartArgs, reply *interface{}) (err error) {
 	// Build the RemoteCmd on this side so that it all pipes over
 	// to the remote side.
@@ -238,6 +242,10 @@ func (c *CommunicatorServer) Download(args *CommunicatorDownloadArgs, reply *int
 	return
 }
 
+func (c *CommunicatorServer) Close(args *interface{}, reply *interface{}) error {
+	return c.c.Close()
+}
+
 func serveSingleCopy(name string, l net.Listener, dst io.Writer, src io.Reader) {
 	defer l.Close()
 
@@ -20,6 +20,8 @@ type testCommunicator struct {
 
 	downloadCalled bool
 	downloadPath   string
+
+	closeCalled bool
 }
 
 func (t *testCommunicator) Start(cmd *packer.RemoteCmd) error {
@@ -43,6 +45,11 @@ func (t *testCommunicator) Download(path string, writer io.Writer) error {
 	return nil
 }
 
+func (t *testCommunicator) Close() error {
+	t.closeCalled = true
+	return nil
+}
+
 func TestCommunicatorRPC(t *testing.T) {
 	assert := asserts.NewTestingAsserts(t, true)
 
@@ -139,6 +146,11 @@ func TestCommunicatorRPC(t *testing.T) {
 	<-downloadDone
 	assert.Nil(downloadErr, "should not error reading download data")
 	assert.Equal(downloadData, "download\n", "should have the proper data")
+
+	// Test that we can close
+	err = remote.Close()
+	assert.Nil(err, "should not error")
+	assert.True(c.closeCalled, "should have called close")
 }
 
 func TestCommunicator_ImplementsCommunicator(t *testing.T) {
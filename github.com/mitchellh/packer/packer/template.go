@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"github.com/mitchellh/mapstructure"
 	"sort"
+	"strings"
+	"time"
 )
 
 // The rawTemplate struct represents the structure of a template read
@@ -17,15 +19,28 @@ type rawTemplate struct {
 	Hooks          map[string][]string
 	Provisioners   []map[string]interface{}
 	PostProcessors []interface{} `mapstructure:"post-processors"`
+	Variables      map[string]interface{}
 }
 
 // The Template struct represents a parsed template, parsed into the most
 // completed form it can be without additional processing by the caller.
 type Template struct {
+	Variables      map[string]RawVariable
 	Builders       map[string]rawBuilderConfig
 	Hooks          map[string][]string
 	PostProcessors [][]rawPostProcessorConfig
 	Provisioners   []rawProvisionerConfig
+
+	userVars  map[string]string
+	timestamp time.Time
+}
+
+// RawVariable represents a user variable defined in the "variables" section
+// of a template. A variable with a null default value is required and must
+// be set by the user.
+type RawVariable struct {
+	Default  string
+	Required bool
 }
 
 // The rawBuilderConfig struct represents a raw, unprocessed builder
@@ -123,10 +138,24 @@ func ParseTemplate(data []byte) (t *Template, err error) {
 	}
 
 	t = &Template{}
+	t.Variables = make(map[string]RawVariable)
 	t.Builders = make(map[string]rawBuilderConfig)
 	t.Hooks = rawTpl.Hooks
 	t.PostProcessors = make([][]rawPostProcessorConfig, len(rawTpl.PostProcessors))
 	t.Provisioners = make([]rawProvisionerConfig, len(rawTpl.Provisioners))
+	t.timestamp = time.Now().UTC()
+
+	// Gather all the variables
+	for name, rawDefault := range rawTpl.Variables {
+		switch v := rawDefault.(type) {
+		case nil:
+			t.Variables[name] = RawVariable{Required: true}
+		case string:
+			t.Variables[name] = RawVariable{Default: v}
+		default:
+			errors = append(errors, fmt.Errorf("variable '%s': default value must be a string or null", name))
+		}
+	}
 
 	// Gather all the builders
 	for i, v := range rawTpl.Builders {
@@ -275,6 +304,63 @@ func parsePostProvisioner(i int, rawV interface{}) (result []map[string]interfac
 	return
 }
 
+// SetUserVariables sets the values of user variables given by the user,
+// usually with the -var and -var-file flags. They override the default
+// values from the template. Setting variables not defined in the
+// template is an error.
+func (t *Template) SetUserVariables(vars map[string]string) error {
+	unknown := make([]string, 0)
+	for name, _ := range vars {
+		if _, ok := t.Variables[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("Unknown user variables: %s", strings.Join(unknown, ", "))
+	}
+
+	t.userVars = vars
+	return nil
+}
+
+// configTemplate returns the ConfigTemplate used to process the
+// configurations of the builds, with the values of all the user variables.
+func (t *Template) configTemplate() (*ConfigTemplate, error) {
+	tpl := NewConfigTemplate(make(map[string]string))
+	if !t.timestamp.IsZero() {
+		tpl.timestamp = t.timestamp
+	}
+
+	errors := make([]error, 0)
+	for name, variable := range t.Variables {
+		if value, ok := t.userVars[name]; ok {
+			tpl.UserVars[name] = value
+			continue
+		}
+
+		if variable.Required {
+			errors = append(errors, fmt.Errorf("Required user variable '%s' not set", name))
+			continue
+		}
+
+		value, err := tpl.processVariable(variable.Default)
+		if err != nil {
+			errors = append(errors, fmt.Errorf("Error processing user variable '%s': %s", name, err))
+			continue
+		}
+
+		tpl.UserVars[name] = value
+	}
+
+	if len(errors) > 0 {
+		return nil, &MultiError{errors}
+	}
+
+	return tpl, nil
+}
+
 // BuildNames returns a slice of the available names of builds that
 // this template represents.
 func (t *Template) BuildNames() []string {
@@ -310,6 +396,17 @@ func (t *Template) Build(name string, components *ComponentFinder) (b Build, err
 		panic("no provisioner function")
 	}
 
+	configTpl, err := t.configTemplate()
+	if err != nil {
+		return
+	}
+
+	rawBuilderConfig, err := configTpl.ProcessConfig(builderConfig.rawConfig)
+	if err != nil {
+		err = fmt.Errorf("Error processing builder '%s': %s", name, err)
+		return
+	}
+
 	builder, err := components.Builder(builderConfig.Type)
 	if err != nil {
 		return
@@ -357,10 +454,15 @@ func (t *Template) Build(name string, components *ComponentFinder) (b Build, err
 				return nil, fmt.Errorf("PostProcessor type not found: %s", rawPP.Type)
 			}
 
+			config, err := configTpl.ProcessConfig(rawPP.rawConfig)
+			if err != nil {
+				return nil, fmt.Errorf("Error processing post-processor '%s': %s", rawPP.Type, err)
+			}
+
 			current[i] = coreBuildPostProcessor{
 				processor:         pp,
 				processorType:     rawPP.Type,
-				config:            rawPP.rawConfig,
+				config:            config,
 				keepInputArtifact: rawPP.KeepInputArtifact,
 			}
 		}
@@ -383,10 +485,20 @@ func (t *Template) Build(name string, components *ComponentFinder) (b Build, err
 		}
 
 		configs := make([]interface{}, 1, 2)
-		configs[0] = rawProvisioner.rawConfig
+		configs[0], err = configTpl.ProcessConfig(rawProvisioner.rawConfig)
+		if err != nil {
+			err = fmt.Errorf("Error processing provisioner '%s': %s", rawProvisioner.Type, err)
+			return
+		}
 
 		if rawProvisioner.Override != nil {
 			if override, ok := rawProvisioner.Override[name]; ok {
+				override, err = configTpl.ProcessConfig(override)
+				if err != nil {
+					err = fmt.Errorf("Error processing provisioner '%s' override: %s", rawProvisioner.Type, err)
+					return
+				}
+
 				configs = append(configs, override)
 			}
 		}
@@ -398,7 +510,7 @@ func (t *Template) Build(name string, components *ComponentFinder) (b Build, err
 	b = &coreBuild{
 		name:           name,
 		builder:        builder,
-		builderConfig:  builderConfig.rawConfig,
+		builderConfig:  rawBuilderConfig,
 		builderType:    builderConfig.Type,
 		hooks:          hooks,
 		postProcessors: postProcessors,
@@ -585,3 +585,104 @@ func TestTemplate_Build_ProvisionerOverride(t *testing.T) {
 	assert.Equal(len(coreBuild.provisioners), 1, "should have one provisioner")
 	assert.Equal(len(coreBuild.provisioners[0].config), 2, "should have two configs on the provisioner")
 }
+
+func TestParseTemplate_Variables(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	data := `
+	{
+		"variables": {
+			"foo": "bar",
+			"required": null
+		},
+
+		"builders": [{"type": "something"}]
+	}
+	`
+
+	result, err := ParseTemplate([]byte(data))
+	assert.Nil(err, "should not error")
+	assert.Equal(result.Variables["foo"], RawVariable{Default: "bar"}, "should have default")
+	assert.Equal(result.Variables["required"], RawVariable{Required: true}, "should be required")
+}
+
+func TestParseTemplate_VariablesInvalidDefault(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	data := `
+	{
+		"variables": {
+			"foo": 7
+		},
+
+		"builders": [{"type": "something"}]
+	}
+	`
+
+	_, err := ParseTemplate([]byte(data))
+	assert.NotNil(err, "should error")
+}
+
+func TestTemplate_Build_UserVariables(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	data := `
+	{
+		"variables": {
+			"region": "us-east-1",
+			"name": null
+		},
+
+		"builders": [
+			{
+				"name": "test1",
+				"type": "test-builder",
+				"region": "{{user ` + "`region`" + `}}",
+				"image_name": "{{user ` + "`name`" + `}}-{{.CreateTime}}"
+			}
+		],
+
+		"provisioners": [
+			{
+				"type": "test-prov",
+				"inline": ["echo {{user ` + "`name`" + `}}"]
+			}
+		]
+	}
+	`
+
+	template, err := ParseTemplate([]byte(data))
+	assert.Nil(err, "should not error")
+
+	builder := testBuilder()
+	provisioner := &TestProvisioner{}
+	components := &ComponentFinder{
+		Builder:     func(n string) (Builder, error) { return builder, nil },
+		Provisioner: func(n string) (Provisioner, error) { return provisioner, nil },
+	}
+
+	// The required variable is missing
+	_, err = template.Build("test1", components)
+	assert.NotNil(err, "should error without required variable")
+
+	err = template.SetUserVariables(map[string]string{"unknown": "foo"})
+	assert.NotNil(err, "should error with unknown variable")
+
+	err = template.SetUserVariables(map[string]string{"name": "web"})
+	assert.Nil(err, "should not error")
+
+	build, err := template.Build("test1", components)
+	assert.Nil(err, "should not error")
+
+	coreBuild := build.(*coreBuild)
+	expectedConfig := map[string]interface{}{
+		"name":       "test1",
+		"type":       "test-builder",
+		"region":     "us-east-1",
+		"image_name": "web-{{.CreateTime}}",
+	}
+	assert.Equal(coreBuild.builderConfig, expectedConfig, "should have processed config")
+
+	provConfig := coreBuild.provisioners[0].config[0].(map[string]interface{})
+	assert.Equal(provConfig["inline"], []interface{}{"echo web"}, "should process provisioners")
+}
@@ -8,8 +8,10 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 )
 
@@ -49,6 +51,23 @@ type PrefixedUi struct {
 	Ui            Ui
 }
 
+// MachineReadableUi is a UI that wraps another UI implementation and
+// turns all the messages going out into machine-readable records, one per
+// line, of the form:
+//
+//	timestamp,target,type,data...
+//
+// The timestamp is a UNIX timestamp, the target is the name of the build
+// the record is about (empty for global records) and the type tells what
+// the data is. Messages are records of type "ui", with the data being the
+// kind of message ("say", "message" or "error") and the message itself.
+// Commas within the data are escaped as "%!(PACKER_COMMA)", and newlines
+// as a literal "\n", so that each record fits on a single line.
+type MachineReadableUi struct {
+	Target string
+	Ui     Ui
+}
+
 // The ReaderWriterUi is a UI that writes and reads from standard Go
 // io.Reader and io.Writer.
 type ReaderWriterUi struct {
@@ -88,6 +107,37 @@ func (u *ColoredUi) colorize(message string, color UiColor, bold bool) string {
 	return fmt.Sprintf("\033[%d;%d;40m%s\033[0m", attr, color, message)
 }
 
+func (u *MachineReadableUi) Ask(query string) (string, error) {
+	return "", errors.New("can't ask for input in machine-readable mode")
+}
+
+func (u *MachineReadableUi) Say(message string) {
+	u.Machine("ui", "say", message)
+}
+
+func (u *MachineReadableUi) Message(message string) {
+	u.Machine("ui", "message", message)
+}
+
+func (u *MachineReadableUi) Error(message string) {
+	u.Machine("ui", "error", message)
+}
+
+// Machine outputs a record of the given type with the given data.
+func (u *MachineReadableUi) Machine(recordType string, data ...string) {
+	fields := make([]string, 0, len(data)+3)
+	fields = append(fields,
+		strconv.FormatInt(time.Now().UTC().Unix(), 10), u.Target, recordType)
+	for _, d := range data {
+		d = strings.Replace(d, ",", "%!(PACKER_COMMA)", -1)
+		d = strings.Replace(d, "\r", "\\r", -1)
+		d = strings.Replace(d, "\n", "\\n", -1)
+		fields = append(fields, d)
+	}
+
+	u.Ui.Say(strings.Join(fields, ","))
+}
+
 func (u *PrefixedUi) Ask(query string) (string, error) {
 	return u.Ui.Ask(u.prefixLines(u.SayPrefix, query))
 }
@@ -3,6 +3,8 @@ package packer
 import (
 	"bytes"
 	"cgl.tideland.biz/asserts"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -55,6 +57,58 @@ func TestPrefixedUi(t *testing.T) {
 	assert.Equal(readWriter(bufferUi), "mitchell: foo\nmitchell: bar\n", "should multiline")
 }
 
+func TestMachineReadableUi(t *testing.T) {
+	bufferUi := testUi()
+	ui := &MachineReadableUi{"foo", bufferUi}
+
+	// Checks the record and its timestamp, and returns the rest of it
+	readRecord := func() string {
+		result := readWriter(bufferUi)
+		parts := strings.SplitN(result, ",", 2)
+		if len(parts) != 2 {
+			t.Fatalf("invalid output: %s", result)
+		}
+
+		if _, err := strconv.ParseInt(parts[0], 10, 64); err != nil {
+			t.Fatalf("invalid timestamp: %s", result)
+		}
+
+		return parts[1]
+	}
+
+	ui.Say("foo")
+	if result := readRecord(); result != "foo,ui,say,foo\n" {
+		t.Fatalf("invalid output: %s", result)
+	}
+
+	ui.Message("foo")
+	if result := readRecord(); result != "foo,ui,message,foo\n" {
+		t.Fatalf("invalid output: %s", result)
+	}
+
+	ui.Error("foo,bar\nbaz")
+	if result := readRecord(); result != "foo,ui,error,foo%!(PACKER_COMMA)bar\\nbaz\n" {
+		t.Fatalf("invalid output: %s", result)
+	}
+
+	ui.Machine("artifact", "0", "id", "bar")
+	if result := readRecord(); result != "foo,artifact,0,id,bar\n" {
+		t.Fatalf("invalid output: %s", result)
+	}
+
+	if _, err := ui.Ask("foo"); err == nil {
+		t.Fatal("asking should error")
+	}
+}
+
+func TestMachineReadableUi_ImplUi(t *testing.T) {
+	var raw interface{}
+	raw = &MachineReadableUi{}
+	if _, ok := raw.(Ui); !ok {
+		t.Fatalf("MachineReadableUi must implement Ui")
+	}
+}
+
 func TestColoredUi_ImplUi(t *testing.T) {
 	var raw interface{}
 	raw = &ColoredUi{}
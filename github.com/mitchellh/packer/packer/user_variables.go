@@ -0,0 +1,64 @@
+package packer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// UserVariablesFlag is a flag.Value that collects the user variables
+// given with repeated "-var 'key=value'" flags.
+type UserVariablesFlag map[string]string
+
+func (v UserVariablesFlag) String() string {
+	return ""
+}
+
+func (v UserVariablesFlag) Set(raw string) error {
+	idx := strings.Index(raw, "=")
+	if idx == -1 {
+		return fmt.Errorf("No '=' value in arg: %s", raw)
+	}
+
+	v[raw[0:idx]] = raw[idx+1:]
+	return nil
+}
+
+// ReadUserVariablesFile reads user variables from a JSON file holding a
+// single object of string values, as given with the "-var-file" flag.
+func ReadUserVariablesFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]string
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("Error reading variables in '%s': %s", path, err)
+	}
+
+	return result, nil
+}
+
+// UserVariablesFileFlag is a flag.Value that reads the user variables in
+// the files given with "-var-file" flags. It is meant to share its map with
+// a UserVariablesFlag, so that the last flag given wins.
+type UserVariablesFileFlag map[string]string
+
+func (v UserVariablesFileFlag) String() string {
+	return ""
+}
+
+func (v UserVariablesFileFlag) Set(path string) error {
+	vars, err := ReadUserVariablesFile(path)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range vars {
+		v[key] = value
+	}
+
+	return nil
+}
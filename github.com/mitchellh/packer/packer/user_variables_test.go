@@ -0,0 +1,33 @@
+package packer
+
+import (
+	"cgl.tideland.biz/asserts"
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestUserVariablesFlags(t *testing.T) {
+	assert := asserts.NewTestingAsserts(t, true)
+
+	tf, err := ioutil.TempFile("", "packer")
+	assert.Nil(err, "should not error")
+	defer os.Remove(tf.Name())
+	tf.Write([]byte(`{"foo": "file", "bar": "file"}`))
+	tf.Close()
+
+	vars := make(map[string]string)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(UserVariablesFlag(vars), "var", "")
+	fs.Var(UserVariablesFileFlag(vars), "var-file", "")
+
+	args := []string{"-var", "foo=cli", "-var-file", tf.Name(), "-var", "bar=a=b"}
+	assert.Nil(fs.Parse(args), "should parse")
+
+	expected := map[string]string{"foo": "file", "bar": "a=b"}
+	assert.Equal(vars, expected, "last flag should win")
+
+	assert.NotNil(UserVariablesFlag(vars).Set("novalue"), "should error without '='")
+	assert.NotNil(UserVariablesFileFlag(vars).Set("/nonexistent"), "should error on missing file")
+}
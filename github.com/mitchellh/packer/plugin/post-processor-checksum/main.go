@@ -0,0 +1,10 @@
+package main
+
+import (
+	"github.com/mitchellh/packer/packer/plugin"
+	"github.com/mitchellh/packer/post-processor/checksum"
+)
+
+func main() {
+	plugin.ServePostProcessor(new(checksum.PostProcessor))
+}
@@ -0,0 +1,41 @@
+package checksum
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const BuilderId = "packer.post-processor.checksum"
+
+// Artifact is the set of checksum manifests of an artifact, one per
+// checksum type.
+type Artifact struct {
+	Paths []string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (a *Artifact) Files() []string {
+	return a.Paths
+}
+
+func (a *Artifact) Id() string {
+	return strings.Join(a.Paths, ",")
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("Checksums of the artifact files: %s", strings.Join(a.Paths, ", "))
+}
+
+func (a *Artifact) Destroy() error {
+	for _, path := range a.Paths {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
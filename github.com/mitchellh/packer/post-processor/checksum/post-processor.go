@@ -0,0 +1,192 @@
+// checksum implements the packer.PostProcessor interface and adds a
+// post-processor that writes manifests with the checksums of all the files
+// of an artifact, in the format of the md5sum, sha1sum and sha256sum tools.
+package checksum
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mitchellh/packer/packer"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// The supported checksum types
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+type Config struct {
+	OutputPath    string   `mapstructure:"output"`
+	ChecksumTypes []string `mapstructure:"checksum_types"`
+
+	PackerBuildName string `mapstructure:"packer_build_name"`
+
+	outputTemplate *template.Template
+}
+
+// OutputPathTemplate is the structure that is available within the
+// output path template.
+type OutputPathTemplate struct {
+	ArtifactId   string
+	BuildName    string
+	ChecksumType string
+}
+
+type PostProcessor struct {
+	config Config
+}
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	var md mapstructure.Metadata
+	decoderConfig := &mapstructure.DecoderConfig{
+		Metadata: &md,
+		Result:   &p.config,
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, raw := range raws {
+		if err := decoder.Decode(raw); err != nil {
+			return err
+		}
+	}
+
+	// Accumulate any errors
+	errs := make([]error, 0)
+
+	// Unused keys are errors
+	if len(md.Unused) > 0 {
+		sort.Strings(md.Unused)
+		for _, unused := range md.Unused {
+			if unused != "type" && unused != "keep_input_artifact" &&
+				!strings.HasPrefix(unused, "packer_") {
+				errs = append(
+					errs, fmt.Errorf("Unknown configuration key: %s", unused))
+			}
+		}
+	}
+
+	if len(p.config.ChecksumTypes) == 0 {
+		p.config.ChecksumTypes = []string{"md5"}
+	}
+
+	for _, checksumType := range p.config.ChecksumTypes {
+		if _, ok := hashes[checksumType]; !ok {
+			errs = append(errs, fmt.Errorf("Unsupported checksum type: %s", checksumType))
+		}
+	}
+
+	if p.config.OutputPath == "" {
+		p.config.OutputPath = "packer_{{.BuildName}}_{{.ChecksumType}}.checksum"
+	}
+
+	p.config.outputTemplate, err = template.New("output").Parse(p.config.OutputPath)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("output invalid template: %s", err))
+	}
+
+	if len(errs) > 0 {
+		return &packer.MultiError{errs}
+	}
+
+	return nil
+}
+
+// PostProcess writes a manifest for each checksum type. The input artifact
+// is always kept since the manifests are only useful along with it.
+func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, error) {
+	files := artifact.Files()
+	if len(files) == 0 {
+		return nil, false, errors.New("The artifact has no files to checksum.")
+	}
+
+	result := &Artifact{make([]string, 0, len(p.config.ChecksumTypes))}
+	for _, checksumType := range p.config.ChecksumTypes {
+		var buf bytes.Buffer
+		tplData := &OutputPathTemplate{
+			ArtifactId:   artifact.Id(),
+			BuildName:    p.config.PackerBuildName,
+			ChecksumType: checksumType,
+		}
+		if err := p.config.outputTemplate.Execute(&buf, tplData); err != nil {
+			result.Destroy()
+			return nil, false, fmt.Errorf("Error processing output: %s", err)
+		}
+		outputPath := buf.String()
+
+		for _, path := range result.Paths {
+			if path == outputPath {
+				result.Destroy()
+				return nil, false, fmt.Errorf(
+					"The output of each checksum type must differ, use {{.ChecksumType}}: %s",
+					outputPath)
+			}
+		}
+
+		ui.Say(fmt.Sprintf("Writing the %s checksums of the artifact files to: %s",
+			checksumType, outputPath))
+		if err := writeManifest(ui, outputPath, hashes[checksumType], files); err != nil {
+			result.Destroy()
+			return nil, false, fmt.Errorf("Error writing checksums: %s", err)
+		}
+
+		result.Paths = append(result.Paths, outputPath)
+	}
+
+	return result, true, nil
+}
+
+// writeManifest writes the checksum of each file, one per line, in the
+// format of the md5sum tool.
+func writeManifest(ui packer.Ui, outputPath string, newHash func() hash.Hash, files []string) error {
+	var manifest bytes.Buffer
+	for _, path := range files {
+		log.Printf("Computing the checksum of: %s", path)
+		ui.Message(fmt.Sprintf("Checksumming: %s", path))
+		sum, err := checksumFile(newHash(), path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&manifest, "%x  %s\n", sum, path)
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, &manifest)
+	return err
+}
+
+func checksumFile(h hash.Hash, path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,191 @@
+package checksum
+
+import (
+	"bytes"
+	"github.com/mitchellh/packer/packer"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testArtifact struct {
+	files []string
+}
+
+func (*testArtifact) BuilderId() string { return "test" }
+func (a *testArtifact) Files() []string { return a.files }
+func (*testArtifact) Id() string        { return "foo" }
+func (*testArtifact) String() string    { return "test" }
+func (*testArtifact) Destroy() error    { return nil }
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func testUi() packer.Ui {
+	return &packer.ReaderWriterUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	}
+}
+
+func TestPostProcessor_ImplementsPostProcessor(t *testing.T) {
+	var raw interface{}
+	raw = &PostProcessor{}
+	if _, ok := raw.(packer.PostProcessor); !ok {
+		t.Fatalf("PostProcessor should be a PostProcessor")
+	}
+}
+
+func TestPostProcessorConfigure_Defaults(t *testing.T) {
+	var p PostProcessor
+	if err := p.Configure(testConfig()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(p.config.ChecksumTypes) != 1 || p.config.ChecksumTypes[0] != "md5" {
+		t.Fatalf("bad types: %#v", p.config.ChecksumTypes)
+	}
+
+	if p.config.OutputPath != "packer_{{.BuildName}}_{{.ChecksumType}}.checksum" {
+		t.Fatalf("bad output: %s", p.config.OutputPath)
+	}
+}
+
+func TestPostProcessorConfigure_ChecksumTypes(t *testing.T) {
+	var p PostProcessor
+	c := testConfig()
+
+	c["checksum_types"] = []string{"sha1", "sha256"}
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	c["checksum_types"] = []string{"sha1", "crc32"}
+	p = PostProcessor{}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorConfigure_InvalidKey(t *testing.T) {
+	var p PostProcessor
+	c := testConfig()
+
+	c["i_should_not_be_valid"] = true
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorConfigure_OutputPath(t *testing.T) {
+	var p PostProcessor
+	c := testConfig()
+
+	c["output"] = "bad {{{{.Template}}}}"
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorPostProcess(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	artifact := new(testArtifact)
+	for _, name := range []string{"foo", "bar"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		artifact.files = append(artifact.files, path)
+	}
+
+	var p PostProcessor
+	c := testConfig()
+	c["checksum_types"] = []string{"md5", "sha256"}
+	c["output"] = filepath.Join(dir, "{{.BuildName}}.{{.ChecksumType}}")
+	c["packer_build_name"] = "vmware"
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result, keep, err := p.PostProcess(testUi(), artifact)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !keep {
+		t.Fatal("should keep the input artifact")
+	}
+
+	md5Path := filepath.Join(dir, "vmware.md5")
+	sha256Path := filepath.Join(dir, "vmware.sha256")
+	files := result.Files()
+	if len(files) != 2 || files[0] != md5Path || files[1] != sha256Path {
+		t.Fatalf("bad files: %#v", files)
+	}
+
+	expected := "acbd18db4cc2f85cedef654fccc4a4d8  " + artifact.files[0] + "\n" +
+		"37b51d194a7513e45b56f6524f2d51f2  " + artifact.files[1] + "\n"
+	data, err := ioutil.ReadFile(md5Path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if string(data) != expected {
+		t.Fatalf("bad manifest: %s", data)
+	}
+
+	expected = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae  " +
+		artifact.files[0] + "\n" +
+		"fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9  " +
+		artifact.files[1] + "\n"
+	data, err = ioutil.ReadFile(sha256Path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if string(data) != expected {
+		t.Fatalf("bad manifest: %s", data)
+	}
+
+	if err := result.Destroy(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestPostProcessorPostProcess_SameOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "foo")
+	if err := ioutil.WriteFile(path, []byte("foo"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var p PostProcessor
+	c := testConfig()
+	c["checksum_types"] = []string{"md5", "sha1"}
+	c["output"] = filepath.Join(dir, "checksums")
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	_, _, err = p.PostProcess(testUi(), &testArtifact{[]string{path}})
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "checksums")); !os.IsNotExist(err) {
+		t.Fatal("partial output should be removed")
+	}
+}
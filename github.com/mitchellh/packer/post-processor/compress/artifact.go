@@ -0,0 +1,33 @@
+package compress
+
+import (
+	"fmt"
+	"os"
+)
+
+const BuilderId = "packer.post-processor.compress"
+
+// Artifact is the archive containing the files of the compressed artifact.
+type Artifact struct {
+	Path string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (a *Artifact) Files() []string {
+	return []string{a.Path}
+}
+
+func (a *Artifact) Id() string {
+	return a.Path
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("Compressed artifact: %s", a.Path)
+}
+
+func (a *Artifact) Destroy() error {
+	return os.Remove(a.Path)
+}
@@ -0,0 +1,280 @@
+// compress implements the packer.PostProcessor interface and adds a
+// post-processor that compresses the files of an artifact into a single
+// tar.gz or zip archive.
+package compress
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
+	"github.com/mitchellh/packer/packer"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// The supported archive formats
+var formats = map[string]bool{
+	"tar.gz": true,
+	"zip":    true,
+}
+
+type Config struct {
+	OutputPath       string `mapstructure:"output"`
+	Format           string `mapstructure:"format"`
+	CompressionLevel int    `mapstructure:"compression_level"`
+
+	PackerBuildName string `mapstructure:"packer_build_name"`
+
+	outputTemplate *template.Template
+}
+
+// OutputPathTemplate is the structure that is available within the
+// output path template.
+type OutputPathTemplate struct {
+	ArtifactId string
+	BuildName  string
+}
+
+type PostProcessor struct {
+	config Config
+}
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	var md mapstructure.Metadata
+	decoderConfig := &mapstructure.DecoderConfig{
+		Metadata: &md,
+		Result:   &p.config,
+	}
+
+	decoder, err := mapstructure.NewDecoder(decoderConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, raw := range raws {
+		if err := decoder.Decode(raw); err != nil {
+			return err
+		}
+	}
+
+	// Accumulate any errors
+	errs := make([]error, 0)
+
+	// Unused keys are errors
+	if len(md.Unused) > 0 {
+		sort.Strings(md.Unused)
+		for _, unused := range md.Unused {
+			if unused != "type" && unused != "keep_input_artifact" &&
+				!strings.HasPrefix(unused, "packer_") {
+				errs = append(
+					errs, fmt.Errorf("Unknown configuration key: %s", unused))
+			}
+		}
+	}
+
+	if p.config.Format == "" {
+		p.config.Format = "tar.gz"
+	}
+
+	// The compression level defaults to the one of gzip, unless set
+	levelSet := false
+	for _, raw := range raws {
+		if m, ok := raw.(map[string]interface{}); ok {
+			if _, ok := m["compression_level"]; ok {
+				levelSet = true
+			}
+		}
+	}
+
+	if !levelSet {
+		p.config.CompressionLevel = flate.DefaultCompression
+	} else if p.config.CompressionLevel < flate.NoCompression ||
+		p.config.CompressionLevel > flate.BestCompression {
+		errs = append(errs, fmt.Errorf(
+			"compression_level must be between %d and %d",
+			flate.NoCompression, flate.BestCompression))
+	}
+
+	if !formats[p.config.Format] {
+		errs = append(errs, fmt.Errorf("Unsupported format: %s", p.config.Format))
+	}
+
+	if p.config.OutputPath == "" {
+		p.config.OutputPath = "packer_{{.BuildName}}." + p.config.Format
+	}
+
+	p.config.outputTemplate, err = template.New("output").Parse(p.config.OutputPath)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("output invalid template: %s", err))
+	}
+
+	if len(errs) > 0 {
+		return &packer.MultiError{errs}
+	}
+
+	return nil
+}
+
+func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, error) {
+	files := artifact.Files()
+	if len(files) == 0 {
+		return nil, false, errors.New("The artifact has no files to compress.")
+	}
+
+	var buf bytes.Buffer
+	tplData := &OutputPathTemplate{
+		ArtifactId: artifact.Id(),
+		BuildName:  p.config.PackerBuildName,
+	}
+	if err := p.config.outputTemplate.Execute(&buf, tplData); err != nil {
+		return nil, false, fmt.Errorf("Error processing output: %s", err)
+	}
+	outputPath := buf.String()
+
+	ui.Say(fmt.Sprintf("Compressing the artifact files into: %s", outputPath))
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch p.config.Format {
+	case "zip":
+		err = p.writeZip(ui, f, files)
+	default:
+		err = p.writeTarGz(ui, f, files)
+	}
+
+	// Close the file in any case, and remove it if it's not complete
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(outputPath)
+		return nil, false, fmt.Errorf("Error compressing the artifact: %s", err)
+	}
+
+	return &Artifact{outputPath}, false, nil
+}
+
+func (p *PostProcessor) writeTarGz(ui packer.Ui, w io.Writer, files []string) error {
+	gzipWriter, err := gzip.NewWriterLevel(w, p.config.CompressionLevel)
+	if err != nil {
+		return err
+	}
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	err = addFiles(ui, files, func(name string, info os.FileInfo, f io.Reader) error {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		header.Name = name
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		_, err = io.Copy(tarWriter, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+func (p *PostProcessor) writeZip(ui packer.Ui, w io.Writer, files []string) error {
+	zipWriter := zip.NewWriter(w)
+	zipWriter.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, p.config.CompressionLevel)
+	})
+
+	err := addFiles(ui, files, func(name string, info os.FileInfo, f io.Reader) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+
+		header.Name = name
+		header.Method = zip.Deflate
+		fw, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return zipWriter.Close()
+}
+
+// addFiles calls add with each file to put in the archive, named after
+// its path relative to the directory containing all the files.
+func addFiles(ui packer.Ui, files []string, add func(string, os.FileInfo, io.Reader) error) error {
+	root := commonDir(files)
+	for _, path := range files {
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Compressing '%s' as '%s'", path, name)
+		ui.Message(fmt.Sprintf("Compressing: %s", path))
+		err = func() error {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			info, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			return add(filepath.ToSlash(name), info, f)
+		}()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commonDir returns the deepest directory containing all the given files.
+func commonDir(files []string) string {
+	dir := filepath.Dir(files[0])
+	for _, path := range files[1:] {
+		for !strings.HasPrefix(filepath.Dir(path)+string(filepath.Separator), dir+string(filepath.Separator)) {
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+
+			dir = parent
+		}
+	}
+
+	return dir
+}
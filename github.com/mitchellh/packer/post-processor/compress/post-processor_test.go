@@ -0,0 +1,312 @@
+package compress
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"github.com/mitchellh/packer/packer"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+type testArtifact struct {
+	files []string
+}
+
+func (*testArtifact) BuilderId() string { return "test" }
+func (a *testArtifact) Files() []string { return a.files }
+func (*testArtifact) Id() string        { return "foo" }
+func (*testArtifact) String() string    { return "test" }
+func (*testArtifact) Destroy() error    { return nil }
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
+func testUi() packer.Ui {
+	return &packer.ReaderWriterUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	}
+}
+
+// testFiles creates an artifact with files in a temporary directory,
+// which must be removed by the caller.
+func testFiles(t *testing.T) (string, *testArtifact) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	files := map[string]string{
+		"disk.vmdk":     "foo",
+		"sub/image.vmx": "bar",
+	}
+
+	artifact := new(testArtifact)
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		artifact.files = append(artifact.files, path)
+	}
+
+	return dir, artifact
+}
+
+func TestPostProcessor_ImplementsPostProcessor(t *testing.T) {
+	var raw interface{}
+	raw = &PostProcessor{}
+	if _, ok := raw.(packer.PostProcessor); !ok {
+		t.Fatalf("PostProcessor should be a PostProcessor")
+	}
+}
+
+func TestPostProcessorConfigure_Defaults(t *testing.T) {
+	var p PostProcessor
+	if err := p.Configure(testConfig()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if p.config.Format != "tar.gz" {
+		t.Fatalf("bad format: %s", p.config.Format)
+	}
+
+	if p.config.CompressionLevel != -1 {
+		t.Fatalf("bad level: %d", p.config.CompressionLevel)
+	}
+
+	if p.config.OutputPath != "packer_{{.BuildName}}.tar.gz" {
+		t.Fatalf("bad output: %s", p.config.OutputPath)
+	}
+}
+
+func TestPostProcessorConfigure_CompressionLevel(t *testing.T) {
+	var p PostProcessor
+	c := testConfig()
+
+	// No compression is a valid level
+	c["compression_level"] = 0
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if p.config.CompressionLevel != 0 {
+		t.Fatalf("bad level: %d", p.config.CompressionLevel)
+	}
+
+	c["compression_level"] = 10
+	p = PostProcessor{}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorConfigure_Format(t *testing.T) {
+	var p PostProcessor
+	c := testConfig()
+
+	c["format"] = "zip"
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if p.config.OutputPath != "packer_{{.BuildName}}.zip" {
+		t.Fatalf("bad output: %s", p.config.OutputPath)
+	}
+
+	c["format"] = "rar"
+	p = PostProcessor{}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorConfigure_InvalidKey(t *testing.T) {
+	var p PostProcessor
+	c := testConfig()
+
+	// The keys handled by Packer itself are fine
+	c["type"] = "compress"
+	c["keep_input_artifact"] = true
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	c["i_should_not_be_valid"] = true
+	p = PostProcessor{}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorConfigure_OutputPath(t *testing.T) {
+	var p PostProcessor
+	c := testConfig()
+
+	c["output"] = "bad {{{{.Template}}}}"
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorPostProcess_NoFiles(t *testing.T) {
+	var p PostProcessor
+	if err := p.Configure(testConfig()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	_, _, err := p.PostProcess(testUi(), new(testArtifact))
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorPostProcess_TarGz(t *testing.T) {
+	dir, artifact := testFiles(t)
+	defer os.RemoveAll(dir)
+
+	var p PostProcessor
+	c := testConfig()
+	c["output"] = filepath.Join(dir, "{{.BuildName}}-{{.ArtifactId}}.tar.gz")
+	c["packer_build_name"] = "vmware"
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result, keep, err := p.PostProcess(testUi(), artifact)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if keep {
+		t.Fatal("should not keep the input artifact")
+	}
+
+	expected := filepath.Join(dir, "vmware-foo.tar.gz")
+	if len(result.Files()) != 1 || result.Files()[0] != expected {
+		t.Fatalf("bad files: %#v", result.Files())
+	}
+
+	f, err := os.Open(expected)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	contents := make(map[string]string)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		contents[header.Name] = string(data)
+	}
+
+	if len(contents) != 2 || contents["disk.vmdk"] != "foo" || contents["sub/image.vmx"] != "bar" {
+		t.Fatalf("bad contents: %#v", contents)
+	}
+
+	if err := result.Destroy(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := os.Stat(expected); !os.IsNotExist(err) {
+		t.Fatal("archive should be destroyed")
+	}
+}
+
+func TestPostProcessorPostProcess_Zip(t *testing.T) {
+	dir, artifact := testFiles(t)
+	defer os.RemoveAll(dir)
+
+	var p PostProcessor
+	c := testConfig()
+	c["format"] = "zip"
+	c["compression_level"] = 9
+	c["output"] = filepath.Join(dir, "out.zip")
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result, _, err := p.PostProcess(testUi(), artifact)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	zipReader, err := zip.OpenReader(result.Files()[0])
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer zipReader.Close()
+
+	names := make([]string, 0, len(zipReader.File))
+	for _, f := range zipReader.File {
+		names = append(names, f.Name)
+
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		if f.Name == "disk.vmdk" && string(data) != "foo" {
+			t.Fatalf("bad contents: %s", data)
+		}
+	}
+
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "disk.vmdk" || names[1] != "sub/image.vmx" {
+		t.Fatalf("bad names: %#v", names)
+	}
+}
+
+func TestCommonDir(t *testing.T) {
+	cases := []struct {
+		files    []string
+		expected string
+	}{
+		{[]string{"/a/b/c"}, "/a/b"},
+		{[]string{"/a/b/c", "/a/b/d/e"}, "/a/b"},
+		{[]string{"/a/b/c", "/a/bc/d"}, "/a"},
+		{[]string{"/a/b", "/c/d"}, "/"},
+		{[]string{"a", "b/c"}, "."},
+	}
+
+	for _, tc := range cases {
+		if dir := commonDir(tc.files); dir != tc.expected {
+			t.Fatalf("bad dir for %#v: %s", tc.files, dir)
+		}
+	}
+}
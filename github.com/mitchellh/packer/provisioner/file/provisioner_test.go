@@ -95,6 +95,10 @@ func (suc *stubUploadCommunicator) Start(cmd *packer.RemoteCmd) error {
 	return nil
 }
 
+func (suc *stubUploadCommunicator) Close() error {
+	return nil
+}
+
 type stubUi struct {
 	sayMessages string
 }
@@ -0,0 +1,62 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	_ "net/http/pprof"
+	"os"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/youtube/vitess/go/proc"
+	"github.com/youtube/vitess/go/vt/router"
+	"github.com/youtube/vitess/go/vt/servenv"
+	tproto "github.com/youtube/vitess/go/vt/tabletserver/proto"
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+var usage = `Route queries to the shards of a keyspace. It serves the same
+SqlQuery RPC API as vttablet, for whole keyspaces: clients pick the
+keyspace and the tablet type when they get their session id.`
+
+var (
+	port           = flag.Int("port", 15001, "port for the server")
+	cell           = flag.String("cell", "", "cell to use for the serving graph")
+	srvCacheTTL    = flag.Duration("srv-cache-ttl", time.Second, "how long to cache the serving graph data")
+	sessionTimeout = flag.Duration("session-timeout", 30*time.Minute, "how long idle sessions are kept")
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, usage)
+	}
+}
+
+func main() {
+	flag.Parse()
+	if *cell == "" {
+		log.Fatalf("-cell is required")
+	}
+	if *sessionTimeout <= 0 {
+		log.Fatalf("-session-timeout must be positive, got %v", *sessionTimeout)
+	}
+	servenv.Init()
+	defer servenv.Close()
+
+	ts := topo.GetServer()
+	defer topo.CloseServers()
+
+	sq := router.NewSqlQuery(ts, *cell, *srvCacheTTL, *sessionTimeout)
+	defer sq.Close()
+	tproto.RegisterAuthenticated(sq)
+	servenv.ServeRPC()
+
+	log.Infof("starting vtrouter %v", *port)
+	proc.ListenAndServe(fmt.Sprintf("%v", *port))
+}
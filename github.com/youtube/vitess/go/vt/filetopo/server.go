@@ -0,0 +1,130 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package filetopo contains a topo.Server implementation that keeps
+// all its data in a directory. It is meant for small deployments
+// where all the processes run on the same host, and don't need
+// zookeeper.
+//
+// The data is a memorytopo.Server tree, saved in a json file after
+// every change. All the processes lock the directory with flock(2)
+// while they use it, and check it periodically for changes while they
+// wait for something.
+package filetopo
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	"github.com/youtube/vitess/go/vt/env"
+	"github.com/youtube/vitess/go/vt/memorytopo"
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+const (
+	dataFile = "topo.json"
+	lockFile = "topo.lock"
+
+	// pollInterval is how often waiting functions check the
+	// directory for changes made by other processes.
+	pollInterval = 100 * time.Millisecond
+)
+
+var rootDir = flag.String("filetopo.root", "", "directory holding the file topo.Server data (defaults to $VTDATAROOT/topo)")
+
+// Server is the file topo.Server implementation.
+type Server struct {
+	*memorytopo.Server
+	storage *fileStorage
+}
+
+// NewServer returns a Server that keeps its data in the given
+// directory. If dir is empty, the -filetopo.root flag is used.
+func NewServer(dir string) *Server {
+	storage := &fileStorage{dir: dir}
+	return &Server{
+		Server:  memorytopo.NewStorageServer(storage, pollInterval),
+		storage: storage,
+	}
+}
+
+func init() {
+	topo.RegisterServer("file", NewServer(""))
+}
+
+// GetDir returns the directory the data is stored in.
+func (fts *Server) GetDir() string {
+	return fts.storage.getDir()
+}
+
+func (fts *Server) GetSubprocessFlags() []string {
+	return []string{"-filetopo.root", fts.GetDir()}
+}
+
+// fileStorage is a memorytopo.Storage that uses files in a directory.
+type fileStorage struct {
+	dir  string
+	lock *os.File
+}
+
+func (fs *fileStorage) getDir() string {
+	if fs.dir != "" {
+		return fs.dir
+	}
+	if *rootDir != "" {
+		return *rootDir
+	}
+	return path.Join(env.VtDataRoot(), "topo")
+}
+
+func (fs *fileStorage) Lock() error {
+	dir := fs.getDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path.Join(dir, lockFile), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot lock %v: %v", f.Name(), err)
+	}
+	fs.lock = f
+	return nil
+}
+
+func (fs *fileStorage) Unlock() error {
+	f := fs.lock
+	fs.lock = nil
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot unlock %v: %v", f.Name(), err)
+	}
+	return f.Close()
+}
+
+func (fs *fileStorage) Load() ([]byte, error) {
+	data, err := ioutil.ReadFile(path.Join(fs.getDir(), dataFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Save writes the data to a temporary file first, and then renames
+// it, so the data file is never partially written.
+func (fs *fileStorage) Save(data []byte) error {
+	dir := fs.getDir()
+	tmp := path.Join(dir, dataFile+".tmp")
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path.Join(dir, dataFile))
+}
@@ -0,0 +1,137 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filetopo
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/test"
+)
+
+type testServer struct {
+	*Server
+	t *testing.T
+}
+
+func newTestServer(t *testing.T) testServer {
+	dir, err := ioutil.TempDir("", "filetopo")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	ts := testServer{NewServer(dir), t}
+	if err := ts.CreateCell("test"); err != nil {
+		t.Fatalf("CreateCell: %v", err)
+	}
+	return ts
+}
+
+func (ts testServer) Close() {
+	ts.Server.Close()
+	if err := os.RemoveAll(ts.GetDir()); err != nil {
+		ts.t.Errorf("RemoveAll: %v", err)
+	}
+}
+
+func TestKeyspace(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckKeyspace(t, ts)
+}
+
+func TestShard(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckShard(t, ts)
+}
+
+func TestTablet(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckTablet(t, ts)
+}
+
+func TestReplicationPaths(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckReplicationPaths(t, ts)
+}
+
+func TestServingGraph(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckServingGraph(t, ts)
+}
+
+func TestKeyspaceLock(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckKeyspaceLock(t, ts)
+}
+
+func TestShardLock(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckShardLock(t, ts)
+}
+
+func TestTabletActions(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckTabletActions(t, ts)
+}
+
+func TestPid(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckPid(t, ts)
+}
+
+// TestSharedDirectory checks two Servers using the same directory,
+// like two processes would, see each other's changes.
+func TestSharedDirectory(t *testing.T) {
+	ts1 := newTestServer(t)
+	defer ts1.Close()
+	ts2 := NewServer(ts1.GetDir())
+
+	if err := ts1.CreateKeyspace("test_keyspace"); err != nil {
+		t.Fatalf("CreateKeyspace: %v", err)
+	}
+	keyspaces, err := ts2.GetKeyspaces()
+	if err != nil || len(keyspaces) != 1 || keyspaces[0] != "test_keyspace" {
+		t.Errorf("GetKeyspaces on the other Server: %v %v", keyspaces, err)
+	}
+
+	// a lock taken by one Server blocks the other one
+	lockPath, err := ts1.LockKeyspaceForAction("test_keyspace", "fake-content", time.Second, nil)
+	if err != nil {
+		t.Fatalf("LockKeyspaceForAction: %v", err)
+	}
+	if _, err := ts2.LockKeyspaceForAction("test_keyspace", "fake-content", 300*time.Millisecond, nil); err != topo.ErrTimeout {
+		t.Errorf("LockKeyspaceForAction on the other Server: %v", err)
+	}
+	result := make(chan error)
+	go func() {
+		lockPath, err := ts2.LockKeyspaceForAction("test_keyspace", "fake-content", 5*time.Second, nil)
+		if err == nil {
+			err = ts2.UnlockKeyspaceForAction("test_keyspace", lockPath, "fake-results")
+		}
+		result <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := ts1.UnlockKeyspaceForAction("test_keyspace", lockPath, "fake-results"); err != nil {
+		t.Errorf("UnlockKeyspaceForAction: %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Errorf("LockKeyspaceForAction on the other Server after unlock: %v", err)
+	}
+
+	if got := ts2.GetSubprocessFlags(); len(got) != 2 || got[1] != ts1.GetDir() {
+		t.Errorf("GetSubprocessFlags: %v", got)
+	}
+}
@@ -0,0 +1,516 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memorytopo
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/youtube/vitess/go/jscfg"
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+/*
+This file contains the per-cell methods of memorytopo.Server
+*/
+
+func tabletPathForAlias(alias topo.TabletAlias) string {
+	return fmt.Sprintf("/%v/tablets/%v", alias.Cell, alias.TabletUidStr())
+}
+
+func tabletActionPathForAlias(alias topo.TabletAlias) string {
+	return path.Join(tabletPathForAlias(alias), "action")
+}
+
+func tabletDirectoryForCell(cell string) string {
+	return fmt.Sprintf("/%v/tablets", cell)
+}
+
+//
+// Tablet management
+//
+
+func (s *Server) CreateTablet(tablet *topo.Tablet) error {
+	tp := tabletPathForAlias(tablet.Alias())
+	return s.write(func(root *node) error {
+		if err := root.create(tp, tablet.Json(), true); err != nil {
+			return err
+		}
+		root.create(path.Join(tp, "action"), "", false)
+		root.create(path.Join(tp, "actionlog"), "", false)
+		return nil
+	})
+}
+
+func (s *Server) UpdateTablet(tablet *topo.TabletInfo, existingVersion int) (newVersion int, err error) {
+	err = s.write(func(root *node) (err error) {
+		newVersion, err = root.set(tabletPathForAlias(tablet.Alias()), tablet.Json(), existingVersion)
+		return err
+	})
+	return newVersion, err
+}
+
+func (s *Server) UpdateTabletFields(tabletAlias topo.TabletAlias, update func(*topo.Tablet) error) error {
+	// The update function is called without holding the lock, so it
+	// can take its time. We retry if the tablet changed in the meantime.
+	tp := tabletPathForAlias(tabletAlias)
+	for {
+		var data string
+		var version int
+		_, err := s.read(func(root *node) (err error) {
+			data, version, err = root.get(tp)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if data == "" {
+			return fmt.Errorf("no data for tablet addr update: %v", tabletAlias)
+		}
+
+		tablet, err := topo.TabletFromJson(data)
+		if err != nil {
+			return err
+		}
+		if err := update(tablet); err != nil {
+			return err
+		}
+
+		err = s.write(func(root *node) error {
+			_, err := root.set(tp, jscfg.ToJson(tablet), version)
+			return err
+		})
+		if err != topo.ErrBadVersion {
+			return err
+		}
+	}
+}
+
+func (s *Server) DeleteTablet(alias topo.TabletAlias) error {
+	return s.write(func(root *node) error {
+		return root.remove(tabletPathForAlias(alias), true)
+	})
+}
+
+func (s *Server) ValidateTablet(alias topo.TabletAlias) error {
+	tp := tabletPathForAlias(alias)
+	_, err := s.read(func(root *node) error {
+		for _, p := range []string{path.Join(tp, "action"), path.Join(tp, "actionlog")} {
+			if root.find(p) == nil {
+				return topo.ErrNoNode
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *Server) GetTablet(alias topo.TabletAlias) (*topo.TabletInfo, error) {
+	var data string
+	var version int
+	_, err := s.read(func(root *node) (err error) {
+		data, version, err = root.get(tabletPathForAlias(alias))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return topo.TabletInfoFromJson(data, version)
+}
+
+func (s *Server) GetTabletsByCell(cell string) ([]topo.TabletAlias, error) {
+	var children []string
+	_, err := s.read(func(root *node) (err error) {
+		children, err = root.children(tabletDirectoryForCell(cell))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]topo.TabletAlias, len(children))
+	for i, child := range children {
+		result[i].Cell = cell
+		result[i].Uid, err = topo.ParseUid(child)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+//
+// Serving Graph management
+//
+
+func pathForSrvKeyspace(cell, keyspace string) string {
+	return fmt.Sprintf("/%v/ns/%v", cell, keyspace)
+}
+
+func pathForSrvShard(cell, keyspace, shard string) string {
+	return path.Join(pathForSrvKeyspace(cell, keyspace), shard)
+}
+
+func pathForSrvTabletType(cell, keyspace, shard string, tabletType topo.TabletType) string {
+	return path.Join(pathForSrvShard(cell, keyspace, shard), string(tabletType))
+}
+
+// getData reads the data and version of a node.
+func (s *Server) getData(p string) (data string, version int, err error) {
+	_, err = s.read(func(root *node) (err error) {
+		data, version, err = root.get(p)
+		return err
+	})
+	return data, version, err
+}
+
+// setData sets the data of a node, creating it if necessary.
+func (s *Server) setData(p, data string) error {
+	return s.write(func(root *node) error {
+		root.createOrSet(p, data)
+		return nil
+	})
+}
+
+func (s *Server) GetSrvTabletTypesPerShard(cell, keyspace, shard string) ([]topo.TabletType, error) {
+	var children []string
+	_, err := s.read(func(root *node) (err error) {
+		children, err = root.children(pathForSrvShard(cell, keyspace, shard))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]topo.TabletType, len(children))
+	for i, tt := range children {
+		result[i] = topo.TabletType(tt)
+	}
+	return result, nil
+}
+
+func (s *Server) UpdateSrvTabletType(cell, keyspace, shard string, tabletType topo.TabletType, addrs *topo.VtnsAddrs) error {
+	return s.setData(pathForSrvTabletType(cell, keyspace, shard, tabletType), jscfg.ToJson(addrs))
+}
+
+func (s *Server) GetSrvTabletType(cell, keyspace, shard string, tabletType topo.TabletType) (*topo.VtnsAddrs, error) {
+	data, version, err := s.getData(pathForSrvTabletType(cell, keyspace, shard, tabletType))
+	if err != nil {
+		return nil, err
+	}
+	return topo.NewVtnsAddrs(data, version)
+}
+
+func (s *Server) DeleteSrvTabletType(cell, keyspace, shard string, tabletType topo.TabletType) error {
+	return s.write(func(root *node) error {
+		return root.remove(pathForSrvTabletType(cell, keyspace, shard, tabletType), false)
+	})
+}
+
+func (s *Server) UpdateSrvShard(cell, keyspace, shard string, srvShard *topo.SrvShard) error {
+	return s.setData(pathForSrvShard(cell, keyspace, shard), jscfg.ToJson(srvShard))
+}
+
+func (s *Server) GetSrvShard(cell, keyspace, shard string) (*topo.SrvShard, error) {
+	data, version, err := s.getData(pathForSrvShard(cell, keyspace, shard))
+	if err != nil {
+		return nil, err
+	}
+	return topo.NewSrvShard(data, version)
+}
+
+func (s *Server) UpdateSrvKeyspace(cell, keyspace string, srvKeyspace *topo.SrvKeyspace) error {
+	return s.setData(pathForSrvKeyspace(cell, keyspace), jscfg.ToJson(srvKeyspace))
+}
+
+func (s *Server) GetSrvKeyspace(cell, keyspace string) (*topo.SrvKeyspace, error) {
+	data, version, err := s.getData(pathForSrvKeyspace(cell, keyspace))
+	if err != nil {
+		return nil, err
+	}
+	return topo.NewSrvKeyspace(data, version)
+}
+
+func (s *Server) UpdateTabletEndpoint(cell, keyspace, shard string, tabletType topo.TabletType, addr *topo.VtnsAddr) error {
+	p := pathForSrvTabletType(cell, keyspace, shard, tabletType)
+	return s.write(func(root *node) error {
+		data, version, err := root.get(p)
+		if err == topo.ErrNoNode {
+			// We haven't been placed in the serving graph yet,
+			// so don't update. Assume the next process that
+			// rebuilds the graph will get the updated tablet
+			// location.
+			return nil
+		}
+
+		var addrs *topo.VtnsAddrs
+		if data != "" {
+			addrs, err = topo.NewVtnsAddrs(data, version)
+			if err != nil {
+				return err
+			}
+
+			foundTablet := false
+			for i, entry := range addrs.Entries {
+				if entry.Uid == addr.Uid {
+					foundTablet = true
+					if !topo.VtnsAddrEquality(&entry, addr) {
+						addrs.Entries[i] = *addr
+					}
+					break
+				}
+			}
+
+			if !foundTablet {
+				addrs.Entries = append(addrs.Entries, *addr)
+			}
+		} else {
+			addrs = topo.NewAddrs()
+			addrs.Entries = append(addrs.Entries, *addr)
+		}
+		_, err = root.set(p, jscfg.ToJson(addrs), -1)
+		return err
+	})
+}
+
+//
+// Remote Tablet Actions
+//
+
+func (s *Server) WriteTabletAction(tabletAlias topo.TabletAlias, contents string) (actionPath string, err error) {
+	err = s.write(func(root *node) (err error) {
+		actionPath, err = root.createSequence(tabletActionPathForAlias(tabletAlias), contents)
+		return err
+	})
+	return actionPath, err
+}
+
+func (s *Server) WaitForTabletAction(actionPath string, waitTime time.Duration, interrupted chan struct{}) (string, error) {
+	timer := time.NewTimer(waitTime)
+	defer timer.Stop()
+
+	actionLogPath := strings.Replace(actionPath, "/action/", "/actionlog/", 1)
+	for {
+		data, found := "", false
+		changed, err := s.read(func(root *node) error {
+			if n := root.find(actionLogPath); n != nil {
+				data, found = n.Data, true
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		if found {
+			return data, nil
+		}
+
+		select {
+		case <-changed:
+		case <-s.poll():
+		case <-timer.C:
+			return "", topo.ErrTimeout
+		case <-interrupted:
+			return "", topo.ErrInterrupted
+		}
+	}
+}
+
+func (s *Server) PurgeTabletActions(tabletAlias topo.TabletAlias, canBePurged func(data string) bool) error {
+	actionDir := tabletActionPathForAlias(tabletAlias)
+	var children []string
+	_, err := s.read(func(root *node) (err error) {
+		children, err = root.children(actionDir)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Purge newer items first so the action queues don't try to process something.
+	for i := len(children) - 1; i >= 0; i-- {
+		actionPath := path.Join(actionDir, children[i])
+		data, _, err := s.getData(actionPath)
+		if err == topo.ErrNoNode {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("PurgeTabletActions(%v) err: %v", actionDir, err)
+		}
+		if !canBePurged(data) {
+			continue
+		}
+
+		err = s.write(func(root *node) error {
+			return root.remove(actionPath, true)
+		})
+		if err != nil && err != topo.ErrNoNode {
+			return fmt.Errorf("PurgeTabletActions(%v) err: %v", actionDir, err)
+		}
+	}
+	return nil
+}
+
+//
+// Supporting the local agent process.
+//
+
+func (s *Server) ValidateTabletActions(tabletAlias topo.TabletAlias) error {
+	// Ensure that the action node is there. There is no conflict creating
+	// this node.
+	err := s.write(func(root *node) error {
+		return root.create(tabletActionPathForAlias(tabletAlias), "", false)
+	})
+	if err != nil && err != topo.ErrNodeExists {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) CreateTabletPidNode(tabletAlias topo.TabletAlias, done chan struct{}) error {
+	// There are no ephemeral nodes, so we remove the pid node
+	// ourselves when we're done.
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("failed creating pid node: %v", err)
+	}
+	data := fmt.Sprintf("host:%v\npid:%v\n", hostname, os.Getpid())
+
+	pidPath := path.Join(tabletPathForAlias(tabletAlias), "pid")
+	err = s.write(func(root *node) error {
+		if root.find(tabletPathForAlias(tabletAlias)) == nil {
+			return topo.ErrNoNode
+		}
+		root.createOrSet(pidPath, data)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-done
+		err := s.write(func(root *node) error {
+			return root.remove(pidPath, false)
+		})
+		if err != nil {
+			log.Warningf("cannot remove pid node %v: %v", pidPath, err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) ValidateTabletPidNode(tabletAlias topo.TabletAlias) error {
+	_, _, err := s.getData(path.Join(tabletPathForAlias(tabletAlias), "pid"))
+	return err
+}
+
+func (s *Server) GetSubprocessFlags() []string {
+	return nil
+}
+
+func (s *Server) handleActionQueue(actionDir string, children []string, dispatchAction func(actionPath, data string) error) {
+	for _, child := range children {
+		actionPath := path.Join(actionDir, child)
+		data, _, err := s.getData(actionPath)
+		if err != nil {
+			log.Errorf("cannot read action %v: %v", actionPath, err)
+			break
+		}
+
+		if err := dispatchAction(actionPath, data); err != nil {
+			break
+		}
+	}
+}
+
+func (s *Server) ActionEventLoop(tabletAlias topo.TabletAlias, dispatchAction func(actionPath, data string) error, done chan struct{}) {
+	actionDir := tabletActionPathForAlias(tabletAlias)
+
+	// handled is the list of actions we processed last time, we
+	// only go through the queue again when it changes.
+	var handled []string
+	for {
+		var children []string
+		changed, err := s.read(func(root *node) (err error) {
+			children, err = root.children(actionDir)
+			return err
+		})
+		if err != nil {
+			log.Warningf("action queue failed: %v", err)
+			select {
+			case <-time.After(5 * time.Second):
+			case <-done:
+				return
+			}
+			continue
+		}
+
+		if handled == nil || strings.Join(children, ",") != strings.Join(handled, ",") {
+			s.handleActionQueue(actionDir, children, dispatchAction)
+			handled = children
+			continue
+		}
+
+		select {
+		case <-changed:
+		case <-s.poll():
+		case <-done:
+			return
+		}
+	}
+}
+
+// actionPathToTabletAlias parses an actionPath back
+// actionPath is /<cell>/tablets/<uid>/action/<number>
+func actionPathToTabletAlias(actionPath string) (topo.TabletAlias, error) {
+	pathParts := strings.Split(actionPath, "/")
+	if len(pathParts) != 6 || pathParts[0] != "" || pathParts[2] != "tablets" || pathParts[4] != "action" {
+		return topo.TabletAlias{}, fmt.Errorf("invalid action path: %v", actionPath)
+	}
+	return topo.ParseTabletAliasString(pathParts[1] + "-" + pathParts[3])
+}
+
+func (s *Server) ReadTabletActionPath(actionPath string) (topo.TabletAlias, string, int, error) {
+	tabletAlias, err := actionPathToTabletAlias(actionPath)
+	if err != nil {
+		return topo.TabletAlias{}, "", 0, err
+	}
+
+	data, version, err := s.getData(actionPath)
+	if err != nil {
+		return topo.TabletAlias{}, "", 0, err
+	}
+
+	return tabletAlias, data, version, nil
+}
+
+func (s *Server) UpdateTabletAction(actionPath, data string, version int) error {
+	return s.write(func(root *node) error {
+		_, err := root.set(actionPath, data, version)
+		return err
+	})
+}
+
+// StoreTabletActionResponse stores the data both in action and actionlog
+func (s *Server) StoreTabletActionResponse(actionPath, data string) error {
+	return s.write(func(root *node) error {
+		if _, err := root.set(actionPath, data, -1); err != nil {
+			return err
+		}
+		root.createOrSet(strings.Replace(actionPath, "/action/", "/actionlog/", 1), data)
+		return nil
+	})
+}
+
+func (s *Server) UnblockTabletAction(actionPath string) error {
+	return s.write(func(root *node) error {
+		return root.remove(actionPath, false)
+	})
+}
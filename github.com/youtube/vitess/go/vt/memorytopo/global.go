@@ -0,0 +1,304 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memorytopo
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+/*
+This file contains the global (not per-cell) methods of memorytopo.Server
+*/
+
+const (
+	globalCell          = "global"
+	globalKeyspacesPath = "/global/keyspaces"
+)
+
+func keyspacePath(keyspace string) string {
+	return path.Join(globalKeyspacesPath, keyspace)
+}
+
+func shardPath(keyspace, shard string) string {
+	return path.Join(globalKeyspacesPath, keyspace, "shards", shard)
+}
+
+// createAll creates all the given paths, recursively. The first one
+// gets the provided data. It returns topo.ErrNodeExists if the first
+// path already existed, after creating the missing ones.
+func createAll(root *node, data string, paths ...string) error {
+	alreadyExists := false
+	for i, p := range paths {
+		c := ""
+		if i == 0 {
+			c = data
+		}
+		if err := root.create(p, c, true); err == topo.ErrNodeExists {
+			if i == 0 {
+				alreadyExists = true
+			}
+		}
+	}
+	if alreadyExists {
+		return topo.ErrNodeExists
+	}
+	return nil
+}
+
+//
+// Cell management
+//
+
+func (s *Server) GetKnownCells() (cells []string, err error) {
+	_, err = s.read(func(root *node) error {
+		children, _ := root.children("/")
+		cells = make([]string, 0, len(children))
+		for _, child := range children {
+			if child != globalCell {
+				cells = append(cells, child)
+			}
+		}
+		return nil
+	})
+	return cells, err
+}
+
+// CreateCell makes a cell known, even if it doesn't have any data yet.
+// Can return topo.ErrNodeExists.
+func (s *Server) CreateCell(cell string) error {
+	return s.write(func(root *node) error {
+		return root.create(cell, "", false)
+	})
+}
+
+//
+// Keyspace management
+//
+
+func (s *Server) CreateKeyspace(keyspace string) error {
+	kp := keyspacePath(keyspace)
+	var alreadyExists bool
+	err := s.write(func(root *node) error {
+		alreadyExists = createAll(root, "", kp,
+			path.Join(kp, "action"),
+			path.Join(kp, "actionlog"),
+			path.Join(kp, "shards")) == topo.ErrNodeExists
+		return nil
+	})
+	if err == nil && alreadyExists {
+		err = topo.ErrNodeExists
+	}
+	return err
+}
+
+func (s *Server) GetKeyspaces() (keyspaces []string, err error) {
+	_, err = s.read(func(root *node) error {
+		keyspaces, err = root.children(globalKeyspacesPath)
+		if err == topo.ErrNoNode {
+			keyspaces = make([]string, 0)
+			err = nil
+		}
+		return err
+	})
+	return keyspaces, err
+}
+
+func (s *Server) DeleteKeyspaceShards(keyspace string) error {
+	return s.write(func(root *node) error {
+		if err := root.remove(path.Join(keyspacePath(keyspace), "shards"), true); err != nil && err != topo.ErrNoNode {
+			return err
+		}
+		return nil
+	})
+}
+
+//
+// Shard management
+//
+
+func (s *Server) CreateShard(keyspace, shard string, value *topo.Shard) error {
+	sp := shardPath(keyspace, shard)
+	var alreadyExists bool
+	err := s.write(func(root *node) error {
+		alreadyExists = createAll(root, value.Json(), sp,
+			path.Join(sp, "action"),
+			path.Join(sp, "actionlog")) == topo.ErrNodeExists
+		return nil
+	})
+	if err == nil && alreadyExists {
+		err = topo.ErrNodeExists
+	}
+	return err
+}
+
+func (s *Server) UpdateShard(si *topo.ShardInfo) error {
+	return s.write(func(root *node) error {
+		_, err := root.set(shardPath(si.Keyspace(), si.ShardName()), si.Json(), -1)
+		return err
+	})
+}
+
+func (s *Server) ValidateShard(keyspace, shard string) error {
+	sp := shardPath(keyspace, shard)
+	_, err := s.read(func(root *node) error {
+		for _, p := range []string{path.Join(sp, "action"), path.Join(sp, "actionlog")} {
+			if root.find(p) == nil {
+				return topo.ErrNoNode
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *Server) GetShard(keyspace, shard string) (*topo.ShardInfo, error) {
+	var data string
+	_, err := s.read(func(root *node) (err error) {
+		data, _, err = root.get(shardPath(keyspace, shard))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return topo.NewShardInfo(keyspace, shard, data)
+}
+
+func (s *Server) GetShardNames(keyspace string) (shards []string, err error) {
+	_, err = s.read(func(root *node) error {
+		shards, err = root.children(path.Join(keyspacePath(keyspace), "shards"))
+		return err
+	})
+	return shards, err
+}
+
+//
+// Replication graph management
+//
+
+func (s *Server) GetReplicationPaths(keyspace, shard, repPath string) ([]topo.TabletAlias, error) {
+	var children []string
+	_, err := s.read(func(root *node) (err error) {
+		children, err = root.children(path.Join(shardPath(keyspace, shard), repPath))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]topo.TabletAlias, 0, len(children))
+	for _, child := range children {
+		// 'action' and 'actionlog' can only be present at the toplevel
+		if (repPath == "" || repPath == "/") && (child == "action" || child == "actionlog") {
+			continue
+		}
+		alias, err := topo.ParseTabletAliasString(child)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, alias)
+	}
+	return result, nil
+}
+
+func (s *Server) CreateReplicationPath(keyspace, shard, repPath string) error {
+	return s.write(func(root *node) error {
+		return root.create(path.Join(shardPath(keyspace, shard), repPath), "", true)
+	})
+}
+
+func (s *Server) DeleteReplicationPath(keyspace, shard, repPath string) error {
+	return s.write(func(root *node) error {
+		return root.remove(path.Join(shardPath(keyspace, shard), repPath), false)
+	})
+}
+
+//
+// Lock management
+//
+
+// lockForAction creates the action node, and waits until it is the
+// first one in the action directory.
+func (s *Server) lockForAction(actionDir, contents string, timeout time.Duration, interrupted chan struct{}) (string, error) {
+	var actionPath string
+	err := s.write(func(root *node) (err error) {
+		actionPath, err = root.createSequence(actionDir, contents)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		first := ""
+		changed, err := s.read(func(root *node) error {
+			children, err := root.children(actionDir)
+			if err == nil && len(children) > 0 {
+				first = path.Join(actionDir, children[0])
+			}
+			return err
+		})
+		if err == nil {
+			if first == actionPath {
+				return actionPath, nil
+			}
+
+			select {
+			case <-changed:
+				continue
+			case <-s.poll():
+				continue
+			case <-timer.C:
+				err = topo.ErrTimeout
+			case <-interrupted:
+				err = topo.ErrInterrupted
+			}
+		}
+
+		// Regardless of the reason, try to cleanup.
+		log.Warningf("Failed to obtain action lock: %v", err)
+		s.write(func(root *node) error {
+			return root.remove(actionPath, true)
+		})
+		if first != "" {
+			log.Warningf("------ Most likely blocking action: %v", first)
+		}
+		return "", err
+	}
+}
+
+func (s *Server) unlockForAction(lockPath, results string) error {
+	return s.write(func(root *node) error {
+		if root.find(lockPath) == nil {
+			return topo.ErrNoNode
+		}
+
+		// Write the data to the actionlog, and delete the action
+		root.createOrSet(strings.Replace(lockPath, "/action/", "/actionlog/", 1), results)
+		return root.remove(lockPath, true)
+	})
+}
+
+func (s *Server) LockKeyspaceForAction(keyspace, contents string, timeout time.Duration, interrupted chan struct{}) (string, error) {
+	return s.lockForAction(path.Join(keyspacePath(keyspace), "action"), contents, timeout, interrupted)
+}
+
+func (s *Server) UnlockKeyspaceForAction(keyspace, lockPath, results string) error {
+	return s.unlockForAction(lockPath, results)
+}
+
+func (s *Server) LockShardForAction(keyspace, shard, contents string, timeout time.Duration, interrupted chan struct{}) (string, error) {
+	return s.lockForAction(path.Join(shardPath(keyspace, shard), "action"), contents, timeout, interrupted)
+}
+
+func (s *Server) UnlockShardForAction(keyspace, shard, lockPath, results string) error {
+	return s.unlockForAction(lockPath, results)
+}
@@ -0,0 +1,180 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memorytopo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+/*
+This file contains the tree of nodes the Server stores its data in.
+It works like a very simple zookeeper: every node has some data, a
+version incremented every time the data changes, and children.
+*/
+
+type node struct {
+	Data     string           `json:",omitempty"`
+	Version  int              `json:",omitempty"`
+	Sequence int              `json:",omitempty"`
+	Children map[string]*node `json:",omitempty"`
+}
+
+func newNode(data string) *node {
+	return &node{Data: data}
+}
+
+// addChild adds a new child with the given data, and returns it. The
+// map of children is allocated lazily, as it is not stored for nodes
+// without children.
+func (n *node) addChild(name, data string) *node {
+	if n.Children == nil {
+		n.Children = make(map[string]*node)
+	}
+	child := newNode(data)
+	n.Children[name] = child
+	return child
+}
+
+func splitPath(p string) []string {
+	parts := strings.Split(p, "/")
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// find returns the node for the given path, or nil.
+func (n *node) find(p string) *node {
+	for _, name := range splitPath(p) {
+		child, ok := n.Children[name]
+		if !ok {
+			return nil
+		}
+		n = child
+	}
+	return n
+}
+
+// get returns the data and version of a node.
+// Can return topo.ErrNoNode.
+func (n *node) get(p string) (string, int, error) {
+	target := n.find(p)
+	if target == nil {
+		return "", 0, topo.ErrNoNode
+	}
+	return target.Data, target.Version, nil
+}
+
+// children returns the sorted list of the children of a node.
+// Can return topo.ErrNoNode.
+func (n *node) children(p string) ([]string, error) {
+	target := n.find(p)
+	if target == nil {
+		return nil, topo.ErrNoNode
+	}
+	result := make([]string, 0, len(target.Children))
+	for name, _ := range target.Children {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// create creates a node with the given data. If recursive is set,
+// the missing parent nodes are created empty, otherwise topo.ErrNoNode
+// is returned.
+// Can return topo.ErrNodeExists.
+func (n *node) create(p, data string, recursive bool) error {
+	parts := splitPath(p)
+	if len(parts) == 0 {
+		return topo.ErrNodeExists
+	}
+	for _, name := range parts[:len(parts)-1] {
+		child, ok := n.Children[name]
+		if !ok {
+			if !recursive {
+				return topo.ErrNoNode
+			}
+			child = n.addChild(name, "")
+		}
+		n = child
+	}
+	name := parts[len(parts)-1]
+	if _, ok := n.Children[name]; ok {
+		return topo.ErrNodeExists
+	}
+	n.addChild(name, data)
+	return nil
+}
+
+// createSequence creates a child of the given directory, named after
+// a counter that increases every time a child is created this way.
+// It returns the path of the new node.
+// Can return topo.ErrNoNode if the directory doesn't exist.
+func (n *node) createSequence(dir, data string) (string, error) {
+	target := n.find(dir)
+	if target == nil {
+		return "", topo.ErrNoNode
+	}
+	name := fmt.Sprintf("%010d", target.Sequence)
+	target.Sequence++
+	target.addChild(name, data)
+	return strings.TrimRight(dir, "/") + "/" + name, nil
+}
+
+// set changes the data of a node, and returns its new version. Use
+// a version of -1 to ignore the current version.
+// Can return topo.ErrNoNode or topo.ErrBadVersion.
+func (n *node) set(p, data string, version int) (int, error) {
+	target := n.find(p)
+	if target == nil {
+		return 0, topo.ErrNoNode
+	}
+	if version != -1 && version != target.Version {
+		return 0, topo.ErrBadVersion
+	}
+	target.Data = data
+	target.Version++
+	return target.Version, nil
+}
+
+// createOrSet sets the data of a node, creating it and its parents
+// if they don't exist.
+func (n *node) createOrSet(p, data string) {
+	if _, err := n.set(p, data, -1); err == topo.ErrNoNode {
+		n.create(p, data, true)
+	}
+}
+
+// remove deletes a node. If recursive is not set, the node cannot
+// have any children.
+// Can return topo.ErrNoNode or topo.ErrNotEmpty.
+func (n *node) remove(p string, recursive bool) error {
+	parts := splitPath(p)
+	if len(parts) == 0 {
+		return fmt.Errorf("cannot remove the root node")
+	}
+	parent := n.find(strings.Join(parts[:len(parts)-1], "/"))
+	if parent == nil {
+		return topo.ErrNoNode
+	}
+	name := parts[len(parts)-1]
+	target, ok := parent.Children[name]
+	if !ok {
+		return topo.ErrNoNode
+	}
+	if !recursive && len(target.Children) > 0 {
+		return topo.ErrNotEmpty
+	}
+	delete(parent.Children, name)
+	return nil
+}
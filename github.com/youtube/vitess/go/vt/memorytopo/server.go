@@ -0,0 +1,191 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package memorytopo contains a topo.Server implementation that
+// keeps all its data in memory, in a tree of nodes laid out like
+// the zookeeper one. It is meant for tests, and doesn't need any
+// external process.
+//
+// A Server can also save its data in a Storage after every change,
+// and reload it before every operation, so a few processes on the
+// same host can share it. See filetopo for such a Storage.
+package memorytopo
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+)
+
+// Storage persists the data of a Server. It has to provide
+// a lock shared by all the processes using the same Storage.
+type Storage interface {
+	// Lock blocks until this process exclusively owns the Storage.
+	Lock() error
+
+	// Unlock releases the lock obtained by Lock.
+	Unlock() error
+
+	// Load returns the data previously saved, or nil if nothing
+	// was saved yet. Only called with the lock held.
+	Load() ([]byte, error)
+
+	// Save replaces the saved data. Only called with the lock held.
+	Save(data []byte) error
+}
+
+// Server is the in-memory topo.Server implementation.
+type Server struct {
+	// mu protects all the fields below
+	mu sync.Mutex
+
+	root *node
+
+	// generation is incremented every time the data changes. It is
+	// saved with the data so we know when other processes changed it.
+	generation int64
+
+	// changed is closed and replaced every time the data changes,
+	// so waiting functions can select on it.
+	changed chan struct{}
+
+	storage      Storage
+	pollInterval time.Duration
+}
+
+// contents is what we save in a Storage
+type contents struct {
+	Generation int64
+	Root       *node
+}
+
+// NewServer returns a new in-memory Server, with the given cells
+// already known.
+func NewServer(cells ...string) *Server {
+	s := &Server{
+		root:    newNode(""),
+		changed: make(chan struct{}),
+	}
+	for _, cell := range cells {
+		s.root.create(cell, "", false)
+	}
+	return s
+}
+
+// NewStorageServer returns a new Server that keeps its data in the
+// provided Storage. Since changes made by other processes cannot be
+// notified, the waiting functions check the Storage every
+// pollInterval.
+func NewStorageServer(storage Storage, pollInterval time.Duration) *Server {
+	s := NewServer()
+	s.storage = storage
+	s.pollInterval = pollInterval
+	s.generation = -1
+	return s
+}
+
+func (s *Server) Close() {
+}
+
+// lock locks the Server, and reloads the data from the Storage if it
+// changed. It returns a channel that will be closed after the next
+// change.
+func (s *Server) lock() (<-chan struct{}, error) {
+	s.mu.Lock()
+	if s.storage == nil {
+		return s.changed, nil
+	}
+
+	if err := s.storage.Lock(); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	data, err := s.storage.Load()
+	if err == nil {
+		c := contents{}
+		if data != nil {
+			err = json.Unmarshal(data, &c)
+		}
+		if err == nil && c.Generation != s.generation {
+			s.root = c.Root
+			if s.root == nil {
+				s.root = newNode("")
+			}
+			s.generation = c.Generation
+			s.notify()
+		}
+	}
+	if err != nil {
+		s.unlock()
+		return nil, err
+	}
+	return s.changed, nil
+}
+
+func (s *Server) unlock() {
+	if s.storage != nil {
+		if err := s.storage.Unlock(); err != nil {
+			log.Warningf("cannot unlock topo storage: %v", err)
+		}
+	}
+	s.mu.Unlock()
+}
+
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// read runs f on the data, and returns the channel that will be
+// closed after the next change.
+func (s *Server) read(f func(root *node) error) (<-chan struct{}, error) {
+	changed, err := s.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer s.unlock()
+	return changed, f(s.root)
+}
+
+// write runs f on the data, and saves the changes it made if it
+// succeeded. f should not change anything if it fails.
+func (s *Server) write(f func(root *node) error) error {
+	if _, err := s.lock(); err != nil {
+		return err
+	}
+	defer s.unlock()
+
+	if err := f(s.root); err != nil {
+		if s.storage != nil {
+			// reload the data next time, just in case
+			s.generation = -1
+		}
+		return err
+	}
+
+	s.generation++
+	if s.storage != nil {
+		data, err := json.Marshal(contents{s.generation, s.root})
+		if err == nil {
+			err = s.storage.Save(data)
+		}
+		if err != nil {
+			s.generation = -1
+			return err
+		}
+	}
+	s.notify()
+	return nil
+}
+
+// poll returns a channel that fires when the Storage should be checked
+// for changes made by other processes, or nil if there is no Storage.
+func (s *Server) poll() <-chan time.Time {
+	if s.storage == nil {
+		return nil
+	}
+	return time.After(s.pollInterval)
+}
@@ -0,0 +1,65 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package memorytopo
+
+import (
+	"testing"
+
+	"github.com/youtube/vitess/go/vt/topo/test"
+)
+
+func TestKeyspace(t *testing.T) {
+	ts := NewServer("test")
+	defer ts.Close()
+	test.CheckKeyspace(t, ts)
+}
+
+func TestShard(t *testing.T) {
+	ts := NewServer("test")
+	defer ts.Close()
+	test.CheckShard(t, ts)
+}
+
+func TestTablet(t *testing.T) {
+	ts := NewServer("test")
+	defer ts.Close()
+	test.CheckTablet(t, ts)
+}
+
+func TestReplicationPaths(t *testing.T) {
+	ts := NewServer("test")
+	defer ts.Close()
+	test.CheckReplicationPaths(t, ts)
+}
+
+func TestServingGraph(t *testing.T) {
+	ts := NewServer("test")
+	defer ts.Close()
+	test.CheckServingGraph(t, ts)
+}
+
+func TestKeyspaceLock(t *testing.T) {
+	ts := NewServer("test")
+	defer ts.Close()
+	test.CheckKeyspaceLock(t, ts)
+}
+
+func TestShardLock(t *testing.T) {
+	ts := NewServer("test")
+	defer ts.Close()
+	test.CheckShardLock(t, ts)
+}
+
+func TestTabletActions(t *testing.T) {
+	ts := NewServer("test")
+	defer ts.Close()
+	test.CheckTabletActions(t, ts)
+}
+
+func TestPid(t *testing.T) {
+	ts := NewServer("test")
+	defer ts.Close()
+	test.CheckPid(t, ts)
+}
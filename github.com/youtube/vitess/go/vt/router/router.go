@@ -0,0 +1,568 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package router implements a server side query router. It serves the
+// same SqlQuery RPC API as vttablet, for a whole keyspace: it finds
+// the shards each query needs using the serving graph and the
+// sqlparser routing, sends the query to all of them, and merges the
+// results. This lets clients use sharded keyspaces without doing the
+// routing themselves, as client2.ShardedConn does.
+package router
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	mproto "github.com/youtube/vitess/go/mysql/proto"
+	rpcproto "github.com/youtube/vitess/go/rpcwrap/proto"
+	"github.com/youtube/vitess/go/stats"
+	"github.com/youtube/vitess/go/sync2"
+	"github.com/youtube/vitess/go/vt/sqlparser"
+	tproto "github.com/youtube/vitess/go/vt/tabletserver/proto"
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+var (
+	routerStats = stats.NewTimings("Router")
+	errorStats  = stats.NewCounters("RouterErrors")
+)
+
+// SqlQuery is the router RPC service. It is registered under the
+// same name as the vttablet service, so the same clients can use it.
+type SqlQuery struct {
+	stc            *srvTopoCache
+	sessionTimeout time.Duration
+
+	// shardConns are indexed by keyspace/shard/tabletType.
+	mu         sync.Mutex
+	shardConns map[string]*shardConn
+	sessions   map[int64]*session
+
+	lastSessionId     sync2.AtomicInt64
+	lastTransactionId sync2.AtomicInt64
+	done              chan struct{}
+}
+
+// NewSqlQuery creates a router for the given cell. The serving graph
+// data is cached for srvCacheTTL. Sessions that are not used for
+// sessionTimeout are closed, and their transactions rolled back.
+// sessionTimeout must be positive.
+func NewSqlQuery(ts topo.Server, cell string, srvCacheTTL, sessionTimeout time.Duration) *SqlQuery {
+	if sessionTimeout <= 0 {
+		panic(fmt.Errorf("router: session timeout must be positive, got %v", sessionTimeout))
+	}
+	sq := &SqlQuery{
+		stc:            newSrvTopoCache(ts, cell, srvCacheTTL),
+		sessionTimeout: sessionTimeout,
+		shardConns:     make(map[string]*shardConn),
+		sessions:       make(map[int64]*session),
+		done:           make(chan struct{}),
+	}
+	// session ids need to be different after a restart
+	sq.lastSessionId.Set(time.Now().UnixNano())
+	sq.lastTransactionId.Set(time.Now().UnixNano())
+	go sq.expireSessions()
+	return sq
+}
+
+// Close stops the router, and closes all the sessions and
+// connections.
+func (sq *SqlQuery) Close() {
+	close(sq.done)
+
+	sq.mu.Lock()
+	sessions := sq.sessions
+	sq.sessions = make(map[int64]*session)
+	sq.mu.Unlock()
+	for _, s := range sessions {
+		s.rollback()
+	}
+
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	for _, sdc := range sq.shardConns {
+		sdc.Close()
+	}
+}
+
+func (sq *SqlQuery) expireSessions() {
+	// Rounded up, so that a 1ns timeout still gives a valid interval.
+	ticker := time.NewTicker((sq.sessionTimeout + 1) / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sq.done:
+			return
+		case <-ticker.C:
+		}
+
+		var expired []*session
+		sq.mu.Lock()
+		for id, s := range sq.sessions {
+			if s.idleTime() > sq.sessionTimeout {
+				expired = append(expired, s)
+				delete(sq.sessions, id)
+			}
+		}
+		sq.mu.Unlock()
+		for _, s := range expired {
+			log.Infof("Closing idle session %v", s.id)
+			s.rollback()
+		}
+	}
+}
+
+func (sq *SqlQuery) getShardConn(keyspace, shard string, tabletType topo.TabletType) *shardConn {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	key := endPointsKey(keyspace, shard, tabletType)
+	sdc, ok := sq.shardConns[key]
+	if !ok {
+		sdc = newShardConn(sq.stc, keyspace, shard, tabletType)
+		sq.shardConns[key] = sdc
+	}
+	return sdc
+}
+
+func (sq *SqlQuery) getSession(sessionId int64) (*session, error) {
+	sq.mu.Lock()
+	defer sq.mu.Unlock()
+	s, ok := sq.sessions[sessionId]
+	if !ok {
+		return nil, fmt.Errorf("retry: Invalid session Id %v", sessionId)
+	}
+	s.touch()
+	return s, nil
+}
+
+// recordError counts errors, and returns them unchanged.
+func recordError(err error) error {
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "retry") {
+			errorStats.Add("Retry", 1)
+		} else {
+			errorStats.Add("Fail", 1)
+		}
+	}
+	return err
+}
+
+//-----------------------------------------------
+// RPC API
+
+func (sq *SqlQuery) GetSessionId(sessionParams *tproto.SessionParams, sessionInfo *tproto.SessionInfo) error {
+	if sessionParams.Keyspace == "" {
+		return fmt.Errorf("fatal: Keyspace required")
+	}
+	tabletType := topo.TYPE_MASTER
+	if sessionParams.TabletType != "" {
+		tabletType = topo.TabletType(sessionParams.TabletType)
+	}
+	if _, err := sq.stc.getKeyspace(sessionParams.Keyspace); err != nil {
+		return recordError(fmt.Errorf("fatal: %v", err))
+	}
+
+	s := &session{
+		sq:         sq,
+		id:         sq.lastSessionId.Add(1),
+		keyspace:   sessionParams.Keyspace,
+		tabletType: tabletType,
+		lastUsed:   time.Now(),
+	}
+	sq.mu.Lock()
+	sq.sessions[s.id] = s
+	sq.mu.Unlock()
+	sessionInfo.SessionId = s.id
+	return nil
+}
+
+func (sq *SqlQuery) Begin(context *rpcproto.Context, session *tproto.Session, txInfo *tproto.TransactionInfo) error {
+	defer routerStats.Record("Begin", time.Now())
+	s, err := sq.getSession(session.SessionId)
+	if err != nil {
+		return recordError(err)
+	}
+	txInfo.TransactionId, err = s.begin()
+	return recordError(err)
+}
+
+func (sq *SqlQuery) Commit(context *rpcproto.Context, session *tproto.Session, noOutput *string) error {
+	defer routerStats.Record("Commit", time.Now())
+	s, err := sq.getSession(session.SessionId)
+	if err != nil {
+		return recordError(err)
+	}
+	return recordError(s.commit(session.TransactionId))
+}
+
+func (sq *SqlQuery) Rollback(context *rpcproto.Context, session *tproto.Session, noOutput *string) error {
+	defer routerStats.Record("Rollback", time.Now())
+	s, err := sq.getSession(session.SessionId)
+	if err != nil {
+		return recordError(err)
+	}
+	if err := s.checkTransaction(session.TransactionId); err != nil {
+		return recordError(err)
+	}
+	return recordError(s.rollback())
+}
+
+func (sq *SqlQuery) CreateReserved(session *tproto.Session, connectionInfo *tproto.ConnectionInfo) error {
+	return fmt.Errorf("error: Reserved connections not supported by the router")
+}
+
+func (sq *SqlQuery) CloseReserved(session *tproto.Session, noOutput *string) error {
+	return fmt.Errorf("error: Reserved connections not supported by the router")
+}
+
+func (sq *SqlQuery) Execute(context *rpcproto.Context, query *tproto.Query, reply *mproto.QueryResult) error {
+	defer routerStats.Record("Execute", time.Now())
+	s, err := sq.getSession(query.SessionId)
+	if err != nil {
+		return recordError(err)
+	}
+	qr, err := s.execute(query)
+	if err != nil {
+		return recordError(err)
+	}
+	*reply = *qr
+	return nil
+}
+
+// the first QueryResult will have Fields set (and Rows nil)
+// the subsequent QueryResult will have Rows set (and Fields nil)
+func (sq *SqlQuery) StreamExecute(context *rpcproto.Context, query *tproto.Query, sendReply func(reply interface{}) error) error {
+	defer routerStats.Record("StreamExecute", time.Now())
+	if query.TransactionId != 0 {
+		return fmt.Errorf("error: Transactions not supported with streaming")
+	}
+	if query.ConnectionId != 0 {
+		return fmt.Errorf("error: Persistent connections not supported with streaming")
+	}
+	s, err := sq.getSession(query.SessionId)
+	if err != nil {
+		return recordError(err)
+	}
+	return recordError(s.streamExecute(query, sendReply))
+}
+
+func (sq *SqlQuery) ExecuteBatch(context *rpcproto.Context, queryList *tproto.QueryList, reply *tproto.QueryResultList) error {
+	defer routerStats.Record("ExecuteBatch", time.Now())
+	ql := queryList.List
+	if len(ql) == 0 {
+		return fmt.Errorf("error: Empty query list")
+	}
+	s, err := sq.getSession(ql[0].SessionId)
+	if err != nil {
+		return recordError(err)
+	}
+
+	transactionId := ql[0].TransactionId
+	beginCalled := false
+	reply.List = make([]mproto.QueryResult, 0, len(ql))
+	for _, query := range ql {
+		switch strings.ToLower(strings.Trim(query.Sql, " \t\r\n")) {
+		case "begin":
+			if transactionId != 0 {
+				return recordError(fmt.Errorf("error: Nested transactions disallowed"))
+			}
+			if transactionId, err = s.begin(); err != nil {
+				return recordError(err)
+			}
+			beginCalled = true
+			reply.List = append(reply.List, mproto.QueryResult{})
+		case "commit":
+			if !beginCalled {
+				return recordError(fmt.Errorf("error: Cannot commit without begin"))
+			}
+			if err = s.commit(transactionId); err != nil {
+				return recordError(err)
+			}
+			transactionId = 0
+			beginCalled = false
+			reply.List = append(reply.List, mproto.QueryResult{})
+		default:
+			query.TransactionId = transactionId
+			qr, err := s.execute(&query)
+			if err != nil {
+				if beginCalled {
+					s.rollback()
+				}
+				return recordError(err)
+			}
+			reply.List = append(reply.List, *qr)
+		}
+	}
+	if beginCalled {
+		s.rollback()
+		return recordError(fmt.Errorf("error: begin called with no commit"))
+	}
+	return nil
+}
+
+//-----------------------------------------------
+// session
+
+// session is the router side of a client session. It is bound to a
+// keyspace and a tablet type. It has at most one transaction at a
+// time, that spans all the shards the queries went to.
+type session struct {
+	sq         *SqlQuery
+	id         int64
+	keyspace   string
+	tabletType topo.TabletType
+
+	mu       sync.Mutex
+	lastUsed time.Time
+
+	// transactionId is the id the client knows the transaction by,
+	// 0 if there is no transaction. shardTxs maps the shards
+	// involved in the transaction to their own transaction id.
+	transactionId int64
+	shardTxs      map[*shardConn]int64
+}
+
+func (s *session) touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastUsed = time.Now()
+}
+
+func (s *session) idleTime() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Now().Sub(s.lastUsed)
+}
+
+func (s *session) checkTransaction(transactionId int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if transactionId == 0 || transactionId != s.transactionId {
+		return fmt.Errorf("error: Transaction %v not found", transactionId)
+	}
+	return nil
+}
+
+func (s *session) begin() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.transactionId != 0 {
+		return 0, fmt.Errorf("error: Nested transactions disallowed")
+	}
+	s.transactionId = s.sq.lastTransactionId.Add(1)
+	s.shardTxs = make(map[*shardConn]int64)
+	return s.transactionId, nil
+}
+
+// shardTransaction returns the transaction id to use for the shard,
+// beginning a transaction on it if needed.
+func (s *session) shardTransaction(transactionId int64, sdc *shardConn) (int64, error) {
+	if transactionId == 0 {
+		return 0, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if transactionId != s.transactionId {
+		return 0, fmt.Errorf("error: Transaction %v not found", transactionId)
+	}
+	if shardTx, ok := s.shardTxs[sdc]; ok {
+		return shardTx, nil
+	}
+	shardTx, err := sdc.Begin()
+	if err != nil {
+		return 0, err
+	}
+	s.shardTxs[sdc] = shardTx
+	return shardTx, nil
+}
+
+// commit commits the transaction on all its shards, in no particular
+// order. If one commit fails, the remaining shards are rolled back,
+// so the transaction may have been partially committed.
+func (s *session) commit(transactionId int64) error {
+	if err := s.checkTransaction(transactionId); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	shardTxs := s.shardTxs
+	s.transactionId = 0
+	s.shardTxs = nil
+	s.mu.Unlock()
+
+	var err error
+	for sdc, shardTx := range shardTxs {
+		if err != nil {
+			sdc.Rollback(shardTx)
+			continue
+		}
+		if err = sdc.Commit(shardTx); err != nil {
+			err = fmt.Errorf("error: commit failed on %v/%v, transaction may be partially committed: %v", sdc.keyspace, sdc.shard, err)
+		}
+	}
+	return err
+}
+
+// rollback rolls back the current transaction, if any.
+func (s *session) rollback() error {
+	s.mu.Lock()
+	shardTxs := s.shardTxs
+	s.transactionId = 0
+	s.shardTxs = nil
+	s.mu.Unlock()
+
+	var err error
+	for sdc, shardTx := range shardTxs {
+		if rerr := sdc.Rollback(shardTx); rerr != nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// shardConns returns the connections to the shards the query has
+// to go to.
+func (s *session) shardConns(query *tproto.Query) ([]*shardConn, error) {
+	ki, err := s.sq.stc.getKeyspace(s.keyspace)
+	if err != nil {
+		return nil, err
+	}
+	// unsharded keyspaces don't need the query to be parsed
+	shards := []int{0}
+	if len(ki.shardMaxKeys) > 1 {
+		shards, err = sqlparser.GetShardList(query.Sql, query.BindVariables, ki.shardMaxKeys)
+		if err != nil {
+			return nil, fmt.Errorf("error: %v", err)
+		}
+	}
+	sdcs := make([]*shardConn, len(shards))
+	for i, shard := range shards {
+		sdcs[i] = s.sq.getShardConn(s.keyspace, ki.shardNames[shard], s.tabletType)
+	}
+	return sdcs, nil
+}
+
+type shardResult struct {
+	qr  *mproto.QueryResult
+	err error
+}
+
+// execute sends the query to all the shards it needs in parallel,
+// and merges the results.
+func (s *session) execute(query *tproto.Query) (*mproto.QueryResult, error) {
+	sdcs, err := s.shardConns(query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]shardResult, len(sdcs))
+	var wg sync.WaitGroup
+	for i, sdc := range sdcs {
+		wg.Add(1)
+		go func(i int, sdc *shardConn) {
+			defer wg.Done()
+			shardTx, err := s.shardTransaction(query.TransactionId, sdc)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			results[i].qr, results[i].err = sdc.Execute(query.Sql, query.BindVariables, shardTx)
+		}(i, sdc)
+	}
+	wg.Wait()
+
+	for i, r := range results {
+		if r.err != nil {
+			if len(results) > 1 {
+				return nil, fmt.Errorf("%v (from %v/%v, partial result set)", r.err, sdcs[i].keyspace, sdcs[i].shard)
+			}
+			return nil, r.err
+		}
+	}
+	return mergeResults(results)
+}
+
+// mergeResults combines the results from multiple shards. They all
+// need to have the same fields.
+func mergeResults(results []shardResult) (*mproto.QueryResult, error) {
+	merged := &mproto.QueryResult{}
+	for i, r := range results {
+		if i == 0 {
+			merged.Fields = r.qr.Fields
+		} else if err := checkFields(merged.Fields, r.qr.Fields); err != nil {
+			return nil, err
+		}
+		merged.RowsAffected += r.qr.RowsAffected
+		if merged.InsertId == 0 {
+			merged.InsertId = r.qr.InsertId
+		}
+		merged.Rows = append(merged.Rows, r.qr.Rows...)
+	}
+	return merged, nil
+}
+
+func checkFields(fields, others []mproto.Field) error {
+	if len(fields) != len(others) {
+		return fmt.Errorf("error: column count mismatch: %v != %v", len(fields), len(others))
+	}
+	for i, field := range others {
+		if field.Name != fields[i].Name {
+			return fmt.Errorf("error: column[%v] name mismatch: %v != %v", i, field.Name, fields[i].Name)
+		}
+	}
+	return nil
+}
+
+// streamExecute streams the query from all the shards it needs in
+// parallel. The fields are sent once, and then the rows as they come.
+func (s *session) streamExecute(query *tproto.Query, sendReply func(reply interface{}) error) error {
+	sdcs, err := s.shardConns(query)
+	if err != nil {
+		return err
+	}
+
+	// mu serializes the replies, and protects fields and sendErr.
+	var mu sync.Mutex
+	var fields []mproto.Field
+	var sendErr error
+	shardErrs := make([]error, len(sdcs))
+	var wg sync.WaitGroup
+	for i, sdc := range sdcs {
+		wg.Add(1)
+		go func(i int, sdc *shardConn) {
+			defer wg.Done()
+			shardErrs[i] = sdc.StreamExecute(query.Sql, query.BindVariables, func(qr *mproto.QueryResult) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if sendErr != nil {
+					return sendErr
+				}
+				if qr.Fields != nil {
+					if fields != nil {
+						sendErr = checkFields(fields, qr.Fields)
+						return sendErr
+					}
+					fields = qr.Fields
+				}
+				sendErr = sendReply(qr)
+				return sendErr
+			})
+		}(i, sdc)
+	}
+	wg.Wait()
+
+	if sendErr != nil {
+		return sendErr
+	}
+	for i, err := range shardErrs {
+		if err != nil {
+			return fmt.Errorf("%v (from %v/%v)", err, sdcs[i].keyspace, sdcs[i].shard)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mproto "github.com/youtube/vitess/go/mysql/proto"
+	"github.com/youtube/vitess/go/sqltypes"
+	"github.com/youtube/vitess/go/vt/key"
+	"github.com/youtube/vitess/go/vt/memorytopo"
+	tproto "github.com/youtube/vitess/go/vt/tabletserver/proto"
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+// fakeTabletConn returns one row with the shard name for every
+// query, and records what it was asked to do.
+type fakeTabletConn struct {
+	shard string
+
+	mu      sync.Mutex
+	queries []string
+	lastTx  int64
+	closed  bool
+}
+
+var fakeFields = []mproto.Field{{Name: "shard", Type: 253}}
+
+func (conn *fakeTabletConn) record(query string) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	conn.queries = append(conn.queries, query)
+}
+
+func (conn *fakeTabletConn) result() *mproto.QueryResult {
+	return &mproto.QueryResult{
+		Fields:       fakeFields,
+		RowsAffected: 1,
+		Rows:         [][]sqltypes.Value{{sqltypes.MakeString([]byte(conn.shard))}},
+	}
+}
+
+func (conn *fakeTabletConn) Execute(query string, bindVars map[string]interface{}, transactionId int64) (*mproto.QueryResult, error) {
+	if strings.HasPrefix(query, "select fail") {
+		return nil, fmt.Errorf("error: failed on %v", conn.shard)
+	}
+	conn.record(fmt.Sprintf("%v %v", query, transactionId))
+	return conn.result(), nil
+}
+
+func (conn *fakeTabletConn) StreamExecute(query string, bindVars map[string]interface{}, sendReply func(*mproto.QueryResult) error) error {
+	conn.record(query)
+	qr := conn.result()
+	if err := sendReply(&mproto.QueryResult{Fields: qr.Fields}); err != nil {
+		return err
+	}
+	return sendReply(&mproto.QueryResult{Rows: qr.Rows})
+}
+
+func (conn *fakeTabletConn) Begin() (int64, error) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	conn.lastTx++
+	conn.queries = append(conn.queries, fmt.Sprintf("begin %v", conn.lastTx))
+	return conn.lastTx, nil
+}
+
+func (conn *fakeTabletConn) Commit(transactionId int64) error {
+	conn.record(fmt.Sprintf("commit %v", transactionId))
+	return nil
+}
+
+func (conn *fakeTabletConn) Rollback(transactionId int64) error {
+	conn.record(fmt.Sprintf("rollback %v", transactionId))
+	return nil
+}
+
+func (conn *fakeTabletConn) Close() {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	conn.closed = true
+}
+
+func (conn *fakeTabletConn) getQueries() []string {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	result := conn.queries
+	conn.queries = nil
+	return result
+}
+
+// fakeTablets is the dialTablet replacement. It keeps one
+// fakeTabletConn per shard.
+type fakeTablets struct {
+	mu    sync.Mutex
+	conns map[string]*fakeTabletConn
+}
+
+func (ft *fakeTablets) dial(addr, keyspace, shard string) (tabletConn, error) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	conn, ok := ft.conns[shard]
+	if !ok {
+		conn = &fakeTabletConn{shard: shard}
+		ft.conns[shard] = conn
+	}
+	return conn, nil
+}
+
+func (ft *fakeTablets) get(shard string) *fakeTabletConn {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if conn, ok := ft.conns[shard]; ok {
+		return conn
+	}
+	return &fakeTabletConn{}
+}
+
+// newTestRouter creates a router for a keyspace with two shards,
+// -80 and 80-, and one master in each.
+func newTestRouter(t *testing.T) (*SqlQuery, *fakeTablets, int64) {
+	ts := memorytopo.NewServer("test")
+	shards := []topo.SrvShard{
+		{KeyRange: key.KeyRange{Start: "", End: "\x80"}},
+		{KeyRange: key.KeyRange{Start: "\x80", End: ""}},
+	}
+	if err := ts.UpdateSrvKeyspace("test", "test_keyspace", &topo.SrvKeyspace{Shards: shards}); err != nil {
+		t.Fatalf("UpdateSrvKeyspace: %v", err)
+	}
+	for i, shard := range []string{"-80", "80-"} {
+		addrs := topo.NewAddrs()
+		addr := topo.NewAddr(uint32(i), "localhost", 0)
+		addr.NamedPortMap = map[string]int{DefaultPortName: 1234}
+		addrs.Entries = append(addrs.Entries, *addr)
+		if err := ts.UpdateSrvTabletType("test", "test_keyspace", shard, topo.TYPE_MASTER, addrs); err != nil {
+			t.Fatalf("UpdateSrvTabletType: %v", err)
+		}
+	}
+
+	ft := &fakeTablets{conns: make(map[string]*fakeTabletConn)}
+	dialTablet = ft.dial
+	sq := NewSqlQuery(ts, "test", time.Minute, time.Minute)
+
+	var sessionInfo tproto.SessionInfo
+	if err := sq.GetSessionId(&tproto.SessionParams{Keyspace: "test_keyspace"}, &sessionInfo); err != nil {
+		t.Fatalf("GetSessionId: %v", err)
+	}
+	return sq, ft, sessionInfo.SessionId
+}
+
+func resultShards(qr *mproto.QueryResult) []string {
+	var shards []string
+	for _, row := range qr.Rows {
+		shards = append(shards, row[0].String())
+	}
+	sort.Strings(shards)
+	return shards
+}
+
+func TestGetSessionId(t *testing.T) {
+	sq, _, _ := newTestRouter(t)
+	defer sq.Close()
+
+	var sessionInfo tproto.SessionInfo
+	if err := sq.GetSessionId(&tproto.SessionParams{}, &sessionInfo); err == nil {
+		t.Errorf("GetSessionId worked without a keyspace")
+	}
+	if err := sq.GetSessionId(&tproto.SessionParams{Keyspace: "other_keyspace"}, &sessionInfo); err == nil {
+		t.Errorf("GetSessionId worked with an unknown keyspace")
+	}
+	if err := sq.Execute(nil, &tproto.Query{Sql: "select 1", SessionId: 12}, new(mproto.QueryResult)); err == nil || !strings.HasPrefix(err.Error(), "retry") {
+		t.Errorf("Execute with invalid session: %v", err)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	sq, ft, sessionId := newTestRouter(t)
+	defer sq.Close()
+
+	testCases := []struct {
+		sql      string
+		bindVars map[string]interface{}
+		shards   []string
+	}{
+		{"select * from t where entity_id = 1", nil, []string{"-80"}},
+		{"select * from t where entity_id = :id", map[string]interface{}{"id": uint64(0x9000000000000000)}, []string{"80-"}},
+		{"select * from t where entity_id in (1, 2)", nil, []string{"-80"}},
+		{"select * from t", nil, []string{"-80", "80-"}},
+	}
+	for _, tc := range testCases {
+		reply := new(mproto.QueryResult)
+		if err := sq.Execute(nil, &tproto.Query{Sql: tc.sql, BindVariables: tc.bindVars, SessionId: sessionId}, reply); err != nil {
+			t.Errorf("Execute(%v): %v", tc.sql, err)
+			continue
+		}
+		if got := resultShards(reply); fmt.Sprint(got) != fmt.Sprint(tc.shards) {
+			t.Errorf("Execute(%v): got rows from %v, want %v", tc.sql, got, tc.shards)
+		}
+		if reply.RowsAffected != uint64(len(tc.shards)) || len(reply.Fields) != 1 {
+			t.Errorf("Execute(%v): bad merged result %v", tc.sql, reply)
+		}
+	}
+
+	// errors on any shard fail the query
+	if err := sq.Execute(nil, &tproto.Query{Sql: "select fail from t", SessionId: sessionId}, new(mproto.QueryResult)); err == nil || !strings.Contains(err.Error(), "partial result set") {
+		t.Errorf("Execute(select fail): %v", err)
+	}
+
+	// both connections are shared across sessions
+	if len(ft.conns) != 2 {
+		t.Errorf("want 2 tablet connections, got %v", ft.conns)
+	}
+}
+
+func TestStreamExecute(t *testing.T) {
+	sq, _, sessionId := newTestRouter(t)
+	defer sq.Close()
+
+	var replies []*mproto.QueryResult
+	err := sq.StreamExecute(nil, &tproto.Query{Sql: "select * from t", SessionId: sessionId}, func(reply interface{}) error {
+		replies = append(replies, reply.(*mproto.QueryResult))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamExecute: %v", err)
+	}
+
+	// the fields are only sent once, first
+	if len(replies) != 3 || replies[0].Fields == nil || replies[1].Fields != nil || replies[2].Fields != nil {
+		t.Fatalf("StreamExecute: bad replies %v", replies)
+	}
+	merged := &mproto.QueryResult{Rows: append(replies[1].Rows, replies[2].Rows...)}
+	if got := resultShards(merged); fmt.Sprint(got) != "[-80 80-]" {
+		t.Errorf("StreamExecute: got rows from %v", got)
+	}
+
+	if err := sq.StreamExecute(nil, &tproto.Query{Sql: "select * from t", SessionId: sessionId, TransactionId: 1}, nil); err == nil {
+		t.Errorf("StreamExecute worked in a transaction")
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	sq, ft, sessionId := newTestRouter(t)
+	defer sq.Close()
+
+	var txInfo tproto.TransactionInfo
+	session := &tproto.Session{SessionId: sessionId}
+	if err := sq.Begin(nil, session, &txInfo); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err := sq.Begin(nil, session, new(tproto.TransactionInfo)); err == nil {
+		t.Errorf("nested Begin worked")
+	}
+
+	// the transaction is started on each shard when it's first used
+	for _, sql := range []string{"update t set a = 1 where entity_id = 1", "select * from t"} {
+		if err := sq.Execute(nil, &tproto.Query{Sql: sql, SessionId: sessionId, TransactionId: txInfo.TransactionId}, new(mproto.QueryResult)); err != nil {
+			t.Fatalf("Execute(%v): %v", sql, err)
+		}
+	}
+	if err := sq.Execute(nil, &tproto.Query{Sql: "select * from t", SessionId: sessionId, TransactionId: txInfo.TransactionId + 1}, new(mproto.QueryResult)); err == nil {
+		t.Errorf("Execute worked with the wrong transaction id")
+	}
+
+	session.TransactionId = txInfo.TransactionId
+	if err := sq.Commit(nil, session, new(string)); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if err := sq.Commit(nil, session, new(string)); err == nil {
+		t.Errorf("second Commit worked")
+	}
+
+	want := map[string]string{
+		"-80": "[begin 1 update t set a = 1 where entity_id = 1 1 select * from t 1 commit 1]",
+		"80-": "[begin 1 select * from t 1 commit 1]",
+	}
+	for shard, queries := range want {
+		if got := fmt.Sprint(ft.get(shard).getQueries()); got != queries {
+			t.Errorf("shard %v: got %v, want %v", shard, got, queries)
+		}
+	}
+
+	// rollback
+	if err := sq.Begin(nil, &tproto.Session{SessionId: sessionId}, &txInfo); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err := sq.Execute(nil, &tproto.Query{Sql: "select * from t", SessionId: sessionId, TransactionId: txInfo.TransactionId}, new(mproto.QueryResult)); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if err := sq.Rollback(nil, &tproto.Session{SessionId: sessionId, TransactionId: txInfo.TransactionId}, new(string)); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	for _, shard := range []string{"-80", "80-"} {
+		if got := fmt.Sprint(ft.get(shard).getQueries()); got != "[begin 2 select * from t 2 rollback 2]" {
+			t.Errorf("shard %v: got %v", shard, got)
+		}
+	}
+}
+
+func TestExecuteBatch(t *testing.T) {
+	sq, ft, sessionId := newTestRouter(t)
+	defer sq.Close()
+
+	queryList := &tproto.QueryList{List: []tproto.Query{
+		{Sql: "begin", SessionId: sessionId},
+		{Sql: "update t set a = 1 where entity_id = 1", SessionId: sessionId},
+		{Sql: "commit", SessionId: sessionId},
+		{Sql: "select * from t", SessionId: sessionId},
+	}}
+	reply := new(tproto.QueryResultList)
+	if err := sq.ExecuteBatch(nil, queryList, reply); err != nil {
+		t.Fatalf("ExecuteBatch: %v", err)
+	}
+	if len(reply.List) != 4 || len(reply.List[3].Rows) != 2 {
+		t.Errorf("ExecuteBatch: bad reply %v", reply.List)
+	}
+	if got := fmt.Sprint(ft.get("-80").getQueries()); got != "[begin 1 update t set a = 1 where entity_id = 1 1 commit 1 select * from t 0]" {
+		t.Errorf("ExecuteBatch: got %v", got)
+	}
+}
+
+func TestNewSqlQueryRejectsNonPositiveTimeout(t *testing.T) {
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewSqlQuery with session timeout %v did not panic", timeout)
+				}
+			}()
+			NewSqlQuery(nil, "test", time.Minute, timeout)
+		}()
+	}
+}
+
+func TestExpireSessions(t *testing.T) {
+	sq, ft, sessionId := newTestRouter(t)
+	sq.Close()
+	sq = NewSqlQuery(sq.stc.ts, "test", time.Minute, 20*time.Millisecond)
+	defer sq.Close()
+
+	var sessionInfo tproto.SessionInfo
+	if err := sq.GetSessionId(&tproto.SessionParams{Keyspace: "test_keyspace"}, &sessionInfo); err != nil {
+		t.Fatalf("GetSessionId: %v", err)
+	}
+	sessionId = sessionInfo.SessionId
+	var txInfo tproto.TransactionInfo
+	if err := sq.Begin(nil, &tproto.Session{SessionId: sessionId}, &txInfo); err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	if err := sq.Execute(nil, &tproto.Query{Sql: "select * from t where entity_id = 1", SessionId: sessionId, TransactionId: txInfo.TransactionId}, new(mproto.QueryResult)); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	ft.get("-80").getQueries()
+
+	time.Sleep(100 * time.Millisecond)
+	if err := sq.Execute(nil, &tproto.Query{Sql: "select 1", SessionId: sessionId}, new(mproto.QueryResult)); err == nil {
+		t.Errorf("Execute worked on an expired session")
+	}
+	if got := fmt.Sprint(ft.get("-80").getQueries()); got != "[rollback 1]" {
+		t.Errorf("expired session: got %v", got)
+	}
+}
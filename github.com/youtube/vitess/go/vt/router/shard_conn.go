@@ -0,0 +1,153 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"sync"
+
+	log "github.com/golang/glog"
+	mproto "github.com/youtube/vitess/go/mysql/proto"
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+// DefaultPortName is the named port of the vttablet SqlQuery service.
+const DefaultPortName = "_vtocc"
+
+// shardConn maintains a connection to one of the tablets of a given
+// type in a shard. The connection is shared by all the sessions.
+// It is established on demand, and dropped when it fails, so the next
+// query connects again, possibly to another tablet.
+type shardConn struct {
+	stc        *srvTopoCache
+	keyspace   string
+	shard      string
+	tabletType topo.TabletType
+
+	mu   sync.Mutex
+	conn tabletConn
+}
+
+func newShardConn(stc *srvTopoCache, keyspace, shard string, tabletType topo.TabletType) *shardConn {
+	return &shardConn{
+		stc:        stc,
+		keyspace:   keyspace,
+		shard:      shard,
+		tabletType: tabletType,
+	}
+}
+
+func (sdc *shardConn) getConn() (tabletConn, error) {
+	sdc.mu.Lock()
+	defer sdc.mu.Unlock()
+	if sdc.conn != nil {
+		return sdc.conn, nil
+	}
+
+	addrs, err := sdc.stc.getEndPoints(sdc.keyspace, sdc.shard, sdc.tabletType)
+	if err != nil {
+		return nil, err
+	}
+	srvs, err := topo.SrvEntries(addrs, DefaultPortName)
+	if err != nil {
+		return nil, err
+	}
+	if len(srvs) == 0 {
+		return nil, fmt.Errorf("retry: no %v tablet available for %v/%v", sdc.tabletType, sdc.keyspace, sdc.shard)
+	}
+
+	// Try to connect to any address.
+	for _, srv := range srvs {
+		sdc.conn, err = dialTablet(topo.SrvAddr(srv), sdc.keyspace, sdc.shard)
+		if err == nil {
+			return sdc.conn, nil
+		}
+		log.Warningf("cannot connect to %v for %v/%v: %v", topo.SrvAddr(srv), sdc.keyspace, sdc.shard, err)
+	}
+	sdc.stc.invalidate(sdc.keyspace, sdc.shard, sdc.tabletType)
+	return nil, fmt.Errorf("retry: cannot connect to %v/%v: %v", sdc.keyspace, sdc.shard, err)
+}
+
+// checkError drops the connection if err shows it cannot be used
+// any more.
+func (sdc *shardConn) checkError(conn tabletConn, err error) {
+	if err == nil || !isConnError(err) {
+		return
+	}
+	sdc.mu.Lock()
+	defer sdc.mu.Unlock()
+	if sdc.conn == conn {
+		log.Warningf("dropping connection for %v/%v: %v", sdc.keyspace, sdc.shard, err)
+		sdc.conn.Close()
+		sdc.conn = nil
+		sdc.stc.invalidate(sdc.keyspace, sdc.shard, sdc.tabletType)
+	}
+}
+
+// Execute runs the query on the shard. Queries outside of
+// transactions are retried once if the connection failed.
+func (sdc *shardConn) Execute(query string, bindVars map[string]interface{}, transactionId int64) (qr *mproto.QueryResult, err error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		var conn tabletConn
+		if conn, err = sdc.getConn(); err != nil {
+			return nil, err
+		}
+		qr, err = conn.Execute(query, bindVars, transactionId)
+		sdc.checkError(conn, err)
+		if err == nil || transactionId != 0 || !isConnError(err) {
+			break
+		}
+	}
+	return qr, err
+}
+
+func (sdc *shardConn) StreamExecute(query string, bindVars map[string]interface{}, sendReply func(*mproto.QueryResult) error) error {
+	conn, err := sdc.getConn()
+	if err != nil {
+		return err
+	}
+	err = conn.StreamExecute(query, bindVars, sendReply)
+	sdc.checkError(conn, err)
+	return err
+}
+
+func (sdc *shardConn) Begin() (transactionId int64, err error) {
+	conn, err := sdc.getConn()
+	if err != nil {
+		return 0, err
+	}
+	transactionId, err = conn.Begin()
+	sdc.checkError(conn, err)
+	return transactionId, err
+}
+
+func (sdc *shardConn) Commit(transactionId int64) error {
+	conn, err := sdc.getConn()
+	if err != nil {
+		return err
+	}
+	err = conn.Commit(transactionId)
+	sdc.checkError(conn, err)
+	return err
+}
+
+func (sdc *shardConn) Rollback(transactionId int64) error {
+	conn, err := sdc.getConn()
+	if err != nil {
+		return err
+	}
+	err = conn.Rollback(transactionId)
+	sdc.checkError(conn, err)
+	return err
+}
+
+func (sdc *shardConn) Close() {
+	sdc.mu.Lock()
+	defer sdc.mu.Unlock()
+	if sdc.conn != nil {
+		sdc.conn.Close()
+		sdc.conn = nil
+	}
+}
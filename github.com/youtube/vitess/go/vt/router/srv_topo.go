@@ -0,0 +1,167 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/youtube/vitess/go/vt/key"
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+// keyspaceInfo is the routing information for a keyspace, derived
+// from its SrvKeyspace.
+type keyspaceInfo struct {
+	srvKeyspace *topo.SrvKeyspace
+
+	// shardMaxKeys is the sorted list of the max keys for each
+	// shard, as used by sqlparser.GetShardList.
+	shardMaxKeys []key.KeyspaceId
+
+	// shardNames are the names of the shards, in the same order.
+	shardNames []string
+}
+
+func newKeyspaceInfo(srvKeyspace *topo.SrvKeyspace) *keyspaceInfo {
+	ki := &keyspaceInfo{
+		srvKeyspace:  srvKeyspace,
+		shardMaxKeys: make([]key.KeyspaceId, len(srvKeyspace.Shards)),
+		shardNames:   make([]string, len(srvKeyspace.Shards)),
+	}
+	for i, srvShard := range srvKeyspace.Shards {
+		ki.shardMaxKeys[i] = srvShard.KeyRange.End
+		ki.shardNames[i] = shardName(i, srvShard.KeyRange)
+	}
+	return ki
+}
+
+// shardName returns the name of a shard in the serving graph.
+// Non-range based shards are named after their index.
+func shardName(index int, keyRange key.KeyRange) string {
+	if !keyRange.IsPartial() {
+		return fmt.Sprintf("%v", index)
+	}
+	return fmt.Sprintf("%v-%v", keyRange.Start.Hex(), keyRange.End.Hex())
+}
+
+// The cache entries remember the last value and error. If reading a
+// value fails after it was read successfully, the old value is used
+// until it can be read again.
+type keyspaceEntry struct {
+	info *keyspaceInfo
+	err  error
+	time time.Time
+}
+
+type endPointsEntry struct {
+	addrs *topo.VtnsAddrs
+	err   error
+	time  time.Time
+}
+
+// srvTopoCache caches the serving graph data of one cell, so every
+// query doesn't have to go to the topo.Server. Entries are read
+// again when they are older than ttl, or when they are invalidated.
+type srvTopoCache struct {
+	ts   topo.Server
+	cell string
+	ttl  time.Duration
+
+	mu        sync.Mutex
+	keyspaces map[string]*keyspaceEntry
+	endPoints map[string]*endPointsEntry
+}
+
+func newSrvTopoCache(ts topo.Server, cell string, ttl time.Duration) *srvTopoCache {
+	return &srvTopoCache{
+		ts:        ts,
+		cell:      cell,
+		ttl:       ttl,
+		keyspaces: make(map[string]*keyspaceEntry),
+		endPoints: make(map[string]*endPointsEntry),
+	}
+}
+
+func endPointsKey(keyspace, shard string, tabletType topo.TabletType) string {
+	return keyspace + "/" + shard + "/" + string(tabletType)
+}
+
+func (stc *srvTopoCache) getKeyspace(keyspace string) (*keyspaceInfo, error) {
+	stc.mu.Lock()
+	defer stc.mu.Unlock()
+
+	entry, ok := stc.keyspaces[keyspace]
+	if ok && time.Now().Sub(entry.time) < stc.ttl {
+		return entry.info, entry.err
+	}
+	if !ok {
+		entry = &keyspaceEntry{}
+		stc.keyspaces[keyspace] = entry
+	}
+	entry.time = time.Now()
+
+	srvKeyspace, err := stc.ts.GetSrvKeyspace(stc.cell, keyspace)
+	if err != nil {
+		if entry.info != nil {
+			log.Warningf("GetSrvKeyspace(%v, %v) failed, using cached value: %v", stc.cell, keyspace, err)
+			return entry.info, nil
+		}
+		entry.err = fmt.Errorf("GetSrvKeyspace(%v, %v) failed: %v", stc.cell, keyspace, err)
+		return nil, entry.err
+	}
+	if len(srvKeyspace.Shards) == 0 {
+		entry.info = nil
+		entry.err = fmt.Errorf("keyspace %v has no shards in cell %v", keyspace, stc.cell)
+		return nil, entry.err
+	}
+	entry.info = newKeyspaceInfo(srvKeyspace)
+	entry.err = nil
+	return entry.info, nil
+}
+
+func (stc *srvTopoCache) getEndPoints(keyspace, shard string, tabletType topo.TabletType) (*topo.VtnsAddrs, error) {
+	stc.mu.Lock()
+	defer stc.mu.Unlock()
+
+	key := endPointsKey(keyspace, shard, tabletType)
+	entry, ok := stc.endPoints[key]
+	if ok && time.Now().Sub(entry.time) < stc.ttl {
+		return entry.addrs, entry.err
+	}
+	if !ok {
+		entry = &endPointsEntry{}
+		stc.endPoints[key] = entry
+	}
+	entry.time = time.Now()
+
+	addrs, err := stc.ts.GetSrvTabletType(stc.cell, keyspace, shard, tabletType)
+	if err != nil {
+		if entry.addrs != nil {
+			log.Warningf("GetSrvTabletType(%v, %v, %v, %v) failed, using cached value: %v", stc.cell, keyspace, shard, tabletType, err)
+			return entry.addrs, nil
+		}
+		entry.err = fmt.Errorf("GetSrvTabletType(%v, %v, %v, %v) failed: %v", stc.cell, keyspace, shard, tabletType, err)
+		return nil, entry.err
+	}
+	entry.addrs = addrs
+	entry.err = nil
+	return entry.addrs, nil
+}
+
+// invalidate makes the next calls read the data for the shard again.
+func (stc *srvTopoCache) invalidate(keyspace, shard string, tabletType topo.TabletType) {
+	stc.mu.Lock()
+	defer stc.mu.Unlock()
+
+	if entry, ok := stc.keyspaces[keyspace]; ok {
+		entry.time = time.Time{}
+	}
+	if entry, ok := stc.endPoints[endPointsKey(keyspace, shard, tabletType)]; ok {
+		entry.time = time.Time{}
+	}
+}
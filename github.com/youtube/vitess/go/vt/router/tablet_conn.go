@@ -0,0 +1,117 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package router
+
+import (
+	"strings"
+
+	mproto "github.com/youtube/vitess/go/mysql/proto"
+	"github.com/youtube/vitess/go/rpcplus"
+	"github.com/youtube/vitess/go/rpcwrap/bsonrpc"
+	tproto "github.com/youtube/vitess/go/vt/tabletserver/proto"
+)
+
+// tabletConn is a connection to the SqlQuery service of a vttablet.
+// It can be used concurrently, the transactions are identified by
+// their transaction id.
+type tabletConn interface {
+	Execute(query string, bindVars map[string]interface{}, transactionId int64) (*mproto.QueryResult, error)
+	StreamExecute(query string, bindVars map[string]interface{}, sendReply func(*mproto.QueryResult) error) error
+	Begin() (transactionId int64, err error)
+	Commit(transactionId int64) error
+	Rollback(transactionId int64) error
+	Close()
+}
+
+// dialTablet connects to the vttablet at addr, serving the given
+// keyspace and shard. It is a variable so tests can replace it.
+var dialTablet = func(addr, keyspace, shard string) (tabletConn, error) {
+	rpcClient, err := bsonrpc.DialHTTP("tcp", addr, 0)
+	if err != nil {
+		return nil, err
+	}
+	var sessionInfo tproto.SessionInfo
+	if err = rpcClient.Call("SqlQuery.GetSessionId", tproto.SessionParams{Keyspace: keyspace, Shard: shard}, &sessionInfo); err != nil {
+		rpcClient.Close()
+		return nil, err
+	}
+	return &rpcTabletConn{rpcClient: rpcClient, sessionId: sessionInfo.SessionId}, nil
+}
+
+// rpcTabletConn is the bsonrpc implementation of tabletConn.
+type rpcTabletConn struct {
+	rpcClient *rpcplus.Client
+	sessionId int64
+}
+
+func (conn *rpcTabletConn) Execute(query string, bindVars map[string]interface{}, transactionId int64) (*mproto.QueryResult, error) {
+	req := &tproto.Query{
+		Sql:           query,
+		BindVariables: bindVars,
+		TransactionId: transactionId,
+		SessionId:     conn.sessionId,
+	}
+	qr := new(mproto.QueryResult)
+	if err := conn.rpcClient.Call("SqlQuery.Execute", req, qr); err != nil {
+		return nil, err
+	}
+	return qr, nil
+}
+
+func (conn *rpcTabletConn) StreamExecute(query string, bindVars map[string]interface{}, sendReply func(*mproto.QueryResult) error) error {
+	req := &tproto.Query{
+		Sql:           query,
+		BindVariables: bindVars,
+		SessionId:     conn.sessionId,
+	}
+	sr := make(chan *mproto.QueryResult, 10)
+	call := conn.rpcClient.StreamGo("SqlQuery.StreamExecute", req, sr)
+
+	// keep reading the stream after an error, so it can finish
+	var err error
+	for qr := range sr {
+		if err == nil {
+			err = sendReply(qr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return call.Error
+}
+
+func (conn *rpcTabletConn) Begin() (transactionId int64, err error) {
+	var txInfo tproto.TransactionInfo
+	if err := conn.rpcClient.Call("SqlQuery.Begin", &tproto.Session{SessionId: conn.sessionId}, &txInfo); err != nil {
+		return 0, err
+	}
+	return txInfo.TransactionId, nil
+}
+
+func (conn *rpcTabletConn) Commit(transactionId int64) error {
+	var noOutput string
+	return conn.rpcClient.Call("SqlQuery.Commit", &tproto.Session{SessionId: conn.sessionId, TransactionId: transactionId}, &noOutput)
+}
+
+func (conn *rpcTabletConn) Rollback(transactionId int64) error {
+	var noOutput string
+	return conn.rpcClient.Call("SqlQuery.Rollback", &tproto.Session{SessionId: conn.sessionId, TransactionId: transactionId}, &noOutput)
+}
+
+func (conn *rpcTabletConn) Close() {
+	conn.rpcClient.Close()
+}
+
+// isConnError returns true if the error means the connection to the
+// tablet cannot be used any more: the tablet is going away, or
+// the connection itself failed. Other errors are application errors.
+func isConnError(err error) bool {
+	serverError, ok := err.(rpcplus.ServerError)
+	if !ok {
+		return true
+	}
+	msg := string(serverError)
+	return strings.HasPrefix(msg, "retry") || strings.HasPrefix(msg, "fatal")
+}
@@ -204,8 +204,10 @@ func FormatNode(buf *TrackedBuffer, node *Node) {
 	case JOIN, STRAIGHT_JOIN, LEFT, RIGHT, CROSS, NATURAL:
 		buf.Fprintf("%v %s %v", node.At(0), node.Value, node.At(1))
 		if node.Len() > 2 {
-			buf.Fprintf(" on %v", node.At(2))
+			buf.Fprintf(" %v", node.At(2))
 		}
+	case ON, USING:
+		buf.Fprintf("%s %v", node.Value, node.At(0))
 	case DUPLICATE:
 		if node.Len() != 0 {
 			buf.Fprintf(" on duplicate key update %v", node.At(0))
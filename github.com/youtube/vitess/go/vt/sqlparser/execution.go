@@ -80,6 +80,9 @@ const (
 	REASON_TABLE_NOINDEX
 	REASON_PK_CHANGE
 	REASON_HAS_HINTS
+	REASON_UNION
+	REASON_JOIN
+	REASON_SUBQUERY
 )
 
 // Must exactly match order of reason constants.
@@ -97,6 +100,9 @@ var reasonName = []string{
 	"TABLE_NOINDEX",
 	"PK_CHANGE",
 	"HAS_HINTS",
+	"UNION",
+	"JOIN",
+	"SUBQUERY",
 }
 
 func (rt ReasonType) String() string {
@@ -246,15 +252,20 @@ func (node *Node) execAnalyzeSelect(getTable TableGetter) (plan *ExecPlan) {
 		return plan
 	}
 
+	if node.isUnion() {
+		plan.Reason = REASON_UNION
+		return plan
+	}
+
 	if !node.execAnalyzeSelectStructure() {
 		plan.Reason = REASON_SELECT
 		return plan
 	}
 
 	// from
-	tableName, hasHints := node.At(SELECT_FROM_OFFSET).execAnalyzeFrom()
+	tableName, hasHints, reason := node.At(SELECT_FROM_OFFSET).execAnalyzeFrom()
 	if tableName == "" {
-		plan.Reason = REASON_TABLE
+		plan.Reason = reason
 		return plan
 	}
 	tableInfo := plan.setTableInfo(tableName, getTable)
@@ -282,7 +293,7 @@ func (node *Node) execAnalyzeSelect(getTable TableGetter) (plan *ExecPlan) {
 	// where
 	conditions := node.At(SELECT_WHERE_OFFSET).execAnalyzeWhere()
 	if conditions == nil {
-		plan.Reason = REASON_WHERE
+		plan.Reason = node.At(SELECT_WHERE_OFFSET).whereReason()
 		return plan
 	}
 
@@ -397,7 +408,7 @@ func (node *Node) execAnalyzeUpdate(getTable TableGetter) (plan *ExecPlan) {
 
 	conditions := node.At(UPDATE_WHERE_OFFSET).execAnalyzeWhere()
 	if conditions == nil {
-		plan.Reason = REASON_WHERE
+		plan.Reason = node.At(UPDATE_WHERE_OFFSET).whereReason()
 		return plan
 	}
 
@@ -430,7 +441,7 @@ func (node *Node) execAnalyzeDelete(getTable TableGetter) (plan *ExecPlan) {
 
 	conditions := node.At(DELETE_WHERE_OFFSET).execAnalyzeWhere()
 	if conditions == nil {
-		plan.Reason = REASON_WHERE
+		plan.Reason = node.At(DELETE_WHERE_OFFSET).whereReason()
 		return plan
 	}
 
@@ -474,9 +485,16 @@ func (node *ExecPlan) setTableInfo(tableName string, getTable TableGetter) *sche
 //-----------------------------------------------
 // Select
 
-func (node *Node) execAnalyzeSelectStructure() bool {
+func (node *Node) isUnion() bool {
 	switch node.Type {
 	case UNION, UNION_ALL, MINUS, EXCEPT, INTERSECT:
+		return true
+	}
+	return false
+}
+
+func (node *Node) execAnalyzeSelectStructure() bool {
+	if node.isUnion() {
 		return false
 	}
 	if node.At(SELECT_DISTINCT_OFFSET).Type == DISTINCT {
@@ -530,15 +548,30 @@ func (node *Node) execAnalyzeSelectExpression() (name string) {
 //-----------------------------------------------
 // From
 
-func (node *Node) execAnalyzeFrom() (tablename string, hasHints bool) {
+// execAnalyzeFrom returns the name of the table if the from clause
+// has only one table. Otherwise it returns the reason why the
+// select cannot be improved.
+func (node *Node) execAnalyzeFrom() (tablename string, hasHints bool, reason ReasonType) {
 	if node.Len() > 1 {
-		return "", false
+		return "", false, REASON_JOIN
 	}
-	if node.At(0).Type != TABLE_EXPR {
-		return "", false
+	tableExpr := node.At(0)
+	for tableExpr.Type == '(' {
+		tableExpr = tableExpr.At(0)
+	}
+	switch tableExpr.Type {
+	case JOIN, STRAIGHT_JOIN, LEFT, RIGHT, CROSS, NATURAL:
+		return "", false, REASON_JOIN
+	case TABLE_EXPR:
+	default:
+		return "", false, REASON_TABLE
 	}
-	hasHints = (node.At(0).At(2).Len() > 0)
-	return node.At(0).At(0).collectTableName(), hasHints
+	tablename = tableExpr.At(0).collectTableName()
+	if tablename == "" {
+		return "", false, REASON_SUBQUERY
+	}
+	hasHints = (tableExpr.At(2).Len() > 0)
+	return tablename, hasHints, REASON_DEFAULT
 }
 
 func (node *Node) collectTableName() string {
@@ -564,6 +597,30 @@ func (node *Node) execAnalyzeWhere() (conditions []*Node) {
 	return node.At(0).execAnalyzeBoolean()
 }
 
+// whereReason returns the reason why the where clause could not be
+// analyzed.
+func (node *Node) whereReason() ReasonType {
+	if node.hasSubquery() {
+		return REASON_SUBQUERY
+	}
+	return REASON_WHERE
+}
+
+// hasSubquery returns true if there is a select statement anywhere
+// under node.
+func (node *Node) hasSubquery() bool {
+	for _, sub := range node.Sub {
+		switch sub.Type {
+		case SELECT, UNION, UNION_ALL, MINUS, EXCEPT, INTERSECT:
+			return true
+		}
+		if sub.hasSubquery() {
+			return true
+		}
+	}
+	return false
+}
+
 func (node *Node) execAnalyzeBoolean() (conditions []*Node) {
 	switch node.Type {
 	case AND:
@@ -953,11 +1010,20 @@ func FormatImpossible(buf *TrackedBuffer, node *Node) {
 			node.At(SELECT_FROM_OFFSET),
 		)
 	case JOIN, STRAIGHT_JOIN, CROSS, NATURAL:
-		// We skip ON clauses (if any)
+		// We skip ON clauses (if any), but keep USING clauses because
+		// they merge the joined columns of select *
 		buf.Fprintf("%v %s %v", node.At(0), node.Value, node.At(1))
+		if node.Len() > 2 && node.At(2).Type == USING {
+			buf.Fprintf(" %v", node.At(2))
+		}
 	case LEFT, RIGHT:
-		// ON clause is requried
-		buf.Fprintf("%v %s %v on 1 != 1", node.At(0), node.Value, node.At(1))
+		// ON or USING clause is requried
+		buf.Fprintf("%v %s %v", node.At(0), node.Value, node.At(1))
+		if node.Len() > 2 && node.At(2).Type == USING {
+			buf.Fprintf(" %v", node.At(2))
+		} else {
+			buf.Fprintf(" on 1 != 1")
+		}
 	default:
 		FormatNode(buf, node)
 	}
@@ -1050,6 +1116,9 @@ func (node *Node) GenerateSelectSubquery(tableInfo *schema.Table, index string)
 	hint.Push(NewSimpleParseNode(COLUMN_LIST, ""))
 	hint.At(0).Push(NewSimpleParseNode(ID, index))
 	table_expr := node.At(SELECT_FROM_OFFSET).At(0)
+	for table_expr.Type == '(' {
+		table_expr = table_expr.At(0)
+	}
 	savedHint := table_expr.Sub[2]
 	table_expr.Sub[2] = hint
 	defer func() {
@@ -51,28 +51,7 @@ func shardListFromPlan(plan *RoutingPlan, bindVariables map[string]interface{},
 	if plan.criteria == nil {
 		return makeList(0, len(tabletKeys))
 	}
-
-	switch plan.criteria.Type {
-	case '=', NULL_SAFE_EQUAL:
-		index := plan.criteria.At(1).findShard(bindVariables, tabletKeys)
-		return []int{index}
-	case '<', LE:
-		index := plan.criteria.At(1).findShard(bindVariables, tabletKeys)
-		return makeList(0, index+1)
-	case '>', GE:
-		index := plan.criteria.At(1).findShard(bindVariables, tabletKeys)
-		return makeList(index, len(tabletKeys))
-	case IN:
-		return plan.criteria.At(1).findShardList(bindVariables, tabletKeys)
-	case BETWEEN:
-		start := plan.criteria.At(1).findShard(bindVariables, tabletKeys)
-		last := plan.criteria.At(2).findShard(bindVariables, tabletKeys)
-		if last < start {
-			start, last = last, start
-		}
-		return makeList(start, last+1)
-	}
-	return makeList(0, len(tabletKeys))
+	return plan.criteria.findCriteriaShardList(bindVariables, tabletKeys)
 }
 
 func (node *Node) getRoutingPlan() (plan *RoutingPlan) {
@@ -86,20 +65,72 @@ func (node *Node) getRoutingPlan() (plan *RoutingPlan) {
 			return node.At(INSERT_VALUES_OFFSET).getRoutingPlan()
 		}
 	}
-	var where *Node
 	plan.routingType = ROUTE_BY_CONDITION
+	plan.criteria = node.routingAnalyzeStatement()
+	return plan
+}
+
+// routingAnalyzeStatement returns the conditions on entity_id that
+// the rows read or changed by the statement satisfy. It returns nil
+// if the statement can use rows from any shard.
+func (node *Node) routingAnalyzeStatement() *Node {
 	switch node.Type {
 	case SELECT:
-		where = node.At(SELECT_WHERE_OFFSET)
+		return andCriteria(
+			node.At(SELECT_FROM_OFFSET).routingAnalyzeTables(),
+			node.At(SELECT_WHERE_OFFSET).routingAnalyzeWhere(),
+		)
+	case UNION, UNION_ALL:
+		return orCriteria(node.At(0).routingAnalyzeStatement(), node.At(1).routingAnalyzeStatement())
+	case MINUS, EXCEPT:
+		// Only rows from the left side are returned
+		return node.At(0).routingAnalyzeStatement()
+	case INTERSECT:
+		return andCriteria(node.At(0).routingAnalyzeStatement(), node.At(1).routingAnalyzeStatement())
 	case UPDATE:
-		where = node.At(UPDATE_WHERE_OFFSET)
+		return node.At(UPDATE_WHERE_OFFSET).routingAnalyzeWhere()
 	case DELETE:
-		where = node.At(DELETE_WHERE_OFFSET)
+		return node.At(DELETE_WHERE_OFFSET).routingAnalyzeWhere()
+	}
+	return nil
+}
+
+// routingAnalyzeTables returns the conditions satisfied by the rows
+// coming from a from clause. Derived tables and inner joins restrict
+// the rows, the inner side of outer joins doesn't.
+func (node *Node) routingAnalyzeTables() *Node {
+	switch node.Type {
+	case NODE_LIST:
+		var criteria *Node
+		for i := 0; i < node.Len(); i++ {
+			criteria = andCriteria(criteria, node.At(i).routingAnalyzeTables())
+		}
+		return criteria
+	case '(':
+		return node.At(0).routingAnalyzeTables()
+	case TABLE_EXPR:
+		if node.At(0).Type == '(' { // derived table
+			return node.At(0).At(0).routingAnalyzeStatement()
+		}
+	case JOIN, STRAIGHT_JOIN, CROSS, NATURAL:
+		criteria := andCriteria(node.At(0).routingAnalyzeTables(), node.At(1).routingAnalyzeTables())
+		if node.Len() > 2 && node.At(2).Type == ON {
+			criteria = andCriteria(criteria, node.At(2).At(0).routingAnalyzeBoolean())
+		}
+		return criteria
+	case LEFT:
+		return node.At(0).routingAnalyzeTables()
+	case RIGHT:
+		return node.At(1).routingAnalyzeTables()
 	}
-	if where != nil && where.Len() > 0 {
-		plan.criteria = where.At(0).routingAnalyzeBoolean()
+	return nil
+}
+
+func (node *Node) routingAnalyzeWhere() *Node {
+	if node.Len() == 0 {
+		return nil
 	}
-	return plan
+	return node.At(0).routingAnalyzeBoolean()
 }
 
 func (node *Node) routingAnalyzeValues() *Node {
@@ -114,26 +145,26 @@ func (node *Node) routingAnalyzeValues() *Node {
 	return node
 }
 
+// routingAnalyzeBoolean returns the conditions of a boolean expression
+// that can be used for routing. The comparisons in the result
+// always have entity_id on the left side.
 func (node *Node) routingAnalyzeBoolean() *Node {
 	switch node.Type {
 	case AND:
-		left := node.At(0).routingAnalyzeBoolean()
-		right := node.At(1).routingAnalyzeBoolean()
-		if left != nil && right != nil {
-			return nil
-		} else if left != nil {
-			return left
-		} else {
-			return right
-		}
+		return andCriteria(node.At(0).routingAnalyzeBoolean(), node.At(1).routingAnalyzeBoolean())
+	case OR:
+		return orCriteria(node.At(0).routingAnalyzeBoolean(), node.At(1).routingAnalyzeBoolean())
 	case '(':
 		return node.At(0).routingAnalyzeBoolean()
 	case '=', '<', '>', LE, GE, NULL_SAFE_EQUAL:
 		left := node.At(0).routingAnalyzeValue()
 		right := node.At(1).routingAnalyzeValue()
-		if (left == EID_NODE && right == VALUE_NODE) || (left == VALUE_NODE && right == EID_NODE) {
+		if left == EID_NODE && right == VALUE_NODE {
 			return node
 		}
+		if left == VALUE_NODE && right == EID_NODE {
+			return NewParseNode(reverseOperator(node.Type), node.Value).PushTwo(node.At(1), node.At(0))
+		}
 	case IN:
 		left := node.At(0).routingAnalyzeValue()
 		right := node.At(1).routingAnalyzeValue()
@@ -151,6 +182,44 @@ func (node *Node) routingAnalyzeBoolean() *Node {
 	return nil
 }
 
+// reverseOperator returns the operator to use when the operands
+// of a comparison are swapped.
+func reverseOperator(operator int) int {
+	switch operator {
+	case '<':
+		return '>'
+	case '>':
+		return '<'
+	case LE:
+		return GE
+	case GE:
+		return LE
+	}
+	return operator
+}
+
+// andCriteria combines two routing criteria, either of which may be
+// nil, when both must be satisfied.
+func andCriteria(left, right *Node) *Node {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	return NewSimpleParseNode(AND, "and").PushTwo(left, right)
+}
+
+// orCriteria combines two routing criteria when either may be
+// satisfied. If one of them can be satisfied on any shard, so can
+// the result.
+func orCriteria(left, right *Node) *Node {
+	if left == nil || right == nil {
+		return nil
+	}
+	return NewSimpleParseNode(OR, "or").PushTwo(left, right)
+}
+
 func (node *Node) routingAnalyzeValue() int {
 	switch node.Type {
 	case ID:
@@ -174,6 +243,74 @@ func (node *Node) routingAnalyzeValue() int {
 	return OTHER_NODE
 }
 
+// findCriteriaShardList returns the list of shards that can have rows
+// satisfying the routing criteria. It is never empty.
+func (node *Node) findCriteriaShardList(bindVariables map[string]interface{}, tabletKeys []key.KeyspaceId) []int {
+	switch node.Type {
+	case AND:
+		left := node.At(0).findCriteriaShardList(bindVariables, tabletKeys)
+		right := node.At(1).findCriteriaShardList(bindVariables, tabletKeys)
+		return intersectShardLists(left, right)
+	case OR:
+		left := node.At(0).findCriteriaShardList(bindVariables, tabletKeys)
+		right := node.At(1).findCriteriaShardList(bindVariables, tabletKeys)
+		return unionShardLists(left, right)
+	case '=', NULL_SAFE_EQUAL:
+		index := node.At(1).findShard(bindVariables, tabletKeys)
+		return []int{index}
+	case '<', LE:
+		index := node.At(1).findShard(bindVariables, tabletKeys)
+		return makeList(0, index+1)
+	case '>', GE:
+		index := node.At(1).findShard(bindVariables, tabletKeys)
+		return makeList(index, len(tabletKeys))
+	case IN:
+		return node.At(1).findShardList(bindVariables, tabletKeys)
+	case BETWEEN:
+		start := node.At(1).findShard(bindVariables, tabletKeys)
+		last := node.At(2).findShard(bindVariables, tabletKeys)
+		if last < start {
+			start, last = last, start
+		}
+		return makeList(start, last+1)
+	}
+	return makeList(0, len(tabletKeys))
+}
+
+// intersectShardLists returns the shards that are in both lists. If
+// there are none, no row can satisfy both criteria, and any shard can
+// be used to return the empty result.
+func intersectShardLists(left, right []int) []int {
+	rightset := make(map[int]bool, len(right))
+	for _, index := range right {
+		rightset[index] = true
+	}
+	shardlist := make([]int, 0, len(left))
+	for _, index := range left {
+		if rightset[index] {
+			shardlist = append(shardlist, index)
+		}
+	}
+	if len(shardlist) == 0 {
+		return left[:1]
+	}
+	return shardlist
+}
+
+func unionShardLists(left, right []int) []int {
+	shardset := make(map[int]bool, len(left)+len(right))
+	shardlist := make([]int, 0, len(left)+len(right))
+	for _, list := range [][]int{left, right} {
+		for _, index := range list {
+			if !shardset[index] {
+				shardset[index] = true
+				shardlist = append(shardlist, index)
+			}
+		}
+	}
+	return shardlist
+}
+
 func (node *Node) findShardList(bindVariables map[string]interface{}, tabletKeys []key.KeyspaceId) []int {
 	shardset := make(map[int]bool)
 	switch node.Type {
@@ -1,9 +1,12 @@
+// Code generated by goyacc -o sql.go sql.y. DO NOT EDIT.
+
 //line sql.y:33
 package sqlparser
 
 import __yyfmt__ "fmt"
 
 //line sql.y:33
+
 func SetParseTree(yylex interface{}, root *Node) {
 	tn := yylex.(*Tokenizer)
 	tn.ParseTree = root
@@ -117,26 +120,26 @@ const CROSS = 57395
 const NATURAL = 57396
 const USE = 57397
 const ON = 57398
-const AND = 57399
-const OR = 57400
-const NOT = 57401
-const UNARY = 57402
-const CASE = 57403
-const WHEN = 57404
-const THEN = 57405
-const ELSE = 57406
-const END = 57407
-const CREATE = 57408
-const ALTER = 57409
-const DROP = 57410
-const RENAME = 57411
-const TABLE = 57412
-const INDEX = 57413
-const TO = 57414
-const IGNORE = 57415
-const IF = 57416
-const UNIQUE = 57417
-const USING = 57418
+const USING = 57399
+const AND = 57400
+const OR = 57401
+const NOT = 57402
+const UNARY = 57403
+const CASE = 57404
+const WHEN = 57405
+const THEN = 57406
+const ELSE = 57407
+const END = 57408
+const CREATE = 57409
+const ALTER = 57410
+const DROP = 57411
+const RENAME = 57412
+const TABLE = 57413
+const INDEX = 57414
+const TO = 57415
+const IGNORE = 57416
+const IF = 57417
+const UNIQUE = 57418
 const NODE_LIST = 57419
 const UPLUS = 57420
 const UMINUS = 57421
@@ -157,7 +160,10 @@ const COMMENT_LIST = 57435
 const COLUMN_LIST = 57436
 const TABLE_EXPR = 57437
 
-var yyToknames = []string{
+var yyToknames = [...]string{
+	"$end",
+	"error",
+	"$unk",
 	"SELECT",
 	"INSERT",
 	"UPDATE",
@@ -197,16 +203,16 @@ var yyToknames = []string{
 	"NE",
 	"NULL_SAFE_EQUAL",
 	"LEX_ERROR",
-	" (",
-	" =",
-	" <",
-	" >",
-	" ~",
+	"'('",
+	"'='",
+	"'<'",
+	"'>'",
+	"'~'",
 	"UNION",
 	"MINUS",
 	"EXCEPT",
 	"INTERSECT",
-	" ,",
+	"','",
 	"JOIN",
 	"STRAIGHT_JOIN",
 	"LEFT",
@@ -217,18 +223,19 @@ var yyToknames = []string{
 	"NATURAL",
 	"USE",
 	"ON",
+	"USING",
 	"AND",
 	"OR",
 	"NOT",
-	" &",
-	" |",
-	" ^",
-	" +",
-	" -",
-	" *",
-	" /",
-	" %",
-	" .",
+	"'&'",
+	"'|'",
+	"'^'",
+	"'+'",
+	"'-'",
+	"'*'",
+	"'/'",
+	"'%'",
+	"'.'",
 	"UNARY",
 	"CASE",
 	"WHEN",
@@ -245,7 +252,6 @@ var yyToknames = []string{
 	"IGNORE",
 	"IF",
 	"UNIQUE",
-	"USING",
 	"NODE_LIST",
 	"UPLUS",
 	"UMINUS",
@@ -265,305 +271,326 @@ var yyToknames = []string{
 	"COMMENT_LIST",
 	"COLUMN_LIST",
 	"TABLE_EXPR",
+	"')'",
 }
-var yyStatenames = []string{}
+
+var yyStatenames = [...]string{}
 
 const yyEofCode = 1
 const yyErrCode = 2
-const yyMaxDepth = 200
+const yyInitialStackSize = 16
 
 //line yacctab:1
-var yyExca = []int{
+var yyExca = [...]int{
 	-1, 1,
 	1, -1,
 	-2, 0,
 	-1, 62,
 	34, 43,
 	-2, 38,
-	-1, 167,
+	-1, 166,
 	34, 43,
-	-2, 64,
+	-2, 65,
 }
 
-const yyNprod = 177
 const yyPrivate = 57344
 
-var yyTokenNames []string
-var yyStates []string
-
-const yyLast = 565
-
-var yyAct = []int{
-
-	65, 48, 294, 212, 261, 133, 215, 179, 236, 165,
-	151, 101, 62, 132, 3, 259, 64, 141, 199, 139,
-	147, 59, 226, 227, 228, 229, 230, 100, 231, 232,
-	22, 23, 24, 25, 60, 40, 22, 23, 24, 25,
-	154, 22, 23, 24, 25, 57, 22, 23, 24, 25,
-	105, 106, 32, 49, 259, 253, 36, 34, 222, 52,
-	95, 12, 13, 14, 15, 100, 100, 280, 129, 134,
-	199, 53, 135, 239, 322, 38, 39, 283, 300, 54,
-	277, 37, 105, 106, 128, 131, 282, 92, 255, 146,
-	16, 218, 142, 284, 143, 252, 104, 140, 197, 278,
-	142, 98, 143, 150, 251, 104, 129, 129, 178, 198,
-	186, 184, 185, 258, 188, 189, 190, 191, 192, 193,
-	194, 195, 176, 177, 250, 240, 174, 196, 142, 200,
-	143, 204, 118, 119, 120, 169, 103, 201, 17, 18,
-	20, 19, 238, 149, 116, 117, 118, 119, 120, 129,
-	187, 102, 203, 302, 172, 207, 208, 205, 211, 202,
-	206, 301, 276, 275, 272, 217, 220, 214, 160, 273,
-	274, 226, 227, 228, 229, 230, 233, 231, 232, 237,
-	234, 201, 148, 245, 246, 223, 242, 74, 158, 244,
-	161, 171, 78, 238, 243, 83, 89, 241, 148, 199,
-	249, 130, 75, 76, 77, 90, 270, 309, 168, 289,
-	68, 271, 180, 99, 81, 12, 50, 235, 257, 304,
-	168, 260, 145, 138, 205, 224, 137, 311, 312, 166,
-	213, 290, 67, 281, 268, 269, 79, 80, 157, 159,
-	156, 89, 279, 84, 142, 168, 143, 265, 286, 234,
-	22, 23, 24, 25, 235, 264, 82, 100, 172, 219,
-	170, 163, 162, 292, 295, 291, 287, 113, 114, 115,
-	116, 117, 118, 119, 120, 296, 96, 175, 94, 74,
-	93, 91, 88, 86, 78, 58, 305, 83, 303, 55,
-	46, 87, 306, 63, 75, 76, 77, 307, 288, 129,
-	201, 129, 68, 12, 313, 315, 81, 45, 317, 319,
-	295, 181, 320, 182, 183, 314, 248, 316, 321, 102,
-	323, 152, 97, 43, 67, 41, 74, 210, 79, 80,
-	61, 78, 325, 85, 83, 84, 262, 299, 263, 216,
-	63, 75, 76, 77, 308, 298, 267, 148, 82, 68,
-	47, 326, 26, 81, 318, 12, 27, 12, 113, 114,
-	115, 116, 117, 118, 119, 120, 28, 29, 30, 31,
-	173, 67, 153, 74, 33, 79, 80, 61, 78, 221,
-	155, 83, 84, 35, 51, 56, 144, 130, 75, 76,
-	77, 254, 324, 310, 74, 82, 68, 293, 297, 78,
-	81, 266, 83, 69, 70, 73, 71, 72, 130, 75,
-	76, 77, 256, 209, 107, 66, 12, 68, 67, 167,
-	225, 81, 79, 80, 164, 42, 21, 44, 11, 84,
-	10, 9, 8, 7, 6, 5, 4, 78, 2, 67,
-	83, 1, 82, 79, 80, 0, 130, 75, 76, 77,
-	84, 0, 0, 0, 0, 136, 0, 0, 78, 81,
-	0, 83, 0, 82, 0, 0, 0, 130, 75, 76,
-	77, 0, 0, 0, 0, 0, 136, 0, 0, 0,
-	81, 79, 80, 0, 0, 0, 0, 0, 84, 0,
-	0, 0, 0, 108, 112, 110, 111, 0, 0, 0,
-	0, 82, 79, 80, 0, 0, 0, 0, 0, 84,
-	124, 125, 126, 127, 0, 0, 121, 122, 123, 0,
-	285, 0, 82, 113, 114, 115, 116, 117, 118, 119,
-	120, 0, 0, 0, 0, 0, 0, 109, 113, 114,
-	115, 116, 117, 118, 119, 120, 247, 0, 0, 113,
-	114, 115, 116, 117, 118, 119, 120, 113, 114, 115,
-	116, 117, 118, 119, 120,
+const yyLast = 528
+
+var yyAct = [...]int{
+	65, 48, 212, 293, 260, 133, 215, 179, 151, 233,
+	165, 147, 62, 141, 258, 101, 64, 258, 139, 59,
+	105, 106, 132, 3, 226, 227, 228, 229, 230, 199,
+	231, 232, 22, 23, 24, 25, 22, 23, 24, 25,
+	60, 100, 258, 100, 40, 100, 22, 23, 24, 25,
+	22, 23, 24, 25, 12, 13, 14, 15, 32, 57,
+	52, 49, 36, 34, 222, 277, 199, 197, 129, 134,
+	95, 160, 135, 328, 53, 142, 324, 143, 38, 39,
+	54, 37, 278, 16, 128, 131, 252, 238, 282, 146,
+	92, 158, 154, 161, 104, 283, 254, 140, 102, 279,
+	281, 257, 249, 150, 239, 186, 129, 129, 178, 250,
+	218, 184, 185, 198, 188, 189, 190, 191, 192, 193,
+	194, 195, 176, 177, 174, 200, 196, 311, 312, 118,
+	119, 120, 17, 18, 20, 19, 142, 201, 143, 251,
+	235, 169, 157, 159, 156, 142, 187, 143, 204, 129,
+	172, 149, 98, 205, 235, 207, 208, 202, 214, 206,
+	104, 203, 105, 106, 220, 217, 302, 211, 113, 114,
+	115, 116, 117, 118, 119, 120, 223, 301, 236, 275,
+	271, 201, 234, 244, 245, 272, 241, 274, 171, 243,
+	237, 148, 103, 299, 300, 240, 269, 175, 273, 74,
+	248, 270, 99, 242, 78, 89, 199, 83, 116, 117,
+	118, 119, 120, 63, 75, 76, 77, 309, 256, 288,
+	205, 259, 68, 148, 90, 74, 81, 317, 180, 168,
+	78, 12, 304, 83, 224, 267, 268, 50, 167, 63,
+	75, 76, 77, 145, 138, 67, 100, 285, 68, 79,
+	80, 61, 81, 137, 213, 289, 84, 22, 23, 24,
+	25, 168, 291, 294, 290, 286, 89, 280, 276, 82,
+	167, 67, 295, 264, 263, 79, 80, 61, 172, 219,
+	170, 163, 84, 162, 96, 305, 303, 94, 93, 91,
+	173, 88, 86, 58, 55, 82, 307, 46, 129, 201,
+	129, 87, 306, 313, 315, 287, 12, 318, 45, 320,
+	294, 247, 152, 321, 314, 97, 316, 74, 322, 102,
+	323, 325, 78, 43, 41, 83, 12, 327, 85, 261,
+	210, 130, 75, 76, 77, 181, 298, 182, 183, 262,
+	68, 216, 74, 297, 81, 266, 148, 78, 47, 329,
+	83, 319, 12, 27, 153, 33, 130, 75, 76, 77,
+	221, 155, 35, 67, 51, 68, 56, 79, 80, 81,
+	12, 144, 253, 326, 84, 142, 310, 143, 292, 296,
+	265, 74, 69, 70, 73, 71, 78, 82, 67, 83,
+	72, 78, 79, 80, 83, 130, 75, 76, 77, 84,
+	130, 75, 76, 77, 68, 255, 26, 78, 81, 136,
+	83, 209, 82, 81, 107, 66, 130, 75, 76, 77,
+	28, 29, 30, 31, 166, 136, 225, 67, 164, 81,
+	42, 79, 80, 21, 44, 11, 79, 80, 84, 10,
+	9, 8, 7, 84, 108, 112, 110, 111, 6, 5,
+	4, 82, 79, 80, 2, 1, 82, 0, 308, 84,
+	0, 124, 125, 126, 127, 0, 0, 121, 122, 123,
+	0, 0, 82, 113, 114, 115, 116, 117, 118, 119,
+	120, 113, 114, 115, 116, 117, 118, 119, 120, 109,
+	113, 114, 115, 116, 117, 118, 119, 120, 284, 0,
+	0, 113, 114, 115, 116, 117, 118, 119, 120, 246,
+	0, 0, 113, 114, 115, 116, 117, 118, 119, 120,
+	226, 227, 228, 229, 230, 0, 231, 232,
 }
-var yyPact = []int{
-
-	57, -1000, -1000, 202, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -33, -32, -4,
-	-10, 351, 308, -1000, -1000, -1000, 305, -1000, 278, 256,
-	342, 182, -30, -15, -1000, -6, -1000, 255, -44, 251,
-	-1000, -1000, 306, -1000, 318, 249, 258, 248, 144, -1000,
-	161, 247, 22, 246, 244, -27, 242, 302, 39, 205,
-	-1000, -1000, 300, 62, 19, 472, -1000, 374, 353, -1000,
-	-1000, 433, 183, -1000, 180, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, 167, -1000, 179, 182, 338, 182,
-	374, -1000, 301, -51, 156, 228, -1000, -1000, 227, 186,
-	306, 226, -1000, 120, 259, 374, 374, 433, 169, 290,
-	433, 433, 85, 433, 433, 433, 433, 433, 433, 433,
-	433, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 472,
-	53, -13, -2, 18, 472, -1000, 412, 306, 351, 51,
-	23, -1000, 374, 374, 299, 196, 189, 327, 374, -1000,
-	-1000, -1000, -1000, 29, 225, -1000, -29, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, 173, 118, 211, 132, -1, -1000,
-	-1000, -1000, -1000, -1000, 14, 306, -1000, -1000, 491, -1000,
-	412, 169, 433, 433, 491, 483, -1000, 291, 75, 75,
-	75, 61, 61, -1000, -1000, -1000, 224, -1000, -1000, 433,
-	-1000, 491, 13, -7, -1000, -1000, 15, -23, -1000, 26,
-	169, 202, 2, -1000, 327, 322, 325, 19, 221, -1000,
-	-1000, 213, -1000, 336, 174, 174, -1000, -1000, 153, 111,
-	117, 110, 109, -31, -12, 351, -1000, 208, -19, 199,
-	-1000, -25, -34, -18, -1000, 491, 457, 433, -1000, 491,
-	-1000, -1000, -1000, 374, -1000, 268, 157, -1000, -1000, 197,
-	322, -1000, 433, 433, -1000, -1000, 334, 324, 118, 16,
-	-1000, 108, -1000, 100, -1000, -1000, -1000, -1000, -1000, 81,
-	176, -1000, -1000, -1000, -1000, 433, 491, -1000, 261, 169,
-	-1000, -1000, 292, 155, -1000, 201, -1000, 327, 374, 433,
-	374, -1000, -1000, -1000, 196, 491, 348, -1000, 433, 433,
-	-1000, -1000, -1000, 322, 19, 147, 19, -37, 182, 491,
-	-1000, 316, -1000, 144, -1000, 345, -1000,
+
+var yyPact = [...]int{
+	50, -1000, -1000, 209, -1000, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -28, -27, -5,
+	-8, 348, 307, -1000, -1000, -1000, 305, -1000, 279, 263,
+	340, 203, -30, -13, -1000, -6, -1000, 260, -31, 259,
+	-1000, -1000, 205, -1000, 313, 258, 268, 257, 153, -1000,
+	180, 255, 24, 254, 253, -18, 250, 295, 90, 194,
+	-1000, -1000, 300, 117, 98, 423, -1000, 361, 322, -1000,
+	-1000, 382, 210, -1000, 201, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, 297, -1000, 200, 203, 337, 203,
+	361, -1000, 292, 29, 59, 249, -1000, -1000, 247, 195,
+	205, 246, -1000, 116, 179, 361, 361, 382, 185, 314,
+	382, 382, 80, 382, 382, 382, 382, 382, 382, 382,
+	382, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 423,
+	51, -44, 2, 14, 423, -1000, 366, 205, 348, 67,
+	-3, -1000, 361, 361, 302, 220, 214, 329, 361, -1000,
+	-1000, -1000, -1000, 48, 245, -1000, -24, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, 182, 467, 79, 227, 12, -1000,
+	-1000, -1000, -1000, -1000, -7, 205, -1000, -1000, 414, -1000,
+	366, 185, 382, 382, 414, 445, -1000, 286, 138, 138,
+	138, 57, 57, -1000, -1000, -1000, 244, -1000, -1000, 382,
+	-1000, 414, -9, -2, -1000, -1000, 58, 7, -1000, 34,
+	185, 209, -10, -1000, 329, 315, 326, 98, 240, -1000,
+	-1000, 239, -1000, 335, 195, 195, -1000, -1000, 143, 127,
+	145, 134, 126, -1000, 234, -22, -29, -12, 233, -1000,
+	-11, -23, -16, -1000, 414, 434, 382, -1000, 414, -1000,
+	-1000, -1000, 361, -1000, 275, 167, -1000, -1000, 221, 315,
+	-1000, 382, 382, -1000, -1000, 332, 323, 467, 131, -1000,
+	124, -1000, 113, -1000, -1000, -1000, 93, 189, -1000, -1000,
+	-1000, -1000, -1000, -1000, 382, 414, -1000, 271, 185, -1000,
+	-1000, 406, 165, -1000, 101, -1000, 329, 361, 382, 361,
+	184, -1000, -1000, -1000, 220, 414, 345, -1000, 382, 382,
+	-1000, -1000, -1000, 315, 98, 154, 98, 220, -35, 203,
+	414, -1000, 311, -38, -1000, 153, -1000, 343, -1000, -1000,
 }
-var yyPgo = []int{
-
-	0, 441, 438, 13, 436, 435, 434, 433, 432, 431,
-	430, 428, 352, 427, 426, 425, 21, 34, 12, 11,
-	424, 9, 420, 419, 8, 20, 16, 415, 414, 413,
-	412, 7, 5, 0, 407, 406, 405, 19, 17, 404,
-	403, 401, 398, 6, 397, 2, 393, 4, 392, 391,
-	386, 3, 1, 53, 385, 384, 383, 380, 379, 374,
-	372, 10, 356,
+
+var yyPgo = [...]int{
+	0, 455, 454, 22, 450, 449, 448, 442, 441, 440,
+	439, 435, 406, 434, 433, 430, 19, 40, 12, 15,
+	428, 10, 426, 424, 9, 11, 16, 415, 414, 411,
+	405, 7, 5, 0, 390, 385, 384, 18, 13, 383,
+	382, 380, 379, 6, 378, 3, 376, 4, 373, 372,
+	371, 2, 1, 61, 366, 364, 362, 361, 360, 355,
+	354, 8, 353,
 }
-var yyR1 = []int{
 
+var yyR1 = [...]int{
 	0, 1, 2, 2, 2, 2, 2, 2, 2, 2,
 	2, 3, 3, 4, 5, 6, 7, 8, 8, 9,
 	9, 10, 11, 11, 62, 12, 13, 13, 14, 14,
 	14, 14, 14, 15, 15, 16, 16, 17, 17, 17,
-	17, 18, 18, 19, 19, 20, 20, 20, 21, 21,
-	21, 21, 22, 22, 22, 22, 22, 22, 22, 22,
-	22, 23, 23, 23, 24, 24, 25, 25, 26, 26,
-	26, 26, 26, 27, 27, 27, 27, 27, 27, 27,
-	27, 27, 27, 28, 28, 28, 28, 28, 28, 28,
-	29, 29, 30, 30, 31, 31, 32, 32, 33, 33,
+	17, 18, 18, 19, 19, 20, 20, 21, 21, 21,
+	21, 21, 21, 22, 22, 22, 22, 22, 22, 22,
+	22, 22, 23, 23, 23, 24, 24, 25, 25, 26,
+	26, 26, 26, 26, 27, 27, 27, 27, 27, 27,
+	27, 27, 27, 27, 28, 28, 28, 28, 28, 28,
+	28, 29, 29, 30, 30, 31, 31, 32, 32, 33,
 	33, 33, 33, 33, 33, 33, 33, 33, 33, 33,
-	33, 33, 33, 33, 33, 33, 34, 34, 35, 35,
-	35, 36, 36, 37, 37, 38, 38, 39, 39, 40,
-	40, 40, 40, 41, 41, 42, 42, 43, 43, 44,
-	44, 45, 46, 46, 46, 47, 47, 47, 48, 48,
-	50, 50, 51, 51, 49, 49, 52, 52, 53, 54,
-	54, 55, 55, 56, 56, 57, 57, 57, 57, 57,
-	58, 58, 59, 59, 60, 60, 61,
+	33, 33, 33, 33, 33, 33, 33, 34, 34, 35,
+	35, 35, 36, 36, 37, 37, 38, 38, 39, 39,
+	40, 40, 40, 40, 41, 41, 42, 42, 43, 43,
+	44, 44, 45, 46, 46, 46, 47, 47, 47, 48,
+	48, 50, 50, 51, 51, 49, 49, 52, 52, 53,
+	54, 54, 55, 55, 56, 56, 57, 57, 57, 57,
+	57, 58, 58, 59, 59, 60, 60, 61,
 }
-var yyR2 = []int{
 
+var yyR2 = [...]int{
 	0, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 12, 3, 7, 8, 7, 3, 5, 8, 6,
 	7, 5, 4, 5, 0, 2, 0, 2, 1, 2,
 	1, 1, 1, 0, 1, 1, 3, 1, 1, 3,
-	3, 1, 1, 0, 1, 1, 3, 3, 2, 4,
-	3, 5, 1, 1, 2, 3, 2, 3, 2, 2,
-	2, 1, 3, 3, 0, 5, 0, 2, 1, 3,
-	3, 2, 3, 3, 3, 4, 3, 4, 5, 6,
-	3, 4, 4, 1, 1, 1, 1, 1, 1, 1,
-	2, 1, 1, 3, 3, 3, 1, 3, 1, 1,
-	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	2, 3, 4, 5, 4, 1, 1, 1, 1, 1,
-	1, 3, 4, 1, 2, 4, 2, 1, 3, 1,
-	1, 1, 1, 0, 3, 0, 2, 0, 3, 1,
-	3, 2, 0, 1, 1, 0, 2, 4, 0, 2,
-	0, 3, 1, 3, 0, 5, 1, 3, 3, 0,
-	2, 0, 3, 0, 1, 1, 1, 1, 1, 1,
-	0, 1, 0, 1, 0, 2, 0,
+	3, 1, 1, 0, 1, 1, 3, 2, 4, 3,
+	3, 5, 7, 1, 1, 2, 3, 2, 3, 2,
+	2, 2, 1, 3, 3, 0, 5, 0, 2, 1,
+	3, 3, 2, 3, 3, 3, 4, 3, 4, 5,
+	6, 3, 4, 4, 1, 1, 1, 1, 1, 1,
+	1, 2, 1, 1, 3, 3, 3, 1, 3, 1,
+	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	3, 2, 3, 4, 5, 4, 1, 1, 1, 1,
+	1, 1, 3, 4, 1, 2, 4, 2, 1, 3,
+	1, 1, 1, 1, 0, 3, 0, 2, 0, 3,
+	1, 3, 2, 0, 1, 1, 0, 2, 4, 0,
+	2, 0, 3, 1, 3, 0, 5, 1, 3, 3,
+	0, 2, 0, 3, 0, 1, 1, 1, 1, 1,
+	1, 0, 1, 0, 1, 0, 2, 0,
 }
-var yyChk = []int{
 
+var yyChk = [...]int{
 	-1000, -1, -2, -3, -4, -5, -6, -7, -8, -9,
-	-10, -11, 4, 5, 6, 7, 33, 81, 82, 84,
-	83, -14, 48, 49, 50, 51, -12, -62, -12, -12,
-	-12, -12, 85, -59, 90, -56, 88, 85, 85, 86,
+	-10, -11, 4, 5, 6, 7, 33, 82, 83, 85,
+	84, -14, 48, 49, 50, 51, -12, -62, -12, -12,
+	-12, -12, 86, -59, 91, -56, 89, 86, 86, 87,
 	-3, 17, -15, 18, -13, 29, 34, 8, -52, -53,
-	34, -55, 89, 86, 85, 34, -54, 89, 34, -16,
-	-17, 71, -18, 34, -26, -33, -27, 65, 43, -40,
-	-39, -35, -34, -36, 20, 35, 36, 37, 25, 69,
-	70, 47, 89, 28, 76, 15, 34, 33, 34, 52,
-	44, 34, 65, 34, 34, 87, 34, 20, 62, 8,
-	52, -19, 19, 74, 43, 63, 64, -28, 21, 65,
-	23, 24, 22, 66, 67, 68, 69, 70, 71, 72,
-	73, 44, 45, 46, 38, 39, 40, 41, -26, -33,
+	34, -55, 90, 87, 86, 34, -54, 90, 34, -16,
+	-17, 72, -18, 34, -26, -33, -27, 66, 43, -40,
+	-39, -35, -34, -36, 20, 35, 36, 37, 25, 70,
+	71, 47, 90, 28, 77, 15, 34, 33, 34, 52,
+	44, 34, 66, 34, 34, 88, 34, 20, 62, 8,
+	52, -19, 19, 75, 43, 64, 65, -28, 21, 66,
+	23, 24, 22, 67, 68, 69, 70, 71, 72, 73,
+	74, 44, 45, 46, 38, 39, 40, 41, -26, -33,
 	34, -26, -3, -32, -33, -33, 43, 43, 43, -37,
-	-18, -38, 77, 79, -50, 43, -52, -25, 9, -53,
-	-18, -61, 20, -60, 91, -57, 84, 82, 32, 83,
-	12, 34, 34, 34, -20, -21, 43, -23, 34, -17,
-	34, 71, 34, 111, -16, 18, -26, -26, -33, -31,
-	43, 21, 23, 24, -33, -33, 25, 65, -33, -33,
-	-33, -33, -33, -33, -33, -33, 74, 111, 111, 52,
-	111, -33, -16, -3, 80, -38, -37, -18, -18, -29,
+	-18, -38, 78, 80, -50, 43, -52, -25, 9, -53,
+	-18, -61, 20, -60, 63, -57, 85, 83, 32, 84,
+	12, 34, 34, 34, -20, -21, -23, 43, 34, -17,
+	34, 72, 34, 111, -16, 18, -26, -26, -33, -31,
+	43, 21, 23, 24, -33, -33, 25, 66, -33, -33,
+	-33, -33, -33, -33, -33, -33, 75, 111, 111, 52,
+	111, -33, -16, -3, 81, -38, -37, -18, -18, -29,
 	28, -3, -51, 34, -25, -43, 12, -26, 62, 34,
-	-61, -58, 87, -25, 52, -22, 53, 54, 55, 56,
-	57, 59, 60, -21, -3, 43, -24, -19, 61, 74,
-	111, -16, -32, -3, -31, -33, -33, 63, 25, -33,
-	111, 111, 80, 78, -49, 62, -30, -31, 111, 52,
-	-43, -47, 14, 13, 34, 34, -41, 10, -21, -21,
-	53, 58, 53, 58, 53, 53, 53, 111, 111, 34,
-	86, 34, 111, 111, 111, 63, -33, -18, 30, 52,
-	34, -47, -33, -44, -45, -33, -61, -42, 11, 13,
-	62, 53, 53, -24, 43, -33, 31, -31, 52, 52,
-	-46, 26, 27, -43, -26, -32, -26, -51, 6, -33,
-	-45, -47, 111, -52, -48, 16, 6,
+	-61, -58, 88, -25, 52, -22, 53, 54, 55, 56,
+	57, 59, 60, -24, -19, 61, -21, -3, 75, 111,
+	-16, -32, -3, -31, -33, -33, 64, 25, -33, 111,
+	111, 81, 79, -49, 62, -30, -31, 111, 52, -43,
+	-47, 14, 13, 34, 34, -41, 10, -21, -21, 53,
+	58, 53, 58, 53, 53, 53, 34, 87, 111, 111,
+	34, 111, 111, 111, 64, -33, -18, 30, 52, 34,
+	-47, -33, -44, -45, -33, -61, -42, 11, 13, 62,
+	63, 53, 53, -24, 43, -33, 31, -31, 52, 52,
+	-46, 26, 27, -43, -26, -32, -26, 43, -51, 6,
+	-33, -45, -47, -51, 111, -52, -48, 16, 111, 6,
 }
-var yyDef = []int{
 
+var yyDef = [...]int{
 	0, -2, 1, 2, 3, 4, 5, 6, 7, 8,
-	9, 10, 24, 24, 24, 24, 24, 172, 163, 0,
+	9, 10, 24, 24, 24, 24, 24, 173, 164, 0,
 	0, 0, 28, 30, 31, 32, 33, 26, 0, 0,
-	0, 0, 161, 0, 173, 0, 164, 0, 159, 0,
-	12, 29, 0, 34, 25, 0, 0, 0, 16, 156,
+	0, 0, 162, 0, 174, 0, 165, 0, 160, 0,
+	12, 29, 0, 34, 25, 0, 0, 0, 16, 157,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	35, 37, -2, 127, 41, 42, 68, 0, 0, 98,
-	99, 0, 0, 115, 0, 129, 130, 131, 132, 118,
-	119, 120, 116, 117, 0, 27, 150, 0, 66, 0,
-	0, 176, 0, 174, 0, 0, 22, 160, 0, 0,
+	35, 37, -2, 128, 41, 42, 69, 0, 0, 99,
+	100, 0, 0, 116, 0, 130, 131, 132, 133, 119,
+	120, 121, 117, 118, 0, 27, 151, 0, 67, 0,
+	0, 177, 0, 175, 0, 0, 22, 161, 0, 0,
 	0, 0, 44, 0, 0, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 83, 84, 85, 86, 87, 88, 89, 71, 0,
-	127, 0, 0, 0, 96, 110, 0, 0, 0, 0,
-	0, 123, 0, 0, 0, 0, 66, 137, 0, 157,
-	158, 17, 162, 0, 0, 176, 170, 165, 166, 167,
-	168, 169, 21, 23, 66, 45, 0, -2, 61, 36,
-	39, 40, 128, 111, 0, 0, 69, 70, 73, 74,
-	0, 0, 0, 0, 76, 0, 80, 0, 102, 103,
-	104, 105, 106, 107, 108, 109, 0, 72, 100, 0,
-	101, 96, 0, 0, 121, 124, 0, 0, 126, 154,
-	0, 91, 0, 152, 137, 145, 0, 67, 0, 175,
-	19, 0, 171, 133, 0, 0, 52, 53, 0, 0,
-	0, 0, 0, 0, 0, 0, 48, 0, 0, 0,
-	112, 0, 0, 0, 75, 77, 0, 0, 81, 97,
-	114, 82, 122, 0, 13, 0, 90, 92, 151, 0,
-	145, 15, 0, 0, 176, 20, 135, 0, 47, 50,
-	54, 0, 56, 0, 58, 59, 60, 46, 63, 64,
-	0, 62, 113, 94, 95, 0, 78, 125, 0, 0,
-	153, 14, 146, 138, 139, 142, 18, 137, 0, 0,
-	0, 55, 57, 49, 0, 79, 0, 93, 0, 0,
-	141, 143, 144, 145, 136, 134, 51, 0, 0, 147,
-	140, 148, 65, 155, 11, 0, 149,
+	0, 84, 85, 86, 87, 88, 89, 90, 72, 0,
+	128, 0, 0, 0, 97, 111, 0, 0, 0, 0,
+	0, 124, 0, 0, 0, 0, 67, 138, 0, 158,
+	159, 17, 163, 0, 0, 177, 171, 166, 167, 168,
+	169, 170, 21, 23, 67, 45, -2, 0, 62, 36,
+	39, 40, 129, 112, 0, 0, 70, 71, 74, 75,
+	0, 0, 0, 0, 77, 0, 81, 0, 103, 104,
+	105, 106, 107, 108, 109, 110, 0, 73, 101, 0,
+	102, 97, 0, 0, 122, 125, 0, 0, 127, 155,
+	0, 92, 0, 153, 138, 146, 0, 68, 0, 176,
+	19, 0, 172, 134, 0, 0, 53, 54, 0, 0,
+	0, 0, 0, 47, 0, 0, 0, 0, 0, 113,
+	0, 0, 0, 76, 78, 0, 0, 82, 98, 115,
+	83, 123, 0, 13, 0, 91, 93, 152, 0, 146,
+	15, 0, 0, 177, 20, 136, 0, 46, 50, 55,
+	0, 57, 0, 59, 60, 61, 65, 0, 49, 64,
+	63, 114, 95, 96, 0, 79, 126, 0, 0, 154,
+	14, 147, 139, 140, 143, 18, 138, 0, 0, 0,
+	0, 56, 58, 48, 0, 80, 0, 94, 0, 0,
+	142, 144, 145, 146, 137, 135, 51, 0, 0, 0,
+	148, 141, 149, 0, 66, 156, 11, 0, 52, 150,
 }
-var yyTok1 = []int{
 
+var yyTok1 = [...]int{
 	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 3, 3, 3, 73, 66, 3,
-	43, 111, 71, 69, 52, 70, 74, 72, 3, 3,
+	3, 3, 3, 3, 3, 3, 3, 74, 67, 3,
+	43, 111, 72, 70, 52, 71, 75, 73, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	45, 44, 46, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 68, 3, 3, 3, 3, 3,
+	3, 3, 3, 3, 69, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 3, 67, 3, 47,
+	3, 3, 3, 3, 68, 3, 47,
 }
-var yyTok2 = []int{
 
+var yyTok2 = [...]int{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
 	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
 	42, 48, 49, 50, 51, 53, 54, 55, 56, 57,
-	58, 59, 60, 61, 62, 63, 64, 65, 75, 76,
+	58, 59, 60, 61, 62, 63, 64, 65, 66, 76,
 	77, 78, 79, 80, 81, 82, 83, 84, 85, 86,
 	87, 88, 89, 90, 91, 92, 93, 94, 95, 96,
 	97, 98, 99, 100, 101, 102, 103, 104, 105, 106,
 	107, 108, 109, 110,
 }
-var yyTok3 = []int{
+
+var yyTok3 = [...]int{
 	0,
 }
 
+var yyErrorMessages = [...]struct {
+	state int
+	token int
+	msg   string
+}{}
+
 //line yaccpar:1
 
 /*	parser for yacc output	*/
 
-var yyDebug = 0
+var (
+	yyDebug        = 0
+	yyErrorVerbose = false
+)
 
 type yyLexer interface {
 	Lex(lval *yySymType) int
 	Error(s string)
 }
 
+type yyParser interface {
+	Parse(yyLexer) int
+	Lookahead() int
+}
+
+type yyParserImpl struct {
+	lval  yySymType
+	stack [yyInitialStackSize]yySymType
+	char  int
+}
+
+func (p *yyParserImpl) Lookahead() int {
+	return p.char
+}
+
+func yyNewParser() yyParser {
+	return &yyParserImpl{}
+}
+
 const yyFlag = -1000
 
 func yyTokname(c int) string {
-	// 4 is TOKSTART above
-	if c >= 4 && c-4 < len(yyToknames) {
-		if yyToknames[c-4] != "" {
-			return yyToknames[c-4]
+	if c >= 1 && c-1 < len(yyToknames) {
+		if yyToknames[c-1] != "" {
+			return yyToknames[c-1]
 		}
 	}
 	return __yyfmt__.Sprintf("tok-%v", c)
@@ -578,51 +605,127 @@ func yyStatname(s int) string {
 	return __yyfmt__.Sprintf("state-%v", s)
 }
 
-func yylex1(lex yyLexer, lval *yySymType) int {
-	c := 0
-	char := lex.Lex(lval)
+func yyErrorMessage(state, lookAhead int) string {
+	const TOKSTART = 4
+
+	if !yyErrorVerbose {
+		return "syntax error"
+	}
+
+	for _, e := range yyErrorMessages {
+		if e.state == state && e.token == lookAhead {
+			return "syntax error: " + e.msg
+		}
+	}
+
+	res := "syntax error: unexpected " + yyTokname(lookAhead)
+
+	// To match Bison, suggest at most four expected tokens.
+	expected := make([]int, 0, 4)
+
+	// Look for shiftable tokens.
+	base := yyPact[state]
+	for tok := TOKSTART; tok-1 < len(yyToknames); tok++ {
+		if n := base + tok; n >= 0 && n < yyLast && yyChk[yyAct[n]] == tok {
+			if len(expected) == cap(expected) {
+				return res
+			}
+			expected = append(expected, tok)
+		}
+	}
+
+	if yyDef[state] == -2 {
+		i := 0
+		for yyExca[i] != -1 || yyExca[i+1] != state {
+			i += 2
+		}
+
+		// Look for tokens that we accept or reduce.
+		for i += 2; yyExca[i] >= 0; i += 2 {
+			tok := yyExca[i]
+			if tok < TOKSTART || yyExca[i+1] == 0 {
+				continue
+			}
+			if len(expected) == cap(expected) {
+				return res
+			}
+			expected = append(expected, tok)
+		}
+
+		// If the default action is to accept or reduce, give up.
+		if yyExca[i+1] != 0 {
+			return res
+		}
+	}
+
+	for i, tok := range expected {
+		if i == 0 {
+			res += ", expecting "
+		} else {
+			res += " or "
+		}
+		res += yyTokname(tok)
+	}
+	return res
+}
+
+func yylex1(lex yyLexer, lval *yySymType) (char, token int) {
+	token = 0
+	char = lex.Lex(lval)
 	if char <= 0 {
-		c = yyTok1[0]
+		token = yyTok1[0]
 		goto out
 	}
 	if char < len(yyTok1) {
-		c = yyTok1[char]
+		token = yyTok1[char]
 		goto out
 	}
 	if char >= yyPrivate {
 		if char < yyPrivate+len(yyTok2) {
-			c = yyTok2[char-yyPrivate]
+			token = yyTok2[char-yyPrivate]
 			goto out
 		}
 	}
 	for i := 0; i < len(yyTok3); i += 2 {
-		c = yyTok3[i+0]
-		if c == char {
-			c = yyTok3[i+1]
+		token = yyTok3[i+0]
+		if token == char {
+			token = yyTok3[i+1]
 			goto out
 		}
 	}
 
 out:
-	if c == 0 {
-		c = yyTok2[1] /* unknown char */
+	if token == 0 {
+		token = yyTok2[1] /* unknown char */
 	}
 	if yyDebug >= 3 {
-		__yyfmt__.Printf("lex %U %s\n", uint(char), yyTokname(c))
+		__yyfmt__.Printf("lex %s(%d)\n", yyTokname(token), uint(char))
 	}
-	return c
+	return char, token
 }
 
 func yyParse(yylex yyLexer) int {
+	return yyNewParser().Parse(yylex)
+}
+
+func (yyrcvr *yyParserImpl) Parse(yylex yyLexer) int {
 	var yyn int
-	var yylval yySymType
 	var yyVAL yySymType
-	yyS := make([]yySymType, yyMaxDepth)
+	var yyDollar []yySymType
+	_ = yyDollar // silence set and not used
+	yyS := yyrcvr.stack[:]
 
 	Nerrs := 0   /* number of errors */
 	Errflag := 0 /* error recovery flag */
 	yystate := 0
-	yychar := -1
+	yyrcvr.char = -1
+	yytoken := -1 // yyrcvr.char translated into internal numbering
+	defer func() {
+		// Make sure we report no lookahead when not parsing.
+		yystate = -1
+		yyrcvr.char = -1
+		yytoken = -1
+	}()
 	yyp := -1
 	goto yystack
 
@@ -635,7 +738,7 @@ ret1:
 yystack:
 	/* put a state and value onto the stack */
 	if yyDebug >= 4 {
-		__yyfmt__.Printf("char %v in %v\n", yyTokname(yychar), yyStatname(yystate))
+		__yyfmt__.Printf("char %v in %v\n", yyTokname(yytoken), yyStatname(yystate))
 	}
 
 	yyp++
@@ -652,17 +755,18 @@ yynewstate:
 	if yyn <= yyFlag {
 		goto yydefault /* simple state */
 	}
-	if yychar < 0 {
-		yychar = yylex1(yylex, &yylval)
+	if yyrcvr.char < 0 {
+		yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
 	}
-	yyn += yychar
+	yyn += yytoken
 	if yyn < 0 || yyn >= yyLast {
 		goto yydefault
 	}
 	yyn = yyAct[yyn]
-	if yyChk[yyn] == yychar { /* valid shift */
-		yychar = -1
-		yyVAL = yylval
+	if yyChk[yyn] == yytoken { /* valid shift */
+		yyrcvr.char = -1
+		yytoken = -1
+		yyVAL = yyrcvr.lval
 		yystate = yyn
 		if Errflag > 0 {
 			Errflag--
@@ -674,8 +778,8 @@ yydefault:
 	/* default state action */
 	yyn = yyDef[yystate]
 	if yyn == -2 {
-		if yychar < 0 {
-			yychar = yylex1(yylex, &yylval)
+		if yyrcvr.char < 0 {
+			yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
 		}
 
 		/* look through exception table */
@@ -688,7 +792,7 @@ yydefault:
 		}
 		for xi += 2; ; xi += 2 {
 			yyn = yyExca[xi+0]
-			if yyn < 0 || yyn == yychar {
+			if yyn < 0 || yyn == yytoken {
 				break
 			}
 		}
@@ -701,11 +805,11 @@ yydefault:
 		/* error ... attempt to resume parsing */
 		switch Errflag {
 		case 0: /* brand new error */
-			yylex.Error("syntax error")
+			yylex.Error(yyErrorMessage(yystate, yytoken))
 			Nerrs++
 			if yyDebug >= 1 {
 				__yyfmt__.Printf("%s", yyStatname(yystate))
-				__yyfmt__.Printf("saw %s\n", yyTokname(yychar))
+				__yyfmt__.Printf(" saw %s\n", yyTokname(yytoken))
 			}
 			fallthrough
 
@@ -733,12 +837,13 @@ yydefault:
 
 		case 3: /* no shift yet; clobber input char */
 			if yyDebug >= 2 {
-				__yyfmt__.Printf("error recovery discards %s\n", yyTokname(yychar))
+				__yyfmt__.Printf("error recovery discards %s\n", yyTokname(yytoken))
 			}
-			if yychar == yyEofCode {
+			if yytoken == yyEofCode {
 				goto ret1
 			}
-			yychar = -1
+			yyrcvr.char = -1
+			yytoken = -1
 			goto yynewstate /* try again in the same state */
 		}
 	}
@@ -753,6 +858,13 @@ yydefault:
 	_ = yypt // guard against "declared and not used"
 
 	yyp -= yyR2[yyn]
+	// yyp is now the index of $0. Perform the default action. Iff the
+	// reduced production is ε, $1 is possibly out of range.
+	if yyp+1 >= len(yyS) {
+		nyys := make([]yySymType, len(yyS)*2)
+		copy(nyys, yyS)
+		yyS = nyys
+	}
 	yyVAL = yyS[yyp+1]
 
 	/* consult goto table to find next state */
@@ -772,807 +884,833 @@ yydefault:
 	switch yynt {
 
 	case 1:
-		//line sql.y:145
-		{
-			SetParseTree(yylex, yyS[yypt-0].node)
-		}
-	case 2:
-		yyVAL.node = yyS[yypt-0].node
-	case 3:
-		yyVAL.node = yyS[yypt-0].node
-	case 4:
-		yyVAL.node = yyS[yypt-0].node
-	case 5:
-		yyVAL.node = yyS[yypt-0].node
-	case 6:
-		yyVAL.node = yyS[yypt-0].node
-	case 7:
-		yyVAL.node = yyS[yypt-0].node
-	case 8:
-		yyVAL.node = yyS[yypt-0].node
-	case 9:
-		yyVAL.node = yyS[yypt-0].node
-	case 10:
-		yyVAL.node = yyS[yypt-0].node
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:145
+		{
+			SetParseTree(yylex, yyDollar[1].node)
+		}
 	case 11:
-		//line sql.y:162
-		{
-			yyVAL.node = yyS[yypt-11].node
-			yyVAL.node.Push(yyS[yypt-10].node) // 0: comment_opt
-			yyVAL.node.Push(yyS[yypt-9].node)  // 1: distinct_opt
-			yyVAL.node.Push(yyS[yypt-8].node)  // 2: select_expression_list
-			yyVAL.node.Push(yyS[yypt-6].node)  // 3: table_expression_list
-			yyVAL.node.Push(yyS[yypt-5].node)  // 4: where_expression_opt
-			yyVAL.node.Push(yyS[yypt-4].node)  // 5: group_by_opt
-			yyVAL.node.Push(yyS[yypt-3].node)  // 6: having_opt
-			yyVAL.node.Push(yyS[yypt-2].node)  // 7: order_by_opt
-			yyVAL.node.Push(yyS[yypt-1].node)  // 8: limit_opt
-			yyVAL.node.Push(yyS[yypt-0].node)  // 9: for_update_opt
+		yyDollar = yyS[yypt-12 : yypt+1]
+//line sql.y:162
+		{
+			yyVAL.node = yyDollar[1].node
+			yyVAL.node.Push(yyDollar[2].node)  // 0: comment_opt
+			yyVAL.node.Push(yyDollar[3].node)  // 1: distinct_opt
+			yyVAL.node.Push(yyDollar[4].node)  // 2: select_expression_list
+			yyVAL.node.Push(yyDollar[6].node)  // 3: table_expression_list
+			yyVAL.node.Push(yyDollar[7].node)  // 4: where_expression_opt
+			yyVAL.node.Push(yyDollar[8].node)  // 5: group_by_opt
+			yyVAL.node.Push(yyDollar[9].node)  // 6: having_opt
+			yyVAL.node.Push(yyDollar[10].node) // 7: order_by_opt
+			yyVAL.node.Push(yyDollar[11].node) // 8: limit_opt
+			yyVAL.node.Push(yyDollar[12].node) // 9: for_update_opt
 		}
 	case 12:
-		//line sql.y:176
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:176
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 13:
-		//line sql.y:182
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line sql.y:182
 		{
-			yyVAL.node = yyS[yypt-6].node
-			yyVAL.node.Push(yyS[yypt-5].node) // 0: comment_opt
-			yyVAL.node.Push(yyS[yypt-3].node) // 1: table_name
-			yyVAL.node.Push(yyS[yypt-2].node) // 2: column_list_opt
-			yyVAL.node.Push(yyS[yypt-1].node) // 3: values
-			yyVAL.node.Push(yyS[yypt-0].node) // 4: on_dup_opt
+			yyVAL.node = yyDollar[1].node
+			yyVAL.node.Push(yyDollar[2].node) // 0: comment_opt
+			yyVAL.node.Push(yyDollar[4].node) // 1: table_name
+			yyVAL.node.Push(yyDollar[5].node) // 2: column_list_opt
+			yyVAL.node.Push(yyDollar[6].node) // 3: values
+			yyVAL.node.Push(yyDollar[7].node) // 4: on_dup_opt
 		}
 	case 14:
-		//line sql.y:193
+		yyDollar = yyS[yypt-8 : yypt+1]
+//line sql.y:193
 		{
-			yyVAL.node = yyS[yypt-7].node
-			yyVAL.node.Push(yyS[yypt-6].node) // 0: comment_opt
-			yyVAL.node.Push(yyS[yypt-5].node) // 1: table_name
-			yyVAL.node.Push(yyS[yypt-3].node) // 2: update_list
-			yyVAL.node.Push(yyS[yypt-2].node) // 3: where_expression_opt
-			yyVAL.node.Push(yyS[yypt-1].node) // 4: order_by_opt
-			yyVAL.node.Push(yyS[yypt-0].node) // 5: limit_opt
+			yyVAL.node = yyDollar[1].node
+			yyVAL.node.Push(yyDollar[2].node) // 0: comment_opt
+			yyVAL.node.Push(yyDollar[3].node) // 1: table_name
+			yyVAL.node.Push(yyDollar[5].node) // 2: update_list
+			yyVAL.node.Push(yyDollar[6].node) // 3: where_expression_opt
+			yyVAL.node.Push(yyDollar[7].node) // 4: order_by_opt
+			yyVAL.node.Push(yyDollar[8].node) // 5: limit_opt
 		}
 	case 15:
-		//line sql.y:205
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line sql.y:205
 		{
-			yyVAL.node = yyS[yypt-6].node
-			yyVAL.node.Push(yyS[yypt-5].node) // 0: comment_opt
-			yyVAL.node.Push(yyS[yypt-3].node) // 1: table_name
-			yyVAL.node.Push(yyS[yypt-2].node) // 2: where_expression_opt
-			yyVAL.node.Push(yyS[yypt-1].node) // 3: order_by_opt
-			yyVAL.node.Push(yyS[yypt-0].node) // 4: limit_opt
+			yyVAL.node = yyDollar[1].node
+			yyVAL.node.Push(yyDollar[2].node) // 0: comment_opt
+			yyVAL.node.Push(yyDollar[4].node) // 1: table_name
+			yyVAL.node.Push(yyDollar[5].node) // 2: where_expression_opt
+			yyVAL.node.Push(yyDollar[6].node) // 3: order_by_opt
+			yyVAL.node.Push(yyDollar[7].node) // 4: limit_opt
 		}
 	case 16:
-		//line sql.y:216
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:216
 		{
-			yyVAL.node = yyS[yypt-2].node
-			yyVAL.node.Push(yyS[yypt-1].node)
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node
+			yyVAL.node.Push(yyDollar[2].node)
+			yyVAL.node.Push(yyDollar[3].node)
 		}
 	case 17:
-		//line sql.y:224
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line sql.y:224
 		{
-			yyVAL.node.Push(yyS[yypt-1].node)
+			yyVAL.node.Push(yyDollar[4].node)
 		}
 	case 18:
-		//line sql.y:228
+		yyDollar = yyS[yypt-8 : yypt+1]
+//line sql.y:228
 		{
 			// Change this to an alter statement
 			yyVAL.node = NewSimpleParseNode(ALTER, "alter")
-			yyVAL.node.Push(yyS[yypt-1].node)
+			yyVAL.node.Push(yyDollar[7].node)
 		}
 	case 19:
-		//line sql.y:236
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line sql.y:236
 		{
-			yyVAL.node.Push(yyS[yypt-2].node)
+			yyVAL.node.Push(yyDollar[4].node)
 		}
 	case 20:
-		//line sql.y:240
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line sql.y:240
 		{
 			// Change this to a rename statement
 			yyVAL.node = NewSimpleParseNode(RENAME, "rename")
-			yyVAL.node.PushTwo(yyS[yypt-3].node, yyS[yypt-0].node)
+			yyVAL.node.PushTwo(yyDollar[4].node, yyDollar[7].node)
 		}
 	case 21:
-		//line sql.y:248
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line sql.y:248
 		{
-			yyVAL.node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node.PushTwo(yyDollar[3].node, yyDollar[5].node)
 		}
 	case 22:
-		//line sql.y:254
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:254
 		{
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node.Push(yyDollar[4].node)
 		}
 	case 23:
-		//line sql.y:258
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line sql.y:258
 		{
 			// Change this to an alter statement
 			yyVAL.node = NewSimpleParseNode(ALTER, "alter")
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node.Push(yyDollar[5].node)
 		}
 	case 24:
-		//line sql.y:265
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:265
 		{
 			SetAllowComments(yylex, true)
 		}
 	case 25:
-		//line sql.y:269
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:269
 		{
-			yyVAL.node = yyS[yypt-0].node
+			yyVAL.node = yyDollar[2].node
 			SetAllowComments(yylex, false)
 		}
 	case 26:
-		//line sql.y:275
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:275
 		{
 			yyVAL.node = NewSimpleParseNode(COMMENT_LIST, "")
 		}
 	case 27:
-		//line sql.y:279
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:279
 		{
-			yyVAL.node = yyS[yypt-1].node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
-	case 28:
-		yyVAL.node = yyS[yypt-0].node
 	case 29:
-		//line sql.y:286
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:286
 		{
 			yyVAL.node = NewSimpleParseNode(UNION_ALL, "union all")
 		}
-	case 30:
-		yyVAL.node = yyS[yypt-0].node
-	case 31:
-		yyVAL.node = yyS[yypt-0].node
-	case 32:
-		yyVAL.node = yyS[yypt-0].node
 	case 33:
-		//line sql.y:294
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:294
 		{
 			yyVAL.node = NewSimpleParseNode(NO_DISTINCT, "")
 		}
 	case 34:
-		//line sql.y:298
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:298
 		{
 			yyVAL.node = NewSimpleParseNode(DISTINCT, "distinct")
 		}
 	case 35:
-		//line sql.y:304
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:304
 		{
 			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node.Push(yyDollar[1].node)
 		}
 	case 36:
-		//line sql.y:309
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:309
 		{
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node.Push(yyDollar[3].node)
 		}
 	case 37:
-		//line sql.y:315
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:315
 		{
 			yyVAL.node = NewSimpleParseNode(SELECT_STAR, "*")
 		}
-	case 38:
-		yyVAL.node = yyS[yypt-0].node
 	case 39:
-		//line sql.y:320
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:320
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 40:
-		//line sql.y:324
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:324
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, NewSimpleParseNode(SELECT_STAR, "*"))
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, NewSimpleParseNode(SELECT_STAR, "*"))
 		}
-	case 41:
-		yyVAL.node = yyS[yypt-0].node
-	case 42:
-		yyVAL.node = yyS[yypt-0].node
 	case 43:
-		//line sql.y:333
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:333
 		{
 			yyVAL.node = NewSimpleParseNode(AS, "as")
 		}
-	case 44:
-		yyVAL.node = yyS[yypt-0].node
 	case 45:
-		//line sql.y:340
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:340
 		{
 			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node.Push(yyDollar[1].node)
 		}
 	case 46:
-		//line sql.y:345
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:345
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-1].node)
+			yyVAL.node.Push(yyDollar[3].node)
 		}
 	case 47:
-		//line sql.y:349
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:351
 		{
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(TABLE_EXPR, "")
+			yyVAL.node.Push(yyDollar[1].node)
+			yyVAL.node.Push(NewSimpleParseNode(NODE_LIST, "node_list"))
+			yyVAL.node.Push(yyDollar[2].node)
 		}
 	case 48:
-		//line sql.y:355
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:358
 		{
 			yyVAL.node = NewSimpleParseNode(TABLE_EXPR, "")
-			yyVAL.node.Push(yyS[yypt-1].node)
-			yyVAL.node.Push(NewSimpleParseNode(NODE_LIST, "node_list"))
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node.Push(yyDollar[1].node)
+			yyVAL.node.Push(NewSimpleParseNode(NODE_LIST, "node_list").Push(yyDollar[3].node))
+			yyVAL.node.Push(yyDollar[4].node)
 		}
 	case 49:
-		//line sql.y:362
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:365
 		{
-			yyVAL.node = NewSimpleParseNode(TABLE_EXPR, "")
-			yyVAL.node.Push(yyS[yypt-3].node)
-			yyVAL.node.Push(NewSimpleParseNode(NODE_LIST, "node_list").Push(yyS[yypt-1].node))
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 50:
-		//line sql.y:369
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:369
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 51:
-		//line sql.y:373
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line sql.y:373
 		{
-			yyVAL.node = yyS[yypt-3].node
-			yyVAL.node.Push(yyS[yypt-4].node)
-			yyVAL.node.Push(yyS[yypt-2].node)
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node
+			yyVAL.node.Push(yyDollar[1].node)
+			yyVAL.node.Push(yyDollar[3].node)
+			yyVAL.node.Push(yyDollar[4].node.Push(yyDollar[5].node))
 		}
 	case 52:
-		yyVAL.node = yyS[yypt-0].node
-	case 53:
-		yyVAL.node = yyS[yypt-0].node
-	case 54:
-		//line sql.y:384
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line sql.y:380
 		{
-			yyVAL.node = NewSimpleParseNode(LEFT, "left join")
+			yyVAL.node = yyDollar[2].node
+			yyVAL.node.Push(yyDollar[1].node)
+			yyVAL.node.Push(yyDollar[3].node)
+			yyVAL.node.Push(yyDollar[4].node.Push(yyDollar[6].node))
 		}
 	case 55:
-		//line sql.y:388
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:391
 		{
 			yyVAL.node = NewSimpleParseNode(LEFT, "left join")
 		}
 	case 56:
-		//line sql.y:392
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:395
 		{
-			yyVAL.node = NewSimpleParseNode(RIGHT, "right join")
+			yyVAL.node = NewSimpleParseNode(LEFT, "left join")
 		}
 	case 57:
-		//line sql.y:396
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:399
 		{
 			yyVAL.node = NewSimpleParseNode(RIGHT, "right join")
 		}
 	case 58:
-		//line sql.y:400
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:403
 		{
-			yyVAL.node = yyS[yypt-0].node
+			yyVAL.node = NewSimpleParseNode(RIGHT, "right join")
 		}
 	case 59:
-		//line sql.y:404
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:407
 		{
-			yyVAL.node = NewSimpleParseNode(CROSS, "cross join")
+			yyVAL.node = yyDollar[2].node
 		}
 	case 60:
-		//line sql.y:408
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:411
 		{
-			yyVAL.node = NewSimpleParseNode(NATURAL, "natural join")
+			yyVAL.node = NewSimpleParseNode(CROSS, "cross join")
 		}
 	case 61:
-		yyVAL.node = yyS[yypt-0].node
-	case 62:
-		//line sql.y:415
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:415
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(NATURAL, "natural join")
 		}
 	case 63:
-		//line sql.y:419
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:422
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-1].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 64:
-		//line sql.y:424
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:426
 		{
-			yyVAL.node = NewSimpleParseNode(USE, "use")
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 65:
-		//line sql.y:428
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:431
 		{
-			yyVAL.node.Push(yyS[yypt-1].node)
+			yyVAL.node = NewSimpleParseNode(USE, "use")
 		}
 	case 66:
-		//line sql.y:433
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line sql.y:435
 		{
-			yyVAL.node = NewSimpleParseNode(WHERE, "where")
+			yyVAL.node.Push(yyDollar[4].node)
 		}
 	case 67:
-		//line sql.y:437
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:440
 		{
-			yyVAL.node = yyS[yypt-1].node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(WHERE, "where")
 		}
 	case 68:
-		yyVAL.node = yyS[yypt-0].node
-	case 69:
-		//line sql.y:444
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:444
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 70:
-		//line sql.y:448
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:451
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 71:
-		//line sql.y:452
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:455
 		{
-			yyVAL.node = yyS[yypt-1].node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 72:
-		//line sql.y:456
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:459
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-1].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 73:
-		//line sql.y:462
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:463
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 74:
-		//line sql.y:466
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:469
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 75:
-		//line sql.y:470
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:473
 		{
-			yyVAL.node = NewSimpleParseNode(NOT_IN, "not in").PushTwo(yyS[yypt-3].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 76:
-		//line sql.y:474
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:477
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(NOT_IN, "not in").PushTwo(yyDollar[1].node, yyDollar[4].node)
 		}
 	case 77:
-		//line sql.y:478
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:481
 		{
-			yyVAL.node = NewSimpleParseNode(NOT_LIKE, "not like").PushTwo(yyS[yypt-3].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 78:
-		//line sql.y:482
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:485
 		{
-			yyVAL.node = yyS[yypt-3].node
-			yyVAL.node.Push(yyS[yypt-4].node)
-			yyVAL.node.Push(yyS[yypt-2].node)
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(NOT_LIKE, "not like").PushTwo(yyDollar[1].node, yyDollar[4].node)
 		}
 	case 79:
-		//line sql.y:489
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line sql.y:489
 		{
-			yyVAL.node = NewSimpleParseNode(NOT_BETWEEN, "not between")
-			yyVAL.node.Push(yyS[yypt-5].node)
-			yyVAL.node.Push(yyS[yypt-2].node)
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node
+			yyVAL.node.Push(yyDollar[1].node)
+			yyVAL.node.Push(yyDollar[3].node)
+			yyVAL.node.Push(yyDollar[5].node)
 		}
 	case 80:
-		//line sql.y:496
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line sql.y:496
 		{
-			yyVAL.node = NewSimpleParseNode(IS_NULL, "is null").Push(yyS[yypt-2].node)
+			yyVAL.node = NewSimpleParseNode(NOT_BETWEEN, "not between")
+			yyVAL.node.Push(yyDollar[1].node)
+			yyVAL.node.Push(yyDollar[4].node)
+			yyVAL.node.Push(yyDollar[6].node)
 		}
 	case 81:
-		//line sql.y:500
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:503
 		{
-			yyVAL.node = NewSimpleParseNode(IS_NOT_NULL, "is not null").Push(yyS[yypt-3].node)
+			yyVAL.node = NewSimpleParseNode(IS_NULL, "is null").Push(yyDollar[1].node)
 		}
 	case 82:
-		//line sql.y:504
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:507
 		{
-			yyVAL.node = yyS[yypt-3].node.Push(yyS[yypt-1].node)
+			yyVAL.node = NewSimpleParseNode(IS_NOT_NULL, "is not null").Push(yyDollar[1].node)
 		}
 	case 83:
-		yyVAL.node = yyS[yypt-0].node
-	case 84:
-		yyVAL.node = yyS[yypt-0].node
-	case 85:
-		yyVAL.node = yyS[yypt-0].node
-	case 86:
-		yyVAL.node = yyS[yypt-0].node
-	case 87:
-		yyVAL.node = yyS[yypt-0].node
-	case 88:
-		yyVAL.node = yyS[yypt-0].node
-	case 89:
-		yyVAL.node = yyS[yypt-0].node
-	case 90:
-		//line sql.y:519
-		{
-			yyVAL.node = yyS[yypt-1].node.Push(yyS[yypt-0].node)
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:511
+		{
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[3].node)
 		}
 	case 91:
-		yyVAL.node = yyS[yypt-0].node
-	case 92:
-		//line sql.y:526
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:526
 		{
-			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 93:
-		//line sql.y:531
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:533
 		{
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
+			yyVAL.node.Push(yyDollar[1].node)
 		}
 	case 94:
-		//line sql.y:537
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:538
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-1].node)
+			yyVAL.node.Push(yyDollar[3].node)
 		}
 	case 95:
-		//line sql.y:541
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:544
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-1].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 96:
-		//line sql.y:547
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:548
 		{
-			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 97:
-		//line sql.y:552
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:554
 		{
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
+			yyVAL.node.Push(yyDollar[1].node)
 		}
 	case 98:
-		yyVAL.node = yyS[yypt-0].node
-	case 99:
-		yyVAL.node = yyS[yypt-0].node
-	case 100:
-		//line sql.y:560
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:559
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-1].node)
+			yyVAL.node.Push(yyDollar[3].node)
 		}
 	case 101:
-		//line sql.y:564
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:567
 		{
-			if yyS[yypt-1].node.Len() == 1 {
-				yyS[yypt-1].node = yyS[yypt-1].node.At(0)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
+		}
+	case 102:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:571
+		{
+			if yyDollar[2].node.Len() == 1 {
+				yyDollar[2].node = yyDollar[2].node.At(0)
 			}
-			switch yyS[yypt-1].node.Type {
+			switch yyDollar[2].node.Type {
 			case NUMBER, STRING, ID, VALUE_ARG, '(', '.':
-				yyVAL.node = yyS[yypt-1].node
+				yyVAL.node = yyDollar[2].node
 			default:
-				yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-1].node)
+				yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 			}
 		}
-	case 102:
-		//line sql.y:576
-		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
-		}
 	case 103:
-		//line sql.y:580
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:583
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 104:
-		//line sql.y:584
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:587
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 105:
-		//line sql.y:588
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:591
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 106:
-		//line sql.y:592
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:595
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 107:
-		//line sql.y:596
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:599
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 108:
-		//line sql.y:600
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:603
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 109:
-		//line sql.y:604
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:607
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 110:
-		//line sql.y:608
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:611
 		{
-			if yyS[yypt-0].node.Type == NUMBER { // Simplify trivial unary expressions
-				switch yyS[yypt-1].node.Type {
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
+		}
+	case 111:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:615
+		{
+			if yyDollar[2].node.Type == NUMBER { // Simplify trivial unary expressions
+				switch yyDollar[1].node.Type {
 				case UMINUS:
-					yyS[yypt-0].node.Value = append(yyS[yypt-1].node.Value, yyS[yypt-0].node.Value...)
-					yyVAL.node = yyS[yypt-0].node
+					yyDollar[2].node.Value = append(yyDollar[1].node.Value, yyDollar[2].node.Value...)
+					yyVAL.node = yyDollar[2].node
 				case UPLUS:
-					yyVAL.node = yyS[yypt-0].node
+					yyVAL.node = yyDollar[2].node
 				default:
-					yyVAL.node = yyS[yypt-1].node.Push(yyS[yypt-0].node)
+					yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 				}
 			} else {
-				yyVAL.node = yyS[yypt-1].node.Push(yyS[yypt-0].node)
+				yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 			}
 		}
-	case 111:
-		//line sql.y:624
-		{
-			yyS[yypt-2].node.Type = FUNCTION
-			yyVAL.node = yyS[yypt-2].node.Push(NewSimpleParseNode(NODE_LIST, "node_list"))
-		}
 	case 112:
-		//line sql.y:629
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:631
 		{
-			yyS[yypt-3].node.Type = FUNCTION
-			yyVAL.node = yyS[yypt-3].node.Push(yyS[yypt-1].node)
+			yyDollar[1].node.Type = FUNCTION
+			yyVAL.node = yyDollar[1].node.Push(NewSimpleParseNode(NODE_LIST, "node_list"))
 		}
 	case 113:
-		//line sql.y:634
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:636
 		{
-			yyS[yypt-4].node.Type = FUNCTION
-			yyVAL.node = yyS[yypt-4].node.Push(yyS[yypt-2].node)
-			yyVAL.node = yyS[yypt-4].node.Push(yyS[yypt-1].node)
+			yyDollar[1].node.Type = FUNCTION
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[3].node)
 		}
 	case 114:
-		//line sql.y:640
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line sql.y:641
 		{
-			yyS[yypt-3].node.Type = FUNCTION
-			yyVAL.node = yyS[yypt-3].node.Push(yyS[yypt-1].node)
+			yyDollar[1].node.Type = FUNCTION
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[3].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[4].node)
 		}
 	case 115:
-		yyVAL.node = yyS[yypt-0].node
-	case 116:
-		yyVAL.node = yyS[yypt-0].node
-	case 117:
-		yyVAL.node = yyS[yypt-0].node
-	case 118:
-		//line sql.y:652
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:647
 		{
-			yyVAL.node = NewSimpleParseNode(UPLUS, "+")
+			yyDollar[1].node.Type = FUNCTION
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[3].node)
 		}
 	case 119:
-		//line sql.y:656
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:659
 		{
-			yyVAL.node = NewSimpleParseNode(UMINUS, "-")
+			yyVAL.node = NewSimpleParseNode(UPLUS, "+")
 		}
 	case 120:
-		yyVAL.node = yyS[yypt-0].node
-	case 121:
-		//line sql.y:663
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:663
 		{
-			yyVAL.node = NewSimpleParseNode(CASE_WHEN, "case")
-			yyVAL.node.Push(yyS[yypt-1].node)
+			yyVAL.node = NewSimpleParseNode(UMINUS, "-")
 		}
 	case 122:
-		//line sql.y:668
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:670
 		{
-			yyVAL.node.PushTwo(yyS[yypt-2].node, yyS[yypt-1].node)
+			yyVAL.node = NewSimpleParseNode(CASE_WHEN, "case")
+			yyVAL.node.Push(yyDollar[2].node)
 		}
 	case 123:
-		//line sql.y:674
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:675
 		{
-			yyVAL.node = NewSimpleParseNode(WHEN_LIST, "when_list")
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node.PushTwo(yyDollar[2].node, yyDollar[3].node)
 		}
 	case 124:
-		//line sql.y:679
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:681
 		{
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(WHEN_LIST, "when_list")
+			yyVAL.node.Push(yyDollar[1].node)
 		}
 	case 125:
-		//line sql.y:685
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:686
 		{
-			yyVAL.node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node.Push(yyDollar[2].node)
 		}
 	case 126:
-		//line sql.y:689
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:692
 		{
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node.PushTwo(yyDollar[2].node, yyDollar[4].node)
 		}
 	case 127:
-		yyVAL.node = yyS[yypt-0].node
-	case 128:
-		//line sql.y:696
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:696
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node.Push(yyDollar[2].node)
 		}
 	case 129:
-		yyVAL.node = yyS[yypt-0].node
-	case 130:
-		yyVAL.node = yyS[yypt-0].node
-	case 131:
-		yyVAL.node = yyS[yypt-0].node
-	case 132:
-		yyVAL.node = yyS[yypt-0].node
-	case 133:
-		//line sql.y:707
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:703
 		{
-			yyVAL.node = NewSimpleParseNode(GROUP, "group")
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 134:
-		//line sql.y:711
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:714
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(GROUP, "group")
 		}
 	case 135:
-		//line sql.y:716
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:718
 		{
-			yyVAL.node = NewSimpleParseNode(HAVING, "having")
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[3].node)
 		}
 	case 136:
-		//line sql.y:720
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:723
 		{
-			yyVAL.node = yyS[yypt-1].node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(HAVING, "having")
 		}
 	case 137:
-		//line sql.y:725
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:727
 		{
-			yyVAL.node = NewSimpleParseNode(ORDER, "order")
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 138:
-		//line sql.y:729
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:732
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(ORDER, "order")
 		}
 	case 139:
-		//line sql.y:735
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:736
 		{
-			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[3].node)
 		}
 	case 140:
-		//line sql.y:740
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:742
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
+			yyVAL.node.Push(yyDollar[1].node)
 		}
 	case 141:
-		//line sql.y:746
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:747
 		{
-			yyVAL.node = yyS[yypt-0].node.Push(yyS[yypt-1].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[3].node)
 		}
 	case 142:
-		//line sql.y:751
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:753
 		{
-			yyVAL.node = NewSimpleParseNode(ASC, "asc")
+			yyVAL.node = yyDollar[2].node.Push(yyDollar[1].node)
 		}
 	case 143:
-		yyVAL.node = yyS[yypt-0].node
-	case 144:
-		yyVAL.node = yyS[yypt-0].node
-	case 145:
-		//line sql.y:758
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:758
 		{
-			yyVAL.node = NewSimpleParseNode(LIMIT, "limit")
+			yyVAL.node = NewSimpleParseNode(ASC, "asc")
 		}
 	case 146:
-		//line sql.y:762
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:765
 		{
-			yyVAL.node = yyS[yypt-1].node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(LIMIT, "limit")
 		}
 	case 147:
-		//line sql.y:766
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:769
 		{
-			yyVAL.node = yyS[yypt-3].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[2].node)
 		}
 	case 148:
-		//line sql.y:771
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line sql.y:773
 		{
-			yyVAL.node = NewSimpleParseNode(NOT_FOR_UPDATE, "")
+			yyVAL.node = yyDollar[1].node.PushTwo(yyDollar[2].node, yyDollar[4].node)
 		}
 	case 149:
-		//line sql.y:775
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:778
 		{
-			yyVAL.node = NewSimpleParseNode(FOR_UPDATE, " for update")
+			yyVAL.node = NewSimpleParseNode(NOT_FOR_UPDATE, "")
 		}
 	case 150:
-		//line sql.y:780
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line sql.y:782
 		{
-			yyVAL.node = NewSimpleParseNode(COLUMN_LIST, "")
+			yyVAL.node = NewSimpleParseNode(FOR_UPDATE, " for update")
 		}
 	case 151:
-		//line sql.y:784
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:787
 		{
-			yyVAL.node = yyS[yypt-1].node
+			yyVAL.node = NewSimpleParseNode(COLUMN_LIST, "")
 		}
 	case 152:
-		//line sql.y:790
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:791
 		{
-			yyVAL.node = NewSimpleParseNode(COLUMN_LIST, "")
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node
 		}
 	case 153:
-		//line sql.y:795
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:797
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(COLUMN_LIST, "")
+			yyVAL.node.Push(yyDollar[1].node)
 		}
 	case 154:
-		//line sql.y:800
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:802
 		{
-			yyVAL.node = NewSimpleParseNode(DUPLICATE, "duplicate")
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[3].node)
 		}
 	case 155:
-		//line sql.y:804
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:807
 		{
-			yyVAL.node = yyS[yypt-3].node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(DUPLICATE, "duplicate")
 		}
 	case 156:
-		//line sql.y:810
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line sql.y:811
 		{
-			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
-			yyVAL.node.Push(yyS[yypt-0].node)
+			yyVAL.node = yyDollar[2].node.Push(yyDollar[5].node)
 		}
 	case 157:
-		//line sql.y:815
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line sql.y:817
 		{
-			yyVAL.node = yyS[yypt-2].node.Push(yyS[yypt-0].node)
+			yyVAL.node = NewSimpleParseNode(NODE_LIST, "node_list")
+			yyVAL.node.Push(yyDollar[1].node)
 		}
 	case 158:
-		//line sql.y:821
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:822
 		{
-			yyVAL.node = yyS[yypt-1].node.PushTwo(yyS[yypt-2].node, yyS[yypt-0].node)
+			yyVAL.node = yyDollar[1].node.Push(yyDollar[3].node)
 		}
 	case 159:
-		//line sql.y:826
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line sql.y:828
 		{
-			yyVAL.node = nil
+			yyVAL.node = yyDollar[2].node.PushTwo(yyDollar[1].node, yyDollar[3].node)
 		}
 	case 160:
-		yyVAL.node = yyS[yypt-0].node
-	case 161:
-		//line sql.y:830
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:833
 		{
 			yyVAL.node = nil
 		}
 	case 162:
-		yyVAL.node = yyS[yypt-0].node
-	case 163:
-		//line sql.y:834
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:837
 		{
 			yyVAL.node = nil
 		}
 	case 164:
-		yyVAL.node = yyS[yypt-0].node
-	case 165:
-		yyVAL.node = yyS[yypt-0].node
-	case 166:
-		yyVAL.node = yyS[yypt-0].node
-	case 167:
-		yyVAL.node = yyS[yypt-0].node
-	case 168:
-		yyVAL.node = yyS[yypt-0].node
-	case 169:
-		yyVAL.node = yyS[yypt-0].node
-	case 170:
-		//line sql.y:845
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:841
 		{
 			yyVAL.node = nil
 		}
 	case 171:
-		yyVAL.node = yyS[yypt-0].node
-	case 172:
-		//line sql.y:849
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:852
 		{
 			yyVAL.node = nil
 		}
 	case 173:
-		yyVAL.node = yyS[yypt-0].node
-	case 174:
-		//line sql.y:853
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:856
 		{
 			yyVAL.node = nil
 		}
 	case 175:
-		yyVAL.node = yyS[yypt-0].node
-	case 176:
-		//line sql.y:857
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:860
+		{
+			yyVAL.node = nil
+		}
+	case 177:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line sql.y:864
 		{
 			ForceEOF(yylex)
 		}
@@ -102,7 +102,7 @@ const (
 %left <node> UNION MINUS EXCEPT INTERSECT
 %left <node> ','
 %left <node> JOIN STRAIGHT_JOIN LEFT RIGHT INNER OUTER CROSS NATURAL USE
-%left <node> ON
+%left <node> ON USING
 %left <node> AND OR
 %right <node> NOT
 %left <node> '&' '|' '^'
@@ -115,7 +115,7 @@ const (
 
 // DDL Tokens
 %token <node> CREATE ALTER DROP RENAME
-%token <node> TABLE INDEX TO IGNORE IF UNIQUE USING
+%token <node> TABLE INDEX TO IGNORE IF UNIQUE
 
 %start any_command
 
@@ -341,10 +341,6 @@ table_expression_list:
 		$$ = NewSimpleParseNode(NODE_LIST, "node_list")
 		$$.Push($1)
 	}
-| '(' table_expression ')'
-	{
-		$$ = $1.Push($2)
-	}
 | table_expression_list ',' table_expression
 	{
 		$$.Push($3)
@@ -365,6 +361,10 @@ table_expression:
     $$.Push(NewSimpleParseNode(NODE_LIST, "node_list").Push($3))
     $$.Push($4)
 	}
+| '(' table_expression ')'
+	{
+		$$ = $1.Push($2)
+	}
 | table_expression join_type table_expression %prec JOIN
 	{
 		$$ = $2.PushTwo($1, $3)
@@ -374,7 +374,14 @@ table_expression:
 		$$ = $2
 		$$.Push($1)
 		$$.Push($3)
-		$$.Push($5)
+		$$.Push($4.Push($5))
+	}
+| table_expression join_type table_expression USING '(' column_list ')' %prec JOIN
+	{
+		$$ = $2
+		$$.Push($1)
+		$$.Push($3)
+		$$.Push($4.Push($6))
 	}
 
 join_type:
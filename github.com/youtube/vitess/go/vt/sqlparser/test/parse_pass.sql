@@ -8,6 +8,9 @@ select /* \0 */ '\0' from a
 select 1 /* drop this comment */ from t#select 1 from t
 select /* union */ 1 from t union select 1 from t
 select /* union all */ 1 from t union all select 1 from t
+select /* union all, union */ 1 from t union all select 1 from t union select 1 from t
+select /* union with where */ 1 from t where a = 1 union all select 1 from t where b = 2
+select /* union with limit */ 1 from t union all select 1 from t order by a asc limit 1
 select /* minus */ 1 from t minus select 1 from t
 select /* except */ 1 from t except select 1 from t
 select /* intersect */ 1 from t intersect select 1 from t
@@ -24,6 +27,8 @@ select /* case_when_else */ case when a = b then c else d end from t
 select /* case_when_when_else */ case when a = b then c when b = d then d else d end from t
 select /* case */ case aa when a = b then c end from t
 select /* parenthesis */ 1 from (t)
+select /* parenthesis, table list */ 1 from (t1), t2
+select /* parenthesis, join */ 1 from (t1 join t2)
 select /* table list */ 1 from t1, t2
 select /* use */ 1 from t1 use index (a) where b = 1
 select /* use */ 1 from t1 as t2 use index (a), t3 use index (b) where b = 1
@@ -39,8 +44,28 @@ select /* inner join */ 1 from t1 inner join t2#select /* inner join */ 1 from t
 select /* cross join */ 1 from t1 cross join t2
 select /* natural join */ 1 from t1 natural join t2
 select /* join on */ 1 from t1 join t2 on a = b
+select /* join on, and */ 1 from t1 join t2 on t1.a = t2.b and t1.c = t2.d
+select /* join on, parenthesis */ 1 from t1 join t2 on (a = b)
+select /* left join on */ 1 from t1 left join t2 on a = b
+select /* left outer join on */ 1 from t1 left outer join t2 on a = b#select /* left outer join on */ 1 from t1 left join t2 on a = b
+select /* right join on */ 1 from t1 right join t2 on a = b
+select /* straight_join on */ 1 from t1 straight_join t2 on a = b
+select /* join using */ 1 from t1 join t2 using (a)
+select /* join using list */ 1 from t1 join t2 using (a, b)
+select /* left join using */ 1 from t1 left join t2 using (a)
+select /* multiple joins */ 1 from t1 join t2 on a = b left join t3 on c = d
+select /* nested join */ 1 from t1 join (t2 join t3 on b = c) on a = b
+select /* nested left join */ 1 from t1 left join (t2 join t3 using (c)) on a = b
+select /* join with alias */ 1 from t1 as a join t2 as b on a.id = b.id
+select /* join with index hint */ 1 from t1 use index (a) join t2 on a = b
+select /* join, table list */ 1 from t1 join t2 on a = b, t3
 select /* s.t */ 1 from s.t
 select /* select in from */ 1 from (select 1 from t)
+select /* select in from with alias */ 1 from (select 1 from t) as a
+select /* select in from with alias, no as */ 1 from (select 1 from t) a#select /* select in from with alias, no as */ 1 from (select 1 from t) as a
+select /* union in from */ 1 from (select 1 from t union all select 1 from t) as a
+select /* join with select */ 1 from t1 join (select 1 from t2) as a on t1.a = a.b
+select /* nested select in from */ 1 from (select 1 from (select 1 from t) as a) as b
 select /* where */ 1 from t where a = b
 select /* and */ 1 from t where a = b and a = c
 select /* or */ 1 from t where a = b or a = c
@@ -49,6 +74,11 @@ select /* exists */ 1 from t where exists (select 1 from t)
 select /* (boolean) */ 1 from t where not (a = b)
 select /* in value list */ 1 from t where a in (b, c)
 select /* in select */ 1 from t where a in (select 1 from t)
+select /* not in select */ 1 from t where a not in (select 1 from t)
+select /* in union */ 1 from t where a in (select 1 from t union select 2 from t)
+select /* = select */ 1 from t where a = (select 1 from t)
+select /* nested in select */ 1 from t where a in (select 1 from t where b in (select 1 from t))
+select /* not exists */ 1 from t where not exists (select 1 from t)
 select /* not in */ 1 from t where a not in (b, c)
 select /* like */ 1 from t where a like b
 select /* not like */ 1 from t where a not like b
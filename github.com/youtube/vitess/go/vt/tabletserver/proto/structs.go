@@ -14,6 +14,10 @@ type SessionParams struct {
 	KeyRange key.KeyRange
 	Keyspace string
 	Shard    string
+
+	// TabletType is only used by the router, to pick the tablets
+	// to send the queries to. It defaults to master.
+	TabletType string
 }
 
 type SessionInfo struct {
@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/youtube/vitess/go/vt/key"
 	"github.com/youtube/vitess/go/vt/sqlparser"
@@ -46,6 +47,15 @@ func TestQueryRules(t *testing.T) {
 	if qrs.rules[0] != qr2 {
 		t.Errorf("want:\n%#v\nreceived:\n%#v", qr2, qrf)
 	}
+
+	// delete from the middle
+	qr3 := NewQueryRule("rule 3", "r3", QR_FAIL_QUERY)
+	qrs.Add(qr1)
+	qrs.Add(qr3)
+	qrs.Delete("r1")
+	if len(qrs.rules) != 2 || qrs.rules[0] != qr2 || qrs.rules[1] != qr3 {
+		t.Errorf("want [r2 r3], received %#v", qrs.rules)
+	}
 }
 
 // TestCopy tests for deep copy
@@ -493,6 +503,111 @@ func TestAction(t *testing.T) {
 	}
 }
 
+func TestThrottle(t *testing.T) {
+	qrs := NewQueryRules()
+	qr := NewQueryRule("rule 1", "r1", QR_THROTTLE)
+	qr.SetUserCond("user")
+	if err := qr.SetThrottle(1, 0, 0); err != nil {
+		t.Fatalf("SetThrottle: %v", err)
+	}
+	qrs.Add(qr)
+
+	// throttling rules don't fail queries by themselves
+	if action, _ := qrs.getAction("123", "user", nil); action != QR_CONTINUE {
+		t.Errorf("Expecting continue")
+	}
+
+	// the limit is shared by the copies of the rule
+	qrs1 := qrs.Copy()
+	acquired, err := qrs.throttle("123", "user", nil)
+	if err != nil || len(acquired) != 1 {
+		t.Fatalf("Expecting one throttler, received %v, %v", acquired, err)
+	}
+	if _, err := qrs1.throttle("123", "user", nil); err == nil {
+		t.Errorf("Expecting throttled query")
+	}
+	if nothing, err := qrs1.throttle("123", "user1", nil); err != nil || len(nothing) != 0 {
+		t.Errorf("Expecting no throttler, received %v, %v", nothing, err)
+	}
+	acquired.release()
+	acquired, err = qrs1.throttle("123", "user", nil)
+	if err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+	acquired.release()
+}
+
+func TestDelay(t *testing.T) {
+	qrs := NewQueryRules()
+	qr := NewQueryRule("rule 1", "r1", QR_DELAY)
+	if err := qr.SetThrottle(1, 0, 0); err == nil {
+		t.Errorf("Expecting error for missing MaxDelay")
+	}
+	if err := qr.SetThrottle(1, 0, 50*time.Millisecond); err != nil {
+		t.Fatalf("SetThrottle: %v", err)
+	}
+	qrs.Add(qr)
+
+	acquired, err := qrs.throttle("123", "user", nil)
+	if err != nil {
+		t.Fatalf("Unexpected: %v", err)
+	}
+
+	// a second query waits, and fails after MaxDelay
+	start := time.Now()
+	if _, err := qrs.throttle("123", "user", nil); err == nil {
+		t.Errorf("Expecting throttled query")
+	}
+	if d := time.Now().Sub(start); d < 50*time.Millisecond {
+		t.Errorf("Expecting a 50ms delay, got %v", d)
+	}
+
+	// a second query waits for the first one to be done
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		acquired.release()
+	}()
+	acquired, err = qrs.throttle("123", "user", nil)
+	if err != nil {
+		t.Errorf("Unexpected: %v", err)
+	}
+	acquired.release()
+}
+
+func TestRateLimit(t *testing.T) {
+	qr := NewQueryRule("rule 1", "r1", QR_THROTTLE)
+	if err := qr.SetThrottle(0, 2, 0); err != nil {
+		t.Fatalf("SetThrottle: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := qr.throttler.acquire(); err != nil {
+			t.Errorf("acquire %d: %v", i, err)
+		}
+	}
+	if err := qr.throttler.acquire(); err != errThrottled {
+		t.Errorf("Expecting errThrottled, received %v", err)
+	}
+
+	qr = NewQueryRule("rule 2", "r2", QR_DELAY)
+	if err := qr.SetThrottle(0, 20, time.Second); err != nil {
+		t.Fatalf("SetThrottle: %v", err)
+	}
+	start := time.Now()
+	for i := 0; i < 21; i++ {
+		if err := qr.throttler.acquire(); err != nil {
+			t.Errorf("acquire %d: %v", i, err)
+		}
+	}
+	if d := time.Now().Sub(start); d < 40*time.Millisecond {
+		t.Errorf("Expecting a 50ms delay, got %v", d)
+	}
+
+	qr = NewQueryRule("rule 3", "r3", QR_FAIL_QUERY)
+	if err := qr.SetThrottle(0, 20, 0); err == nil {
+		t.Errorf("Expecting error for FAIL_QUERY action")
+	}
+}
+
 var jsondata = `[{
 	"Description": "desc1",
 	"Name": "name1",
@@ -514,6 +629,12 @@ var jsondata = `[{
 },{
 	"Description": "desc2",
 	"Name": "name2"
+},{
+	"Name": "name3",
+	"Action": "DELAY",
+	"MaxConcurrency": 5,
+	"MaxQPS": 100,
+	"MaxDelay": 0.5
 }]`
 
 func TestImport(t *testing.T) {
@@ -591,6 +712,22 @@ func TestImport(t *testing.T) {
 	if qrs.rules[1].bindVarConds != nil {
 		t.Errorf("Expecting nil")
 	}
+	if qrs.rules[1].act != QR_FAIL_QUERY {
+		t.Errorf("Expecting %v, received %v", QR_FAIL_QUERY, qrs.rules[1].act)
+	}
+	if qrs.rules[1].throttler != nil {
+		t.Errorf("Expecting nil")
+	}
+	if qrs.rules[2].act != QR_DELAY {
+		t.Errorf("Expecting %v, received %v", QR_DELAY, qrs.rules[2].act)
+	}
+	rt := qrs.rules[2].throttler
+	if rt == nil {
+		t.Fatalf("Expecting non-nil")
+	}
+	if cap(rt.slots) != 5 || rt.rate != 100 || rt.maxDelay != 500*time.Millisecond {
+		t.Errorf("Expecting 5, 100, 500ms, received %v, %v, %v", cap(rt.slots), rt.rate, rt.maxDelay)
+	}
 }
 
 type ValidJSONCase struct {
@@ -688,6 +825,13 @@ var invalidjsons = []InvalidJSONCase{
 	{`[{"User": "[" }]`, "Could not set User condition: ["},
 	{`[{"Query": "[" }]`, "Could not set Query condition: ["},
 	{`[{"Plans": [1] }]`, "Expecting string for Plans"},
+	{`[{"Action": 1 }]`, "Expecting string for Action"},
+	{`[{"Action": "invalid" }]`, "Invalid action name: invalid"},
+	{`[{"MaxQPS": "1" }]`, "Expecting number for MaxQPS"},
+	{`[{"MaxQPS": 1 }]`, "Limits are only allowed for THROTTLE and DELAY actions"},
+	{`[{"Action": "THROTTLE" }]`, "MaxConcurrency or MaxQPS required for THROTTLE action"},
+	{`[{"Action": "THROTTLE", "MaxConcurrency": -1 }]`, "Limits cannot be negative"},
+	{`[{"Action": "DELAY", "MaxConcurrency": 1 }]`, "MaxDelay required for DELAY action"},
 	{`[{"Plans": ["invalid"] }]`, "Invalid plan name: invalid"},
 	{`[{"BindVarConds": [1] }]`, "Expecting json object for bind var conditions"},
 	{`[{"BindVarConds": [{}] }]`, "Name missing in BindVarConds"},
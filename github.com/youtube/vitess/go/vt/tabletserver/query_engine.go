@@ -203,21 +203,27 @@ func (qe *QueryEngine) Execute(logStats *sqlQueryStats, query *proto.Query) (rep
 	basePlan := qe.schemaInfo.GetPlan(logStats, query.Sql)
 	planName := basePlan.PlanId.String()
 	logStats.PlanType = planName
-	defer func(start time.Time) {
+	defer func(sql string, start time.Time) {
 		duration := time.Now().Sub(start)
 		queryStats.Add(planName, duration)
 		if reply == nil {
-			basePlan.AddStats(1, duration, 0, 1)
+			basePlan.AddStats(1, duration, 0, 1, logStats.CacheHits)
 		} else {
-			basePlan.AddStats(1, duration, int64(len(reply.Rows)), 0)
+			basePlan.AddStats(1, duration, int64(len(reply.Rows)), 0, logStats.CacheHits)
 		}
-	}(time.Now())
+		QueryStatsLogger.Send(newPerQueryStats(sql, basePlan))
+	}(query.Sql, time.Now())
 
 	// Run it by the rules engine
 	action, desc := basePlan.Rules.getAction(logStats.RemoteAddr(), logStats.Username(), query.BindVariables)
 	if action == QR_FAIL_QUERY {
 		panic(NewTabletError(FAIL, "Query disallowed due to rule: %s", desc))
 	}
+	throttlers, err := basePlan.Rules.throttle(logStats.RemoteAddr(), logStats.Username(), query.BindVariables)
+	if err != nil {
+		panic(err)
+	}
+	defer throttlers.release()
 
 	if basePlan.PlanId == sqlparser.PLAN_DDL {
 		return qe.execDDL(logStats, query.Sql)
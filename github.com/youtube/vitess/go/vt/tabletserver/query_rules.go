@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/youtube/vitess/go/vt/key"
 	"github.com/youtube/vitess/go/vt/sqlparser"
@@ -61,7 +62,7 @@ func (qrs *QueryRules) Find(name string) (qr *QueryRule) {
 func (qrs *QueryRules) Delete(name string) (qr *QueryRule) {
 	for i, qr := range qrs.rules {
 		if qr.Name == name {
-			for j := i; j < len(qrs.rules)-i-1; j++ {
+			for j := i; j < len(qrs.rules)-1; j++ {
 				qrs.rules[j] = qrs.rules[j+1]
 			}
 			qrs.rules = qrs.rules[:len(qrs.rules)-1]
@@ -103,12 +104,34 @@ func (qrs *QueryRules) filterByPlan(query string, planid sqlparser.PlanType) (ne
 func (qrs *QueryRules) getAction(ip, user string, bindVars map[string]interface{}) (action Action, desc string) {
 	for _, qr := range qrs.rules {
 		if qr.getAction(ip, user, bindVars) == QR_FAIL_QUERY {
+			qrFailedStats.Add(qr.Name, 1)
 			return QR_FAIL_QUERY, qr.Description
 		}
 	}
 	return QR_CONTINUE, ""
 }
 
+// throttle enforces the limits of all the QR_THROTTLE and QR_DELAY
+// rules that match the request. It may wait for QR_DELAY rules.
+// On success, the returned throttlers have to be released once the
+// query is done. On failure, nothing needs to be released.
+func (qrs *QueryRules) throttle(ip, user string, bindVars map[string]interface{}) (acquired ruleThrottlers, err error) {
+	for _, qr := range qrs.rules {
+		if qr.throttler == nil {
+			continue
+		}
+		if action := qr.getAction(ip, user, bindVars); action != QR_THROTTLE && action != QR_DELAY {
+			continue
+		}
+		if err = qr.throttler.acquire(); err != nil {
+			acquired.release()
+			return nil, NewTabletError(FAIL, "Query throttled due to rule: %s", qr.Description)
+		}
+		acquired = append(acquired, qr.throttler)
+	}
+	return acquired, nil
+}
+
 //-----------------------------------------------
 
 // QueryRule represents one rule (conditions-action).
@@ -119,6 +142,7 @@ func (qrs *QueryRules) getAction(ip, user string, bindVars map[string]interface{
 // all requests.
 // Every QueryRule has an associated Action. If all the conditions
 // of the QueryRule are met, then the Action is triggerred.
+// QR_THROTTLE and QR_DELAY rules also need limits, see SetThrottle.
 type QueryRule struct {
 	Description string
 	Name        string
@@ -133,12 +157,19 @@ type QueryRule struct {
 
 	// All BindVar conditions have to be fulfilled to make this true (AND)
 	bindVarConds []BindVarCond
+
+	// act is the action to perform when the rule fires.
+	act Action
+
+	// throttler enforces the limits of QR_THROTTLE and QR_DELAY rules.
+	// It is shared by all the copies of the rule, so the limits apply
+	// to all the plans the rule was filtered into.
+	throttler *ruleThrottler
 }
 
 // NewQueryRule creates a new QueryRule.
 func NewQueryRule(description, name string, act Action) (qr *QueryRule) {
-	// We ignore act because there's only one action right now
-	return &QueryRule{Description: description, Name: name}
+	return &QueryRule{Description: description, Name: name, act: act}
 }
 
 // Copy performs a deep copy of a QueryRule.
@@ -149,6 +180,8 @@ func (qr *QueryRule) Copy() (newqr *QueryRule) {
 		requestIP:   qr.requestIP,
 		user:        qr.user,
 		query:       qr.query,
+		act:         qr.act,
+		throttler:   qr.throttler,
 	}
 	if qr.plans != nil {
 		newqr.plans = make([]sqlparser.PlanType, len(qr.plans))
@@ -188,6 +221,33 @@ func (qr *QueryRule) SetQueryCond(pattern string) (err error) {
 	return
 }
 
+// SetThrottle sets the limits of a QR_THROTTLE or QR_DELAY rule.
+// maxConcurrency is the maximum number of matching queries that can
+// run at the same time, and maxQPS the maximum number of matching
+// queries that can start every second. Zero means no limit, but at
+// least one of them has to be set. Queries over the limits fail for
+// QR_THROTTLE rules. For QR_DELAY rules, they wait for up to maxDelay
+// before failing.
+func (qr *QueryRule) SetThrottle(maxConcurrency int, maxQPS float64, maxDelay time.Duration) error {
+	if qr.act != QR_THROTTLE && qr.act != QR_DELAY {
+		return NewTabletError(FAIL, "Limits are only allowed for THROTTLE and DELAY actions")
+	}
+	if maxConcurrency < 0 || maxQPS < 0 || maxDelay < 0 {
+		return NewTabletError(FAIL, "Limits cannot be negative")
+	}
+	if maxConcurrency == 0 && maxQPS == 0 {
+		return NewTabletError(FAIL, "MaxConcurrency or MaxQPS required for %v action", qr.act)
+	}
+	if qr.act == QR_DELAY && maxDelay == 0 {
+		return NewTabletError(FAIL, "MaxDelay required for %v action", qr.act)
+	}
+	if qr.act == QR_THROTTLE {
+		maxDelay = 0
+	}
+	qr.throttler = newRuleThrottler(qr.Name, maxConcurrency, maxQPS, maxDelay)
+	return nil
+}
+
 // makeExact forces a full string match for the regex instead of substring
 func makeExact(pattern string) string {
 	return fmt.Sprintf("^%s$", pattern)
@@ -286,7 +346,7 @@ func (qr *QueryRule) getAction(ip, user string, bindVars map[string]interface{})
 			return QR_CONTINUE
 		}
 	}
-	return QR_FAIL_QUERY
+	return qr.act
 }
 
 func reMatch(re *regexp.Regexp, val string) bool {
@@ -325,6 +385,24 @@ type Action int
 
 const QR_CONTINUE = Action(0)
 const QR_FAIL_QUERY = Action(1)
+const QR_THROTTLE = Action(2)
+const QR_DELAY = Action(3)
+
+var actionmap = map[string]Action{
+	"CONTINUE":   QR_CONTINUE,
+	"FAIL_QUERY": QR_FAIL_QUERY,
+	"THROTTLE":   QR_THROTTLE,
+	"DELAY":      QR_DELAY,
+}
+
+func (act Action) String() string {
+	for name, a := range actionmap {
+		if a == act {
+			return name
+		}
+	}
+	return fmt.Sprintf("Action(%d)", int(act))
+}
 
 // BindVarCond represents a bind var condition.
 type BindVarCond struct {
@@ -647,12 +725,16 @@ func getstring(val interface{}) (sv string, status int) {
 
 func buildQueryRule(ruleInfo map[string]interface{}) (qr *QueryRule, err error) {
 	qr = NewQueryRule("", "", QR_FAIL_QUERY)
+	// The limits can only be checked once the action is known.
+	var maxConcurrency, maxQPS, maxDelay float64
+	hasLimits := false
 	for k, v := range ruleInfo {
 		var sv string
 		var lv []interface{}
+		var fv float64
 		var ok bool
 		switch k {
-		case "Name", "Description", "RequestIP", "User", "Query":
+		case "Name", "Description", "RequestIP", "User", "Query", "Action":
 			sv, ok = v.(string)
 			if !ok {
 				return nil, NewTabletError(FAIL, "Expecting string for %s", k)
@@ -662,6 +744,12 @@ func buildQueryRule(ruleInfo map[string]interface{}) (qr *QueryRule, err error)
 			if !ok {
 				return nil, NewTabletError(FAIL, "Expecting list for %s", k)
 			}
+		case "MaxConcurrency", "MaxQPS", "MaxDelay":
+			fv, ok = v.(float64)
+			if !ok {
+				return nil, NewTabletError(FAIL, "Expecting number for %s", k)
+			}
+			hasLimits = true
 		default:
 			return nil, NewTabletError(FAIL, "unrecognized tag %s", k)
 		}
@@ -709,6 +797,22 @@ func buildQueryRule(ruleInfo map[string]interface{}) (qr *QueryRule, err error)
 					return nil, err
 				}
 			}
+		case "Action":
+			qr.act, ok = actionmap[sv]
+			if !ok {
+				return nil, NewTabletError(FAIL, "Invalid action name: %s", sv)
+			}
+		case "MaxConcurrency":
+			maxConcurrency = fv
+		case "MaxQPS":
+			maxQPS = fv
+		case "MaxDelay":
+			maxDelay = fv
+		}
+	}
+	if hasLimits || qr.act == QR_THROTTLE || qr.act == QR_DELAY {
+		if err = qr.SetThrottle(int(maxConcurrency), maxQPS, time.Duration(maxDelay*1e9)); err != nil {
+			return nil, err
 		}
 	}
 	return qr, nil
@@ -0,0 +1,181 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/youtube/vitess/go/streamlog"
+	"github.com/youtube/vitess/go/vt/sqlparser"
+)
+
+// QueryStatsLogger streams the updated stats of a query pattern
+// every time a query matching it completes.
+// Call QueryStatsLogger.ServeLogs in your main program to enable logging.
+var QueryStatsLogger = streamlog.New("QueryStats", 50)
+
+// perQueryStats is a snapshot of the stats of a normalized query,
+// as kept by its plan in the query cache.
+type perQueryStats struct {
+	Query      string
+	Table      string
+	Plan       sqlparser.PlanType
+	QueryCount int64
+	Time       time.Duration
+	MaxTime    time.Duration
+	RowCount   int64
+	ErrorCount int64
+	CacheHits  int64
+}
+
+func newPerQueryStats(query string, plan *ExecPlan) *perQueryStats {
+	pqstats := &perQueryStats{
+		Query: unicoded(query),
+		Table: plan.TableName,
+		Plan:  plan.PlanId,
+	}
+	pqstats.QueryCount, pqstats.Time, pqstats.MaxTime, pqstats.RowCount, pqstats.ErrorCount, pqstats.CacheHits = plan.Stats()
+	return pqstats
+}
+
+// AvgTime returns the mean execution time of the query.
+func (pqstats *perQueryStats) AvgTime() time.Duration {
+	if pqstats.QueryCount == 0 {
+		return 0
+	}
+	return pqstats.Time / time.Duration(pqstats.QueryCount)
+}
+
+// Format returns a tab separated list of the stats.
+func (pqstats *perQueryStats) Format(params url.Values) string {
+	return fmt.Sprintf(
+		"%q\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t\n",
+		pqstats.Query,
+		pqstats.Table,
+		pqstats.Plan,
+		pqstats.QueryCount,
+		pqstats.Time.Seconds(),
+		pqstats.MaxTime.Seconds(),
+		pqstats.RowCount,
+		pqstats.ErrorCount,
+		pqstats.CacheHits,
+	)
+}
+
+// queryStatsKeys are the values accepted by the sort parameter of
+// /debug/query_stats. Results are sorted in descending order.
+var queryStatsKeys = map[string]func(*perQueryStats) int64{
+	"QueryCount": func(s *perQueryStats) int64 { return s.QueryCount },
+	"Time":       func(s *perQueryStats) int64 { return int64(s.Time) },
+	"AvgTime":    func(s *perQueryStats) int64 { return int64(s.AvgTime()) },
+	"MaxTime":    func(s *perQueryStats) int64 { return int64(s.MaxTime) },
+	"RowCount":   func(s *perQueryStats) int64 { return s.RowCount },
+	"ErrorCount": func(s *perQueryStats) int64 { return s.ErrorCount },
+	"CacheHits":  func(s *perQueryStats) int64 { return s.CacheHits },
+}
+
+type queryStatsSorter struct {
+	stats []*perQueryStats
+	key   func(*perQueryStats) int64
+}
+
+func (qss *queryStatsSorter) Len() int      { return len(qss.stats) }
+func (qss *queryStatsSorter) Swap(i, j int) { qss.stats[i], qss.stats[j] = qss.stats[j], qss.stats[i] }
+func (qss *queryStatsSorter) Less(i, j int) bool {
+	return qss.key(qss.stats[i]) > qss.key(qss.stats[j])
+}
+
+// QueryStats returns the stats of all the queries in the query cache,
+// sorted in descending order by sortKey. An empty sortKey leaves them
+// in cache order.
+func (si *SchemaInfo) QueryStats(sortKey string) ([]*perQueryStats, error) {
+	var key func(*perQueryStats) int64
+	if sortKey != "" {
+		var ok bool
+		if key, ok = queryStatsKeys[sortKey]; !ok {
+			return nil, fmt.Errorf("invalid sort key: %s", sortKey)
+		}
+	}
+	keys := si.queries.Keys()
+	qstats := make([]*perQueryStats, 0, len(keys))
+	for _, v := range keys {
+		if plan := si.getQuery(v); plan != nil {
+			qstats = append(qstats, newPerQueryStats(v, plan))
+		}
+	}
+	if key != nil {
+		sort.Stable(&queryStatsSorter{qstats, key})
+	}
+	return qstats, nil
+}
+
+var queryStatsTemplate = template.Must(template.New("query_stats").Parse(`
+<html>
+<head><title>Query stats</title></head>
+<body>
+<table border="1" cellpadding="2">
+<tr>
+  <th>Query</th>
+  <th>Table</th>
+  <th>Plan</th>
+  <th><a href="?format=html&sort=QueryCount">Count</a></th>
+  <th><a href="?format=html&sort=Time">Time (s)</a></th>
+  <th><a href="?format=html&sort=AvgTime">Avg time (s)</a></th>
+  <th><a href="?format=html&sort=MaxTime">Max time (s)</a></th>
+  <th><a href="?format=html&sort=RowCount">Rows</a></th>
+  <th><a href="?format=html&sort=ErrorCount">Errors</a></th>
+  <th><a href="?format=html&sort=CacheHits">Cache hits</a></th>
+</tr>
+{{range .}}<tr>
+  <td>{{.Query}}</td>
+  <td>{{.Table}}</td>
+  <td>{{.Plan}}</td>
+  <td>{{.QueryCount}}</td>
+  <td>{{.Time.Seconds}}</td>
+  <td>{{.AvgTime.Seconds}}</td>
+  <td>{{.MaxTime.Seconds}}</td>
+  <td>{{.RowCount}}</td>
+  <td>{{.ErrorCount}}</td>
+  <td>{{.CacheHits}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// serveQueryStats serves /debug/query_stats. The stats are returned
+// as JSON, or as an HTML table if format=html. Use sort=<field> to
+// order them by one of the numeric fields.
+func (si *SchemaInfo) serveQueryStats(response http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+	qstats, err := si.QueryStats(request.FormValue("sort"))
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.FormValue("format") == "html" {
+		response.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := queryStatsTemplate.Execute(response, qstats); err != nil {
+			log.Errorf("query_stats: %v", err)
+		}
+		return
+	}
+	response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if b, err := json.MarshalIndent(qstats, "", "  "); err != nil {
+		response.Write([]byte(err.Error()))
+	} else {
+		response.Write(b)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/cache"
+	"github.com/youtube/vitess/go/vt/sqlparser"
+)
+
+func newTestStatsSchemaInfo() *SchemaInfo {
+	si := &SchemaInfo{queries: cache.NewLRUCache(10)}
+	plans := []struct {
+		sql   string
+		count int
+		d     time.Duration
+	}{
+		{"select a from t where id = :id", 3, time.Millisecond},
+		{"select b from t", 1, time.Second},
+		{"update t set a = 1 where id = :id", 2, 10 * time.Millisecond},
+	}
+	for _, p := range plans {
+		plan := &ExecPlan{ExecPlan: &sqlparser.ExecPlan{PlanId: sqlparser.PLAN_PASS_SELECT, TableName: "t"}}
+		for i := 0; i < p.count; i++ {
+			plan.AddStats(1, p.d*time.Duration(i+1), 1, 0, 2)
+		}
+		si.queries.Set(p.sql, plan)
+	}
+	return si
+}
+
+func TestExecPlanStats(t *testing.T) {
+	plan := &ExecPlan{ExecPlan: &sqlparser.ExecPlan{}}
+	plan.AddStats(1, 2*time.Second, 5, 0, 1)
+	plan.AddStats(1, time.Second, 0, 1, 0)
+	queryCount, duration, maxTime, rowCount, errorCount, cacheHits := plan.Stats()
+	if queryCount != 2 || duration != 3*time.Second || maxTime != 2*time.Second || rowCount != 5 || errorCount != 1 || cacheHits != 1 {
+		t.Errorf("got %v %v %v %v %v %v", queryCount, duration, maxTime, rowCount, errorCount, cacheHits)
+	}
+}
+
+func TestQueryStatsSort(t *testing.T) {
+	si := newTestStatsSchemaInfo()
+	testcases := []struct {
+		key  string
+		want string
+	}{
+		{"QueryCount", "select a from t where id = :id"},
+		{"Time", "select b from t"},
+		{"MaxTime", "select b from t"},
+		{"RowCount", "select a from t where id = :id"},
+		{"AvgTime", "select b from t"},
+	}
+	for _, tc := range testcases {
+		qstats, err := si.QueryStats(tc.key)
+		if err != nil {
+			t.Fatalf("QueryStats(%s): %v", tc.key, err)
+		}
+		if len(qstats) != 3 {
+			t.Fatalf("QueryStats(%s): got %d entries, want 3", tc.key, len(qstats))
+		}
+		if qstats[0].Query != tc.want {
+			t.Errorf("QueryStats(%s): got %q first, want %q", tc.key, qstats[0].Query, tc.want)
+		}
+	}
+	if _, err := si.QueryStats("Foo"); err == nil {
+		t.Errorf("QueryStats(Foo): want error")
+	}
+}
+
+func TestServeQueryStats(t *testing.T) {
+	si := newTestStatsSchemaInfo()
+
+	request, _ := http.NewRequest("GET", "/debug/query_stats?sort=MaxTime", nil)
+	response := httptest.NewRecorder()
+	si.ServeHTTP(response, request)
+	// PlanType marshals to its name, so Plan is read back as a string.
+	type jsonQueryStats struct {
+		Query      string
+		Table      string
+		Plan       string
+		QueryCount int64
+		Time       time.Duration
+		MaxTime    time.Duration
+		RowCount   int64
+		ErrorCount int64
+		CacheHits  int64
+	}
+	var qstats []jsonQueryStats
+	if err := json.Unmarshal(response.Body.Bytes(), &qstats); err != nil {
+		t.Fatalf("cannot unmarshal %s: %v", response.Body.String(), err)
+	}
+	if len(qstats) != 3 {
+		t.Fatalf("got %d entries, want 3", len(qstats))
+	}
+	want := jsonQueryStats{
+		Query:      "select b from t",
+		Table:      "t",
+		Plan:       "PASS_SELECT",
+		QueryCount: 1,
+		Time:       time.Second,
+		MaxTime:    time.Second,
+		RowCount:   1,
+		CacheHits:  2,
+	}
+	if qstats[0] != want {
+		t.Errorf("got\n%#v\nwant\n%#v", qstats[0], want)
+	}
+
+	request, _ = http.NewRequest("GET", "/debug/query_stats?format=html", nil)
+	response = httptest.NewRecorder()
+	si.ServeHTTP(response, request)
+	if body := response.Body.String(); !strings.Contains(body, "<td>select b from t</td>") {
+		t.Errorf("html output does not contain query:\n%s", body)
+	}
+
+	request, _ = http.NewRequest("GET", "/debug/query_stats?sort=Foo", nil)
+	response = httptest.NewRecorder()
+	si.ServeHTTP(response, request)
+	if response.Code != http.StatusBadRequest {
+		t.Errorf("got code %d, want %d", response.Code, http.StatusBadRequest)
+	}
+}
@@ -6,8 +6,10 @@ package tabletserver
 
 import (
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"sync"
 
 	log "github.com/golang/glog"
 	"github.com/youtube/vitess/go/jscfg"
@@ -20,6 +22,7 @@ import (
 var (
 	queryLogHandler = flag.String("query-log-stream-handler", "/debug/querylog", "URL handler for streaming queries log")
 	txLogHandler    = flag.String("transaction-log-stream-handler", "/debug/txlog", "URL handler for streaming transactions log")
+	qsLogHandler    = flag.String("query-stats-stream-handler", "/debug/querystats", "URL handler for streaming per-query stats")
 	qsConfigFile    = flag.String("queryserver-config-file", "", "config file name for the query service")
 	customRules     = flag.String("customrules", "", "custom query rules file")
 )
@@ -70,6 +73,7 @@ func RegisterQueryService(config Config) {
 	SqlQueryRpcService = NewSqlQuery(config)
 	proto.RegisterAuthenticated(SqlQueryRpcService)
 	http.HandleFunc("/debug/health", healthCheck)
+	http.HandleFunc("/debug/reload_custom_rules", reloadCustomRulesHandler)
 }
 
 // AllowQueries can take an indefinite amount of time to return because
@@ -141,6 +145,7 @@ func healthCheck(w http.ResponseWriter, r *http.Request) {
 func InitQueryService() {
 	SqlQueryLogger.ServeLogs(*queryLogHandler)
 	TxLogger.ServeLogs(*txLogHandler)
+	QueryStatsLogger.ServeLogs(*qsLogHandler)
 
 	qsConfig := DefaultQsConfig
 	if *qsConfigFile != "" {
@@ -152,22 +157,76 @@ func InitQueryService() {
 	RegisterQueryService(qsConfig)
 }
 
+// loadedCustomRules are the custom rules last returned by
+// LoadCustomRules or ReloadCustomRules, so they can be replaced
+// without touching the other rules in use.
+var loadedCustomRules struct {
+	sync.Mutex
+	qrs *QueryRules
+}
+
 // LoadCustomRules returns custom rules as specified by the command
 // line flags.
 func LoadCustomRules() (qrs *QueryRules) {
-	if *customRules == "" {
-		return NewQueryRules()
+	qrs, err := readCustomRules()
+	if err != nil {
+		log.Fatalf("%v", err)
 	}
 
-	data, err := ioutil.ReadFile(*customRules)
+	loadedCustomRules.Lock()
+	defer loadedCustomRules.Unlock()
+	loadedCustomRules.qrs = qrs.Copy()
+	return qrs
+}
+
+// ReloadCustomRules reads the custom rules file again, and replaces
+// the custom rules used by the query service with the new ones.
+// The other rules in use are kept. Custom rules are matched by name,
+// so they should have unique names.
+func ReloadCustomRules() error {
+	qrs, err := readCustomRules()
 	if err != nil {
-		log.Fatalf("Error reading file %v: %v", *customRules, err)
+		return err
+	}
+
+	loadedCustomRules.Lock()
+	defer loadedCustomRules.Unlock()
+	rules := GetQueryRules()
+	if loadedCustomRules.qrs != nil {
+		for _, qr := range loadedCustomRules.qrs.rules {
+			rules.Delete(qr.Name)
+		}
+	}
+	for _, qr := range qrs.rules {
+		rules.Add(qr)
 	}
+	SetQueryRules(rules)
+	loadedCustomRules.qrs = qrs
+	log.Infof("Reloaded %v custom rules from %v", len(qrs.rules), *customRules)
+	return nil
+}
 
+func readCustomRules() (qrs *QueryRules, err error) {
 	qrs = NewQueryRules()
-	err = qrs.UnmarshalJSON(data)
+	if *customRules == "" {
+		return qrs, nil
+	}
+
+	data, err := ioutil.ReadFile(*customRules)
 	if err != nil {
-		log.Fatalf("Error unmarshaling query rules %v", err)
+		return nil, fmt.Errorf("Error reading file %v: %v", *customRules, err)
 	}
-	return qrs
+	if err = qrs.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("Error unmarshaling query rules %v", err)
+	}
+	return qrs, nil
+}
+
+func reloadCustomRulesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	if err := ReloadCustomRules(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write([]byte("ok"))
 }
@@ -0,0 +1,145 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tabletserver
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/youtube/vitess/go/stats"
+)
+
+// Per-rule stats, indexed by rule name. They are kept across rule
+// reloads, as long as the rule keeps its name.
+var (
+	qrFailedStats    = stats.NewCounters("QueryRulesFailed")
+	qrThrottledStats = stats.NewCounters("QueryRulesThrottled")
+	qrDelayStats     = stats.NewTimings("QueryRulesDelays")
+)
+
+var errThrottled = errors.New("throttled")
+
+// ruleThrottler limits the number of concurrent queries, and the rate
+// at which they start, for one QueryRule. If maxDelay is zero,
+// queries over the limits are rejected right away. Otherwise, they
+// wait for up to maxDelay for the limits to allow them.
+type ruleThrottler struct {
+	name     string
+	maxDelay time.Duration
+
+	// slots holds one entry per running query. nil if the concurrency
+	// is not limited.
+	slots chan struct{}
+
+	// token bucket for the rate limit. rate is 0 if the rate is not
+	// limited. tokens can go negative when queries wait for their turn.
+	mu       sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newRuleThrottler(name string, maxConcurrency int, maxQPS float64, maxDelay time.Duration) *ruleThrottler {
+	rt := &ruleThrottler{
+		name:     name,
+		maxDelay: maxDelay,
+		rate:     maxQPS,
+	}
+	if maxConcurrency > 0 {
+		rt.slots = make(chan struct{}, maxConcurrency)
+	}
+	if maxQPS > 0 {
+		// allow bursts of up to one second worth of queries
+		rt.capacity = maxQPS
+		if rt.capacity < 1 {
+			rt.capacity = 1
+		}
+		rt.tokens = rt.capacity
+		rt.last = time.Now()
+	}
+	return rt
+}
+
+// acquire returns once the query is allowed to run, or errThrottled.
+// If it succeeds, release has to be called when the query is done.
+func (rt *ruleThrottler) acquire() error {
+	start := time.Now()
+	if rt.slots != nil {
+		select {
+		case rt.slots <- struct{}{}:
+		default:
+			if rt.maxDelay == 0 {
+				qrThrottledStats.Add(rt.name, 1)
+				return errThrottled
+			}
+			timer := time.NewTimer(rt.maxDelay)
+			select {
+			case rt.slots <- struct{}{}:
+				timer.Stop()
+			case <-timer.C:
+				qrThrottledStats.Add(rt.name, 1)
+				return errThrottled
+			}
+		}
+	}
+
+	if rt.rate > 0 {
+		wait, ok := rt.reserve(rt.maxDelay - time.Now().Sub(start))
+		if !ok {
+			rt.releaseSlot()
+			qrThrottledStats.Add(rt.name, 1)
+			return errThrottled
+		}
+		if wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+
+	if delay := time.Now().Sub(start); rt.maxDelay != 0 && delay > time.Millisecond {
+		qrDelayStats.Add(rt.name, delay)
+	}
+	return nil
+}
+
+// reserve takes a token from the bucket, and returns how long the
+// caller has to wait before using it. If that would be longer than
+// maxWait, it returns false and doesn't take the token.
+func (rt *ruleThrottler) reserve(maxWait time.Duration) (wait time.Duration, ok bool) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	now := time.Now()
+	rt.tokens += now.Sub(rt.last).Seconds() * rt.rate
+	if rt.tokens > rt.capacity {
+		rt.tokens = rt.capacity
+	}
+	rt.last = now
+
+	if rt.tokens < 1 {
+		wait = time.Duration((1 - rt.tokens) / rt.rate * 1e9)
+		if wait > maxWait {
+			return 0, false
+		}
+	}
+	rt.tokens--
+	return wait, true
+}
+
+func (rt *ruleThrottler) releaseSlot() {
+	if rt.slots != nil {
+		<-rt.slots
+	}
+}
+
+// ruleThrottlers is the list of throttlers acquired for a query.
+type ruleThrottlers []*ruleThrottler
+
+func (rts ruleThrottlers) release() {
+	for _, rt := range rts {
+		rt.releaseSlot()
+	}
+}
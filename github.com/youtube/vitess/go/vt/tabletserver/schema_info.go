@@ -34,29 +34,37 @@ type ExecPlan struct {
 	mu         sync.Mutex
 	QueryCount int64
 	Time       time.Duration
+	MaxTime    time.Duration
 	RowCount   int64
 	ErrorCount int64
+	CacheHits  int64
 }
 
 func (*ExecPlan) Size() int {
 	return 1
 }
 
-func (ep *ExecPlan) AddStats(queryCount int64, duration time.Duration, rowCount, errorCount int64) {
+func (ep *ExecPlan) AddStats(queryCount int64, duration time.Duration, rowCount, errorCount, cacheHits int64) {
 	ep.mu.Lock()
 	ep.QueryCount += queryCount
 	ep.Time += duration
+	if duration > ep.MaxTime {
+		ep.MaxTime = duration
+	}
 	ep.RowCount += rowCount
 	ep.ErrorCount += errorCount
+	ep.CacheHits += cacheHits
 	ep.mu.Unlock()
 }
 
-func (ep *ExecPlan) Stats() (queryCount int64, duration time.Duration, rowCount, errorCount int64) {
+func (ep *ExecPlan) Stats() (queryCount int64, duration, maxTime time.Duration, rowCount, errorCount, cacheHits int64) {
 	ep.mu.Lock()
 	queryCount = ep.QueryCount
 	duration = ep.Time
+	maxTime = ep.MaxTime
 	rowCount = ep.RowCount
 	errorCount = ep.ErrorCount
+	cacheHits = ep.CacheHits
 	ep.mu.Unlock()
 	return
 }
@@ -376,16 +384,6 @@ func (si *SchemaInfo) SetReloadTime(reloadTime time.Duration) {
 	si.ticks.SetInterval(reloadTime)
 }
 
-type perQueryStats struct {
-	Query      string
-	Table      string
-	Plan       sqlparser.PlanType
-	QueryCount int64
-	Time       time.Duration
-	RowCount   int64
-	ErrorCount int64
-}
-
 func (si *SchemaInfo) ServeHTTP(response http.ResponseWriter, request *http.Request) {
 	if request.URL.Path == "/debug/query_plans" {
 		keys := si.queries.Keys()
@@ -403,24 +401,7 @@ func (si *SchemaInfo) ServeHTTP(response http.ResponseWriter, request *http.Requ
 			}
 		}
 	} else if request.URL.Path == "/debug/query_stats" {
-		keys := si.queries.Keys()
-		response.Header().Set("Content-Type", "application/json; charset=utf-8")
-		qstats := make([]perQueryStats, 0, len(keys))
-		for _, v := range keys {
-			if plan := si.getQuery(v); plan != nil {
-				var pqstats perQueryStats
-				pqstats.Query = unicoded(v)
-				pqstats.Table = plan.TableName
-				pqstats.Plan = plan.PlanId
-				pqstats.QueryCount, pqstats.Time, pqstats.RowCount, pqstats.ErrorCount = plan.Stats()
-				qstats = append(qstats, pqstats)
-			}
-		}
-		if b, err := json.MarshalIndent(qstats, "", "  "); err != nil {
-			response.Write([]byte(err.Error()))
-		} else {
-			response.Write(b)
-		}
+		si.serveQueryStats(response, request)
 	} else if request.URL.Path == "/debug/table_stats" {
 		response.Header().Set("Content-Type", "application/json; charset=utf-8")
 		si.mu.Lock()
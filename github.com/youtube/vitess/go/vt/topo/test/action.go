@@ -0,0 +1,145 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+func createTestTablet(t *testing.T, ts topo.Server) topo.TabletAlias {
+	tablet := &topo.Tablet{
+		Cell:     getLocalCell(t, ts),
+		Uid:      1,
+		Addr:     "localhost:3333",
+		Keyspace: "test_keyspace",
+		Shard:    "0",
+		Type:     topo.TYPE_IDLE,
+		State:    topo.STATE_READ_ONLY,
+	}
+	if err := ts.CreateTablet(tablet); err != nil {
+		t.Fatalf("CreateTablet: %v", err)
+	}
+	return tablet.Alias()
+}
+
+func CheckTabletActions(t *testing.T, ts topo.Server) {
+	tabletAlias := createTestTablet(t, ts)
+	if err := ts.ValidateTabletActions(tabletAlias); err != nil {
+		t.Errorf("ValidateTabletActions: %v", err)
+	}
+
+	actionPath, err := ts.WriteTabletAction(tabletAlias, "contents1")
+	if err != nil {
+		t.Fatalf("WriteTabletAction: %v", err)
+	}
+
+	alias, data, version, err := ts.ReadTabletActionPath(actionPath)
+	if err != nil {
+		t.Fatalf("ReadTabletActionPath: %v", err)
+	}
+	if alias != tabletAlias || data != "contents1" {
+		t.Errorf("ReadTabletActionPath: got %v %v, want %v %v", alias, data, tabletAlias, "contents1")
+	}
+	if err := ts.UpdateTabletAction(actionPath, "contents2", version); err != nil {
+		t.Errorf("UpdateTabletAction: %v", err)
+	}
+	if err := ts.UpdateTabletAction(actionPath, "contents3", version); err != topo.ErrBadVersion {
+		t.Errorf("UpdateTabletAction(old version): %v", err)
+	}
+
+	// nobody processes the action yet
+	if _, err := ts.WaitForTabletAction(actionPath, 100*time.Millisecond, nil); err != topo.ErrTimeout {
+		t.Errorf("WaitForTabletAction(no response): %v", err)
+	}
+	interrupted := make(chan struct{})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(interrupted)
+	}()
+	if _, err := ts.WaitForTabletAction(actionPath, 5*time.Second, interrupted); err != topo.ErrInterrupted {
+		t.Errorf("WaitForTabletAction(interrupted): %v", err)
+	}
+
+	// start the action loop, it processes the queued action, and
+	// a new one
+	done := make(chan struct{})
+	loopDone := make(chan struct{})
+	dispatched := make(chan string, 10)
+	go func() {
+		ts.ActionEventLoop(tabletAlias, func(actionPath, data string) error {
+			dispatched <- data
+			if err := ts.StoreTabletActionResponse(actionPath, data+"-response"); err != nil {
+				return err
+			}
+			return ts.UnblockTabletAction(actionPath)
+		}, done)
+		close(loopDone)
+	}()
+
+	response, err := ts.WaitForTabletAction(actionPath, 5*time.Second, nil)
+	if err != nil {
+		t.Errorf("WaitForTabletAction: %v", err)
+	}
+	if response != "contents2-response" {
+		t.Errorf("WaitForTabletAction: got %v", response)
+	}
+
+	actionPath, err = ts.WriteTabletAction(tabletAlias, "contents4")
+	if err != nil {
+		t.Fatalf("WriteTabletAction: %v", err)
+	}
+	response, err = ts.WaitForTabletAction(actionPath, 5*time.Second, nil)
+	if err != nil {
+		t.Errorf("WaitForTabletAction: %v", err)
+	}
+	if response != "contents4-response" {
+		t.Errorf("WaitForTabletAction: got %v", response)
+	}
+	close(done)
+	<-loopDone
+
+	if len(dispatched) != 2 || <-dispatched != "contents2" || <-dispatched != "contents4" {
+		t.Errorf("ActionEventLoop dispatched the wrong actions")
+	}
+
+	// queue two actions, and purge one of them
+	for _, contents := range []string{"keep", "purge"} {
+		if _, err := ts.WriteTabletAction(tabletAlias, contents); err != nil {
+			t.Fatalf("WriteTabletAction: %v", err)
+		}
+	}
+	if err := ts.PurgeTabletActions(tabletAlias, func(data string) bool {
+		return data == "purge"
+	}); err != nil {
+		t.Errorf("PurgeTabletActions: %v", err)
+	}
+	if err := ts.PurgeTabletActions(tabletAlias, func(data string) bool {
+		if data != "keep" {
+			t.Errorf("PurgeTabletActions: unexpected action left: %v", data)
+		}
+		return true
+	}); err != nil {
+		t.Errorf("PurgeTabletActions: %v", err)
+	}
+}
+
+func CheckPid(t *testing.T, ts topo.Server) {
+	tabletAlias := createTestTablet(t, ts)
+	if err := ts.ValidateTabletPidNode(tabletAlias); err == nil {
+		t.Errorf("ValidateTabletPidNode worked without a pid node")
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	if err := ts.CreateTabletPidNode(tabletAlias, done); err != nil {
+		t.Fatalf("CreateTabletPidNode: %v", err)
+	}
+	if err := ts.ValidateTabletPidNode(tabletAlias); err != nil {
+		t.Errorf("ValidateTabletPidNode: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package test contains utilities to test topo.Server
+// implementations. Every implementation should pass all the Check*
+// functions, in a test like:
+//
+//	func TestKeyspace(t *testing.T) {
+//		ts := newTestServer(t, []string{"test"})
+//		defer ts.Close()
+//		test.CheckKeyspace(t, ts)
+//	}
+//
+// The functions use the first known cell of the Server, and expect it
+// to be empty.
+package test
+
+import (
+	"testing"
+
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+func CheckKeyspace(t *testing.T, ts topo.Server) {
+	keyspaces, err := ts.GetKeyspaces()
+	if err == nil && len(keyspaces) != 0 {
+		t.Errorf("len(GetKeyspaces()) != 0: %v", keyspaces)
+	}
+
+	if err := ts.CreateKeyspace("test_keyspace"); err != nil {
+		t.Errorf("CreateKeyspace: %v", err)
+	}
+	if err := ts.CreateKeyspace("test_keyspace"); err != topo.ErrNodeExists {
+		t.Errorf("CreateKeyspace(again) is not ErrNodeExists: %v", err)
+	}
+
+	keyspaces, err = ts.GetKeyspaces()
+	if err != nil {
+		t.Errorf("GetKeyspaces: %v", err)
+	}
+	if len(keyspaces) != 1 || keyspaces[0] != "test_keyspace" {
+		t.Errorf("GetKeyspaces: want %v, got %v", []string{"test_keyspace"}, keyspaces)
+	}
+
+	if err := ts.CreateKeyspace("test_keyspace2"); err != nil {
+		t.Errorf("CreateKeyspace: %v", err)
+	}
+	keyspaces, err = ts.GetKeyspaces()
+	if err != nil {
+		t.Errorf("GetKeyspaces: %v", err)
+	}
+	if len(keyspaces) != 2 ||
+		keyspaces[0] != "test_keyspace" ||
+		keyspaces[1] != "test_keyspace2" {
+		t.Errorf("GetKeyspaces: want %v, got %v", []string{"test_keyspace", "test_keyspace2"}, keyspaces)
+	}
+}
+
+func CheckShard(t *testing.T, ts topo.Server) {
+	if err := ts.CreateKeyspace("test_keyspace"); err != nil {
+		t.Fatalf("CreateKeyspace: %v", err)
+	}
+
+	if err := topo.CreateShard(ts, "test_keyspace", "B0-C0"); err != nil {
+		t.Fatalf("CreateShard: %v", err)
+	}
+	if err := topo.CreateShard(ts, "test_keyspace", "B0-C0"); err != topo.ErrNodeExists {
+		t.Errorf("CreateShard called second time, got: %v", err)
+	}
+
+	if _, err := ts.GetShard("test_keyspace", "666"); err != topo.ErrNoNode {
+		t.Errorf("GetShard(666): %v", err)
+	}
+
+	si, err := ts.GetShard("test_keyspace", "B0-C0")
+	if err != nil {
+		t.Fatalf("GetShard: %v", err)
+	}
+	if si.Keyspace() != "test_keyspace" || si.ShardName() != "B0-C0" {
+		t.Errorf("GetShard returned the wrong shard: %v/%v", si.Keyspace(), si.ShardName())
+	}
+	if _, keyRange, _ := topo.ValidateShardName("B0-C0"); si.KeyRange != keyRange {
+		t.Errorf("GetShard returned the wrong KeyRange: %v, want %v", si.KeyRange, keyRange)
+	}
+
+	master := topo.TabletAlias{Cell: "ny", Uid: 1}
+	si.MasterAlias = master
+	if err := ts.UpdateShard(si); err != nil {
+		t.Errorf("UpdateShard: %v", err)
+	}
+
+	si, err = ts.GetShard("test_keyspace", "B0-C0")
+	if err != nil {
+		t.Fatalf("GetShard: %v", err)
+	}
+	if si.MasterAlias != master {
+		t.Errorf("after UpdateShard: MasterAlias is %v, want %v", si.MasterAlias, master)
+	}
+
+	if err := ts.ValidateShard("test_keyspace", "B0-C0"); err != nil {
+		t.Errorf("ValidateShard: %v", err)
+	}
+
+	shards, err := ts.GetShardNames("test_keyspace")
+	if err != nil {
+		t.Errorf("GetShardNames: %v", err)
+	}
+	if len(shards) != 1 || shards[0] != "B0-C0" {
+		t.Errorf(`GetShardNames: want [%v], got %v`, "B0-C0", shards)
+	}
+
+	if _, err := ts.GetShardNames("test_keyspace666"); err != topo.ErrNoNode {
+		t.Errorf("GetShardNames(666): %v", err)
+	}
+
+	if err := ts.DeleteKeyspaceShards("test_keyspace"); err != nil {
+		t.Errorf("DeleteKeyspaceShards: %v", err)
+	}
+	if _, err := ts.GetShard("test_keyspace", "B0-C0"); err != topo.ErrNoNode {
+		t.Errorf("GetShard after DeleteKeyspaceShards: %v", err)
+	}
+}
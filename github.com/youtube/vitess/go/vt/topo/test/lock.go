@@ -0,0 +1,86 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+func CheckKeyspaceLock(t *testing.T, ts topo.Server) {
+	if err := ts.CreateKeyspace("test_keyspace"); err != nil {
+		t.Fatalf("CreateKeyspace: %v", err)
+	}
+
+	checkLock(t, func(timeout time.Duration, interrupted chan struct{}) (string, error) {
+		return ts.LockKeyspaceForAction("test_keyspace", "fake-content", timeout, interrupted)
+	}, func(lockPath string) error {
+		return ts.UnlockKeyspaceForAction("test_keyspace", lockPath, "fake-results")
+	})
+}
+
+func CheckShardLock(t *testing.T, ts topo.Server) {
+	if err := ts.CreateKeyspace("test_keyspace"); err != nil {
+		t.Fatalf("CreateKeyspace: %v", err)
+	}
+	if err := topo.CreateShard(ts, "test_keyspace", "10-20"); err != nil {
+		t.Fatalf("CreateShard: %v", err)
+	}
+
+	checkLock(t, func(timeout time.Duration, interrupted chan struct{}) (string, error) {
+		return ts.LockShardForAction("test_keyspace", "10-20", "fake-content", timeout, interrupted)
+	}, func(lockPath string) error {
+		return ts.UnlockShardForAction("test_keyspace", "10-20", lockPath, "fake-results")
+	})
+}
+
+// checkLock checks a lock can only be taken once at a time, that
+// taking it times out and can be interrupted, and that it can be
+// taken again after it has been released.
+func checkLock(t *testing.T, lock func(timeout time.Duration, interrupted chan struct{}) (string, error), unlock func(lockPath string) error) {
+	interrupted := make(chan struct{})
+	lockPath, err := lock(time.Second, interrupted)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+
+	// a second lock times out
+	if _, err := lock(100*time.Millisecond, interrupted); err != topo.ErrTimeout {
+		t.Errorf("lock(again): %v", err)
+	}
+
+	// and can be interrupted
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(interrupted)
+	}()
+	if _, err := lock(5*time.Second, interrupted); err != topo.ErrInterrupted {
+		t.Errorf("lock(interrupted): %v", err)
+	}
+
+	// a lock waiting for the first one gets it once it is released
+	result := make(chan error)
+	go func() {
+		lockPath, err := lock(5*time.Second, nil)
+		if err == nil {
+			err = unlock(lockPath)
+		}
+		result <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := unlock(lockPath); err != nil {
+		t.Errorf("unlock: %v", err)
+	}
+	if err := <-result; err != nil {
+		t.Errorf("lock(after unlock): %v", err)
+	}
+
+	// unlocking again fails
+	if err := unlock(lockPath); err == nil {
+		t.Error("unlock(again) worked")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+func CheckServingGraph(t *testing.T, ts topo.Server) {
+	cell := getLocalCell(t, ts)
+
+	if _, err := ts.GetSrvTabletTypesPerShard(cell, "test_keyspace", "-10"); err != topo.ErrNoNode {
+		t.Errorf("GetSrvTabletTypesPerShard(invalid): %v", err)
+	}
+	if _, err := ts.GetSrvTabletType(cell, "test_keyspace", "-10", topo.TYPE_REPLICA); err != topo.ErrNoNode {
+		t.Errorf("GetSrvTabletType(invalid): %v", err)
+	}
+
+	addrs := topo.NewAddrs()
+	addrs.Entries = append(addrs.Entries, *topo.NewAddr(1, "host1", 1234))
+	if err := ts.UpdateSrvTabletType(cell, "test_keyspace", "-10", topo.TYPE_MASTER, addrs); err != nil {
+		t.Fatalf("UpdateSrvTabletType: %v", err)
+	}
+
+	types, err := ts.GetSrvTabletTypesPerShard(cell, "test_keyspace", "-10")
+	if err != nil {
+		t.Errorf("GetSrvTabletTypesPerShard: %v", err)
+	}
+	if len(types) != 1 || types[0] != topo.TYPE_MASTER {
+		t.Errorf("GetSrvTabletTypesPerShard: want [%v], got %v", topo.TYPE_MASTER, types)
+	}
+
+	addrs, err = ts.GetSrvTabletType(cell, "test_keyspace", "-10", topo.TYPE_MASTER)
+	if err != nil {
+		t.Fatalf("GetSrvTabletType: %v", err)
+	}
+	if len(addrs.Entries) != 1 || addrs.Entries[0].Uid != 1 || addrs.Entries[0].Host != "host1" {
+		t.Errorf("GetSrvTabletType: bad result %v", addrs.Entries)
+	}
+
+	// UpdateTabletEndpoint changes existing entries, and adds new ones
+	if err := ts.UpdateTabletEndpoint(cell, "test_keyspace", "-10", topo.TYPE_MASTER, topo.NewAddr(1, "host1", 4321)); err != nil {
+		t.Errorf("UpdateTabletEndpoint(existing): %v", err)
+	}
+	if err := ts.UpdateTabletEndpoint(cell, "test_keyspace", "-10", topo.TYPE_MASTER, topo.NewAddr(2, "host2", 1234)); err != nil {
+		t.Errorf("UpdateTabletEndpoint(new): %v", err)
+	}
+	addrs, err = ts.GetSrvTabletType(cell, "test_keyspace", "-10", topo.TYPE_MASTER)
+	if err != nil {
+		t.Fatalf("GetSrvTabletType: %v", err)
+	}
+	if len(addrs.Entries) != 2 || addrs.Entries[0].Port != 4321 || addrs.Entries[1].Host != "host2" {
+		t.Errorf("GetSrvTabletType after UpdateTabletEndpoint: bad result %v", addrs.Entries)
+	}
+
+	// UpdateTabletEndpoint doesn't add a type that doesn't exist yet
+	if err := ts.UpdateTabletEndpoint(cell, "test_keyspace", "-10", topo.TYPE_REPLICA, topo.NewAddr(3, "host3", 1234)); err != nil {
+		t.Errorf("UpdateTabletEndpoint(missing type): %v", err)
+	}
+	if _, err := ts.GetSrvTabletType(cell, "test_keyspace", "-10", topo.TYPE_REPLICA); err != topo.ErrNoNode {
+		t.Errorf("GetSrvTabletType(replica): %v", err)
+	}
+
+	srvShard := &topo.SrvShard{
+		ServedTypes: []topo.TabletType{topo.TYPE_MASTER},
+		ReadOnly:    true,
+	}
+	if err := ts.UpdateSrvShard(cell, "test_keyspace", "-10", srvShard); err != nil {
+		t.Errorf("UpdateSrvShard: %v", err)
+	}
+	if _, err := ts.GetSrvShard(cell, "test_keyspace", "666"); err != topo.ErrNoNode {
+		t.Errorf("GetSrvShard(invalid): %v", err)
+	}
+	srvShard, err = ts.GetSrvShard(cell, "test_keyspace", "-10")
+	if err != nil {
+		t.Fatalf("GetSrvShard: %v", err)
+	}
+	if len(srvShard.ServedTypes) != 1 || srvShard.ServedTypes[0] != topo.TYPE_MASTER || !srvShard.ReadOnly {
+		t.Errorf("GetSrvShard: bad result %v", srvShard)
+	}
+
+	srvKeyspace := &topo.SrvKeyspace{
+		TabletTypes: []topo.TabletType{topo.TYPE_MASTER},
+	}
+	if err := ts.UpdateSrvKeyspace(cell, "test_keyspace", srvKeyspace); err != nil {
+		t.Errorf("UpdateSrvKeyspace: %v", err)
+	}
+	if _, err := ts.GetSrvKeyspace(cell, "test_keyspace666"); err != topo.ErrNoNode {
+		t.Errorf("GetSrvKeyspace(invalid): %v", err)
+	}
+	srvKeyspace, err = ts.GetSrvKeyspace(cell, "test_keyspace")
+	if err != nil {
+		t.Fatalf("GetSrvKeyspace: %v", err)
+	}
+	if len(srvKeyspace.TabletTypes) != 1 || srvKeyspace.TabletTypes[0] != topo.TYPE_MASTER {
+		t.Errorf("GetSrvKeyspace: bad result %v", srvKeyspace)
+	}
+
+	// the serving graph for the shard is still there
+	if _, err := ts.GetSrvTabletType(cell, "test_keyspace", "-10", topo.TYPE_MASTER); err != nil {
+		t.Errorf("GetSrvTabletType after UpdateSrvShard: %v", err)
+	}
+
+	if err := ts.DeleteSrvTabletType(cell, "test_keyspace", "-10", topo.TYPE_MASTER); err != nil {
+		t.Errorf("DeleteSrvTabletType: %v", err)
+	}
+	if _, err := ts.GetSrvTabletType(cell, "test_keyspace", "-10", topo.TYPE_MASTER); err != topo.ErrNoNode {
+		t.Errorf("GetSrvTabletType(deleted): %v", err)
+	}
+}
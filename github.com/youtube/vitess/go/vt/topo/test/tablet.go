@@ -0,0 +1,199 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package test
+
+import (
+	"testing"
+
+	"github.com/youtube/vitess/go/vt/topo"
+)
+
+// getLocalCell returns the first known cell of the Server.
+func getLocalCell(t *testing.T, ts topo.Server) string {
+	cells, err := ts.GetKnownCells()
+	if err != nil {
+		t.Fatalf("GetKnownCells: %v", err)
+	}
+	if len(cells) < 1 {
+		t.Fatalf("provided topo.Server doesn't have enough cells (need at least 1): %v", cells)
+	}
+	return cells[0]
+}
+
+func CheckTablet(t *testing.T, ts topo.Server) {
+	cell := getLocalCell(t, ts)
+	tablet := &topo.Tablet{
+		Cell:        cell,
+		Uid:         1,
+		Parent:      topo.TabletAlias{},
+		Addr:        "localhost:3333",
+		MysqlAddr:   "localhost:3334",
+		MysqlIpAddr: "10.11.12.13:3334",
+		Keyspace:    "test_keyspace",
+		Type:        topo.TYPE_MASTER,
+		State:       topo.STATE_READ_WRITE,
+	}
+	if err := ts.CreateTablet(tablet); err != nil {
+		t.Fatalf("CreateTablet: %v", err)
+	}
+	if err := ts.CreateTablet(tablet); err != topo.ErrNodeExists {
+		t.Errorf("CreateTablet(again): %v", err)
+	}
+
+	if _, err := ts.GetTablet(topo.TabletAlias{Cell: cell, Uid: 666}); err != topo.ErrNoNode {
+		t.Errorf("GetTablet(666): %v", err)
+	}
+
+	ti, err := ts.GetTablet(tablet.Alias())
+	if err != nil {
+		t.Fatalf("GetTablet %v: %v", tablet.Alias(), err)
+	}
+	if ti.Tablet.Alias() != tablet.Alias() {
+		t.Errorf("ti.Tablet.Alias is not equal to tablet.Alias: %v and %v", ti.Tablet.Alias(), tablet.Alias())
+	}
+	if ti.Addr != tablet.Addr {
+		t.Errorf("ti.Addr: want %v, got %v", tablet.Addr, ti.Addr)
+	}
+
+	if err := ts.ValidateTablet(tablet.Alias()); err != nil {
+		t.Errorf("ValidateTablet: %v", err)
+	}
+
+	inCell, err := ts.GetTabletsByCell(cell)
+	if err != nil {
+		t.Errorf("GetTabletsByCell: %v", err)
+	}
+	if len(inCell) != 1 || inCell[0] != tablet.Alias() {
+		t.Errorf("GetTabletsByCell: want [%v], got %v", tablet.Alias(), inCell)
+	}
+
+	// the first update makes sure we get a version we can check
+	ti.State = topo.STATE_READ_ONLY
+	if err := topo.UpdateTablet(ts, ti); err != nil {
+		t.Errorf("UpdateTablet: %v", err)
+	}
+
+	ti, err = ts.GetTablet(tablet.Alias())
+	if err != nil {
+		t.Fatalf("GetTablet %v: %v", tablet.Alias(), err)
+	}
+	if ti.State != topo.STATE_READ_ONLY {
+		t.Errorf("ti.State: want %v, got %v", topo.STATE_READ_ONLY, ti.State)
+	}
+	oldTi, err := ts.GetTablet(tablet.Alias())
+	if err != nil {
+		t.Fatalf("GetTablet %v: %v", tablet.Alias(), err)
+	}
+
+	ti.Addr = "127.0.0.1:3333"
+	if err := topo.UpdateTablet(ts, ti); err != nil {
+		t.Errorf("UpdateTablet: %v", err)
+	}
+	if err := topo.UpdateTablet(ts, oldTi); err != topo.ErrBadVersion {
+		t.Errorf("UpdateTablet(old version): %v", err)
+	}
+
+	if err := ts.UpdateTabletFields(tablet.Alias(), func(tt *topo.Tablet) error {
+		tt.State = topo.STATE_READ_WRITE
+		return nil
+	}); err != nil {
+		t.Errorf("UpdateTabletFields: %v", err)
+	}
+	ti, err = ts.GetTablet(tablet.Alias())
+	if err != nil {
+		t.Fatalf("GetTablet %v: %v", tablet.Alias(), err)
+	}
+	if ti.State != topo.STATE_READ_WRITE || ti.Addr != "127.0.0.1:3333" {
+		t.Errorf("after UpdateTabletFields: got State %v and Addr %v", ti.State, ti.Addr)
+	}
+
+	if err := ts.DeleteTablet(tablet.Alias()); err != nil {
+		t.Errorf("DeleteTablet: %v", err)
+	}
+	if err := ts.DeleteTablet(tablet.Alias()); err != topo.ErrNoNode {
+		t.Errorf("DeleteTablet(again): %v", err)
+	}
+	if _, err := ts.GetTablet(tablet.Alias()); err != topo.ErrNoNode {
+		t.Errorf("GetTablet: expected error, tablet was deleted: %v", err)
+	}
+}
+
+func CheckReplicationPaths(t *testing.T, ts topo.Server) {
+	if err := ts.CreateKeyspace("test_keyspace"); err != nil {
+		t.Fatalf("CreateKeyspace: %v", err)
+	}
+	if err := topo.CreateShard(ts, "test_keyspace", "0"); err != nil {
+		t.Fatalf("CreateShard: %v", err)
+	}
+
+	if _, err := ts.GetReplicationPaths("test_keyspace", "-10", "/"); err != topo.ErrNoNode {
+		t.Errorf("GetReplicationPaths(bad shard): %v", err)
+	}
+
+	paths, err := ts.GetReplicationPaths("test_keyspace", "0", "/")
+	if err != nil {
+		t.Errorf("GetReplicationPaths(empty shard): %v", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("GetReplicationPaths(empty shard): want nothing, got %v", paths)
+	}
+
+	master := topo.TabletAlias{Cell: "cell1", Uid: 1}
+	slave := topo.TabletAlias{Cell: "cell1", Uid: 2}
+	if err := ts.CreateReplicationPath("test_keyspace", "0", "/"+master.String()); err != nil {
+		t.Fatalf("CreateReplicationPath: %v", err)
+	}
+	if err := ts.CreateReplicationPath("test_keyspace", "0", "/"+master.String()); err != topo.ErrNodeExists {
+		t.Errorf("CreateReplicationPath(again): %v", err)
+	}
+	if err := ts.CreateReplicationPath("test_keyspace", "0", "/"+master.String()+"/"+slave.String()); err != nil {
+		t.Fatalf("CreateReplicationPath: %v", err)
+	}
+
+	paths, err = ts.GetReplicationPaths("test_keyspace", "0", "/")
+	if err != nil {
+		t.Errorf("GetReplicationPaths: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != master {
+		t.Errorf("GetReplicationPaths: want [%v], got %v", master, paths)
+	}
+
+	paths, err = ts.GetReplicationPaths("test_keyspace", "0", "/"+master.String())
+	if err != nil {
+		t.Errorf("GetReplicationPaths: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != slave {
+		t.Errorf("GetReplicationPaths: want [%v], got %v", slave, paths)
+	}
+
+	aliases, err := topo.FindAllTabletAliasesInShard(ts, "test_keyspace", "0")
+	if err != nil {
+		t.Errorf("FindAllTabletAliasesInShard: %v", err)
+	}
+	if len(aliases) != 2 {
+		t.Errorf("FindAllTabletAliasesInShard: want [%v %v], got %v", master, slave, aliases)
+	}
+
+	if err := ts.DeleteReplicationPath("test_keyspace", "0", "/"+master.String()); err != topo.ErrNotEmpty {
+		t.Errorf("DeleteReplicationPath(master with slaves): %v", err)
+	}
+	if err := ts.DeleteReplicationPath("test_keyspace", "0", "/"+master.String()+"/"+slave.String()); err != nil {
+		t.Errorf("DeleteReplicationPath(slave): %v", err)
+	}
+	if err := ts.DeleteReplicationPath("test_keyspace", "0", "/"+master.String()); err != nil {
+		t.Errorf("DeleteReplicationPath(master): %v", err)
+	}
+	if err := ts.DeleteReplicationPath("test_keyspace", "0", "/"+master.String()); err != topo.ErrNoNode {
+		t.Errorf("DeleteReplicationPath(again): %v", err)
+	}
+
+	paths, err = ts.GetReplicationPaths("test_keyspace", "0", "/")
+	if err != nil {
+		t.Errorf("GetReplicationPaths: %v", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("GetReplicationPaths: want nothing, got %v", paths)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2013, Google Inc. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package zktopo
+
+import (
+	"os"
+	"testing"
+
+	"github.com/youtube/vitess/go/vt/topo"
+	"github.com/youtube/vitess/go/vt/topo/test"
+	"github.com/youtube/vitess/go/zk"
+	"github.com/youtube/vitess/go/zk/fakezk"
+	"launchpad.net/gozk/zookeeper"
+)
+
+// newTestServer returns a Server using fakezk, with the cells listed
+// in test_zk_client.json.
+func newTestServer(t *testing.T) topo.Server {
+	os.Setenv("ZK_CLIENT_CONFIG", "test_zk_client.json")
+	zconn := fakezk.NewConn()
+	for _, zkPath := range []string{"/zk/test/vt", "/zk/global/vt"} {
+		if _, err := zk.CreateRecursive(zconn, zkPath, "", 0, zookeeper.WorldACL(zookeeper.PERM_ALL)); err != nil {
+			t.Fatalf("cannot init fake zk: %v", err)
+		}
+	}
+	return NewServer(zconn)
+}
+
+func TestKeyspace(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckKeyspace(t, ts)
+}
+
+func TestShard(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckShard(t, ts)
+}
+
+func TestTablet(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckTablet(t, ts)
+}
+
+func TestReplicationPaths(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckReplicationPaths(t, ts)
+}
+
+func TestServingGraph(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckServingGraph(t, ts)
+}
+
+func TestKeyspaceLock(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckKeyspaceLock(t, ts)
+}
+
+func TestShardLock(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckShardLock(t, ts)
+}
+
+func TestTabletActions(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckTabletActions(t, ts)
+}
+
+func TestPid(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+	test.CheckPid(t, ts)
+}
@@ -200,6 +200,7 @@ func (conn *zconn) Create(zkPath, value string, flags int, aclv []zookeeper.ACL)
 	for _, watch := range node.childrenWatches {
 		watch <- childrenEvent
 	}
+	node.childrenWatches = nil
 
 	node.cversion++
 
@@ -272,6 +273,7 @@ func (conn *zconn) Delete(zkPath string, version int) (err error) {
 	for _, watch := range parent.childrenWatches {
 		watch <- childrenEvent
 	}
+	parent.childrenWatches = nil
 	return nil
 }
 
@@ -399,6 +399,9 @@ class TestNocache(framework.TestCase):
       self.assertEqual(stat["RowCount"], rows)
       self.assertEqual(stat["ErrorCount"], errors)
       self.assertTrue(stat["Time"] > 0)
+      self.assertTrue(stat["MaxTime"] > 0)
+      self.assertTrue(stat["MaxTime"] <= stat["Time"])
+      self.assertEqual(stat["CacheHits"], 0)
       return
     self.fail("query %s not found" % query)
 